@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"sync"
 	"time"
 
@@ -16,10 +17,19 @@ import (
 )
 
 var (
-	errDivisionByZero = errors.New("деление на ноль")
-	errFirstNil       = errors.New("первый оператор не может быть nil")
+	errFirstNil  = errors.New("первый оператор не может быть nil")
+	errSecondNil = errors.New("второй оператор не может быть nil")
 )
 
+// calculation - результат вычисления задачи.
+type calculation struct {
+	value   float64    // Результат вычисления
+	exact   *big.Rat   // Точный результат (только для точных задач)
+	decimal *big.Float // Результат произвольной точности (только для десятичных задач)
+	imag    float64    // Мнимая часть результата (только для комплексных задач)
+	upper   *float64   // Верхняя граница результата (только для интервальных задач)
+}
+
 // Worker представляет собой рабочего, выполняющего задачи.
 type Worker struct {
 	errChan  chan error                   // Канал для отправки ошибок, возникающих при выполнении задач.
@@ -107,6 +117,17 @@ func (w *Worker) Start(ctx context.Context) {
 				Operation:  resp.GetOperation(),
 				Expression: resp.GetExpression(),
 				Error:      resp.GetError(),
+				Exact:      resp.GetExact(),
+				Precision:  int(resp.GetPrecision()),
+				ExactArgs:  convertExactArgs(resp.GetExactArgs()),
+				Complex:    resp.GetComplex(),
+				ImagArgs:   convertArgs(resp.GetImagArgs()),
+				Interval:   resp.GetInterval(),
+				UpperArgs:  convertArgs(resp.GetUpperArgs()),
+				Seed:       resp.GetSeed(),
+			}
+			if task.Precision > 0 {
+				task.DecimalArgs = convertDecimalArgs(resp.GetDecimalArgs())
 			}
 			logger.Log.Debugf("Рабочий %d: Получена задача %d", w.workerID, task.ID)
 			waiting = true //  Устанавливаем флаг, что воркер снова готов к выполнению задач
@@ -116,8 +137,8 @@ func (w *Worker) Start(ctx context.Context) {
 			defer cancel()
 
 			// Запускаем вычисление в горутине
-			resultChan := make(chan float64, 1) // Канал для результата
-			errorChan := make(chan error, 1)    // Канал для ошибок
+			resultChan := make(chan calculation, 1) // Канал для результата
+			errorChan := make(chan error, 1)        // Канал для ошибок
 
 			go func(t *models.TaskResponse) {
 				//  Обеспечиваем, что если возникла паника, ее можно было перехватить (recover)
@@ -129,17 +150,37 @@ func (w *Worker) Start(ctx context.Context) {
 					}
 				}()
 
-				result, err := Calculate(t) // Вычисляем задачу
+				var calculated calculation
+				var err error
+				switch {
+				case t.Precision > 0:
+					calculated.value, calculated.decimal, err = CalculateDecimal(t) // Вычисляем десятичную задачу
+				case t.Complex:
+					var value complex128
+					value, err = CalculateComplex(t) // Вычисляем комплексную задачу
+					calculated.value, calculated.imag = real(value), imag(value)
+				case t.Interval:
+					var value operators.Interval
+					value, err = CalculateInterval(t) // Вычисляем интервальную задачу
+					calculated.value, calculated.upper = value.Lo, &value.Hi
+				default:
+					calculated.value, calculated.exact, err = Calculate(t) // Вычисляем задачу
+				}
 				if err != nil {
 					errorChan <- err // Отправляем ошибку в канал ошибок
 					return
 				}
-				resultChan <- result // Отправляем результат в канал
+				resultChan <- calculated // Отправляем результат в канал
 			}(task)
 
-			var result float64 // Переменная для хранения результата
+			var result float64     // Переменная для хранения результата
+			var exact *big.Rat     // Переменная для хранения точного результата
+			var decimal *big.Float // Переменная для хранения результата произвольной точности
+			var imag float64       // Переменная для хранения мнимой части результата
+			var upper *float64     // Переменная для хранения верхней границы результата
 			select {
-			case result = <-resultChan:
+			case calculated := <-resultChan:
+				result, exact, decimal, imag, upper = calculated.value, calculated.exact, calculated.decimal, calculated.imag, calculated.upper
 				// Успешное завершение вычисления
 				<-taskCtx.Done() //  Ждем, пока истечет таймаут (если задача выполнилась слишком быстро)
 				logger.Log.Debugf("Рабочий %d: Задача %d успешно выполнена", w.workerID, task.ID)
@@ -152,21 +193,39 @@ func (w *Worker) Start(ctx context.Context) {
 
 			//  Проверка на значения +Inf и -Inf
 			if math.IsInf(result, 1) {
-				result = 0
+				result, exact = 0, nil
 				task.Error = "Результат - +Inf"
 			}
 
 			if math.IsInf(result, -1) {
-				result = 0
+				result, exact = 0, nil
 				task.Error = "Результат - -Inf"
 			}
 
+			if math.IsInf(imag, 0) {
+				result, imag = 0, 0
+				task.Error = "Мнимая часть результата - бесконечность"
+			}
+
+			if upper != nil && math.IsInf(*upper, 0) {
+				result, upper = 0, nil
+				task.Error = "Верхняя граница результата - бесконечность"
+			}
+
 			// Формируем сообщение с результатом для отправки
 			completedTask := &pb.TaskCompleted{
-				Expression: task.Expression,
-				Id:         task.ID,
-				Result:     result,
-				Error:      task.Error,
+				Expression:  task.Expression,
+				Id:          task.ID,
+				Result:      result,
+				Error:       task.Error,
+				ExactResult: pb.NewRational(exact),
+				ImagResult:  imag,
+			}
+			if upper != nil && task.Error == "" {
+				completedTask.UpperResult = &pb.WrappedDouble{Value: upper}
+			}
+			if decimal != nil {
+				completedTask.DecimalResult = operators.FormatDecimalDigits(decimal, task.Precision+operators.GuardDigits)
 			}
 
 			//  Отправляем результат в оркестратор
@@ -182,8 +241,10 @@ func (w *Worker) Start(ctx context.Context) {
 	}
 }
 
-// Calculate выполняет математическую операцию над двумя аргументами, указанными в задаче.
-// Если второй аргумент равен nil, выполняется унарный минус для первого аргумента.
+// Calculate выполняет математическую операцию над аргументами, указанными в задаче.
+// Количество используемых аргументов и функция вычисления берутся из реестра операций.
+// Функции случайных чисел вычисляются по зерну задачи.
+// Точные задачи вычисляются над точными аргументами в рациональной арифметике.
 //
 // Args:
 //
@@ -192,49 +253,215 @@ func (w *Worker) Start(ctx context.Context) {
 // Returns:
 //
 //	float64 - Результат выполнения операции.
+//	*big.Rat - Точный результат выполнения операции. nil, если задача не точная.
 //	error - Ошибка, если операция не может быть выполнена (например, деление на ноль или неизвестная операция).
-func Calculate(task *models.TaskResponse) (float64, error) {
-	var arg1, arg2 float64
+func Calculate(task *models.TaskResponse) (float64, *big.Rat, error) {
+	if task.Exact {
+		return calculateExact(task)
+	}
 
-	// Nil попадает в операнд только если оператором является унарный минус.
-	// При этом число которое необходимо обратить всегда первый операнд.
-	if task.Args[0] == nil {
+	// Первый операнд присутствует у любой операции, кроме функций без аргументов (rand).
+	// Количество операндов определяется операцией, а для функций с переменным числом аргументов - задачей.
+	operator, ok := operators.Lookup(task.Operation)
+	if (!ok || operator.ArgCount(len(task.Args)) > 0) && task.Args[0] == nil {
 		// Первый оператор никогда не может быть nil
-		return 0, errFirstNil
+		return 0, nil, errFirstNil
 	}
-	arg1 = *task.Args[0]
-	// Если 2-й операнд - nil, то операнд всегда унарный минус
-	if task.Args[1] == nil {
-		return -*task.Args[0], nil
+	if !ok {
+		return 0, nil, fmt.Errorf("неизвестный оператор: %s", task.Operation)
 	}
-	arg2 = *task.Args[1]
 
-	switch task.Operation {
+	args := make([]float64, operator.ArgCount(len(task.Args)))
+	for i := range args {
+		if i >= len(task.Args) || task.Args[i] == nil {
+			return 0, nil, errSecondNil
+		}
+		args[i] = *task.Args[i]
+	}
 
-	case operators.OpAdd:
-		return arg1 + arg2, nil
+	if operator.Random != nil {
+		// Функция случайных чисел вычисляется по зерну задачи, поэтому результат не зависит от агента
+		result, err := operator.Random(task.Seed, args...)
+		return result, nil, err
+	}
+	result, err := operator.Eval(args...)
+	return result, nil, err
+}
 
-	case operators.OpSubtract:
-		return arg1 - arg2, nil
+// calculateExact выполняет математическую операцию над точными аргументами задачи.
+//
+// Args:
+//
+//	task: (*models.TaskResponse) - Точная задача, содержащая аргументы в виде дробей "num/den" и операцию.
+//
+// Returns:
+//
+//	float64 - Ближайшее к точному результату число float64.
+//	*big.Rat - Точный результат выполнения операции.
+//	error - Ошибка, если операция не может быть выполнена точно или аргумент записан неверно.
+func calculateExact(task *models.TaskResponse) (float64, *big.Rat, error) {
+	if len(task.ExactArgs) == 0 || task.ExactArgs[0] == nil {
+		return 0, nil, errFirstNil
+	}
 
-	case operators.OpMultiply:
-		return arg1 * arg2, nil
+	operator, ok := operators.Lookup(task.Operation)
+	if !ok {
+		return 0, nil, fmt.Errorf("неизвестный оператор: %s", task.Operation)
+	}
+	if operator.Exact == nil {
+		return 0, nil, fmt.Errorf("операция %s не поддерживает точный режим", task.Operation)
+	}
 
-	case operators.OpDivide:
-		if arg2 == 0 {
-			return 0, errDivisionByZero
+	args := make([]*big.Rat, operator.ArgCount(len(task.ExactArgs)))
+	for i := range args {
+		if i >= len(task.ExactArgs) || task.ExactArgs[i] == nil {
+			return 0, nil, errSecondNil
+		}
+		arg, err := operators.ParseExact(*task.ExactArgs[i])
+		if err != nil {
+			return 0, nil, err
 		}
-		return arg1 / arg2, nil
+		args[i] = arg
+	}
 
-	case operators.OpPower:
-		return math.Pow(arg1, arg2), nil
+	exact, err := operator.Exact(args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	result, _ := exact.Float64()
+	return result, exact, nil
+}
+
+// CalculateDecimal выполняет математическую операцию над десятичными аргументами задачи с произвольной точностью.
+// Вычисления ведутся с точностью задачи и запасными цифрами operators.GuardDigits.
+//
+// Args:
+//
+//	task: (*models.TaskResponse) - Десятичная задача, содержащая аргументы в виде десятичных записей, операцию и точность.
+//
+// Returns:
+//
+//	float64 - Ближайшее к результату число float64 (по модулю не больше math.MaxFloat64).
+//	*big.Float - Результат выполнения операции.
+//	error - Ошибка, если операция не может быть выполнена или аргумент записан неверно.
+func CalculateDecimal(task *models.TaskResponse) (float64, *big.Float, error) {
+	if len(task.DecimalArgs) == 0 || task.DecimalArgs[0] == nil {
+		return 0, nil, errFirstNil
 	}
 
-	return 0, fmt.Errorf("неизвестный оператор: %s", task.Operation)
+	operator, ok := operators.Lookup(task.Operation)
+	if !ok {
+		return 0, nil, fmt.Errorf("неизвестный оператор: %s", task.Operation)
+	}
+	if operator.Decimal == nil {
+		return 0, nil, fmt.Errorf("операция %s не поддерживает десятичный режим", task.Operation)
+	}
+
+	prec := operators.PrecisionBits(task.Precision + operators.GuardDigits)
+	args := make([]*big.Float, operator.ArgCount(len(task.DecimalArgs)))
+	for i := range args {
+		if i >= len(task.DecimalArgs) || task.DecimalArgs[i] == nil {
+			return 0, nil, errSecondNil
+		}
+		arg, err := operators.ParseDecimal(*task.DecimalArgs[i], prec)
+		if err != nil {
+			return 0, nil, err
+		}
+		args[i] = arg
+	}
+
+	decimal, err := operator.Decimal(prec, args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	result, _ := decimal.Float64()
+	if math.IsInf(result, 0) {
+		// Результат вне диапазона float64 передается только десятичной записью
+		result = math.Copysign(math.MaxFloat64, result)
+	}
+	return result, decimal, nil
+}
+
+// CalculateComplex выполняет математическую операцию над комплексными аргументами задачи.
+// Действительные части аргументов берутся из Args, мнимые - из ImagArgs (отсутствующая мнимая часть равна 0).
+//
+// Args:
+//
+//	task: (*models.TaskResponse) - Комплексная задача, содержащая аргументы и операцию.
+//
+// Returns:
+//
+//	complex128 - Результат выполнения операции.
+//	error - Ошибка, если операция не может быть выполнена (например, деление на ноль или логарифм нуля).
+func CalculateComplex(task *models.TaskResponse) (complex128, error) {
+	if len(task.Args) == 0 || task.Args[0] == nil {
+		return 0, errFirstNil
+	}
+
+	operator, ok := operators.Lookup(task.Operation)
+	if !ok {
+		return 0, fmt.Errorf("неизвестный оператор: %s", task.Operation)
+	}
+	if operator.Complex == nil {
+		return 0, fmt.Errorf("операция %s не поддерживает комплексный режим", task.Operation)
+	}
+
+	args := make([]complex128, operator.ArgCount(len(task.Args)))
+	for i := range args {
+		if i >= len(task.Args) || task.Args[i] == nil {
+			return 0, errSecondNil
+		}
+		var imag float64
+		if i < len(task.ImagArgs) && task.ImagArgs[i] != nil {
+			imag = *task.ImagArgs[i]
+		}
+		args[i] = complex(*task.Args[i], imag)
+	}
+
+	return operator.Complex(args...)
+}
+
+// CalculateInterval выполняет математическую операцию над интервальными аргументами задачи.
+// Нижние границы аргументов берутся из Args, верхние - из UpperArgs (отсутствующая верхняя граница
+// равна нижней: аргумент без погрешности).
+//
+// Args:
+//
+//	task: (*models.TaskResponse) - Интервальная задача, содержащая аргументы и операцию.
+//
+// Returns:
+//
+//	operators.Interval - Интервал, гарантированно содержащий результат операции.
+//	error - Ошибка, если операция не может быть выполнена (например, делитель содержит ноль).
+func CalculateInterval(task *models.TaskResponse) (operators.Interval, error) {
+	if len(task.Args) == 0 || task.Args[0] == nil {
+		return operators.Interval{}, errFirstNil
+	}
+
+	operator, ok := operators.Lookup(task.Operation)
+	if !ok {
+		return operators.Interval{}, fmt.Errorf("неизвестный оператор: %s", task.Operation)
+	}
+	if operator.Interval == nil {
+		return operators.Interval{}, fmt.Errorf("операция %s не поддерживает интервальный режим", task.Operation)
+	}
+
+	args := make([]operators.Interval, operator.ArgCount(len(task.Args)))
+	for i := range args {
+		if i >= len(task.Args) || task.Args[i] == nil {
+			return operators.Interval{}, errSecondNil
+		}
+		args[i] = operators.Degenerate(*task.Args[i])
+		if i < len(task.UpperArgs) && task.UpperArgs[i] != nil {
+			args[i].Hi = *task.UpperArgs[i]
+		}
+	}
+
+	return operator.Interval(args...)
 }
 
 // calcOperationTime возвращает длительность выполнения для указанной математической операции.
-// Время выполнения берется из конфигурации приложения.
+// Время выполнения берется из параметра конфигурации, указанного в реестре операций.
 //
 // Args:
 //
@@ -244,32 +471,18 @@ func Calculate(task *models.TaskResponse) (float64, error) {
 //
 //	time.Duration - Длительность выполнения операции в миллисекундах
 func calcOperationTime(operation string) time.Duration {
-	var timeMs int
-
-	switch operation {
-	case operators.OpAdd:
-		timeMs = config.Cfg.Math.TIME_ADDITION_MS
-		break
-	case operators.OpSubtract:
-		timeMs = config.Cfg.Math.TIME_SUBTRACTION_MS
-		break
-	case operators.OpMultiply:
-		timeMs = config.Cfg.Math.TIME_MULTIPLICATION_MS
-		break
-	case operators.OpDivide:
-		timeMs = config.Cfg.Math.TIME_DIVISION_MS
-		break
-	case operators.OpPower:
-		timeMs = config.Cfg.Math.TIME_POWER_MS
-		break
-	case operators.OpUnaryMinus:
-		timeMs = config.Cfg.Math.TIME_UNARY_MINUS_MS
-		break
-	default:
+	operator, ok := operators.Lookup(operation)
+	if !ok {
 		logger.Log.Warnf("Оператор %s не найден", operation)
 		return 0
 	}
 
+	timeMs, ok := config.Cfg.Math.OperationTime(operator.TimeKey)
+	if !ok {
+		logger.Log.Warnf("Время выполнения оператора %s (%s) не задано", operation, operator.TimeKey)
+		return 0
+	}
+
 	return time.Duration(timeMs) * time.Millisecond
 }
 
@@ -291,3 +504,44 @@ func convertArgs(pbArgs []*pb.WrappedDouble) []*float64 {
 	}
 	return goArgs
 }
+
+// convertExactArgs преобразует срез указателей на pb.WrappedRational в срез точных записей аргументов "num/den".
+//
+// Args:
+//
+//	pbArgs: []*pb.WrappedRational - Срез указателей на WrappedRational из proto-файла.
+//
+// Returns:
+//
+//	[]*string - Срез точных записей аргументов. Элемент равен nil, если значение не передано или записано неверно.
+func convertExactArgs(pbArgs []*pb.WrappedRational) []*string {
+	goArgs := make([]*string, len(pbArgs))
+	for i, arg := range pbArgs {
+		value, err := arg.GetValue().Rat()
+		if err != nil {
+			continue
+		}
+		exact := operators.FormatExact(value)
+		goArgs[i] = &exact
+	}
+	return goArgs
+}
+
+// convertDecimalArgs преобразует десятичные записи аргументов из proto-файла в срез указателей на строки.
+//
+// Args:
+//
+//	pbArgs: []string - Десятичные записи аргументов. Пустая строка означает, что аргумент не передан.
+//
+// Returns:
+//
+//	[]*string - Срез десятичных записей аргументов. Элемент равен nil, если значение не передано.
+func convertDecimalArgs(pbArgs []string) []*string {
+	goArgs := make([]*string, len(pbArgs))
+	for i, arg := range pbArgs {
+		if arg != "" {
+			goArgs[i] = &pbArgs[i]
+		}
+	}
+	return goArgs
+}
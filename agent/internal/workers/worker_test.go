@@ -6,12 +6,14 @@ import (
 	"github.com/OinkiePie/calc_3/agent/internal/workers"
 	"github.com/OinkiePie/calc_3/config"
 	"github.com/OinkiePie/calc_3/pkg/logger"
+	"github.com/OinkiePie/calc_3/pkg/models"
 	"github.com/OinkiePie/calc_3/pkg/operators"
 	pb "github.com/OinkiePie/calc_3/pkg/proto"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 	"io"
 	"log"
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -213,6 +215,86 @@ func TestWorker(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "calculation sqrt",
+			setupMock: func() *MockOrchestratorClient {
+				return &MockOrchestratorClient{
+					GetTaskFunc: func(ctx context.Context, empty *pb.Empty) (*pb.TaskResponse, error) {
+						return &pb.TaskResponse{
+							Id:         1,
+							Args:       []*pb.WrappedDouble{{Value: float64Ptr(16)}, nil},
+							Operation:  operators.FnSqrt,
+							Expression: 1,
+						}, nil
+					},
+					SubmitResultFunc: func(ctx context.Context, completed *pb.TaskCompleted) (*pb.Empty, error) {
+						assert.Equal(t, 4.0, completed.Result)
+						return &pb.Empty{}, nil
+					},
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "calculation abs",
+			setupMock: func() *MockOrchestratorClient {
+				return &MockOrchestratorClient{
+					GetTaskFunc: func(ctx context.Context, empty *pb.Empty) (*pb.TaskResponse, error) {
+						return &pb.TaskResponse{
+							Id:         1,
+							Args:       []*pb.WrappedDouble{{Value: float64Ptr(-2.5)}, nil},
+							Operation:  operators.FnAbs,
+							Expression: 1,
+						}, nil
+					},
+					SubmitResultFunc: func(ctx context.Context, completed *pb.TaskCompleted) (*pb.Empty, error) {
+						assert.Equal(t, 2.5, completed.Result)
+						return &pb.Empty{}, nil
+					},
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "calculation error sqrt of negative",
+			setupMock: func() *MockOrchestratorClient {
+				return &MockOrchestratorClient{
+					GetTaskFunc: func(ctx context.Context, empty *pb.Empty) (*pb.TaskResponse, error) {
+						return &pb.TaskResponse{
+							Id:         1,
+							Args:       []*pb.WrappedDouble{{Value: float64Ptr(-4)}, nil},
+							Operation:  operators.FnSqrt,
+							Expression: 1,
+						}, nil
+					},
+					SubmitResultFunc: func(ctx context.Context, completed *pb.TaskCompleted) (*pb.Empty, error) {
+						assert.Equal(t, "корень из отрицательного числа", completed.Error)
+						return &pb.Empty{}, nil
+					},
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "calculation error log of zero",
+			setupMock: func() *MockOrchestratorClient {
+				return &MockOrchestratorClient{
+					GetTaskFunc: func(ctx context.Context, empty *pb.Empty) (*pb.TaskResponse, error) {
+						return &pb.TaskResponse{
+							Id:         1,
+							Args:       []*pb.WrappedDouble{{Value: float64Ptr(0)}, nil},
+							Operation:  operators.FnLn,
+							Expression: 1,
+						}, nil
+					},
+					SubmitResultFunc: func(ctx context.Context, completed *pb.TaskCompleted) (*pb.Empty, error) {
+						assert.Equal(t, "логарифм неположительного числа", completed.Error)
+						return &pb.Empty{}, nil
+					},
+				}
+			},
+			wantErr: false,
+		},
 		{
 			name: "calculation compiler error",
 			setupMock: func() *MockOrchestratorClient {
@@ -312,6 +394,76 @@ func TestWorker(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "calculation error unknown operator",
+			setupMock: func() *MockOrchestratorClient {
+				return &MockOrchestratorClient{
+					GetTaskFunc: func(ctx context.Context, empty *pb.Empty) (*pb.TaskResponse, error) {
+						return &pb.TaskResponse{
+							Id:         1,
+							Args:       []*pb.WrappedDouble{{Value: float64Ptr(1)}, {Value: float64Ptr(2)}},
+							Operation:  "?",
+							Expression: 1,
+						}, nil
+					},
+					SubmitResultFunc: func(ctx context.Context, completed *pb.TaskCompleted) (*pb.Empty, error) {
+						assert.Equal(t, "неизвестный оператор: ?", completed.Error)
+						return &pb.Empty{}, nil
+					},
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "calculation exact add",
+			setupMock: func() *MockOrchestratorClient {
+				return &MockOrchestratorClient{
+					GetTaskFunc: func(ctx context.Context, empty *pb.Empty) (*pb.TaskResponse, error) {
+						return &pb.TaskResponse{
+							Id:   1,
+							Args: []*pb.WrappedDouble{{Value: float64Ptr(0.1)}, {Value: float64Ptr(0.2)}},
+							ExactArgs: []*pb.WrappedRational{
+								{Value: &pb.Rational{Num: "1", Den: "10"}},
+								{Value: &pb.Rational{Num: "1", Den: "5"}},
+							},
+							Exact:      true,
+							Operation:  operators.OpAdd,
+							Expression: 1,
+						}, nil
+					},
+					SubmitResultFunc: func(ctx context.Context, completed *pb.TaskCompleted) (*pb.Empty, error) {
+						assert.Equal(t, "3", completed.ExactResult.GetNum())
+						assert.Equal(t, "10", completed.ExactResult.GetDen())
+						assert.Equal(t, 0.3, completed.Result)
+						return &pb.Empty{}, nil
+					},
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "calculation exact inexact operation",
+			setupMock: func() *MockOrchestratorClient {
+				return &MockOrchestratorClient{
+					GetTaskFunc: func(ctx context.Context, empty *pb.Empty) (*pb.TaskResponse, error) {
+						return &pb.TaskResponse{
+							Id:         1,
+							Args:       []*pb.WrappedDouble{{Value: float64Ptr(2)}, nil},
+							ExactArgs:  []*pb.WrappedRational{{Value: &pb.Rational{Num: "2", Den: "1"}}, {}},
+							Exact:      true,
+							Operation:  operators.FnSqrt,
+							Expression: 1,
+						}, nil
+					},
+					SubmitResultFunc: func(ctx context.Context, completed *pb.TaskCompleted) (*pb.Empty, error) {
+						assert.Equal(t, "операция sqrt не поддерживает точный режим", completed.Error)
+						assert.Nil(t, completed.ExactResult)
+						return &pb.Empty{}, nil
+					},
+				}
+			},
+			wantErr: false,
+		},
 		{
 			name: "task submit error",
 			setupMock: func() *MockOrchestratorClient {
@@ -352,3 +504,327 @@ func TestWorker(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateExact(t *testing.T) {
+	exact := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		task     *models.TaskResponse
+		expected string
+		wantErr  string
+	}{
+		{
+			name: "add",
+			task: &models.TaskResponse{
+				Operation: operators.OpAdd, Exact: true,
+				ExactArgs: []*string{exact("1/10"), exact("1/5")},
+			},
+			expected: "3/10",
+		},
+		{
+			name: "divide",
+			task: &models.TaskResponse{
+				Operation: operators.OpDivide, Exact: true,
+				ExactArgs: []*string{exact("1"), exact("3")},
+			},
+			expected: "1/3",
+		},
+		{
+			name: "unary minus",
+			task: &models.TaskResponse{
+				Operation: operators.OpUnaryMinus, Exact: true,
+				ExactArgs: []*string{exact("2/7"), nil},
+			},
+			expected: "-2/7",
+		},
+		{
+			name: "division by zero",
+			task: &models.TaskResponse{
+				Operation: operators.OpDivide, Exact: true,
+				ExactArgs: []*string{exact("1"), exact("0")},
+			},
+			wantErr: "деление на ноль",
+		},
+		{
+			name: "factorial beyond float range",
+			task: &models.TaskResponse{
+				Operation: operators.OpFactorial, Exact: true,
+				ExactArgs: []*string{exact("30"), nil},
+			},
+			expected: "265252859812191058636308480000000",
+		},
+		{
+			name: "mod by zero",
+			task: &models.TaskResponse{
+				Operation: operators.OpMod, Exact: true,
+				ExactArgs: []*string{exact("7"), exact("0")},
+			},
+			wantErr: "деление на ноль",
+		},
+		{
+			name: "median of many arguments",
+			task: &models.TaskResponse{
+				Operation: operators.FnMedian, Exact: true,
+				ExactArgs: []*string{exact("1/2"), exact("3"), exact("1/3"), exact("-1")},
+			},
+			expected: "5/12",
+		},
+		{
+			name:    "variadic function without arguments",
+			task:    &models.TaskResponse{Operation: operators.FnSum, Exact: true},
+			wantErr: "первый оператор не может быть nil",
+		},
+		{
+			name: "missing second argument",
+			task: &models.TaskResponse{
+				Operation: operators.OpMultiply, Exact: true,
+				ExactArgs: []*string{exact("1"), nil},
+			},
+			wantErr: "второй оператор не может быть nil",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, rat, err := workers.Calculate(tt.task)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, operators.FormatExact(rat))
+			expected, _ := rat.Float64()
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestCalculateRandom(t *testing.T) {
+	t.Run("same seed gives the same result", func(t *testing.T) {
+		for _, task := range []*models.TaskResponse{
+			{Operation: operators.FnRand, Args: []*float64{}, Seed: 42},
+			{Operation: operators.FnRandInt, Args: []*float64{float64Ptr(1), float64Ptr(100)}, Seed: 42},
+			{Operation: operators.FnNormal, Args: []*float64{float64Ptr(0), float64Ptr(1)}, Seed: 42},
+		} {
+			first, _, err := workers.Calculate(task)
+			assert.NoError(t, err, task.Operation)
+			second, _, err := workers.Calculate(task)
+			assert.NoError(t, err, task.Operation)
+			assert.Equal(t, first, second, task.Operation)
+
+			op, _ := operators.Lookup(task.Operation)
+			args := make([]float64, len(task.Args))
+			for i, arg := range task.Args {
+				args[i] = *arg
+			}
+			expected, _ := op.Random(task.Seed, args...)
+			assert.Equal(t, expected, first, task.Operation)
+		}
+	})
+
+	t.Run("different seeds", func(t *testing.T) {
+		first, _, err := workers.Calculate(&models.TaskResponse{Operation: operators.FnRand, Seed: 1})
+		assert.NoError(t, err)
+		second, _, err := workers.Calculate(&models.TaskResponse{Operation: operators.FnRand, Seed: 2})
+		assert.NoError(t, err)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("invalid bounds", func(t *testing.T) {
+		_, _, err := workers.Calculate(&models.TaskResponse{
+			Operation: operators.FnRandInt, Args: []*float64{float64Ptr(5), float64Ptr(1)}, Seed: 1,
+		})
+		assert.ErrorIs(t, err, operators.ErrRandomBounds)
+	})
+}
+
+func TestCalculateDecimal(t *testing.T) {
+	decimal := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		task     *models.TaskResponse
+		expected string
+		wantErr  string
+	}{
+		{
+			name: "power with fractional exponent",
+			task: &models.TaskResponse{
+				Operation: operators.OpPower, Precision: 50,
+				DecimalArgs: []*string{decimal("2"), decimal("0.5")},
+			},
+			expected: "1.4142135623730950488016887242096980785696718753769",
+		},
+		{
+			name: "divide",
+			task: &models.TaskResponse{
+				Operation: operators.OpDivide, Precision: 30,
+				DecimalArgs: []*string{decimal("1"), decimal("3")},
+			},
+			expected: "0.333333333333333333333333333333",
+		},
+		{
+			name: "result beyond float64",
+			task: &models.TaskResponse{
+				Operation: operators.OpMultiply, Precision: 10,
+				DecimalArgs: []*string{decimal("1e300"), decimal("1e300")},
+			},
+			expected: "1e+600",
+		},
+		{
+			name: "negative sqrt",
+			task: &models.TaskResponse{
+				Operation: operators.FnSqrt, Precision: 10,
+				DecimalArgs: []*string{decimal("-2"), nil},
+			},
+			wantErr: "корень из отрицательного числа",
+		},
+		{
+			name: "invalid argument",
+			task: &models.TaskResponse{
+				Operation: operators.OpAdd, Precision: 10,
+				DecimalArgs: []*string{decimal("1"), decimal("1..2")},
+			},
+			wantErr: "неверная запись числа: 1..2",
+		},
+		{
+			name: "rational arguments are not decimal",
+			task: &models.TaskResponse{
+				Operation: operators.OpAdd, Precision: 10,
+				ExactArgs: []*string{decimal("1/3"), decimal("1/3")},
+			},
+			wantErr: "первый оператор не может быть nil",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, value, err := workers.CalculateDecimal(tt.task)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, operators.FormatDecimalDigits(value, tt.task.Precision))
+			assert.False(t, math.IsInf(result, 0))
+		})
+	}
+}
+
+func TestCalculateComplex(t *testing.T) {
+	tests := []struct {
+		name     string
+		task     *models.TaskResponse
+		expected complex128
+		wantErr  string
+	}{
+		{
+			name: "multiply",
+			task: &models.TaskResponse{
+				Operation: operators.OpMultiply, Complex: true,
+				Args:     []*float64{float64Ptr(1), float64Ptr(3)},
+				ImagArgs: []*float64{float64Ptr(2), float64Ptr(-1)},
+			},
+			expected: complex(5, 5),
+		},
+		{
+			name: "sqrt of negative number",
+			task: &models.TaskResponse{
+				Operation: operators.FnSqrt, Complex: true,
+				Args:     []*float64{float64Ptr(-4), nil},
+				ImagArgs: []*float64{float64Ptr(0), nil},
+			},
+			expected: complex(0, 2),
+		},
+		{
+			name: "missing imaginary part",
+			task: &models.TaskResponse{
+				Operation: operators.OpAdd, Complex: true,
+				Args: []*float64{float64Ptr(1), float64Ptr(2)},
+			},
+			expected: complex(3, 0),
+		},
+		{
+			name: "division by zero",
+			task: &models.TaskResponse{
+				Operation: operators.OpDivide, Complex: true,
+				Args:     []*float64{float64Ptr(1), float64Ptr(0)},
+				ImagArgs: []*float64{float64Ptr(1), float64Ptr(0)},
+			},
+			wantErr: "деление на ноль",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := workers.CalculateComplex(tt.task)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCalculateInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		task     *models.TaskResponse
+		expected operators.Interval
+		wantErr  string
+	}{
+		{
+			name: "multiply",
+			task: &models.TaskResponse{
+				Operation: operators.OpMultiply, Interval: true,
+				Args:      []*float64{float64Ptr(2.9), float64Ptr(1.95)},
+				UpperArgs: []*float64{float64Ptr(3.1), float64Ptr(2.05)},
+			},
+			expected: operators.Interval{Lo: 5.655, Hi: 6.355},
+		},
+		{
+			name: "plus minus",
+			task: &models.TaskResponse{
+				Operation: operators.OpPlusMinus, Interval: true,
+				Args:      []*float64{float64Ptr(1), float64Ptr(0.5)},
+				UpperArgs: []*float64{float64Ptr(3), float64Ptr(0.5)},
+			},
+			expected: operators.Interval{Lo: 0.5, Hi: 3.5},
+		},
+		{
+			name: "missing upper bound",
+			task: &models.TaskResponse{
+				Operation: operators.OpSubtract, Interval: true,
+				Args: []*float64{float64Ptr(5), float64Ptr(2)},
+			},
+			expected: operators.Interval{Lo: 3, Hi: 3},
+		},
+		{
+			name: "divisor contains zero",
+			task: &models.TaskResponse{
+				Operation: operators.OpDivide, Interval: true,
+				Args:      []*float64{float64Ptr(1), float64Ptr(-1)},
+				UpperArgs: []*float64{float64Ptr(2), float64Ptr(1)},
+			},
+			wantErr: "деление на ноль",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := workers.CalculateInterval(tt.task)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, result.Lo, tt.expected.Lo)
+			assert.GreaterOrEqual(t, result.Hi, tt.expected.Hi)
+			assert.InDelta(t, tt.expected.Lo, result.Lo, 1e-12)
+			assert.InDelta(t, tt.expected.Hi, result.Hi, 1e-12)
+		})
+	}
+}
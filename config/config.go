@@ -5,6 +5,8 @@ import (
 	"io"
 	"log"
 	"os"
+	"reflect"
+	"slices"
 	"strconv"
 
 	"github.com/joho/godotenv"
@@ -14,6 +16,7 @@ import (
 type Config struct {
 	Services   ServicesConfig   `yaml:"services"`
 	Math       MathConfig       `yaml:"math"`
+	Splitter   SplitterConfig   `yaml:"splitter"`
 	Middleware MiddlewareConfig `yaml:"middleware"`
 	Logger     LoggerConfig     `yaml:"logger"`
 }
@@ -43,8 +46,42 @@ type MathConfig struct {
 	TIME_DIVISION_MS       int `yaml:"TIME_DIVISION_MS"`
 	TIME_UNARY_MINUS_MS    int `yaml:"TIME_UNARY_MINUS_MS"`
 	TIME_POWER_MS          int `yaml:"TIME_POWER_MS"`
+	TIME_SIN_MS            int `yaml:"TIME_SIN_MS"`
+	TIME_COS_MS            int `yaml:"TIME_COS_MS"`
+	TIME_TAN_MS            int `yaml:"TIME_TAN_MS"`
+	TIME_SQRT_MS           int `yaml:"TIME_SQRT_MS"`
+	TIME_LN_MS             int `yaml:"TIME_LN_MS"`
+	TIME_LOG_MS            int `yaml:"TIME_LOG_MS"`
+	TIME_ABS_MS            int `yaml:"TIME_ABS_MS"`
+	TIME_EXP_MS            int `yaml:"TIME_EXP_MS"`
+	TIME_AGGREGATE_MS      int `yaml:"TIME_AGGREGATE_MS"`
+	TIME_COMPARISON_MS     int `yaml:"TIME_COMPARISON_MS"`
+	TIME_INTEGER_MS        int `yaml:"TIME_INTEGER_MS"`
+	TIME_RANDOM_MS         int `yaml:"TIME_RANDOM_MS"`
 }
 
+// SplitterConfig представляет параметры разбора выражений на задачи
+type SplitterConfig struct {
+	// LEGACY_PRECEDENCE включает прежние правила разбора: левоассоциативное возведение в степень
+	// (2^3^2 = (2^3)^2) и унарный минус, выталкивающий операторы из стека (2^-1 и --2 - ошибки)
+	LEGACY_PRECEDENCE bool `yaml:"LEGACY_PRECEDENCE"`
+	// BALANCE_CHAINS включает перестроение длинных цепочек ассоциативных операций (1+2+3+...)
+	// в сбалансированные деревья, чтобы их задачи вычислялись параллельно
+	BALANCE_CHAINS bool `yaml:"BALANCE_CHAINS"`
+	// FOLD_POLICY задает политику свертки констант - какие операции над числами оркестратор вычисляет сам:
+	// none - никакие, unary - только унарный минус, literal - все, cost - операции со временем выполнения
+	// (секция math) не больше FOLD_THRESHOLD_MS
+	FOLD_POLICY string `yaml:"FOLD_POLICY"`
+	// FOLD_THRESHOLD_MS - наибольшее время выполнения сворачиваемой операции для политики cost
+	FOLD_THRESHOLD_MS int `yaml:"FOLD_THRESHOLD_MS"`
+	// SERIES_LIMIT - наибольшее количество подстановок тела рядов (sum(i, 1, 1000, i^2)) и integrate в одном выражении;
+	// solve учитывается количеством созданных задач. 0 - без ограничения
+	SERIES_LIMIT int `yaml:"SERIES_LIMIT"`
+}
+
+// foldPolicies - допустимые значения FOLD_POLICY
+var foldPolicies = []string{"none", "unary", "literal", "cost"}
+
 type MiddlewareConfig struct {
 	TOKEN_TTL_MIN     int      `yaml:"TOKEN_TTL_MIN"`
 	SESSION_CLEAR_MIN int      `yaml:"SESSION_CLEAR_MIN"`
@@ -85,6 +122,25 @@ func defaultConfig() *Config {
 			TIME_DIVISION_MS:       0,
 			TIME_UNARY_MINUS_MS:    0,
 			TIME_POWER_MS:          0,
+			TIME_SIN_MS:            0,
+			TIME_COS_MS:            0,
+			TIME_TAN_MS:            0,
+			TIME_SQRT_MS:           0,
+			TIME_LN_MS:             0,
+			TIME_LOG_MS:            0,
+			TIME_ABS_MS:            0,
+			TIME_EXP_MS:            0,
+			TIME_AGGREGATE_MS:      0,
+			TIME_COMPARISON_MS:     0,
+			TIME_INTEGER_MS:        0,
+			TIME_RANDOM_MS:         0,
+		},
+		Splitter: SplitterConfig{
+			LEGACY_PRECEDENCE: false,
+			BALANCE_CHAINS:    true,
+			FOLD_POLICY:       "none",
+			FOLD_THRESHOLD_MS: 0,
+			SERIES_LIMIT:      10000,
 		},
 		Middleware: MiddlewareConfig{
 			SESSION_CLEAR_MIN: 10,
@@ -219,68 +275,103 @@ func loadEnv() error {
 		Cfg.Services.Agent.AGENT_REPEAT = agentRepeat
 	}
 
-	// TIME_ADDITION_MS
-	timeAdditionMSStr := os.Getenv("TIME_ADDITION_MS")
-	if timeAdditionMSStr != "" {
-		timeAdditionMS, err := strconv.Atoi(timeAdditionMSStr)
+	// LEGACY_PRECEDENCE
+	legacyPrecedenceStr := os.Getenv("LEGACY_PRECEDENCE")
+	if legacyPrecedenceStr != "" {
+		legacyPrecedence, err := strconv.ParseBool(legacyPrecedenceStr)
 		if err != nil {
-			return fmt.Errorf("ошибка преобразования TIME_ADDITION_MS в int: %w", err)
+			return fmt.Errorf("ошибка преобразования LEGACY_PRECEDENCE в bool: %w", err)
 		}
-		Cfg.Math.TIME_ADDITION_MS = timeAdditionMS
+		Cfg.Splitter.LEGACY_PRECEDENCE = legacyPrecedence
 	}
 
-	// TIME_SUBTRACTION_MS
-	timeSubtractionMSStr := os.Getenv("TIME_SUBTRACTION_MS")
-	if timeSubtractionMSStr != "" {
-		timeSubtractionMS, err := strconv.Atoi(timeSubtractionMSStr)
+	// BALANCE_CHAINS
+	balanceChainsStr := os.Getenv("BALANCE_CHAINS")
+	if balanceChainsStr != "" {
+		balanceChains, err := strconv.ParseBool(balanceChainsStr)
 		if err != nil {
-			return fmt.Errorf("ошибка преобразования TIME_SUBTRACTION_MS в int: %w", err)
+			return fmt.Errorf("ошибка преобразования BALANCE_CHAINS в bool: %w", err)
 		}
-		Cfg.Math.TIME_SUBTRACTION_MS = timeSubtractionMS
+		Cfg.Splitter.BALANCE_CHAINS = balanceChains
 	}
 
-	// TIME_MULTIPLICATION_MS
-	timeMultiplicationMSStr := os.Getenv("TIME_MULTIPLICATION_MS")
-	if timeMultiplicationMSStr != "" {
-		timeMultiplicationMS, err := strconv.Atoi(timeMultiplicationMSStr)
-		if err != nil {
-			return fmt.Errorf("ошибка преобразования TIME_MULTIPLICATION_MS в int: %w", err)
-		}
-		Cfg.Math.TIME_MULTIPLICATION_MS = timeMultiplicationMS
+	// FOLD_POLICY
+	foldPolicy := os.Getenv("FOLD_POLICY")
+	if foldPolicy != "" {
+		Cfg.Splitter.FOLD_POLICY = foldPolicy
 	}
 
-	// TIME_DIVISION_MS
-	timeDivisionMSStr := os.Getenv("TIME_DIVISION_MS")
-	if timeDivisionMSStr != "" {
-		timeDivisionMS, err := strconv.Atoi(timeDivisionMSStr)
+	// FOLD_THRESHOLD_MS
+	foldThresholdStr := os.Getenv("FOLD_THRESHOLD_MS")
+	if foldThresholdStr != "" {
+		foldThreshold, err := strconv.Atoi(foldThresholdStr)
 		if err != nil {
-			return fmt.Errorf("ошибка преобразования TIME_DIVISION_MS в int: %w", err)
+			return fmt.Errorf("ошибка преобразования FOLD_THRESHOLD_MS в int: %w", err)
 		}
-		Cfg.Math.TIME_DIVISION_MS = timeDivisionMS
+		Cfg.Splitter.FOLD_THRESHOLD_MS = foldThreshold
 	}
 
-	// TIME_UNARY_MINUS_MS
-	timeUnaryMinusMSStr := os.Getenv("TIME_UNARY_MINUS_MS")
-	if timeUnaryMinusMSStr != "" {
-		timeUnaryMinusMS, err := strconv.Atoi(timeUnaryMinusMSStr)
+	// SERIES_LIMIT
+	seriesLimitStr := os.Getenv("SERIES_LIMIT")
+	if seriesLimitStr != "" {
+		seriesLimit, err := strconv.Atoi(seriesLimitStr)
 		if err != nil {
-			return fmt.Errorf("ошибка преобразования TIME_UNARY_MINUS_MS в int: %w", err)
+			return fmt.Errorf("ошибка преобразования SERIES_LIMIT в int: %w", err)
 		}
-		Cfg.Math.TIME_UNARY_MINUS_MS = timeUnaryMinusMS
+		Cfg.Splitter.SERIES_LIMIT = seriesLimit
 	}
 
-	// TIME_POWER_MS
-	timePowerMSStr := os.Getenv("TIME_POWER_MS")
-	if timePowerMSStr != "" {
-		timePowerMS, err := strconv.Atoi(timePowerMSStr)
+	// TIME_*_MS - время выполнения математических операций
+	if err := loadMathEnv(); err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// loadMathEnv перезаписывает параметры секции math значениями переменных среды.
+// Имя переменной совпадает с yaml-тегом поля MathConfig, поэтому для новой операции
+// достаточно добавить поле в структуру.
+//
+// Returns:
+//
+//	error - Ошибка, если значение переменной не является целым числом.
+func loadMathEnv() error {
+	value := reflect.ValueOf(&Cfg.Math).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		key := value.Type().Field(i).Tag.Get("yaml")
+		envStr := os.Getenv(key)
+		if envStr == "" {
+			continue
+		}
+		envValue, err := strconv.Atoi(envStr)
 		if err != nil {
-			return fmt.Errorf("ошибка преобразования TIME_POWER_MS в int: %w", err)
+			return fmt.Errorf("ошибка преобразования %s в int: %w", key, err)
 		}
-		Cfg.Math.TIME_POWER_MS = timePowerMS
+		value.Field(i).SetInt(int64(envValue))
 	}
-
 	return nil
+}
 
+// OperationTime возвращает время выполнения операции в миллисекундах по ключу конфигурации.
+//
+// Args:
+//
+//	key: string - Ключ параметра (yaml-тег поля MathConfig), например "TIME_POWER_MS".
+//
+// Returns:
+//
+//	int - Время выполнения операции в миллисекундах.
+//	bool - true, если параметр с таким ключом существует.
+func (m MathConfig) OperationTime(key string) (int, bool) {
+	value := reflect.ValueOf(m)
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("yaml") == key {
+			return int(value.Field(i).Int()), true
+		}
+	}
+	return 0, false
 }
 
 func InitConfig() error {
@@ -322,6 +413,12 @@ func InitConfig() error {
 	}
 
 	// Записываем переменные среды поверх других
-	err := loadEnv()
-	return err
+	if err := loadEnv(); err != nil {
+		return err
+	}
+
+	if !slices.Contains(foldPolicies, Cfg.Splitter.FOLD_POLICY) {
+		return fmt.Errorf("недопустимое значение FOLD_POLICY: %q (допустимые значения: %v)", Cfg.Splitter.FOLD_POLICY, foldPolicies)
+	}
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/OinkiePie/calc_3/orchestrator/internal/managers"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/providers"
 	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/OinkiePie/calc_3/pkg/operators"
 	pb "github.com/OinkiePie/calc_3/pkg/proto"
 )
 
@@ -27,7 +28,7 @@ func (s *OrchestratorGRPCServer) GetTask(
 		return nil, err
 	}
 
-	pbArgs := make([]*pb.WrappedDouble, 2)
+	pbArgs := make([]*pb.WrappedDouble, len(task.Args))
 	for i, ptr := range task.Args {
 		pbArgs[i] = &pb.WrappedDouble{
 			Value: ptr,
@@ -39,6 +40,41 @@ func (s *OrchestratorGRPCServer) GetTask(
 		Args:       pbArgs,
 		Operation:  task.Operation,
 		Expression: task.Expression,
+		Exact:      task.Exact,
+		Precision:  int32(task.Precision),
+		Complex:    task.Complex,
+		Interval:   task.Interval,
+		Seed:       task.Seed,
+	}
+
+	if task.Exact {
+		response.ExactArgs = make([]*pb.WrappedRational, len(task.ExactArgs))
+		for i, ptr := range task.ExactArgs {
+			response.ExactArgs[i] = &pb.WrappedRational{Value: toRational(ptr)}
+		}
+	}
+
+	if task.Precision > 0 {
+		response.DecimalArgs = make([]string, len(task.ExactArgs))
+		for i, ptr := range task.ExactArgs {
+			if ptr != nil {
+				response.DecimalArgs[i] = *ptr
+			}
+		}
+	}
+
+	if task.Complex {
+		response.ImagArgs = make([]*pb.WrappedDouble, len(task.ImagArgs))
+		for i, ptr := range task.ImagArgs {
+			response.ImagArgs[i] = &pb.WrappedDouble{Value: ptr}
+		}
+	}
+
+	if task.Interval {
+		response.UpperArgs = make([]*pb.WrappedDouble, len(task.UpperArgs))
+		for i, ptr := range task.UpperArgs {
+			response.UpperArgs[i] = &pb.WrappedDouble{Value: ptr}
+		}
 	}
 
 	return response, nil
@@ -53,8 +89,48 @@ func (s *OrchestratorGRPCServer) SubmitResult(
 		ID:         in.GetId(),
 		Expression: in.GetExpression(),
 		Error:      in.GetError(),
+		ImagResult: in.GetImagResult(),
+	}
+
+	if in.GetUpperResult() != nil {
+		completed.UpperResult = in.GetUpperResult().Value
+	}
+
+	if in.GetExactResult() != nil {
+		exact, err := in.GetExactResult().Rat()
+		if err != nil {
+			return nil, err
+		}
+		completed.ExactResult = operators.FormatExact(exact)
+	}
+
+	if in.GetDecimalResult() != "" {
+		if _, err := operators.ParseDecimal(in.GetDecimalResult(), 64); err != nil {
+			return nil, err
+		}
+		completed.ExactResult = in.GetDecimalResult()
 	}
 
 	err, _ := s.exprManager.CompleteTask(ctx, completed)
 	return &pb.Empty{}, err
 }
+
+// toRational преобразует точную запись числа "num/den" в сообщение Rational.
+//
+// Args:
+//
+//	exact: *string - Запись числа. nil означает, что значение ещё не вычислено.
+//
+// Returns:
+//
+//	*pb.Rational - Сообщение с дробью или nil, если значения нет или запись неверна.
+func toRational(exact *string) *pb.Rational {
+	if exact == nil {
+		return nil
+	}
+	value, err := operators.ParseExact(*exact)
+	if err != nil {
+		return nil
+	}
+	return pb.NewRational(value)
+}
@@ -59,6 +59,217 @@ func TestGetTask_Success(t *testing.T) {
 	mockEM.AssertExpectations(t)
 }
 
+func TestGetTask_ExactTask(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockPr := &providers.Providers{ExprManager: mockEM}
+	server := grpcservice.NewOrchestratorGRPCServer(mockPr)
+
+	oneTenth := "1/10"
+	expectedTask := &models.Task{
+		ID:         1,
+		Args:       []*float64{float64Ptr(0.1), nil},
+		Operation:  "+",
+		Expression: 1,
+		Exact:      true,
+		ExactArgs:  []*string{&oneTenth, nil},
+	}
+
+	mockEM.On("ReadTask", mock.Anything).Return(expectedTask, nil, http.StatusOK)
+
+	resp, err := server.GetTask(context.Background(), &pb.Empty{})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Exact)
+	require.Len(t, resp.ExactArgs, 2)
+	assert.Equal(t, "1", resp.ExactArgs[0].GetValue().GetNum())
+	assert.Equal(t, "10", resp.ExactArgs[0].GetValue().GetDen())
+	assert.Nil(t, resp.ExactArgs[1].GetValue())
+	mockEM.AssertExpectations(t)
+}
+
+func TestSubmitResult_ExactResult(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockPr := &providers.Providers{ExprManager: mockEM}
+	server := grpcservice.NewOrchestratorGRPCServer(mockPr)
+
+	completedTask := &pb.TaskCompleted{
+		Id:          1,
+		Result:      0.3,
+		Expression:  1,
+		ExactResult: &pb.Rational{Num: "6", Den: "20"},
+	}
+
+	mockEM.On("CompleteTask", mock.Anything, &models.TaskCompleted{
+		ID: 1, Expression: 1, Result: 0.3, ExactResult: "3/10",
+	}).Return(nil, http.StatusOK)
+
+	_, err := server.SubmitResult(context.Background(), completedTask)
+	assert.NoError(t, err)
+
+	_, err = server.SubmitResult(context.Background(), &pb.TaskCompleted{
+		Id: 1, Expression: 1, ExactResult: &pb.Rational{Num: "1", Den: "0"},
+	})
+	assert.Error(t, err)
+	mockEM.AssertExpectations(t)
+}
+
+func TestGetTask_DecimalTask(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockPr := &providers.Providers{ExprManager: mockEM}
+	server := grpcservice.NewOrchestratorGRPCServer(mockPr)
+
+	two := "2"
+	expectedTask := &models.Task{
+		ID:         1,
+		Args:       []*float64{float64Ptr(2), nil},
+		Operation:  "^",
+		Expression: 1,
+		Precision:  50,
+		ExactArgs:  []*string{&two, nil},
+	}
+
+	mockEM.On("ReadTask", mock.Anything).Return(expectedTask, nil, http.StatusOK)
+
+	resp, err := server.GetTask(context.Background(), &pb.Empty{})
+
+	assert.NoError(t, err)
+	assert.False(t, resp.Exact)
+	assert.Equal(t, int32(50), resp.Precision)
+	assert.Equal(t, []string{"2", ""}, resp.DecimalArgs)
+	assert.Empty(t, resp.ExactArgs)
+	mockEM.AssertExpectations(t)
+}
+
+func TestSubmitResult_DecimalResult(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockPr := &providers.Providers{ExprManager: mockEM}
+	server := grpcservice.NewOrchestratorGRPCServer(mockPr)
+
+	mockEM.On("CompleteTask", mock.Anything, &models.TaskCompleted{
+		ID: 1, Expression: 1, Result: 1.4142135623730951, ExactResult: "1.41421356237309504880168872",
+	}).Return(nil, http.StatusOK)
+
+	_, err := server.SubmitResult(context.Background(), &pb.TaskCompleted{
+		Id: 1, Expression: 1, Result: 1.4142135623730951, DecimalResult: "1.41421356237309504880168872",
+	})
+	assert.NoError(t, err)
+
+	_, err = server.SubmitResult(context.Background(), &pb.TaskCompleted{
+		Id: 1, Expression: 1, DecimalResult: "1.4.1",
+	})
+	assert.Error(t, err)
+	mockEM.AssertExpectations(t)
+}
+
+func TestGetTask_ComplexTask(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockPr := &providers.Providers{ExprManager: mockEM}
+	server := grpcservice.NewOrchestratorGRPCServer(mockPr)
+
+	expectedTask := &models.Task{
+		ID:         1,
+		Args:       []*float64{float64Ptr(1), float64Ptr(3)},
+		Operation:  "*",
+		Expression: 1,
+		Complex:    true,
+		ImagArgs:   []*float64{float64Ptr(2), float64Ptr(-1)},
+	}
+
+	mockEM.On("ReadTask", mock.Anything).Return(expectedTask, nil, http.StatusOK)
+
+	resp, err := server.GetTask(context.Background(), &pb.Empty{})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Complex)
+	if assert.Len(t, resp.ImagArgs, 2) {
+		assert.Equal(t, float64(2), resp.ImagArgs[0].GetValue())
+		assert.Equal(t, float64(-1), resp.ImagArgs[1].GetValue())
+	}
+	mockEM.AssertExpectations(t)
+}
+
+func TestSubmitResult_ComplexResult(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockPr := &providers.Providers{ExprManager: mockEM}
+	server := grpcservice.NewOrchestratorGRPCServer(mockPr)
+
+	mockEM.On("CompleteTask", mock.Anything, &models.TaskCompleted{
+		ID: 1, Expression: 1, Result: 5, ImagResult: 5,
+	}).Return(nil, http.StatusOK)
+
+	_, err := server.SubmitResult(context.Background(), &pb.TaskCompleted{
+		Id: 1, Expression: 1, Result: 5, ImagResult: 5,
+	})
+	assert.NoError(t, err)
+	mockEM.AssertExpectations(t)
+}
+
+func TestGetTask_IntervalTask(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockPr := &providers.Providers{ExprManager: mockEM}
+	server := grpcservice.NewOrchestratorGRPCServer(mockPr)
+
+	expectedTask := &models.Task{
+		ID:         1,
+		Args:       []*float64{float64Ptr(2.9), nil},
+		Operation:  "*",
+		Expression: 1,
+		Interval:   true,
+		UpperArgs:  []*float64{float64Ptr(3.1), nil},
+	}
+
+	mockEM.On("ReadTask", mock.Anything).Return(expectedTask, nil, http.StatusOK)
+
+	resp, err := server.GetTask(context.Background(), &pb.Empty{})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Interval)
+	if assert.Len(t, resp.UpperArgs, 2) {
+		assert.Equal(t, float64(3.1), resp.UpperArgs[0].GetValue())
+		assert.Nil(t, resp.UpperArgs[1].Value)
+	}
+	mockEM.AssertExpectations(t)
+}
+
+func TestGetTask_RandomTask(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockPr := &providers.Providers{ExprManager: mockEM}
+	server := grpcservice.NewOrchestratorGRPCServer(mockPr)
+
+	expectedTask := &models.Task{
+		ID:         1,
+		Args:       []*float64{},
+		Operation:  "rand",
+		Expression: 1,
+		Seed:       -4242,
+	}
+
+	mockEM.On("ReadTask", mock.Anything).Return(expectedTask, nil, http.StatusOK)
+
+	resp, err := server.GetTask(context.Background(), &pb.Empty{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-4242), resp.GetSeed())
+	assert.Empty(t, resp.Args)
+	mockEM.AssertExpectations(t)
+}
+
+func TestSubmitResult_IntervalResult(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockPr := &providers.Providers{ExprManager: mockEM}
+	server := grpcservice.NewOrchestratorGRPCServer(mockPr)
+
+	mockEM.On("CompleteTask", mock.Anything, &models.TaskCompleted{
+		ID: 1, Expression: 1, Result: 5.5, UpperResult: float64Ptr(6.5),
+	}).Return(nil, http.StatusOK)
+
+	_, err := server.SubmitResult(context.Background(), &pb.TaskCompleted{
+		Id: 1, Expression: 1, Result: 5.5, UpperResult: &pb.WrappedDouble{Value: float64Ptr(6.5)},
+	})
+	assert.NoError(t, err)
+	mockEM.AssertExpectations(t)
+}
+
 func TestGetTask_NoTaskAvailable(t *testing.T) {
 	mockEM := new(mm.MockExpressionManager)
 	mockPr := &providers.Providers{ExprManager: mockEM}
@@ -2,15 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/managers"
+	"github.com/OinkiePie/calc_3/orchestrator/internal/task_splitter"
 	"github.com/OinkiePie/calc_3/pkg/jwt_manager"
 	"github.com/OinkiePie/calc_3/pkg/logger"
 	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/OinkiePie/calc_3/pkg/operators"
 	"github.com/gorilla/mux"
 	"net/http"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Handlers представляет структуру обработчиков HTTP-запросов оркестратора.
@@ -254,14 +258,28 @@ func (h *Handlers) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 //   - Заголовок Authorization: Bearer <token>
 //
 // Ожидаемые поля в теле запроса (JSON):
-//   - expression: string - Математическое выражение для вычисления
+//   - expression: string - Математическое выражение для вычисления, может ссылаться на другие выражения ($42)
+//   - variables: map[string]float64 - Значения переменных выражения (необязательно)
+//   - numeric: string - Режим вычисления: "float" (по умолчанию), "rational", "decimal", "complex" или "interval" (необязательно)
+//   - precision: int - Точность режима "decimal" в значащих цифрах, по умолчанию 34 (необязательно)
+//   - seed: int64 - Зерно функций случайных чисел, по умолчанию выбирается оркестратором (необязательно)
 //
 // Ответ (JSON):
 //   - id: int64 - ID созданного выражения
 //
+// Ответ при ошибке разбора выражения (JSON):
+//   - error: string - Текст ошибки
+//   - code: string - Код ошибки
+//   - offset: int - Смещение ошибочного фрагмента от начала выражения в байтах
+//   - length: int - Длина ошибочного фрагмента в байтах
+//   - expected: string - Подсказка, какой токен ожидался (необязательно)
+//
 // Возможные HTTP-статусы ответа:
 //   - 200 OK - при успешном создании выражения
-//   - 400 Bad Request - при пустом выражении
+//   - 400 Bad Request - при пустом или невалидном выражении, при несвязанной переменной, неизвестном режиме вычисления или недопустимой точности,
+//     а также при ссылке на выражение, завершившееся с ошибкой
+//   - 403 Forbidden - при ссылке на выражение другого пользователя
+//   - 404 Not Found - при ссылке на несуществующее выражение
 //   - 405 Method Not Allowed - при неправильном методе запроса
 //   - 422 Unprocessable Entity - при ошибке парсинга JSON
 //   - 500 Internal Server Error - при внутренних ошибках сервера
@@ -293,9 +311,13 @@ func (h *Handlers) AddExpressionHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	id, err, code := h.exprManager.AddExpression(r.Context(), trimmedBody, claims.Subject)
+	// Количество отброшенных пробелов нужно, чтобы смещение ошибки разбора считалось от начала исходного выражения
+	leading := len(requestBody.Expression) - len(strings.TrimLeftFunc(requestBody.Expression, unicode.IsSpace))
+	requestBody.Expression = trimmedBody
+
+	id, err, code := h.exprManager.AddExpression(r.Context(), &requestBody, claims.Subject)
 	if err != nil {
-		http.Error(w, err.Error(), code)
+		writeExpressionError(w, err, code, leading)
 		return
 	}
 
@@ -328,6 +350,222 @@ func (h *Handlers) AddExpressionHandler(w http.ResponseWriter, r *http.Request)
 	logger.Log.Debugf("Выражение №%d пользователя №%d создано", id, claims.Subject)
 }
 
+// ExplainExpressionHandler обрабатывает HTTP-запрос на разбор выражения без его сохранения.
+// Позволяет проверить выражение перед отправкой и увидеть, как оно будет разделено на задачи для агентов.
+//
+// Args:
+//
+//	w: http.ResponseWriter - Интерфейс для записи HTTP-ответа
+//	r: *http.Request - Входящий HTTP-запрос
+//
+// Требования:
+//   - Метод: POST
+//   - Заголовок Authorization: Bearer <token>
+//
+// Ожидаемые поля в теле запроса (JSON) совпадают с AddExpressionHandler:
+//   - expression: string - Математическое выражение
+//   - variables: map[string]float64 - Значения переменных выражения (необязательно)
+//   - numeric: string - Режим вычисления: "float" (по умолчанию), "rational", "decimal", "complex" или "interval" (необязательно)
+//   - precision: int - Точность режима "decimal" в значащих цифрах, по умолчанию 34 (необязательно)
+//
+// Ответ (JSON):
+//   - tokens: []models.TokenResponse - Токены выражения
+//   - rpn: [][]string - Инструкции сценария в обратной польской нотации
+//   - tasks: []models.ExplainTaskResponse - Задачи с аргументами и индексами зависимостей
+//   - depth: int - Длина критического пути
+//   - parallelism: int - Максимальное количество одновременно выполнимых задач
+//   - folded: int - Количество операций, вычисленных при разборе согласно политике свертки
+//   - dispatched: int - Количество задач, которые будут отправлены агентам
+//   - result: float64 - Результат выражения, если все операции свернуты (необязательно)
+//
+// Ответ при ошибке разбора выражения совпадает с AddExpressionHandler.
+//
+// Возможные HTTP-статусы ответа:
+//   - 200 OK - при успешном разборе выражения
+//   - 400 Bad Request - при пустом или невалидном выражении, при несвязанной переменной, неизвестном режиме вычисления или недопустимой точности,
+//     а также при ссылке на выражение, завершившееся с ошибкой
+//   - 403 Forbidden - при ссылке на выражение другого пользователя
+//   - 404 Not Found - при ссылке на несуществующее выражение
+//   - 405 Method Not Allowed - при неправильном методе запроса
+//   - 422 Unprocessable Entity - при ошибке парсинга JSON
+//   - 500 Internal Server Error - при внутренних ошибках сервера
+func (h *Handlers) ExplainExpressionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.ContentLength == 0 {
+		http.Error(w, "пустое тело запроса", http.StatusBadRequest)
+		return
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	claims, _ := h.jwtManager.Validate(token)
+
+	var requestBody models.ExpressionAdd
+
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "некорректный запрос", http.StatusUnprocessableEntity)
+		return
+	}
+
+	trimmedBody := strings.TrimSpace(requestBody.Expression)
+	if trimmedBody == "" {
+		http.Error(w, "выражения обязательно", http.StatusBadRequest)
+		return
+	}
+
+	// Смещения токенов и ошибок считаются от начала выражения, переданного клиентом
+	leading := len(requestBody.Expression) - len(strings.TrimLeftFunc(requestBody.Expression, unicode.IsSpace))
+	requestBody.Expression = trimmedBody
+
+	plan, err, code := h.exprManager.ExplainExpression(r.Context(), &requestBody, claims.Subject)
+	if err != nil {
+		writeExpressionError(w, err, code, leading)
+		return
+	}
+
+	response := models.ExplainResponse{
+		Canonical:   plan.Canonical,
+		Tokens:      make([]models.TokenResponse, 0, len(plan.Tokens)),
+		RPN:         make([][]string, 0, len(plan.RPN)),
+		Tasks:       make([]models.ExplainTaskResponse, 0, len(plan.Tasks)),
+		Depth:       plan.Depth(),
+		Parallelism: plan.Parallelism(),
+		Folded:      plan.Folded,
+		Dispatched:  len(plan.Tasks),
+		Result:      plan.Result,
+		Unit:        plan.Unit,
+	}
+
+	for _, tok := range plan.Tokens {
+		response.Tokens = append(response.Tokens, models.TokenResponse{
+			Kind:   tok.Kind,
+			Text:   tok.Text,
+			Offset: tok.Offset + leading,
+			Length: tok.Length,
+		})
+	}
+
+	for _, statement := range plan.RPN {
+		rpn := make([]string, len(statement))
+		for i, tok := range statement {
+			rpn[i] = tok.Text
+		}
+		response.RPN = append(response.RPN, rpn)
+	}
+
+	for _, task := range plan.Tasks {
+		taskResponse := models.ExplainTaskResponse{
+			ID:           task.ID,
+			Operation:    task.Operation,
+			Args:         task.Args,
+			Dependencies: task.DependencyIndexes,
+			ExactArgs:    task.ExactArgs,
+			ImagArgs:     task.ImagArgs,
+			UpperArgs:    task.UpperArgs,
+		}
+		for i, dep := range task.Dependencies {
+			if dep > 0 {
+				// Аргумент вычисляется корневой задачей другого выражения
+				if taskResponse.ExternalDependencies == nil {
+					taskResponse.ExternalDependencies = make([]int64, len(task.Dependencies))
+				}
+				taskResponse.ExternalDependencies[i] = dep
+			}
+		}
+		for _, guard := range task.Guards {
+			taskResponse.Guards = append(taskResponse.Guards, models.ExplainGuardResponse{Condition: guard.ConditionIndex, Value: guard.Value})
+		}
+		response.Tasks = append(response.Tasks, taskResponse)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "ошибка при кодировании ответа в JSON", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Log.Debugf("Разбор выражения пользователя №%d отправлен", claims.Subject)
+}
+
+// setNumericResult дополняет ответ с выражением точного, десятичного, комплексного или интервального режима
+// режимом вычисления и результатом: точной дробью и ее десятичной записью, десятичной записью заданной точности,
+// мнимой частью и записью комплексного числа "a+bi" или границами интервала [lo, hi].
+//
+// Args:
+//
+//	response: *models.ExpressionResponse - Ответ с выражением.
+//	expression: *models.Expression - Выражение.
+func setNumericResult(response *models.ExpressionResponse, expression *models.Expression) {
+	switch expression.Numeric {
+	case models.NumericRational, models.NumericDecimal:
+	case models.NumericComplex:
+		response.Numeric = expression.Numeric
+		if expression.Result != nil {
+			imag := 0.0
+			if expression.ImagResult != nil {
+				imag = *expression.ImagResult
+			}
+			response.Imag = &imag
+			response.Complex = operators.FormatComplex(complex(*expression.Result, imag))
+		}
+		return
+	case models.NumericInterval:
+		response.Numeric = expression.Numeric
+		if expression.LowerResult != nil && expression.UpperResult != nil {
+			response.Interval = []float64{*expression.LowerResult, *expression.UpperResult}
+		}
+		return
+	default:
+		return
+	}
+	response.Numeric = expression.Numeric
+	if expression.ExactResult == nil {
+		return
+	}
+	if expression.Numeric == models.NumericDecimal {
+		response.Decimal = *expression.ExactResult
+		return
+	}
+	exact, err := operators.ParseExact(*expression.ExactResult)
+	if err != nil {
+		logger.Log.Warnf("Неверный точный результат выражения №%d: %v", expression.ID, err)
+		return
+	}
+	response.Exact = expression.ExactResult
+	response.Decimal = operators.FormatDecimal(exact)
+}
+
+// writeExpressionError записывает в ответ ошибку добавления или разбора выражения.
+// Ошибка разбора (*task_splitter.ParseError) записывается в формате JSON, остальные ошибки - текстом.
+//
+// Args:
+//
+//	w: http.ResponseWriter - Интерфейс для записи HTTP-ответа
+//	err: error - Ошибка выражения
+//	code: int - HTTP-статус ответа
+//	leading: int - Количество байт пробельных символов, отброшенных в начале выражения
+func writeExpressionError(w http.ResponseWriter, err error, code int, leading int) {
+	var parseErr *task_splitter.ParseError
+	if !errors.As(err, &parseErr) {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	// Смещение ошибки разбора считается от начала выражения, переданного клиентом
+	response := *parseErr
+	response.Offset += leading
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Log.Errorf("Не удалось записать ошибку разбора в ответ: %v", err)
+	}
+}
+
 // GetExpressionsHandler обрабатывает HTTP-запрос на получение списка выражений пользователя.
 //
 // Args:
@@ -370,9 +608,13 @@ func (h *Handlers) GetExpressionsHandler(w http.ResponseWriter, r *http.Request)
 			ID:               expression.ID,
 			Status:           expression.Status,
 			ExpressionString: expression.ExpressionString,
+			CanonicalString:  expression.CanonicalString,
 			Result:           expression.Result,
+			Unit:             expression.Unit,
 			Error:            expression.Error,
+			Seed:             expression.Seed,
 		}
+		setNumericResult(&expressionResponse, expression)
 		expressionResponses = append(expressionResponses, expressionResponse)
 	}
 
@@ -400,7 +642,8 @@ func (h *Handlers) GetExpressionsHandler(w http.ResponseWriter, r *http.Request)
 //   - Параметр URL: id - числовой идентификатор выражения
 //
 // Ответ (JSON):
-//   - expression: models.ExpressionResponse - Данные запрошенного выражения
+//   - expression: models.ExpressionResponse - Данные запрошенного выражения,
+//     включая именованные промежуточные значения сценария (variables)
 //
 // Возможные HTTP-статусы ответа:
 //   - 200 OK - при успешном получении выражения
@@ -442,8 +685,24 @@ func (h *Handlers) GetExpressionHandler(w http.ResponseWriter, r *http.Request)
 		ID:               expression.ID,
 		Status:           expression.Status,
 		ExpressionString: expression.ExpressionString,
+		CanonicalString:  expression.CanonicalString,
 		Result:           expression.Result,
+		Unit:             expression.Unit,
 		Error:            expression.Error,
+		Seed:             expression.Seed,
+	}
+	setNumericResult(&expressionResponse, expression)
+
+	for _, variable := range expression.Variables {
+		value := models.VariableResponse{Name: variable.Name, Value: variable.Value}
+		if variable.Estimate {
+			expressionResponse.Estimates = append(expressionResponse.Estimates, value)
+		} else {
+			expressionResponse.Variables = append(expressionResponse.Variables, value)
+		}
+	}
+	if expression.Progress != nil {
+		expressionResponse.Progress = &models.ProgressResponse{Done: expression.Progress.Done, Total: expression.Progress.Total}
 	}
 
 	response := map[string]models.ExpressionResponse{"expression": expressionResponse}
@@ -457,3 +716,187 @@ func (h *Handlers) GetExpressionHandler(w http.ResponseWriter, r *http.Request)
 
 	logger.Log.Debugf("Выражение №%d пользователя №%d отправлено", expression.ID, id)
 }
+
+// AddFunctionHandler обрабатывает HTTP-запрос на добавление функции пользователя.
+// Функцию можно вызывать в выражениях пользователя: "f(2, 3) + 1".
+//
+// Args:
+//
+//	w: http.ResponseWriter - Интерфейс для записи HTTP-ответа
+//	r: *http.Request - Входящий HTTP-запрос
+//
+// Требования:
+//   - Метод: POST
+//   - Заголовок Authorization: Bearer <token>
+//
+// Ожидаемые поля в теле запроса (JSON):
+//   - definition: string - Объявление функции: "f(x, y) = x^2 + y^2"
+//
+// Ответ (JSON):
+//   - function: models.FunctionResponse - Добавленная функция
+//
+// Ответ при ошибке разбора объявления совпадает с ответом AddExpressionHandler,
+// смещение ошибочного фрагмента считается от начала объявления.
+//
+// Возможные HTTP-статусы ответа:
+//   - 200 OK - при успешном добавлении функции
+//   - 400 Bad Request - при пустом или невалидном объявлении, в том числе при рекурсивном вызове
+//   - 405 Method Not Allowed - при неправильном методе запроса
+//   - 409 Conflict - если функция с таким именем уже существует
+//   - 422 Unprocessable Entity - при ошибке парсинга JSON
+//   - 500 Internal Server Error - при внутренних ошибках сервера
+func (h *Handlers) AddFunctionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.ContentLength == 0 {
+		http.Error(w, "пустое тело запроса", http.StatusBadRequest)
+		return
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	claims, _ := h.jwtManager.Validate(token)
+
+	var requestBody models.FunctionAdd
+
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "некорректный запрос", http.StatusUnprocessableEntity)
+		return
+	}
+
+	trimmedBody := strings.TrimSpace(requestBody.Definition)
+	if trimmedBody == "" {
+		http.Error(w, "объявление функции обязательно", http.StatusBadRequest)
+		return
+	}
+
+	// Количество отброшенных пробелов нужно, чтобы смещение ошибки разбора считалось от начала исходного объявления
+	leading := len(requestBody.Definition) - len(strings.TrimLeftFunc(requestBody.Definition, unicode.IsSpace))
+
+	function, err, code := h.exprManager.AddFunction(r.Context(), trimmedBody, claims.Subject)
+	if err != nil {
+		writeExpressionError(w, err, code, leading)
+		return
+	}
+
+	response := map[string]models.FunctionResponse{"function": functionResponse(function)}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "ошибка при кодировании ответа в JSON", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Log.Debugf("Функция %s пользователя №%d создана", function.Name, claims.Subject)
+}
+
+// GetFunctionsHandler обрабатывает HTTP-запрос на получение списка функций пользователя.
+//
+// Args:
+//
+//	w: http.ResponseWriter - Интерфейс для записи HTTP-ответа
+//	r: *http.Request - Входящий HTTP-запрос
+//
+// Требования:
+//   - Метод: GET
+//   - Заголовок Authorization: Bearer <token>
+//
+// Ответ (JSON):
+//   - functions: []models.FunctionResponse - Функции пользователя (пустой массив, если функций нет)
+//
+// Возможные HTTP-статусы ответа:
+//   - 200 OK - при успешном получении списка
+//   - 405 Method Not Allowed - при неправильном методе запроса
+//   - 500 Internal Server Error - при внутренних ошибках сервера
+func (h *Handlers) GetFunctionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	claims, _ := h.jwtManager.Validate(token)
+
+	functions, err, code := h.exprManager.ReadFunctions(r.Context(), claims.Subject)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	functionResponses := make([]models.FunctionResponse, 0, len(functions))
+	for _, function := range functions {
+		functionResponses = append(functionResponses, functionResponse(function))
+	}
+
+	response := map[string][]models.FunctionResponse{"functions": functionResponses}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "ошибка при кодировании ответа в JSON", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Log.Debugf("Список функций пользователя №%d отправлен", claims.Subject)
+}
+
+// DeleteFunctionHandler обрабатывает HTTP-запрос на удаление функции пользователя по имени.
+// Уже добавленные выражения, вызывающие функцию, вычисляются как прежде.
+//
+// Args:
+//
+//	w: http.ResponseWriter - Интерфейс для записи HTTP-ответа
+//	r: *http.Request - Входящий HTTP-запрос с параметром name в URL
+//
+// Требования:
+//   - Метод: DELETE
+//   - Заголовок Authorization: Bearer <token>
+//   - Параметр URL: name - имя функции
+//
+// Возможные HTTP-статусы ответа:
+//   - 200 OK - при успешном удалении
+//   - 404 Not Found - если функция не найдена
+//   - 405 Method Not Allowed - при неправильном методе запроса
+//   - 500 Internal Server Error - при внутренних ошибках сервера
+func (h *Handlers) DeleteFunctionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	claims, _ := h.jwtManager.Validate(token)
+
+	name := mux.Vars(r)["name"]
+	if err, code := h.exprManager.DeleteFunction(r.Context(), name, claims.Subject); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	logger.Log.Debugf("Функция %s пользователя №%d удалена", name, claims.Subject)
+}
+
+// functionResponse преобразует функцию пользователя в HTTP-ответ.
+//
+// Args:
+//
+//	function: *models.Function - Функция пользователя.
+//
+// Returns:
+//
+//	models.FunctionResponse - Функция в HTTP-ответе.
+func functionResponse(function *models.Function) models.FunctionResponse {
+	params := function.Params
+	if params == nil {
+		params = []string{} // Функция без параметров
+	}
+	return models.FunctionResponse{
+		Name:       function.Name,
+		Params:     params,
+		Body:       function.Body,
+		Definition: function.Definition(),
+	}
+}
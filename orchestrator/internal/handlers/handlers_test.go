@@ -7,12 +7,14 @@ import (
 	"github.com/OinkiePie/calc_3/config"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/handlers"
 	mm "github.com/OinkiePie/calc_3/orchestrator/internal/managers"
+	"github.com/OinkiePie/calc_3/orchestrator/internal/task_splitter"
 	mj "github.com/OinkiePie/calc_3/pkg/jwt_manager"
 	"github.com/OinkiePie/calc_3/pkg/logger"
 	"github.com/OinkiePie/calc_3/pkg/models"
 	"github.com/gorilla/mux"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -582,7 +584,7 @@ func TestAddExpressionHandler_CorrectExpression_StatusOK(t *testing.T) {
 
 	testClaims := mj.Claims{Subject: 1}
 	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
-	mockEM.On("AddExpression", mock.Anything, "2+2", testClaims.Subject).
+	mockEM.On("AddExpression", mock.Anything, &models.ExpressionAdd{Expression: "2+2"}, testClaims.Subject).
 		Return(int64(1), nil, http.StatusCreated)
 
 	reqBody := map[string]string{"expression": "2+2"}
@@ -604,6 +606,38 @@ func TestAddExpressionHandler_CorrectExpression_StatusOK(t *testing.T) {
 	mockJWT.AssertExpectations(t)
 }
 
+func TestAddExpressionHandler_WithVariables_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	expected := &models.ExpressionAdd{
+		Expression: "a*x^2+b",
+		Variables:  map[string]float64{"a": 2, "x": 3, "b": -1},
+	}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+	mockEM.On("AddExpression", mock.Anything, expected, testClaims.Subject).
+		Return(int64(2), nil, http.StatusCreated)
+
+	body := `{"expression": " a*x^2+b ", "variables": {"a": 2, "x": 3, "b": -1}}`
+
+	req := httptest.NewRequest(http.MethodPost, "/expressions", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.AddExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]int64
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), response["id"])
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
 func TestAddExpressionHandler_InvalidMethod_StatusMethodNotAllowed(t *testing.T) {
 	h := handlers.NewOrchestratorHandlers(nil, nil, nil)
 
@@ -685,6 +719,50 @@ func TestAddExpressionHandler_EmptyExpression_StatusBadRequest(t *testing.T) {
 	}
 }
 
+func TestAddExpressionHandler_ParseError_StatusBadRequest(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	parseErr := &task_splitter.ParseError{
+		Code:     task_splitter.CodeUnclosedParen,
+		Message:  "не найдена закрывающая скобка",
+		Offset:   4,
+		Length:   1,
+		Expected: ")",
+	}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+	mockEM.On("AddExpression", mock.Anything, &models.ExpressionAdd{Expression: "2 + (3"}, testClaims.Subject).
+		Return(int64(0), parseErr, http.StatusBadRequest)
+
+	// Пробелы в начале выражения учитываются в смещении ошибки
+	body := `{"expression": "  2 + (3"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/expressions", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.AddExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var response map[string]any
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"code":     task_splitter.CodeUnclosedParen,
+		"error":    "не найдена закрывающая скобка",
+		"offset":   float64(6),
+		"length":   float64(1),
+		"expected": ")",
+	}, response)
+	assert.Equal(t, 4, parseErr.Offset)
+	mockJWT.AssertExpectations(t)
+	mockEM.AssertExpectations(t)
+}
+
 func TestAddExpressionHandler_InternalError_StatusInternalServerError(t *testing.T) {
 	mockEM := new(mm.MockExpressionManager)
 	mockJWT := new(mj.MockJWTManager)
@@ -692,7 +770,7 @@ func TestAddExpressionHandler_InternalError_StatusInternalServerError(t *testing
 
 	testClaims := mj.Claims{Subject: 1}
 	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
-	mockEM.On("AddExpression", mock.Anything, "error", int64(1)).
+	mockEM.On("AddExpression", mock.Anything, &models.ExpressionAdd{Expression: "error"}, int64(1)).
 		Return(int64(0), errors.New("error"), http.StatusInternalServerError)
 
 	reqBody := map[string]string{"expression": "error"}
@@ -717,7 +795,7 @@ func TestAddExpressionHandler_JSONEncodeError_StatusInternalServerError(t *testi
 
 	testClaims := mj.Claims{Subject: 1}
 	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
-	mockEM.On("AddExpression", mock.Anything, "2+2", int64(1)).
+	mockEM.On("AddExpression", mock.Anything, &models.ExpressionAdd{Expression: "2+2"}, int64(1)).
 		Return(int64(1), nil, http.StatusCreated)
 
 	reqBody := map[string]string{"expression": "2+2"}
@@ -734,6 +812,150 @@ func TestAddExpressionHandler_JSONEncodeError_StatusInternalServerError(t *testi
 	mockEM.AssertExpectations(t)
 }
 
+func TestExplainExpressionHandler_CorrectExpression_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	one, two := float64(1), float64(2)
+	plan := &task_splitter.Plan{
+		Tasks: []*models.Task{
+			{ID: 1, Operation: "+", Args: []*float64{&one, &two}, Dependencies: []int64{-1, -1}, DependencyIndexes: []int{0, 0}},
+			{ID: 2, Operation: "*", Args: []*float64{nil, nil}, Dependencies: []int64{-1, 31}, DependencyIndexes: []int{1, 0}},
+		},
+		Tokens: []task_splitter.Token{
+			{Kind: task_splitter.TokenSymbol, Text: "(", Offset: 0, Length: 1},
+			{Kind: task_splitter.TokenNumber, Text: "1", Offset: 1, Length: 1},
+		},
+		Folded:    1,
+		Canonical: "(1 + 2) * $7",
+		RPN: [][]task_splitter.Token{{
+			{Kind: task_splitter.TokenNumber, Text: "1"},
+			{Kind: task_splitter.TokenNumber, Text: "2"},
+			{Kind: task_splitter.TokenSymbol, Text: "+"},
+		}},
+	}
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+	mockEM.On("ExplainExpression", mock.Anything, &models.ExpressionAdd{Expression: "(1+2)*$7"}, testClaims.Subject).
+		Return(plan, nil, http.StatusOK)
+
+	body := `{"expression": " (1+2)*$7"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/explain", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.ExplainExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ExplainResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TokenResponse{
+		{Kind: "symbol", Text: "(", Offset: 1, Length: 1},
+		{Kind: "number", Text: "1", Offset: 2, Length: 1},
+	}, response.Tokens)
+	assert.Equal(t, "(1 + 2) * $7", response.Canonical)
+	assert.Equal(t, [][]string{{"1", "2", "+"}}, response.RPN)
+	assert.Equal(t, []models.ExplainTaskResponse{
+		{ID: 1, Operation: "+", Args: []*float64{&one, &two}, Dependencies: []int{0, 0}},
+		{ID: 2, Operation: "*", Args: []*float64{nil, nil}, Dependencies: []int{1, 0}, ExternalDependencies: []int64{0, 31}},
+	}, response.Tasks)
+	assert.Equal(t, 2, response.Depth)
+	assert.Equal(t, 1, response.Parallelism)
+	assert.Equal(t, 1, response.Folded)
+	assert.Equal(t, 2, response.Dispatched)
+	assert.Nil(t, response.Result)
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestExplainExpressionHandler_InvalidMethod_StatusMethodNotAllowed(t *testing.T) {
+	h := handlers.NewOrchestratorHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/explain", nil)
+	w := httptest.NewRecorder()
+
+	h.ExplainExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "метод не поддерживается\n", w.Body.String())
+}
+
+func TestExplainExpressionHandler_EmptyExpression_StatusBadRequest(t *testing.T) {
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, nil, mockJWT)
+
+	mockJWT.On("Validate", "valid.token").Return(mj.Claims{Subject: 1}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/explain", strings.NewReader(`{"expression": "  "}`))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.ExplainExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "выражения обязательно\n", w.Body.String())
+}
+
+func TestExplainExpressionHandler_ParseError_StatusBadRequest(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	parseErr := &task_splitter.ParseError{
+		Code:     task_splitter.CodeNotEnoughOperands,
+		Message:  "недостаточно операндов",
+		Offset:   2,
+		Length:   1,
+		Expected: "операнд",
+	}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+	mockEM.On("ExplainExpression", mock.Anything, &models.ExpressionAdd{Expression: "2 +"}, testClaims.Subject).
+		Return((*task_splitter.Plan)(nil), parseErr, http.StatusBadRequest)
+
+	req := httptest.NewRequest(http.MethodPost, "/explain", strings.NewReader(`{"expression": "2 +"}`))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.ExplainExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response task_splitter.ParseError
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, task_splitter.CodeNotEnoughOperands, response.Code)
+	assert.Equal(t, 2, response.Offset)
+	assert.Equal(t, "операнд", response.Expected)
+	mockEM.AssertExpectations(t)
+}
+
+func TestExplainExpressionHandler_ReferenceError_StatusForbidden(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+	mockEM.On("ExplainExpression", mock.Anything, &models.ExpressionAdd{Expression: "$7*2"}, testClaims.Subject).
+		Return((*task_splitter.Plan)(nil), errors.New("невозможно получить выражение другого пользователя"), http.StatusForbidden)
+
+	req := httptest.NewRequest(http.MethodPost, "/explain", strings.NewReader(`{"expression": "$7*2"}`))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.ExplainExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "невозможно получить выражение другого пользователя\n", w.Body.String())
+	mockEM.AssertExpectations(t)
+}
+
 func TestGetExpressionsHandler_CorrectToken_StatusOK(t *testing.T) {
 	mockEM := new(mm.MockExpressionManager)
 	mockJWT := new(mj.MockJWTManager)
@@ -875,6 +1097,7 @@ func TestGetExpressionHandler_CorrectID_StatusOK(t *testing.T) {
 		UserID:           1,
 		Status:           "completed",
 		ExpressionString: "2+2",
+		CanonicalString:  "2 + 2",
 	}
 	mockEM.On("ReadExpression", mock.Anything, int64(1)).
 		Return(expectedExpression, nil, http.StatusOK)
@@ -895,6 +1118,327 @@ func TestGetExpressionHandler_CorrectID_StatusOK(t *testing.T) {
 	err := json.NewDecoder(w.Body).Decode(&response)
 	assert.NoError(t, err)
 	assert.Equal(t, "2+2", response["expression"].ExpressionString)
+	assert.Equal(t, "2 + 2", response["expression"].CanonicalString)
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestGetExpressionHandler_RationalExpression_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+
+	exact := "1/3"
+	result := 1.0 / 3
+	expectedExpression := &models.Expression{
+		ID:               1,
+		UserID:           1,
+		Status:           "completed",
+		ExpressionString: "1/3",
+		Result:           &result,
+		Numeric:          models.NumericRational,
+		ExactResult:      &exact,
+	}
+	mockEM.On("ReadExpression", mock.Anything, int64(1)).
+		Return(expectedExpression, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/expressions/1", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	w := httptest.NewRecorder()
+
+	h.GetExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]models.ExpressionResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.NumericRational, response["expression"].Numeric)
+	if assert.NotNil(t, response["expression"].Exact) {
+		assert.Equal(t, "1/3", *response["expression"].Exact)
+	}
+	assert.Equal(t, "0.33333333333333333333", response["expression"].Decimal)
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestGetExpressionHandler_DecimalExpression_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+
+	decimal := "1.4142135623730950488016887242096980785696718753769"
+	result := math.Sqrt2
+	expectedExpression := &models.Expression{
+		ID:               1,
+		UserID:           1,
+		Status:           "completed",
+		ExpressionString: "2^0.5",
+		Result:           &result,
+		Numeric:          models.NumericDecimal,
+		ExactResult:      &decimal,
+	}
+	mockEM.On("ReadExpression", mock.Anything, int64(1)).
+		Return(expectedExpression, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/expressions/1", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	w := httptest.NewRecorder()
+
+	h.GetExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]models.ExpressionResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.NumericDecimal, response["expression"].Numeric)
+	assert.Nil(t, response["expression"].Exact)
+	assert.Equal(t, decimal, response["expression"].Decimal)
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestGetExpressionHandler_ComplexExpression_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+
+	result, imag := 5.0, 5.0
+	expectedExpression := &models.Expression{
+		ID:               1,
+		UserID:           1,
+		Status:           "completed",
+		ExpressionString: "(1+2i)*(3-i)",
+		Result:           &result,
+		Numeric:          models.NumericComplex,
+		ImagResult:       &imag,
+	}
+	mockEM.On("ReadExpression", mock.Anything, int64(1)).
+		Return(expectedExpression, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/expressions/1", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	w := httptest.NewRecorder()
+
+	h.GetExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]models.ExpressionResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.NumericComplex, response["expression"].Numeric)
+	assert.Equal(t, 5.0, *response["expression"].Imag)
+	assert.Equal(t, "5+5i", response["expression"].Complex)
+	assert.Empty(t, response["expression"].Decimal)
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestGetExpressionHandler_IntervalExpression_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+
+	result, lower, upper := 6.0, 5.655, 6.355
+	expectedExpression := &models.Expression{
+		ID:               1,
+		UserID:           1,
+		Status:           "completed",
+		ExpressionString: "3.0±0.1 * 2.0±0.05",
+		Result:           &result,
+		Numeric:          models.NumericInterval,
+		LowerResult:      &lower,
+		UpperResult:      &upper,
+	}
+	mockEM.On("ReadExpression", mock.Anything, int64(1)).
+		Return(expectedExpression, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/expressions/1", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	w := httptest.NewRecorder()
+
+	h.GetExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]models.ExpressionResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.NumericInterval, response["expression"].Numeric)
+	assert.Equal(t, []float64{5.655, 6.355}, response["expression"].Interval)
+	assert.Equal(t, 6.0, *response["expression"].Result)
+	assert.Nil(t, response["expression"].Imag)
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestGetExpressionHandler_UnitExpression_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+
+	result := 13.3
+	expectedExpression := &models.Expression{
+		ID:               1,
+		UserID:           1,
+		Status:           "completed",
+		ExpressionString: "5 km / 2 h + 3 m/s in km/h",
+		Result:           &result,
+		Unit:             "km/h",
+		Numeric:          models.NumericFloat,
+	}
+	mockEM.On("ReadExpression", mock.Anything, int64(1)).
+		Return(expectedExpression, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/expressions/1", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	w := httptest.NewRecorder()
+
+	h.GetExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]models.ExpressionResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, 13.3, *response["expression"].Result)
+	assert.Equal(t, "km/h", response["expression"].Unit)
+	assert.Empty(t, response["expression"].Numeric)
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestGetExpressionHandler_ScriptVariables_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+
+	r := 5.0
+	expectedExpression := &models.Expression{
+		ID:               1,
+		UserID:           1,
+		Status:           "processing",
+		ExpressionString: "r = 5; area = 3.14159*r^2; area*2",
+		Variables: []*models.ExpressionVariable{
+			{Name: "r", Value: &r},
+			{Name: "area", TaskIndex: 2, TaskID: 7},
+		},
+	}
+	mockEM.On("ReadExpression", mock.Anything, int64(1)).
+		Return(expectedExpression, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/expressions/1", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	w := httptest.NewRecorder()
+
+	h.GetExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"variables":[{"name":"r","value":5},{"name":"area"}]`)
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestGetExpressionHandler_EstimatesAndProgress_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+
+	e := 1e-10
+	expectedExpression := &models.Expression{
+		ID:               1,
+		UserID:           1,
+		Status:           "processing",
+		ExpressionString: "solve(x^2 - 2, x, 0, 2)",
+		Variables: []*models.ExpressionVariable{
+			{Name: "solve#1.error", Value: &e, Estimate: true},
+			{Name: "solve#1.residual", TaskID: 7, Estimate: true},
+		},
+		Progress: &models.ExpressionProgress{Done: 12, Total: 34},
+	}
+	mockEM.On("ReadExpression", mock.Anything, int64(1)).
+		Return(expectedExpression, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/expressions/1", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	w := httptest.NewRecorder()
+
+	h.GetExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"estimates":[{"name":"solve#1.error","value":1e-10},{"name":"solve#1.residual"}]`)
+	assert.Contains(t, w.Body.String(), `"progress":{"done":12,"total":34}`)
+	assert.NotContains(t, w.Body.String(), `"variables"`)
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestGetExpressionHandler_Seed_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+
+	seed := int64(12345)
+	expectedExpression := &models.Expression{
+		ID:               1,
+		UserID:           1,
+		Status:           "pending",
+		ExpressionString: "randint(1, 6)",
+		Seed:             &seed,
+	}
+	mockEM.On("ReadExpression", mock.Anything, int64(1)).
+		Return(expectedExpression, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/expressions/1", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	w := httptest.NewRecorder()
+
+	h.GetExpressionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"seed":12345`)
 	mockEM.AssertExpectations(t)
 	mockJWT.AssertExpectations(t)
 }
@@ -1049,3 +1593,208 @@ func TestGetExpressionHandler_JSONEncodeError_StatusInternalServerError(t *testi
 	mockEM.AssertExpectations(t)
 	mockJWT.AssertExpectations(t)
 }
+
+func TestAddFunctionHandler_CorrectDefinition_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+	mockEM.On("AddFunction", mock.Anything, "f(x, y) = x^2 + y^2", testClaims.Subject).
+		Return(&models.Function{ID: 1, UserID: 1, Name: "f", Params: []string{"x", "y"}, Body: "x^2 + y^2"}, nil, http.StatusCreated)
+
+	body := `{"definition": " f(x, y) = x^2 + y^2 "}`
+
+	req := httptest.NewRequest(http.MethodPost, "/define", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.AddFunctionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]models.FunctionResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.FunctionResponse{
+		Name:       "f",
+		Params:     []string{"x", "y"},
+		Body:       "x^2 + y^2",
+		Definition: "f(x, y) = x^2 + y^2",
+	}, response["function"])
+	mockEM.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestAddFunctionHandler_InvalidMethod_StatusMethodNotAllowed(t *testing.T) {
+	h := handlers.NewOrchestratorHandlers(nil, nil, nil)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		req := httptest.NewRequest(method, "/define", nil)
+		w := httptest.NewRecorder()
+
+		h.AddFunctionHandler(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestAddFunctionHandler_EmptyDefinition_StatusBadRequest(t *testing.T) {
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, nil, mockJWT)
+
+	mockJWT.On("Validate", "valid.token").Return(mj.Claims{Subject: 1}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/define", strings.NewReader(`{"definition": "  "}`))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.AddFunctionHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "объявление функции обязательно\n", w.Body.String())
+}
+
+func TestAddFunctionHandler_RecursiveFunction_StatusBadRequest(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	parseErr := &task_splitter.ParseError{
+		Code:    task_splitter.CodeRecursiveFunction,
+		Message: "функция f вызывает сама себя",
+		Offset:  7,
+		Length:  8,
+	}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+	mockEM.On("AddFunction", mock.Anything, "f(x) = f(x - 1)", testClaims.Subject).
+		Return((*models.Function)(nil), parseErr, http.StatusBadRequest)
+
+	req := httptest.NewRequest(http.MethodPost, "/define", strings.NewReader(`{"definition": "  f(x) = f(x - 1)"}`))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.AddFunctionHandler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response task_splitter.ParseError
+	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, task_splitter.CodeRecursiveFunction, response.Code)
+	assert.Equal(t, 9, response.Offset)
+	mockEM.AssertExpectations(t)
+}
+
+func TestAddFunctionHandler_DuplicateName_StatusConflict(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+	mockEM.On("AddFunction", mock.Anything, "f(x) = x", testClaims.Subject).
+		Return((*models.Function)(nil), errors.New("функция f уже существует"), http.StatusConflict)
+
+	req := httptest.NewRequest(http.MethodPost, "/define", strings.NewReader(`{"definition": "f(x) = x"}`))
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.AddFunctionHandler(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "функция f уже существует\n", w.Body.String())
+	mockEM.AssertExpectations(t)
+}
+
+func TestGetFunctionsHandler_CorrectToken_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	testClaims := mj.Claims{Subject: 1}
+	mockJWT.On("Validate", "valid.token").Return(testClaims, nil)
+	mockEM.On("ReadFunctions", mock.Anything, int64(1)).
+		Return([]*models.Function{{Name: "answer", Body: "42"}}, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/functions", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.GetFunctionsHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"functions": [{"name": "answer", "params": [], "body": "42", "definition": "answer() = 42"}]}`, w.Body.String())
+	mockEM.AssertExpectations(t)
+}
+
+func TestGetFunctionsHandler_NoFunctions_EmptyList(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	mockJWT.On("Validate", "valid.token").Return(mj.Claims{Subject: 1}, nil)
+	mockEM.On("ReadFunctions", mock.Anything, int64(1)).
+		Return([]*models.Function{}, nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodGet, "/functions", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	w := httptest.NewRecorder()
+
+	h.GetFunctionsHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"functions": []}`, w.Body.String())
+}
+
+func TestDeleteFunctionHandler_ExistingFunction_StatusOK(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	mockJWT.On("Validate", "valid.token").Return(mj.Claims{Subject: 1}, nil)
+	mockEM.On("DeleteFunction", mock.Anything, "f", int64(1)).Return(nil, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodDelete, "/functions/f", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"name": "f"})
+	w := httptest.NewRecorder()
+
+	h.DeleteFunctionHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockEM.AssertExpectations(t)
+}
+
+func TestDeleteFunctionHandler_UnknownFunction_StatusNotFound(t *testing.T) {
+	mockEM := new(mm.MockExpressionManager)
+	mockJWT := new(mj.MockJWTManager)
+	h := handlers.NewOrchestratorHandlers(nil, mockEM, mockJWT)
+
+	mockJWT.On("Validate", "valid.token").Return(mj.Claims{Subject: 1}, nil)
+	mockEM.On("DeleteFunction", mock.Anything, "g", int64(1)).
+		Return(errors.New("функция g не найдена"), http.StatusNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/functions/g", nil)
+	req.Header.Set("Authorization", "Bearer valid.token")
+	req = mux.SetURLVars(req, map[string]string{"name": "g"})
+	w := httptest.NewRecorder()
+
+	h.DeleteFunctionHandler(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "функция g не найдена\n", w.Body.String())
+}
+
+func TestDeleteFunctionHandler_InvalidMethod_StatusMethodNotAllowed(t *testing.T) {
+	h := handlers.NewOrchestratorHandlers(nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/functions/f", nil)
+	w := httptest.NewRecorder()
+
+	h.DeleteFunctionHandler(w, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -3,11 +3,15 @@ package expressions_manager
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/OinkiePie/calc_3/config"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/task_splitter"
 	"github.com/OinkiePie/calc_3/pkg/models"
 	"github.com/OinkiePie/calc_3/pkg/operators"
+	"math/big"
+	"math/rand/v2"
 	"net/http"
 )
 
@@ -16,6 +20,7 @@ type ExpressionManager struct {
 	db       *sql.DB                                     // Подключение к базе данных
 	exprRepo repositories.ExpressionsRepositoryInterface // Репозиторий выражений
 	taskRepo repositories.TasksRepositoryInterface       // Репозиторий задач
+	funcRepo repositories.FunctionsRepositoryInterface   // Репозиторий функций пользователей
 }
 
 // NewExpressionManager создает новый экземпляр менеджера выражений.
@@ -25,6 +30,7 @@ type ExpressionManager struct {
 //	db: *sql.DB - Подключение к базе данных
 //	exprRepo: *repositories2.ExpressionsRepository - Репозиторий выражений
 //	taskRepo: *repositories2.TasksRepository - Репозиторий задач
+//	funcRepo: *repositories2.FunctionsRepository - Репозиторий функций пользователей
 //
 // Returns:
 //
@@ -33,20 +39,34 @@ func NewExpressionManager(
 	db *sql.DB,
 	exprRepo repositories.ExpressionsRepositoryInterface,
 	taskRepo repositories.TasksRepositoryInterface,
+	funcRepo repositories.FunctionsRepositoryInterface,
 ) *ExpressionManager {
 	return &ExpressionManager{
 		db:       db,
 		exprRepo: exprRepo,
 		taskRepo: taskRepo,
+		funcRepo: funcRepo,
 	}
 }
 
 // AddExpression добавляет новое выражение в систему и создает связанные задачи.
 //
+// Выражение может ссылаться на результаты других выражений пользователя ($42). Результат вычисленного
+// выражения подставляется как число, а от ещё не вычисленного выражения задачи зависят через его
+// корневую задачу и ожидают его завершения.
+//
+// Операции над числами могут быть вычислены сразу согласно политике свертки (FOLD_POLICY).
+// Если свернуты все операции, выражение сохраняется уже вычисленным.
+//
+// Вместе с выражением сохраняется его каноническая запись (например, "x + 2 * 3" для "3*2+x").
+//
+// Выражению с функциями случайных чисел назначается зерно (переданное пользователем или случайное),
+// из которого выводятся зерна его задач. Выражение, добавленное повторно с тем же зерном, вычисляется одинаково.
+//
 // Args:
 //
 //	ctx: context.Context - Контекст выполнения.
-//	expressionString: string - Строка с математическим выражением.
+//	expressionAdd: *models.ExpressionAdd - Строка с математическим выражением и значения переменных.
 //	claims: int64 - ID пользователя-владельца.
 //
 // Returns:
@@ -55,30 +75,57 @@ func NewExpressionManager(
 //	error - Ошибка выполнения.
 //	int - HTTP статус код:
 //		- 201 Created при успешном выполнении
-//		- 400 Bad Request при невозможность преобразовать выражение
+//		- 400 Bad Request при невозможность преобразовать выражение, несвязанной переменной
+//		  или ссылке на выражение, завершившееся с ошибкой
+//		- 403 Forbidden при ссылке на выражение другого пользователя
+//		- 404 Not Found при ссылке на несуществующее выражение
 //		- 500 Internal Server Error при ошибках
-func (m *ExpressionManager) AddExpression(ctx context.Context, expressionString string, claims int64) (int64, error, int) {
-	tasks, err := task_splitter.ParseExpression(expressionString)
+func (m *ExpressionManager) AddExpression(ctx context.Context, expressionAdd *models.ExpressionAdd, claims int64) (int64, error, int) {
+	tx, err := m.db.BeginTx(ctx, nil)
 	if err != nil {
-		return 0, err, http.StatusBadRequest
+		return 0, fmt.Errorf("не удалось начать добавление выражения: %w", err), http.StatusInternalServerError
+	}
+	defer tx.Rollback()
+
+	plan, err, code := m.parseExpression(ctx, tx, expressionAdd, claims)
+	if err != nil {
+		return 0, err, code
 	}
 
 	expression := models.Expression{
-		Tasks:            tasks,
-		ExpressionString: expressionString,
+		Tasks:            plan.Tasks,
+		Variables:        plan.Variables,
+		ExpressionString: expressionAdd.Expression,
+		CanonicalString:  plan.Canonical,
+		Unit:             plan.Unit,
+		Numeric:          models.NumericFloat,
 		UserID:           claims,
+		Seed:             expressionSeed(plan.Tasks, expressionAdd.Seed),
 	}
-
-	tx, err := m.db.BeginTx(ctx, nil)
-	if err != nil {
-		return 0, fmt.Errorf("не удалось начать добавление выражения: %w", err), http.StatusInternalServerError
+	if expressionAdd.Numeric != "" {
+		expression.Numeric = expressionAdd.Numeric
+	}
+	if expression.Seed != nil {
+		for i, task := range expression.Tasks {
+			if operator, ok := operators.Lookup(task.Operation); ok && operator.Random != nil {
+				task.Seed = operators.TaskSeed(*expression.Seed, i+1)
+			}
+		}
 	}
-	defer tx.Rollback()
 
 	id, err, code := m.exprRepo.CreateExpression(ctx, tx, &expression)
 	if err != nil {
 		return 0, err, code
 	}
+	if plan.Result != nil {
+		// Все операции свернуты при разборе - выражение вычислено без агентов
+		if err, code = m.exprRepo.UpdateExpressionStatus(ctx, tx, id, "completed"); err != nil {
+			return 0, err, code
+		}
+		if err, code = m.exprRepo.UpdateExpressionResult(ctx, tx, id, *plan.Result); err != nil {
+			return 0, err, code
+		}
+	}
 	for _, task := range expression.Tasks {
 		task.Expression = id
 		if err, code = m.taskRepo.UpdateTaskExpressionID(ctx, tx, task.ID, id); err != nil {
@@ -93,6 +140,308 @@ func (m *ExpressionManager) AddExpression(ctx context.Context, expressionString
 	return id, nil, http.StatusCreated
 }
 
+// ExplainExpression разбирает выражение так же, как AddExpression, но ничего не сохраняет.
+// Используется для проверки выражения и просмотра задач, которые будут отправлены агентам.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения.
+//	expressionAdd: *models.ExpressionAdd - Строка с математическим выражением и значения переменных.
+//	claims: int64 - ID пользователя.
+//
+// Returns:
+//
+//	*task_splitter.Plan - Результат разбора выражения: токены, RPN и задачи.
+//	error - Ошибка выполнения.
+//	int - HTTP статус код:
+//		- 200 OK при успешном разборе
+//		- 400 Bad Request при невозможность преобразовать выражение, несвязанной переменной
+//		  или ссылке на выражение, завершившееся с ошибкой
+//		- 403 Forbidden при ссылке на выражение другого пользователя
+//		- 404 Not Found при ссылке на несуществующее выражение
+//		- 500 Internal Server Error при ошибках
+func (m *ExpressionManager) ExplainExpression(ctx context.Context, expressionAdd *models.ExpressionAdd, claims int64) (*task_splitter.Plan, error, int) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать разбор выражения: %w", err), http.StatusInternalServerError
+	}
+	// Транзакция только читает выражения, на которые ссылается разбираемое выражение, и всегда откатывается
+	defer tx.Rollback()
+
+	plan, err, code := m.parseExpression(ctx, tx, expressionAdd, claims)
+	if err != nil {
+		return nil, err, code
+	}
+
+	return plan, nil, http.StatusOK
+}
+
+// parseExpression разбирает выражение пользователя на задачи, получая значения ссылок на другие выражения.
+// Вызовы функций пользователя заменяются их телами, поэтому задачи выражения не зависят от функций
+// и удаление функции не затрагивает уже добавленные выражения.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения.
+//	tx: *sql.Tx - Транзакция, в которой читаются выражения, на которые указывают ссылки.
+//	expressionAdd: *models.ExpressionAdd - Строка с математическим выражением и значения переменных.
+//	claims: int64 - ID пользователя.
+//
+// Returns:
+//
+//	*task_splitter.Plan - Результат разбора выражения.
+//	error - Ошибка разбора или получения ссылки.
+//	int - HTTP статус код ошибки (см. resolveReference), 400 Bad Request при ошибке разбора,
+//	      неизвестном режиме вычисления или недопустимой точности, 500 Internal Server Error при ошибке
+//	      получения функций пользователя.
+func (m *ExpressionManager) parseExpression(ctx context.Context, tx *sql.Tx, expressionAdd *models.ExpressionAdd, claims int64) (*task_splitter.Plan, error, int) {
+	switch expressionAdd.Numeric {
+	case "", models.NumericFloat, models.NumericRational, models.NumericDecimal, models.NumericComplex, models.NumericInterval:
+	default:
+		return nil, fmt.Errorf("неизвестный режим вычисления: %s", expressionAdd.Numeric), http.StatusBadRequest
+	}
+
+	precision := 0 // Точность десятичного режима в значащих цифрах
+	switch {
+	case expressionAdd.Numeric != models.NumericDecimal:
+		if expressionAdd.Precision != 0 {
+			return nil, errors.New("точность задается только в режиме decimal"), http.StatusBadRequest
+		}
+	case expressionAdd.Precision == 0:
+		precision = operators.DefaultPrecision
+	case expressionAdd.Precision < 0 || expressionAdd.Precision > operators.MaxPrecision:
+		return nil, fmt.Errorf("точность должна быть от 1 до %d значащих цифр", operators.MaxPrecision), http.StatusBadRequest
+	default:
+		precision = expressionAdd.Precision
+	}
+
+	functions, err, code := m.readFunctions(ctx, tx, claims)
+	if err != nil {
+		return nil, err, code
+	}
+
+	refCode := http.StatusBadRequest // Код ответа при ошибке получения ссылки
+	resolve := func(id int64) (*task_splitter.Reference, error) {
+		ref, err, code := m.resolveReference(ctx, tx, id, claims)
+		if err != nil {
+			refCode = code
+		}
+		return ref, err
+	}
+
+	plan, err := task_splitter.ParseExpression(expressionAdd.Expression, task_splitter.Options{
+		Variables:        expressionAdd.Variables,
+		ResolveReference: resolve,
+		LegacyPrecedence: config.Cfg.Splitter.LEGACY_PRECEDENCE,
+		BalanceChains:    config.Cfg.Splitter.BALANCE_CHAINS,
+		Fold:             task_splitter.FoldPolicy(config.Cfg.Splitter.FOLD_POLICY),
+		FoldThreshold:    config.Cfg.Splitter.FOLD_THRESHOLD_MS,
+		OperationTime:    config.Cfg.Math.OperationTime,
+		SeriesLimit:      config.Cfg.Splitter.SERIES_LIMIT,
+		Exact:            expressionAdd.Numeric == models.NumericRational,
+		Precision:        precision,
+		Complex:          expressionAdd.Numeric == models.NumericComplex,
+		Interval:         expressionAdd.Numeric == models.NumericInterval,
+		Functions:        functions,
+	})
+	if err != nil {
+		return nil, err, refCode
+	}
+
+	return plan, nil, http.StatusOK
+}
+
+// resolveReference получает значение выражения, на которое ссылается новое выражение ($42).
+// Владелец выражения проверяется так же, как при получении выражения по ID.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения.
+//	tx: *sql.Tx - Транзакция добавления выражения.
+//	id: int64 - ID выражения, на которое указывает ссылка.
+//	claims: int64 - ID пользователя, добавляющего выражение.
+//
+// Returns:
+//
+//	*task_splitter.Reference - Результат выражения, если оно вычислено, или ID его корневой задачи, если оно ещё вычисляется.
+//	error - Ошибка выполнения.
+//	int - HTTP статус код:
+//		- 200 OK при успешном получении
+//		- 400 Bad Request если выражение завершилось с ошибкой
+//		- 403 Forbidden если выражение принадлежит другому пользователю
+//		- 404 Not Found если выражение не найдено
+//		- 500 Internal Server Error при ошибках
+func (m *ExpressionManager) resolveReference(ctx context.Context, tx *sql.Tx, id, claims int64) (*task_splitter.Reference, error, int) {
+	expression, err, code := m.exprRepo.ReadExpressionByID(ctx, tx, id)
+	if err != nil {
+		return nil, err, code
+	}
+
+	if expression.UserID != claims {
+		return nil, errors.New("невозможно получить выражение другого пользователя"), http.StatusForbidden
+	}
+
+	switch expression.Status {
+	case "completed":
+		return &task_splitter.Reference{
+			Result:      expression.Result,
+			ExactResult: expression.ExactResult,
+			ImagResult:  expression.ImagResult,
+			LowerResult: expression.LowerResult,
+			UpperResult: expression.UpperResult,
+			Unit:        expression.Unit,
+		}, nil, http.StatusOK
+	case "error":
+		return nil, fmt.Errorf("выражение №%d завершилось с ошибкой: %s", id, expression.Error), http.StatusBadRequest
+	}
+
+	if len(expression.Tasks) == 0 {
+		return nil, fmt.Errorf("у выражения №%d нет задач", id), http.StatusInternalServerError
+	}
+
+	// Корневая задача выражения - задача с наибольшим ID
+	var rootID int64
+	for _, task := range expression.Tasks {
+		if task.ID > rootID {
+			rootID = task.ID
+		}
+	}
+
+	return &task_splitter.Reference{TaskID: rootID, Unit: expression.Unit}, nil, http.StatusOK
+}
+
+// AddFunction добавляет функцию пользователя, например "f(x, y) = x^2 + y^2".
+// Тело функции может вызывать ранее добавленные функции пользователя, но не может вызывать само себя.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения.
+//	definition: string - Объявление функции.
+//	claims: int64 - ID пользователя-владельца.
+//
+// Returns:
+//
+//	*models.Function - Добавленная функция.
+//	error - Ошибка выполнения.
+//	int - HTTP статус код:
+//		- 201 Created при успешном выполнении
+//		- 400 Bad Request при ошибке разбора объявления
+//		- 409 Conflict если у пользователя уже есть функция с таким именем
+//		- 500 Internal Server Error при ошибках
+func (m *ExpressionManager) AddFunction(ctx context.Context, definition string, claims int64) (*models.Function, error, int) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать добавление функции: %w", err), http.StatusInternalServerError
+	}
+	defer tx.Rollback()
+
+	functions, err, code := m.readFunctions(ctx, tx, claims)
+	if err != nil {
+		return nil, err, code
+	}
+
+	name, parsed, err := task_splitter.ParseFunction(definition, functions)
+	if err != nil {
+		return nil, err, http.StatusBadRequest
+	}
+
+	function := models.Function{
+		UserID: claims,
+		Name:   name,
+		Params: parsed.Params,
+		Body:   parsed.Body,
+	}
+	function.ID, err, code = m.funcRepo.CreateFunction(ctx, tx, &function)
+	if err != nil {
+		return nil, err, code
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("не удалось создать функцию: %w", err), http.StatusInternalServerError
+	}
+
+	return &function, nil, http.StatusCreated
+}
+
+// ReadFunctions получает все функции пользователя.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения.
+//	claims: int64 - ID пользователя.
+//
+// Returns:
+//
+//	[]*models.Function - Функции пользователя. Пустой список, если функций нет.
+//	error - Ошибка выполнения.
+//	int - HTTP статус код:
+//		- 200 OK при успешном выполнении
+//		- 500 Internal Server Error при ошибках
+func (m *ExpressionManager) ReadFunctions(ctx context.Context, claims int64) ([]*models.Function, error, int) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать получение функций: %w", err), http.StatusInternalServerError
+	}
+	defer tx.Rollback()
+
+	functions, err, code := m.funcRepo.ReadFunctionsByUserID(ctx, tx, claims)
+	if err != nil {
+		return nil, err, code
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("не удалось получить функции: %w", err), http.StatusInternalServerError
+	}
+
+	return functions, nil, http.StatusOK
+}
+
+// DeleteFunction удаляет функцию пользователя. Тела функций подставляются в выражения при добавлении,
+// поэтому уже добавленные выражения, вызывающие функцию, вычисляются как прежде.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения.
+//	name: string - Имя функции.
+//	claims: int64 - ID пользователя-владельца.
+//
+// Returns:
+//
+//	error - Ошибка выполнения.
+//	int - HTTP статус код:
+//		- 200 OK при успешном удалении
+//		- 404 Not Found если у пользователя нет функции с таким именем
+//		- 500 Internal Server Error при ошибках
+func (m *ExpressionManager) DeleteFunction(ctx context.Context, name string, claims int64) (error, int) {
+	return m.funcRepo.DeleteFunction(ctx, claims, name)
+}
+
+// readFunctions получает функции пользователя в виде, используемом при разборе выражений.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения.
+//	tx: *sql.Tx - Транзакция, в которой читаются функции.
+//	claims: int64 - ID пользователя.
+//
+// Returns:
+//
+//	map[string]task_splitter.Function - Функции пользователя по именам.
+//	error - Ошибка выполнения.
+//	int - HTTP статус код ошибки (см. FunctionsRepository.ReadFunctionsByUserID).
+func (m *ExpressionManager) readFunctions(ctx context.Context, tx *sql.Tx, claims int64) (map[string]task_splitter.Function, error, int) {
+	stored, err, code := m.funcRepo.ReadFunctionsByUserID(ctx, tx, claims)
+	if err != nil {
+		return nil, err, code
+	}
+
+	functions := make(map[string]task_splitter.Function, len(stored))
+	for _, function := range stored {
+		functions[function.Name] = task_splitter.Function{Params: function.Params, Body: function.Body}
+	}
+	return functions, nil, http.StatusOK
+}
+
 // ReadExpressions получает все выражения пользователя.
 //
 // Args:
@@ -127,7 +476,8 @@ func (m *ExpressionManager) ReadExpressions(ctx context.Context, id int64) ([]*m
 	return expressions, nil, http.StatusOK
 }
 
-// ReadExpression получает выражение по его ID.
+// ReadExpression получает выражение по его ID вместе с переменными и, пока выражение вычисляется,
+// ходом вычисления итераций integrate и solve.
 //
 // Args:
 //
@@ -154,6 +504,19 @@ func (m *ExpressionManager) ReadExpression(ctx context.Context, id int64) (*mode
 		return nil, err, code
 	}
 
+	expression.Variables, err, code = m.exprRepo.ReadExpressionVariables(ctx, tx, id)
+	if err != nil {
+		return nil, err, code
+	}
+
+	// Задачи вычисленного выражения удалены, поэтому ход итераций известен только до завершения
+	if expression.Status == "pending" || expression.Status == "processing" {
+		expression.Progress, err, code = m.taskRepo.ReadIterationProgress(ctx, tx, id)
+		if err != nil {
+			return nil, err, code
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("не удалось отправить выражение: %w", err), http.StatusInternalServerError
 	}
@@ -162,7 +525,8 @@ func (m *ExpressionManager) ReadExpression(ctx context.Context, id int64) (*mode
 }
 
 // ReadTask находит и возвращает следующую задачу для выполнения.
-// Проверяет готовность зависимостей и обновляет статусы.
+// Проверяет готовность зависимостей и обновляет статусы. Задача невыбранной ветви if (статус "skipped")
+// передается аргументом нулем: ее значение не выбирается.
 //
 // Args:
 //
@@ -190,13 +554,20 @@ func (m *ExpressionManager) ReadTask(ctx context.Context) (*models.Task, error,
 
 outerLoop:
 	for _, task := range tasks {
+		// Количество аргументов задает операция, а для функций с переменным числом аргументов - задача
+		arity := len(task.Args)
+		if operator, ok := operators.Lookup(task.Operation); ok {
+			arity = operator.ArgCount(len(task.Args))
+		}
 		for i := range task.Args {
-			if task.Args[i] == nil && (i == 0 || task.Operation != operators.OpUnaryMinus) {
+			if task.Args[i] == nil && i < arity {
 				dep, err, code := m.taskRepo.ReadTaskByID(ctx, tx, task.Dependencies[i])
 				if dep == nil {
 					return nil, err, code
 				}
-				if dep.Status != "completed" {
+				if dep.Status == "skipped" {
+					dep.Result, dep.ExactResult, dep.ImagResult, dep.UpperResult = new(float64), nil, nil, nil
+				} else if dep.Status != "completed" {
 					continue outerLoop
 				}
 				err, code = m.taskRepo.UpdateTaskArguments(ctx, tx, task.ID, i, dep.Result)
@@ -204,6 +575,34 @@ outerLoop:
 					return nil, err, code
 				}
 				task.Args[i] = dep.Result
+
+				if task.HasExactArgs() {
+					exact := exactValue(task, *dep.Result, dep.ExactResult)
+					if err, code = m.taskRepo.UpdateTaskExactArgument(ctx, tx, task.ID, i, exact); err != nil {
+						return nil, err, code
+					}
+					task.ExactArgs[i] = &exact
+				}
+				if task.Complex {
+					imag := 0.0 // Действительный результат зависимости не имеет мнимой части
+					if dep.ImagResult != nil {
+						imag = *dep.ImagResult
+					}
+					if err, code = m.taskRepo.UpdateTaskImagArgument(ctx, tx, task.ID, i, imag); err != nil {
+						return nil, err, code
+					}
+					task.ImagArgs[i] = &imag
+				}
+				if task.Interval {
+					upper := *dep.Result // Вырожденный интервал зависимости имеет равные границы
+					if dep.UpperResult != nil {
+						upper = *dep.UpperResult
+					}
+					if err, code = m.taskRepo.UpdateTaskUpperArgument(ctx, tx, task.ID, i, upper); err != nil {
+						return nil, err, code
+					}
+					task.UpperArgs[i] = &upper
+				}
 			}
 		}
 
@@ -225,6 +624,7 @@ outerLoop:
 
 // CompleteTask завершает выполнение задачи и обновляет связанные данные.
 // При ошибке в задаче помечает всё выражение как ошибочное.
+// Если задача - условие ветвей if, задачи невыбранной ветви получают статус "skipped" и не вычисляются.
 //
 // Args:
 //
@@ -245,13 +645,7 @@ func (m *ExpressionManager) CompleteTask(ctx context.Context, taskCompleted *mod
 	defer tx.Rollback()
 
 	if taskCompleted.Error != "" {
-		if err, code := m.exprRepo.UpdateExpressionError(ctx, tx, taskCompleted.Expression, taskCompleted.Error); err != nil {
-			return err, code
-		}
-		if err, code := m.exprRepo.UpdateExpressionStatus(ctx, tx, taskCompleted.Expression, "error"); err != nil {
-			return err, code
-		}
-		if err, code := m.taskRepo.DeleteTasks(ctx, tx, taskCompleted.Expression); err != nil {
+		if err, code := m.failExpression(ctx, tx, taskCompleted.Expression, taskCompleted.Error); err != nil {
 			return err, code
 		}
 
@@ -264,27 +658,106 @@ func (m *ExpressionManager) CompleteTask(ctx context.Context, taskCompleted *mod
 	if err, code := m.taskRepo.UpdateTaskResult(ctx, tx, taskCompleted.Result, taskCompleted.ID); err != nil {
 		return err, code
 	}
+	if taskCompleted.ExactResult != "" {
+		if err, code := m.taskRepo.UpdateTaskExactResult(ctx, tx, taskCompleted.ExactResult, taskCompleted.ID); err != nil {
+			return err, code
+		}
+	}
+	if taskCompleted.ImagResult != 0 {
+		if err, code := m.taskRepo.UpdateTaskImagResult(ctx, tx, taskCompleted.ImagResult, taskCompleted.ID); err != nil {
+			return err, code
+		}
+	}
+	if taskCompleted.UpperResult != nil {
+		if err, code := m.taskRepo.UpdateTaskUpperResult(ctx, tx, *taskCompleted.UpperResult, taskCompleted.ID); err != nil {
+			return err, code
+		}
+	}
 	if err, code := m.taskRepo.UpdateTaskStatus(ctx, tx, taskCompleted.ID, "completed"); err != nil {
 		return err, code
 	}
+	if err, code := m.taskRepo.SkipGuardedTasks(ctx, tx, taskCompleted.ID, truth(taskCompleted)); err != nil {
+		return err, code
+	}
 
 	tasks, err, code := m.taskRepo.ReadTasksByExpressionID(ctx, tx, taskCompleted.Expression)
 	if err != nil {
 		return err, code
 	}
 	allCompleted := true
+	// Корневая задача выражения - задача с наибольшим ID: разбиение всегда создает ее последней,
+	// даже если от общих подвыражений зависят несколько задач
+	root := tasks[0]
 	for _, task := range tasks {
-		if task.Status != "completed" {
+		if task.Status != "completed" && task.Status != "skipped" {
 			allCompleted = false
 			break
 		}
+		if task.ID > root.ID {
+			root = task
+		}
 	}
 
 	if allCompleted {
+		result := taskCompleted.Result
+		if root.ID != taskCompleted.ID && root.Result != nil {
+			result = *root.Result
+		}
+		exact := root.ExactResult
+		if root.ID == taskCompleted.ID && taskCompleted.ExactResult != "" {
+			exact = &taskCompleted.ExactResult
+		}
+		if exact != nil && root.Precision > 0 {
+			// Промежуточные результаты хранят запасные цифры, результат выражения округляется до заданной точности
+			rounded, err := operators.RoundDecimal(*exact, root.Precision)
+			if err != nil {
+				return fmt.Errorf("не удалось округлить результат: %w", err), http.StatusInternalServerError
+			}
+			exact = &rounded
+		}
+		imag := 0.0 // Мнимая часть результата выражения в режиме complex
+		switch {
+		case root.ID == taskCompleted.ID:
+			imag = taskCompleted.ImagResult
+		case root.ImagResult != nil:
+			imag = *root.ImagResult
+		}
+		bounds := operators.Degenerate(result) // Интервал результата выражения в режиме interval
+		if root.Interval {
+			switch {
+			case root.ID == taskCompleted.ID && taskCompleted.UpperResult != nil:
+				bounds.Hi = *taskCompleted.UpperResult
+			case root.ID != taskCompleted.ID && root.UpperResult != nil:
+				bounds.Hi = *root.UpperResult
+			}
+			result = bounds.Mid() // Результатом выражения считается середина интервала
+		}
+
 		if err, code = m.exprRepo.UpdateExpressionStatus(ctx, tx, taskCompleted.Expression, "completed"); err != nil {
 			return err, code
 		}
-		if err, code = m.exprRepo.UpdateExpressionResult(ctx, tx, taskCompleted.Expression, taskCompleted.Result); err != nil {
+		if err, code = m.exprRepo.UpdateExpressionResult(ctx, tx, taskCompleted.Expression, result); err != nil {
+			return err, code
+		}
+		if exact != nil {
+			if err, code = m.exprRepo.UpdateExpressionExactResult(ctx, tx, taskCompleted.Expression, *exact); err != nil {
+				return err, code
+			}
+		}
+		if root.Complex {
+			if err, code = m.exprRepo.UpdateExpressionImagResult(ctx, tx, taskCompleted.Expression, imag); err != nil {
+				return err, code
+			}
+		}
+		if root.Interval {
+			if err, code = m.exprRepo.UpdateExpressionInterval(ctx, tx, taskCompleted.Expression, bounds.Lo, bounds.Hi); err != nil {
+				return err, code
+			}
+		}
+		if err, code = m.exprRepo.UpdateExpressionVariables(ctx, tx, taskCompleted.Expression); err != nil {
+			return err, code
+		}
+		if err, code = m.propagateResult(ctx, tx, taskCompleted.Expression, root.ID, result, exact, imag, bounds); err != nil {
 			return err, code
 		}
 		if err, code = m.taskRepo.DeleteTasks(ctx, tx, taskCompleted.Expression); err != nil {
@@ -298,3 +771,185 @@ func (m *ExpressionManager) CompleteTask(ctx context.Context, taskCompleted *mod
 
 	return nil, http.StatusOK
 }
+
+// propagateResult передает результат вычисленного выражения задачам других выражений,
+// которые ссылаются на него ($42) и ожидают его корневую задачу.
+// Вызывается перед удалением задач выражения.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения
+//	tx: *sql.Tx - Транзакция завершения задачи
+//	id: int64 - ID вычисленного выражения
+//	rootID: int64 - ID корневой задачи выражения
+//	result: float64 - Результат выражения
+//	exact: *string - Точный результат выражения: дробь "num/den" или десятичная запись. nil, если выражение вычислялось в float64
+//	imag: float64 - Мнимая часть результата выражения. Передается только комплексным задачам
+//	bounds: operators.Interval - Интервал результата выражения. Передается только интервальным задачам: нижняя
+//	    граница - аргументом, верхняя - верхней границей аргумента. Для выражения другого режима - [result, result]
+//
+// Returns:
+//
+//	error - Ошибка выполнения
+//	int - HTTP статус код:
+//		- 200 OK при успешном выполнении
+//	    - 500 Internal Server Error при ошибках
+func (m *ExpressionManager) propagateResult(ctx context.Context, tx *sql.Tx, id, rootID int64, result float64, exact *string, imag float64, bounds operators.Interval) (error, int) {
+	dependents, err, code := m.taskRepo.ReadDependentTasks(ctx, tx, id)
+	if err != nil {
+		return err, code
+	}
+
+	for _, task := range dependents {
+		for i, dep := range task.Dependencies {
+			if dep != rootID || task.Args[i] != nil {
+				continue
+			}
+			value := result
+			if task.Interval {
+				value = bounds.Lo
+			}
+			if err, code = m.taskRepo.UpdateTaskArguments(ctx, tx, task.ID, i, &value); err != nil {
+				return err, code
+			}
+			if task.HasExactArgs() {
+				if err, code = m.taskRepo.UpdateTaskExactArgument(ctx, tx, task.ID, i, exactValue(task, result, exact)); err != nil {
+					return err, code
+				}
+			}
+			if task.Complex {
+				if err, code = m.taskRepo.UpdateTaskImagArgument(ctx, tx, task.ID, i, imag); err != nil {
+					return err, code
+				}
+			}
+			if task.Interval {
+				if err, code = m.taskRepo.UpdateTaskUpperArgument(ctx, tx, task.ID, i, bounds.Hi); err != nil {
+					return err, code
+				}
+			}
+		}
+	}
+
+	return nil, http.StatusOK
+}
+
+// failExpression помечает выражение как ошибочное и удаляет его задачи.
+// Выражения, ожидающие результат этого выражения ($42), также завершаются с ошибкой.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения
+//	tx: *sql.Tx - Транзакция завершения задачи
+//	id: int64 - ID выражения
+//	errContent: string - Текст ошибки
+//
+// Returns:
+//
+//	error - Ошибка выполнения
+//	int - HTTP статус код:
+//		- 200 OK при успешном выполнении
+//	    - 500 Internal Server Error при ошибках
+func (m *ExpressionManager) failExpression(ctx context.Context, tx *sql.Tx, id int64, errContent string) (error, int) {
+	if err, code := m.exprRepo.UpdateExpressionError(ctx, tx, id, errContent); err != nil {
+		return err, code
+	}
+	if err, code := m.exprRepo.UpdateExpressionStatus(ctx, tx, id, "error"); err != nil {
+		return err, code
+	}
+	if err, code := m.exprRepo.UpdateExpressionVariables(ctx, tx, id); err != nil {
+		return err, code
+	}
+
+	dependents, err, code := m.taskRepo.ReadDependentTasks(ctx, tx, id)
+	if err != nil {
+		return err, code
+	}
+	failed := make(map[int64]bool)
+	for _, task := range dependents {
+		if failed[task.Expression] {
+			continue
+		}
+		failed[task.Expression] = true
+		if err, code := m.failExpression(ctx, tx, task.Expression, fmt.Sprintf("выражение №%d завершилось с ошибкой: %s", id, errContent)); err != nil {
+			return err, code
+		}
+	}
+
+	if err, code := m.taskRepo.DeleteTasks(ctx, tx, id); err != nil {
+		return err, code
+	}
+	return nil, http.StatusOK
+}
+
+// expressionSeed возвращает зерно функций случайных чисел выражения.
+//
+// Args:
+//
+//	tasks: []*models.Task - Задачи выражения.
+//	seed: *int64 - Зерно, переданное пользователем. nil, если не передано.
+//
+// Returns:
+//
+//	*int64 - Зерно пользователя, если оно передано. Иначе случайное зерно, если среди задач есть функции
+//	случайных чисел, или nil.
+func expressionSeed(tasks []*models.Task, seed *int64) *int64 {
+	if seed != nil {
+		return seed
+	}
+	for _, task := range tasks {
+		if operator, ok := operators.Lookup(task.Operation); ok && operator.Random != nil {
+			seed := rand.Int64N(operators.MaxSeed + 1)
+			return &seed
+		}
+	}
+	return nil
+}
+
+// exactValue возвращает значение результата для аргумента точной или десятичной задачи:
+// дробь "num/den" для точной задачи и десятичную запись с точностью задачи для десятичной.
+// Если точного результата нет (задача или выражение вычислялись в float64),
+// используется десятичная запись числа: 0.1 -> "1/10". Результат выражения другого режима ($42)
+// приводится к режиму задачи.
+//
+// Args:
+//
+//	task: *models.Task - Задача, аргументом которой становится результат.
+//	result: float64 - Результат.
+//	exact: *string - Точный результат (дробь или десятичная запись) или nil.
+//
+// Returns:
+//
+//	string - Значение аргумента.
+func exactValue(task *models.Task, result float64, exact *string) string {
+	value := operators.ExactFromFloat(result)
+	if exact != nil {
+		if parsed, ok := new(big.Rat).SetString(*exact); ok {
+			value = parsed
+		}
+	}
+
+	if task.Precision > 0 {
+		digits := task.Precision + operators.GuardDigits
+		return operators.FormatDecimalDigits(new(big.Float).SetPrec(operators.PrecisionBits(digits)).SetRat(value), digits)
+	}
+	return operators.FormatExact(value)
+}
+
+// truth возвращает истинность результата задачи как условия if: любое ненулевое число истинно.
+// Точный результат проверяется без округления до float64.
+//
+// Args:
+//
+//	task: *models.TaskCompleted - Результат задачи.
+//
+// Returns:
+//
+//	bool - Истинность результата.
+func truth(task *models.TaskCompleted) bool {
+	if task.ExactResult != "" {
+		if exact, ok := new(big.Rat).SetString(task.ExactResult); ok {
+			return exact.Sign() != 0
+		}
+	}
+	return task.Result != 0
+}
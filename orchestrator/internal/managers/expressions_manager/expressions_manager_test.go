@@ -10,7 +10,9 @@ import (
 	"github.com/OinkiePie/calc_3/orchestrator/internal/managers/expressions_manager"
 	mr "github.com/OinkiePie/calc_3/orchestrator/internal/repositories"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories/expressions_repository"
+	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories/functions_repository"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories/tasks_repository"
+	"github.com/OinkiePie/calc_3/orchestrator/internal/task_splitter"
 	"github.com/OinkiePie/calc_3/pkg/logger"
 	"github.com/OinkiePie/calc_3/pkg/models"
 	"github.com/OinkiePie/calc_3/pkg/operators"
@@ -18,6 +20,8 @@ import (
 	"github.com/stretchr/testify/mock"
 	"io"
 	"log"
+	"math"
+	"math/big"
 	"net/http"
 	"testing"
 )
@@ -33,11 +37,13 @@ func TestNew(t *testing.T) {
 	mockDB := &sql.DB{}
 	mockExpressionsRepo := new(mr.MockExpressionsRepository)
 	mockTasksRepo := new(mr.MockTasksRepository)
+	mockFunctionsRepo := new(mr.MockFunctionsRepository)
 
 	manager := expressions_manager.NewExpressionManager(
 		mockDB,
 		mockExpressionsRepo,
 		mockTasksRepo,
+		mockFunctionsRepo,
 	)
 
 	assert.NotNil(t, manager)
@@ -53,13 +59,17 @@ func TestExpressionManager_AddExpression(t *testing.T) {
 	defer db.Close()
 	mockExprRepo := new(mr.MockExpressionsRepository)
 	mockTaskRepo := new(mr.MockTasksRepository)
+	mockFuncRepo := new(mr.MockFunctionsRepository)
 
-	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo)
+	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo, mockFuncRepo)
 
 	ctx := context.Background()
-	validExpression := "2 + 2"
-	invalidExpression := "2 + "
+	validExpression := &models.ExpressionAdd{Expression: "2 + 2"}
+	invalidExpression := &models.ExpressionAdd{Expression: "2 + "}
+	unboundExpression := &models.ExpressionAdd{Expression: "a * x", Variables: map[string]float64{"a": 2}}
 	userID := int64(1)
+	mockFuncRepo.On("ReadFunctionsByUserID", ctx, mock.AnythingOfType("*sql.Tx"), userID).
+		Return([]*models.Function{}, nil, http.StatusOK)
 
 	t.Run("successful expression addition", func(t *testing.T) {
 		mockExprRepo.On("CreateExpression", ctx, mock.AnythingOfType("*sql.Tx"), mock.AnythingOfType("*models.Expression")).
@@ -83,12 +93,165 @@ func TestExpressionManager_AddExpression(t *testing.T) {
 	})
 
 	t.Run("invalid expression", func(t *testing.T) {
+		mockDB.ExpectBegin()
+		mockDB.ExpectRollback()
+
 		_, err, code := manager.AddExpression(ctx, invalidExpression, userID)
 
 		assert.Error(t, err)
 		assert.Equal(t, http.StatusBadRequest, code)
 	})
 
+	t.Run("unknown numeric mode", func(t *testing.T) {
+		mockDB.ExpectBegin()
+		mockDB.ExpectRollback()
+
+		_, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "2+2", Numeric: "quaternion"}, userID)
+
+		assert.EqualError(t, err, "неизвестный режим вычисления: quaternion")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("invalid precision", func(t *testing.T) {
+		for _, expressionAdd := range []*models.ExpressionAdd{
+			{Expression: "2+2", Precision: 10},
+			{Expression: "2+2", Numeric: models.NumericDecimal, Precision: -1},
+			{Expression: "2+2", Numeric: models.NumericDecimal, Precision: 1001},
+		} {
+			mockDB.ExpectBegin()
+			mockDB.ExpectRollback()
+
+			_, err, code := manager.AddExpression(ctx, expressionAdd, userID)
+
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, code)
+		}
+	})
+
+	t.Run("unbound variable", func(t *testing.T) {
+		mockDB.ExpectBegin()
+		mockDB.ExpectRollback()
+
+		_, err, code := manager.AddExpression(ctx, unboundExpression, userID)
+
+		assert.EqualError(t, err, "неизвестная переменная: x")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("reference to completed expression", func(t *testing.T) {
+		referenced := float64(21)
+		mockExprRepo.On("ReadExpressionByID", ctx, mock.AnythingOfType("*sql.Tx"), int64(7)).
+			Return(&models.Expression{ID: 7, UserID: userID, Status: "completed", Result: &referenced}, nil, http.StatusOK).Once()
+
+		mockExprRepo.On("CreateExpression", ctx, mock.AnythingOfType("*sql.Tx"), mock.MatchedBy(func(expr *models.Expression) bool {
+			return len(expr.Tasks) == 1 && *expr.Tasks[0].Args[0] == referenced
+		})).Return(int64(1), nil, http.StatusCreated).Once()
+
+		mockTaskRepo.On("UpdateTaskExpressionID", ctx, mock.AnythingOfType("*sql.Tx"), int64(1), int64(1)).
+			Return(nil, http.StatusOK).Once()
+
+		mockDB.ExpectBegin()
+		mockDB.ExpectCommit()
+
+		id, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "$7*2"}, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), id)
+		assert.Equal(t, http.StatusCreated, code)
+		mockExprRepo.AssertExpectations(t)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("reference to pending expression", func(t *testing.T) {
+		mockExprRepo.On("ReadExpressionByID", ctx, mock.AnythingOfType("*sql.Tx"), int64(7)).
+			Return(&models.Expression{ID: 7, UserID: userID, Status: "pending", Tasks: []*models.Task{{ID: 30}, {ID: 31}}}, nil, http.StatusOK).Once()
+
+		mockExprRepo.On("CreateExpression", ctx, mock.AnythingOfType("*sql.Tx"), mock.MatchedBy(func(expr *models.Expression) bool {
+			return len(expr.Tasks) == 1 && expr.Tasks[0].Args[0] == nil && expr.Tasks[0].Dependencies[0] == 31
+		})).Return(int64(1), nil, http.StatusCreated).Once()
+
+		mockTaskRepo.On("UpdateTaskExpressionID", ctx, mock.AnythingOfType("*sql.Tx"), int64(1), int64(1)).
+			Return(nil, http.StatusOK).Once()
+
+		mockDB.ExpectBegin()
+		mockDB.ExpectCommit()
+
+		_, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "$7*2"}, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+		mockExprRepo.AssertExpectations(t)
+		mockTaskRepo.AssertExpectations(t)
+	})
+
+	t.Run("reference errors", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			expression *models.Expression
+			err        error
+			code       int
+			wantCode   int
+		}{
+			{
+				name:       "expression of another user",
+				expression: &models.Expression{ID: 7, UserID: userID + 1, Status: "completed"},
+				code:       http.StatusOK,
+				wantCode:   http.StatusForbidden,
+			},
+			{
+				name:     "expression not found",
+				err:      errors.New("выражение не найдено"),
+				code:     http.StatusNotFound,
+				wantCode: http.StatusNotFound,
+			},
+			{
+				name:       "expression failed",
+				expression: &models.Expression{ID: 7, UserID: userID, Status: "error", Error: "деление на ноль"},
+				code:       http.StatusOK,
+				wantCode:   http.StatusBadRequest,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockExprRepo.On("ReadExpressionByID", ctx, mock.AnythingOfType("*sql.Tx"), int64(7)).
+					Return(tt.expression, tt.err, tt.code).Once()
+
+				mockDB.ExpectBegin()
+				mockDB.ExpectRollback()
+
+				_, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "$7*2"}, userID)
+
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, code)
+				mockExprRepo.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("fully folded expression is completed immediately", func(t *testing.T) {
+		config.Cfg.Splitter.FOLD_POLICY = "literal"
+		defer func() { config.Cfg.Splitter.FOLD_POLICY = "none" }()
+
+		mockExprRepo.On("CreateExpression", ctx, mock.AnythingOfType("*sql.Tx"), mock.MatchedBy(func(expr *models.Expression) bool {
+			return len(expr.Tasks) == 0
+		})).Return(int64(1), nil, http.StatusCreated).Once()
+		mockExprRepo.On("UpdateExpressionStatus", ctx, mock.AnythingOfType("*sql.Tx"), int64(1), "completed").
+			Return(nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionResult", ctx, mock.AnythingOfType("*sql.Tx"), int64(1), float64(4)).
+			Return(nil, http.StatusOK).Once()
+
+		mockDB.ExpectBegin()
+		mockDB.ExpectCommit()
+
+		id, err, code := manager.AddExpression(ctx, validExpression, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), id)
+		assert.Equal(t, http.StatusCreated, code)
+		mockExprRepo.AssertExpectations(t)
+	})
+
 	t.Run("failed to create expression", func(t *testing.T) {
 		mockExprRepo.On("CreateExpression", ctx, mock.AnythingOfType("*sql.Tx"), mock.Anything).
 			Return(int64(0), errors.New("database error"), http.StatusInternalServerError).Once()
@@ -148,6 +311,88 @@ func TestExpressionManager_AddExpression(t *testing.T) {
 	})
 }
 
+func TestExpressionManager_ExplainExpression(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mockExprRepo := new(mr.MockExpressionsRepository)
+	mockTaskRepo := new(mr.MockTasksRepository)
+	mockFuncRepo := new(mr.MockFunctionsRepository)
+
+	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo, mockFuncRepo)
+
+	ctx := context.Background()
+	userID := int64(1)
+	mockFuncRepo.On("ReadFunctionsByUserID", ctx, mock.AnythingOfType("*sql.Tx"), userID).
+		Return([]*models.Function{}, nil, http.StatusOK)
+
+	t.Run("successful explain does not persist anything", func(t *testing.T) {
+		mockDB.ExpectBegin()
+		mockDB.ExpectRollback()
+
+		plan, err, code := manager.ExplainExpression(ctx, &models.ExpressionAdd{Expression: "(1+2)*(3+4)"}, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Len(t, plan.Tasks, 3)
+		assert.Equal(t, 2, plan.Depth())
+		assert.Equal(t, 2, plan.Parallelism())
+		mockExprRepo.AssertNotCalled(t, "CreateExpression", mock.Anything, mock.Anything, mock.Anything)
+		assert.NoError(t, mockDB.ExpectationsWereMet())
+	})
+
+	t.Run("reference to pending expression", func(t *testing.T) {
+		mockExprRepo.On("ReadExpressionByID", ctx, mock.AnythingOfType("*sql.Tx"), int64(7)).
+			Return(&models.Expression{ID: 7, UserID: userID, Status: "pending", Tasks: []*models.Task{{ID: 30}, {ID: 31}}}, nil, http.StatusOK).Once()
+
+		mockDB.ExpectBegin()
+		mockDB.ExpectRollback()
+
+		plan, err, code := manager.ExplainExpression(ctx, &models.ExpressionAdd{Expression: "$7*2"}, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, int64(31), plan.Tasks[0].Dependencies[0])
+		mockExprRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		mockDB.ExpectBegin()
+		mockDB.ExpectRollback()
+
+		plan, err, code := manager.ExplainExpression(ctx, &models.ExpressionAdd{Expression: "2 + "}, userID)
+
+		assert.Nil(t, plan)
+		assert.EqualError(t, err, "недостаточно операндов")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("reference to expression of another user", func(t *testing.T) {
+		mockExprRepo.On("ReadExpressionByID", ctx, mock.AnythingOfType("*sql.Tx"), int64(7)).
+			Return(&models.Expression{ID: 7, UserID: userID + 1, Status: "completed"}, nil, http.StatusOK).Once()
+
+		mockDB.ExpectBegin()
+		mockDB.ExpectRollback()
+
+		_, err, code := manager.ExplainExpression(ctx, &models.ExpressionAdd{Expression: "$7*2"}, userID)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, code)
+		mockExprRepo.AssertExpectations(t)
+	})
+
+	t.Run("transaction begin error", func(t *testing.T) {
+		mockDB.ExpectBegin().WillReturnError(errors.New("begin error"))
+
+		_, err, code := manager.ExplainExpression(ctx, &models.ExpressionAdd{Expression: "2 + 2"}, userID)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+}
+
 func TestExpressionManager_ReadExpressions(t *testing.T) {
 	db, mockDB, err := sqlmock.New()
 	if err != nil {
@@ -156,8 +401,9 @@ func TestExpressionManager_ReadExpressions(t *testing.T) {
 	defer db.Close()
 	mockExprRepo := new(mr.MockExpressionsRepository)
 	mockTaskRepo := new(mr.MockTasksRepository)
+	mockFuncRepo := new(mr.MockFunctionsRepository)
 
-	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo)
+	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo, mockFuncRepo)
 
 	ctx := context.Background()
 	userID := int64(1)
@@ -250,8 +496,9 @@ func TestExpressionManager_ReadExpression(t *testing.T) {
 	defer db.Close()
 	mockExprRepo := new(mr.MockExpressionsRepository)
 	mockTaskRepo := new(mr.MockTasksRepository)
+	mockFuncRepo := new(mr.MockFunctionsRepository)
 
-	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo)
+	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo, mockFuncRepo)
 
 	ctx := context.Background()
 	exprID := int64(1)
@@ -265,6 +512,8 @@ func TestExpressionManager_ReadExpression(t *testing.T) {
 	t.Run("successful read expression", func(t *testing.T) {
 		mockExprRepo.On("ReadExpressionByID", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
 			Return(testExpression, nil, http.StatusOK).Once()
+		mockExprRepo.On("ReadExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.ExpressionVariable{{Name: "r", Value: mr.Float64Ptr(5)}}, nil, http.StatusOK).Once()
 
 		mockDB.ExpectBegin()
 		mockDB.ExpectCommit()
@@ -274,6 +523,24 @@ func TestExpressionManager_ReadExpression(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, code)
 		assert.Equal(t, testExpression, result)
+		assert.Equal(t, "r", result.Variables[0].Name)
+		mockExprRepo.AssertExpectations(t)
+
+	})
+
+	t.Run("variables read error", func(t *testing.T) {
+		mockExprRepo.On("ReadExpressionByID", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return(&models.Expression{ID: exprID}, nil, http.StatusOK).Once()
+		mockExprRepo.On("ReadExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.ExpressionVariable(nil), errors.New("vars error"), http.StatusInternalServerError).Once()
+
+		mockDB.ExpectBegin()
+
+		result, err, code := manager.ReadExpression(ctx, exprID)
+
+		assert.EqualError(t, err, "vars error")
+		assert.Equal(t, http.StatusInternalServerError, code)
+		assert.Nil(t, result)
 		mockExprRepo.AssertExpectations(t)
 
 	})
@@ -327,6 +594,8 @@ func TestExpressionManager_ReadExpression(t *testing.T) {
 
 		mockExprRepo.On("ReadExpressionByID", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
 			Return(testExpression, nil, http.StatusOK).Once()
+		mockExprRepo.On("ReadExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.ExpressionVariable(nil), nil, http.StatusOK).Once()
 
 		result, err, code := manager.ReadExpression(ctx, exprID)
 
@@ -346,8 +615,9 @@ func TestExpressionManager_ReadTask(t *testing.T) {
 	defer db.Close()
 	mockExprRepo := new(mr.MockExpressionsRepository)
 	mockTaskRepo := new(mr.MockTasksRepository)
+	mockFuncRepo := new(mr.MockFunctionsRepository)
 
-	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo)
+	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo, mockFuncRepo)
 
 	ctx := context.Background()
 
@@ -578,8 +848,9 @@ func TestExpressionManager_CompleteTask(t *testing.T) {
 	defer db.Close()
 	mockExprRepo := new(mr.MockExpressionsRepository)
 	mockTaskRepo := new(mr.MockTasksRepository)
+	mockFuncRepo := new(mr.MockFunctionsRepository)
 
-	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo)
+	manager := expressions_manager.NewExpressionManager(db, mockExprRepo, mockTaskRepo, mockFuncRepo)
 
 	ctx := context.Background()
 	successResult := float64(5)
@@ -599,6 +870,8 @@ func TestExpressionManager_CompleteTask(t *testing.T) {
 
 		mockTaskRepo.On("UpdateTaskStatus", ctx, mock.AnythingOfType("*sql.Tx"), taskID, "completed").
 			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("SkipGuardedTasks", ctx, mock.AnythingOfType("*sql.Tx"), taskID, mock.AnythingOfType("bool")).
+			Return(nil, http.StatusOK).Once()
 
 		mockTaskRepo.On("ReadTasksByExpressionID", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
 			Return([]*models.Task{
@@ -612,6 +885,11 @@ func TestExpressionManager_CompleteTask(t *testing.T) {
 		mockExprRepo.On("UpdateExpressionResult", ctx, mock.AnythingOfType("*sql.Tx"), exprID, successResult).
 			Return(nil, http.StatusOK).Once()
 
+		mockExprRepo.On("UpdateExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("ReadDependentTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.Task(nil), nil, http.StatusNotFound).Once()
+
 		mockTaskRepo.On("DeleteTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
 			Return(nil, http.StatusOK).Once()
 
@@ -627,19 +905,39 @@ func TestExpressionManager_CompleteTask(t *testing.T) {
 
 	})
 
-	t.Run("task with error marks expression as error", func(t *testing.T) {
-		taskError := "division by zero"
+	t.Run("expression result is taken from root task", func(t *testing.T) {
+		// Независимая инструкция сценария завершилась последней, результат - у корневой задачи
 		taskCompleted := &models.TaskCompleted{
-			ID:         taskID,
+			ID:         2,
 			Expression: exprID,
-			Error:      taskError,
+			Result:     successResult,
 		}
+		rootResult := float64(42)
 
-		mockExprRepo.On("UpdateExpressionError", ctx, mock.AnythingOfType("*sql.Tx"), exprID, taskError).
+		mockTaskRepo.On("UpdateTaskResult", ctx, mock.AnythingOfType("*sql.Tx"), successResult, int64(2)).
 			Return(nil, http.StatusOK).Once()
 
-		mockExprRepo.On("UpdateExpressionStatus", ctx, mock.AnythingOfType("*sql.Tx"), exprID, "error").
+		mockTaskRepo.On("UpdateTaskStatus", ctx, mock.AnythingOfType("*sql.Tx"), int64(2), "completed").
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("SkipGuardedTasks", ctx, mock.AnythingOfType("*sql.Tx"), int64(2), mock.AnythingOfType("bool")).
+			Return(nil, http.StatusOK).Once()
+
+		mockTaskRepo.On("ReadTasksByExpressionID", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.Task{
+				{ID: 2, Status: "completed", Result: &successResult},
+				{ID: taskID, Status: "completed", Result: &rootResult},
+			}, nil, http.StatusOK).Once()
+
+		mockExprRepo.On("UpdateExpressionStatus", ctx, mock.AnythingOfType("*sql.Tx"), exprID, "completed").
+			Return(nil, http.StatusOK).Once()
+
+		mockExprRepo.On("UpdateExpressionResult", ctx, mock.AnythingOfType("*sql.Tx"), exprID, rootResult).
+			Return(nil, http.StatusOK).Once()
+
+		mockExprRepo.On("UpdateExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
 			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("ReadDependentTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.Task(nil), nil, http.StatusNotFound).Once()
 
 		mockTaskRepo.On("DeleteTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
 			Return(nil, http.StatusOK).Once()
@@ -651,30 +949,32 @@ func TestExpressionManager_CompleteTask(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, code)
-		mockExprRepo.AssertExpectations(t)
 		mockTaskRepo.AssertExpectations(t)
+		mockExprRepo.AssertExpectations(t)
 
 	})
 
-	t.Run("not all tasks completed", func(t *testing.T) {
+	t.Run("task with error marks expression as error", func(t *testing.T) {
+		taskError := "division by zero"
 		taskCompleted := &models.TaskCompleted{
 			ID:         taskID,
 			Expression: exprID,
-			Result:     successResult,
-			Error:      "",
+			Error:      taskError,
 		}
 
-		mockTaskRepo.On("UpdateTaskResult", ctx, mock.AnythingOfType("*sql.Tx"), successResult, taskID).
+		mockExprRepo.On("UpdateExpressionError", ctx, mock.AnythingOfType("*sql.Tx"), exprID, taskError).
 			Return(nil, http.StatusOK).Once()
 
-		mockTaskRepo.On("UpdateTaskStatus", ctx, mock.AnythingOfType("*sql.Tx"), taskID, "completed").
+		mockExprRepo.On("UpdateExpressionStatus", ctx, mock.AnythingOfType("*sql.Tx"), exprID, "error").
 			Return(nil, http.StatusOK).Once()
 
-		mockTaskRepo.On("ReadTasksByExpressionID", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
-			Return([]*models.Task{
-				{ID: taskID, Status: "completed"},
-				{ID: 2, Status: "pending"},
-			}, nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("ReadDependentTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.Task(nil), nil, http.StatusNotFound).Once()
+
+		mockTaskRepo.On("DeleteTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return(nil, http.StatusOK).Once()
 
 		mockDB.ExpectBegin()
 		mockDB.ExpectCommit()
@@ -683,50 +983,104 @@ func TestExpressionManager_CompleteTask(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, code)
+		mockExprRepo.AssertExpectations(t)
 		mockTaskRepo.AssertExpectations(t)
-		mockExprRepo.AssertNotCalled(t, "UpdateExpressionStatus")
 
 	})
 
-	t.Run("error updating task result", func(t *testing.T) {
+	t.Run("result is passed to dependent expressions", func(t *testing.T) {
 		taskCompleted := &models.TaskCompleted{
 			ID:         taskID,
 			Expression: exprID,
 			Result:     successResult,
-			Error:      "",
 		}
 
 		mockTaskRepo.On("UpdateTaskResult", ctx, mock.AnythingOfType("*sql.Tx"), successResult, taskID).
-			Return(errors.New("db error"), http.StatusInternalServerError).Once()
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("UpdateTaskStatus", ctx, mock.AnythingOfType("*sql.Tx"), taskID, "completed").
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("SkipGuardedTasks", ctx, mock.AnythingOfType("*sql.Tx"), taskID, mock.AnythingOfType("bool")).
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("ReadTasksByExpressionID", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.Task{{ID: taskID, Status: "completed"}}, nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionStatus", ctx, mock.AnythingOfType("*sql.Tx"), exprID, "completed").
+			Return(nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionResult", ctx, mock.AnythingOfType("*sql.Tx"), exprID, successResult).
+			Return(nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return(nil, http.StatusOK).Once()
+
+		// $1 * $1 - обе зависимости указывают на корневую задачу выражения
+		mockTaskRepo.On("ReadDependentTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.Task{
+				{ID: 20, Expression: 2, Args: []*float64{nil, nil}, Dependencies: []int64{taskID, taskID}},
+			}, nil, http.StatusOK).Once()
+		mockTaskRepo.On("UpdateTaskArguments", ctx, mock.AnythingOfType("*sql.Tx"), int64(20), 0, &successResult).
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("UpdateTaskArguments", ctx, mock.AnythingOfType("*sql.Tx"), int64(20), 1, &successResult).
+			Return(nil, http.StatusOK).Once()
+
+		mockTaskRepo.On("DeleteTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return(nil, http.StatusOK).Once()
 
 		mockDB.ExpectBegin()
-		mockDB.ExpectRollback()
+		mockDB.ExpectCommit()
 
 		err, code := manager.CompleteTask(ctx, taskCompleted)
 
-		assert.Error(t, err)
-		assert.Equal(t, http.StatusInternalServerError, code)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
 		mockTaskRepo.AssertExpectations(t)
-
+		mockExprRepo.AssertExpectations(t)
 	})
 
-	t.Run("transaction begin error", func(t *testing.T) {
+	t.Run("error is passed to dependent expressions", func(t *testing.T) {
+		taskError := "деление на ноль"
+		dependentExprID := int64(2)
 		taskCompleted := &models.TaskCompleted{
 			ID:         taskID,
 			Expression: exprID,
+			Error:      taskError,
 		}
 
-		mockDB.ExpectBegin().WillReturnError(errors.New("begin error"))
+		mockExprRepo.On("UpdateExpressionError", ctx, mock.AnythingOfType("*sql.Tx"), exprID, taskError).
+			Return(nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionStatus", ctx, mock.AnythingOfType("*sql.Tx"), exprID, "error").
+			Return(nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("ReadDependentTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.Task{
+				{ID: 20, Expression: dependentExprID},
+				{ID: 21, Expression: dependentExprID},
+			}, nil, http.StatusOK).Once()
 
-		err, code := manager.CompleteTask(ctx, taskCompleted)
+		mockExprRepo.On("UpdateExpressionError", ctx, mock.AnythingOfType("*sql.Tx"), dependentExprID, "выражение №1 завершилось с ошибкой: "+taskError).
+			Return(nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionStatus", ctx, mock.AnythingOfType("*sql.Tx"), dependentExprID, "error").
+			Return(nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), dependentExprID).
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("ReadDependentTasks", ctx, mock.AnythingOfType("*sql.Tx"), dependentExprID).
+			Return([]*models.Task(nil), nil, http.StatusNotFound).Once()
+		mockTaskRepo.On("DeleteTasks", ctx, mock.AnythingOfType("*sql.Tx"), dependentExprID).
+			Return(nil, http.StatusOK).Once()
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "не удалось начать завершение задачи")
-		assert.Equal(t, http.StatusInternalServerError, code)
+		mockTaskRepo.On("DeleteTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return(nil, http.StatusOK).Once()
+
+		mockDB.ExpectBegin()
+		mockDB.ExpectCommit()
 
+		err, code := manager.CompleteTask(ctx, taskCompleted)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		mockExprRepo.AssertExpectations(t)
+		mockTaskRepo.AssertExpectations(t)
 	})
 
-	t.Run("transaction commit error", func(t *testing.T) {
+	t.Run("not all tasks completed", func(t *testing.T) {
 		taskCompleted := &models.TaskCompleted{
 			ID:         taskID,
 			Expression: exprID,
@@ -736,17 +1090,95 @@ func TestExpressionManager_CompleteTask(t *testing.T) {
 
 		mockTaskRepo.On("UpdateTaskResult", ctx, mock.AnythingOfType("*sql.Tx"), successResult, taskID).
 			Return(nil, http.StatusOK).Once()
+
 		mockTaskRepo.On("UpdateTaskStatus", ctx, mock.AnythingOfType("*sql.Tx"), taskID, "completed").
 			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("SkipGuardedTasks", ctx, mock.AnythingOfType("*sql.Tx"), taskID, mock.AnythingOfType("bool")).
+			Return(nil, http.StatusOK).Once()
+
 		mockTaskRepo.On("ReadTasksByExpressionID", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
 			Return([]*models.Task{
 				{ID: taskID, Status: "completed"},
-				{ID: 2, Status: "completed"},
+				{ID: 2, Status: "pending"},
+			}, nil, http.StatusOK).Once()
+
+		mockDB.ExpectBegin()
+		mockDB.ExpectCommit()
+
+		err, code := manager.CompleteTask(ctx, taskCompleted)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		mockTaskRepo.AssertExpectations(t)
+		mockExprRepo.AssertNotCalled(t, "UpdateExpressionStatus")
+
+	})
+
+	t.Run("error updating task result", func(t *testing.T) {
+		taskCompleted := &models.TaskCompleted{
+			ID:         taskID,
+			Expression: exprID,
+			Result:     successResult,
+			Error:      "",
+		}
+
+		mockTaskRepo.On("UpdateTaskResult", ctx, mock.AnythingOfType("*sql.Tx"), successResult, taskID).
+			Return(errors.New("db error"), http.StatusInternalServerError).Once()
+
+		mockDB.ExpectBegin()
+		mockDB.ExpectRollback()
+
+		err, code := manager.CompleteTask(ctx, taskCompleted)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusInternalServerError, code)
+		mockTaskRepo.AssertExpectations(t)
+
+	})
+
+	t.Run("transaction begin error", func(t *testing.T) {
+		taskCompleted := &models.TaskCompleted{
+			ID:         taskID,
+			Expression: exprID,
+		}
+
+		mockDB.ExpectBegin().WillReturnError(errors.New("begin error"))
+
+		err, code := manager.CompleteTask(ctx, taskCompleted)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "не удалось начать завершение задачи")
+		assert.Equal(t, http.StatusInternalServerError, code)
+
+	})
+
+	t.Run("transaction commit error", func(t *testing.T) {
+		taskCompleted := &models.TaskCompleted{
+			ID:         taskID,
+			Expression: exprID,
+			Result:     successResult,
+			Error:      "",
+		}
+
+		mockTaskRepo.On("UpdateTaskResult", ctx, mock.AnythingOfType("*sql.Tx"), successResult, taskID).
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("UpdateTaskStatus", ctx, mock.AnythingOfType("*sql.Tx"), taskID, "completed").
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("SkipGuardedTasks", ctx, mock.AnythingOfType("*sql.Tx"), taskID, mock.AnythingOfType("bool")).
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("ReadTasksByExpressionID", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.Task{
+				{ID: taskID, Status: "completed"},
+				{ID: 2, Status: "completed"},
 			}, nil, http.StatusOK).Once()
 		mockExprRepo.On("UpdateExpressionStatus", ctx, mock.AnythingOfType("*sql.Tx"), exprID, "completed").
 			Return(nil, http.StatusOK).Once()
 		mockExprRepo.On("UpdateExpressionResult", ctx, mock.AnythingOfType("*sql.Tx"), exprID, successResult).
 			Return(nil, http.StatusOK).Once()
+		mockExprRepo.On("UpdateExpressionVariables", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return(nil, http.StatusOK).Once()
+		mockTaskRepo.On("ReadDependentTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
+			Return([]*models.Task(nil), nil, http.StatusNotFound).Once()
 		mockTaskRepo.On("DeleteTasks", ctx, mock.AnythingOfType("*sql.Tx"), exprID).
 			Return(nil, http.StatusOK).Once()
 
@@ -779,11 +1211,12 @@ func TestExpressionManager_AddExpression_Integration(t *testing.T) {
 	argsRepo := tasks_repository.NewTaskArgsRepository(db)
 	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
 	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
 
-	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo)
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
 
 	ctx := context.Background()
-	validExpression := "2 + 2"
+	validExpression := &models.ExpressionAdd{Expression: "2 + 2"}
 	userID := int64(1)
 
 	t.Run("successful integration", func(t *testing.T) {
@@ -801,7 +1234,8 @@ func TestExpressionManager_AddExpression_Integration(t *testing.T) {
 
 		expr, err, _ := exprRepo.ReadExpressionByID(ctx, tx, id)
 		assert.NoError(t, err)
-		assert.Equal(t, validExpression, expr.ExpressionString)
+		assert.Equal(t, validExpression.Expression, expr.ExpressionString)
+		assert.Equal(t, "2 + 2", expr.CanonicalString)
 		assert.Equal(t, userID, expr.UserID)
 
 		tasks, err, _ := taskRepo.ReadTasksByExpressionID(ctx, tx, id)
@@ -812,6 +1246,857 @@ func TestExpressionManager_AddExpression_Integration(t *testing.T) {
 			t.Fatalf("не удалось закоммитить транзакцию: %v", err)
 		}
 	})
+
+	t.Run("result unit is stored", func(t *testing.T) {
+		id, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "5 km / 2 h in km/h"}, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		expr, err, _ := manager.ReadExpression(ctx, id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "km/h", expr.Unit)
+			assert.Equal(t, "5 km / 2 h in km/h", expr.CanonicalString)
+			assert.Len(t, expr.Tasks, 2)
+		}
+	})
+
+	t.Run("dimension mismatch is rejected", func(t *testing.T) {
+		_, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "5 km + 2 h"}, userID)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestExpressionManager_Script_Integration(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:testdb?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if setupTestDatabase(db) != nil {
+		t.Fatal(err)
+	}
+
+	depsRepo := tasks_repository.NewTaskDepsRepository(db)
+	argsRepo := tasks_repository.NewTaskArgsRepository(db)
+	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
+	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
+
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
+
+	ctx := context.Background()
+	userID := int64(1)
+
+	t.Run("script is calculated through task dependencies", func(t *testing.T) {
+		script := &models.ExpressionAdd{Expression: "r = 5; area = 3.14159*r^2; side = 1 + 1; area*2"}
+
+		id, err, code := manager.AddExpression(ctx, script, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		expr, err, _ := manager.ReadExpression(ctx, id)
+		assert.NoError(t, err)
+		if assert.Len(t, expr.Variables, 3) {
+			assert.Equal(t, "r", expr.Variables[0].Name)
+			assert.Equal(t, float64(5), *expr.Variables[0].Value)
+			assert.Nil(t, expr.Variables[1].Value)
+		}
+
+		// Выполняем задачи так же, как это делают агенты
+		for {
+			task, err, code := manager.ReadTask(ctx)
+			assert.NoError(t, err)
+			if code == http.StatusNotFound {
+				break
+			}
+
+			operator, _ := operators.Lookup(task.Operation)
+			args := make([]float64, operator.Arity)
+			for i := range args {
+				args[i] = *task.Args[i]
+			}
+			result, _ := operator.Eval(args...)
+
+			err, _ = manager.CompleteTask(ctx, &models.TaskCompleted{ID: task.ID, Expression: task.Expression, Result: result})
+			assert.NoError(t, err)
+		}
+
+		expr, err, _ = manager.ReadExpression(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		assert.InDelta(t, 3.14159*25*2, *expr.Result, 1e-9)
+
+		values := make(map[string]float64)
+		for _, variable := range expr.Variables {
+			if assert.NotNil(t, variable.Value, variable.Name) {
+				values[variable.Name] = *variable.Value
+			}
+		}
+		assert.Equal(t, float64(5), values["r"])
+		assert.InDelta(t, 3.14159*25, values["area"], 1e-9)
+		assert.Equal(t, float64(2), values["side"])
+	})
+}
+
+func TestExpressionManager_Conditional_Integration(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:testdb?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if setupTestDatabase(db) != nil {
+		t.Fatal(err)
+	}
+
+	depsRepo := tasks_repository.NewTaskDepsRepository(db)
+	argsRepo := tasks_repository.NewTaskArgsRepository(db)
+	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
+	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
+
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
+
+	ctx := context.Background()
+	userID := int64(1)
+
+	tests := []struct {
+		name       string
+		x          float64
+		result     float64
+		operations []string // Операции, отправленные агентам
+	}{
+		{name: "untaken branch is skipped", x: -4, result: 0, operations: []string{">", "if"}},
+		{name: "taken branch is calculated", x: 9, result: 3, operations: []string{">", "sqrt", "if"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression := &models.ExpressionAdd{Expression: "if(x > 0, sqrt(x), 0)", Variables: map[string]float64{"x": tt.x}}
+			id, err, code := manager.AddExpression(ctx, expression, userID)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, code)
+
+			var operations []string
+			for {
+				task, err, code := manager.ReadTask(ctx)
+				assert.NoError(t, err)
+				if code == http.StatusNotFound {
+					break
+				}
+				operations = append(operations, task.Operation)
+
+				operator, _ := operators.Lookup(task.Operation)
+				args := make([]float64, operator.Arity)
+				for i := range args {
+					args[i] = *task.Args[i]
+				}
+				result, err := operator.Eval(args...)
+				assert.NoError(t, err)
+
+				err, _ = manager.CompleteTask(ctx, &models.TaskCompleted{ID: task.ID, Expression: task.Expression, Result: result})
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.operations, operations)
+
+			expr, err, _ := manager.ReadExpression(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, "completed", expr.Status)
+			if assert.NotNil(t, expr.Result) {
+				assert.Equal(t, tt.result, *expr.Result)
+			}
+		})
+	}
+}
+
+func TestExpressionManager_Solve_Integration(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:testdb?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if setupTestDatabase(db) != nil {
+		t.Fatal(err)
+	}
+
+	depsRepo := tasks_repository.NewTaskDepsRepository(db)
+	argsRepo := tasks_repository.NewTaskArgsRepository(db)
+	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
+	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
+
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
+
+	ctx := context.Background()
+	id, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "solve(x - 1, x, 0, 4)"}, 1)
+	if !assert.NoError(t, err) || !assert.Equal(t, http.StatusCreated, code) {
+		return
+	}
+
+	expr, err, _ := manager.ReadExpression(ctx, id)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &models.ExpressionProgress{Done: 0, Total: 34}, expr.Progress)
+
+	for {
+		task, err, code := manager.ReadTask(ctx)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if code == http.StatusNotFound {
+			break
+		}
+
+		operator, _ := operators.Lookup(task.Operation)
+		args := make([]float64, operator.Arity)
+		for i := range args {
+			args[i] = *task.Args[i]
+		}
+		result, err := operator.Eval(args...)
+		assert.NoError(t, err)
+
+		err, _ = manager.CompleteTask(ctx, &models.TaskCompleted{ID: task.ID, Expression: task.Expression, Result: result})
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	expr, err, _ = manager.ReadExpression(ctx, id)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "completed", expr.Status)
+	assert.Nil(t, expr.Progress)
+	if assert.NotNil(t, expr.Result) {
+		assert.InDelta(t, 1, *expr.Result, 1e-9)
+	}
+
+	estimates := make(map[string]float64)
+	for _, variable := range expr.Variables {
+		if assert.True(t, variable.Estimate) && assert.NotNil(t, variable.Value, variable.Name) {
+			estimates[variable.Name] = *variable.Value
+		}
+	}
+	assert.InDelta(t, 0, estimates["solve#1.error"], 1e-9)
+	assert.InDelta(t, 0, estimates["solve#1.residual"], 1e-9)
+	assert.Equal(t, 1.0, estimates["solve#1.bracketed"])
+}
+
+func TestExpressionManager_Functions_Integration(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:testdb?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if setupTestDatabase(db) != nil {
+		t.Fatal(err)
+	}
+
+	depsRepo := tasks_repository.NewTaskDepsRepository(db)
+	argsRepo := tasks_repository.NewTaskArgsRepository(db)
+	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
+	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
+
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
+
+	ctx := context.Background()
+	userID := int64(1)
+
+	t.Run("function is inlined and survives deletion", func(t *testing.T) {
+		function, err, code := manager.AddFunction(ctx, "f(x, y) = x*x + y*y", userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, "f(x, y) = x*x + y*y", function.Definition())
+
+		_, err, code = manager.AddFunction(ctx, "g(x) = f(x, x) * 2", userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		id, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "f(2, z) + g(1)", Variables: map[string]float64{"z": 3}}, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		// Функции удаляются до вычисления выражения
+		err, code = manager.DeleteFunction(ctx, "g", userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		err, code = manager.DeleteFunction(ctx, "f", userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+
+		for {
+			task, err, code := manager.ReadTask(ctx)
+			assert.NoError(t, err)
+			if code == http.StatusNotFound {
+				break
+			}
+
+			operator, _ := operators.Lookup(task.Operation)
+			args := make([]float64, operator.Arity)
+			for i := range args {
+				args[i] = *task.Args[i]
+			}
+			result, _ := operator.Eval(args...)
+
+			err, _ = manager.CompleteTask(ctx, &models.TaskCompleted{ID: task.ID, Expression: task.Expression, Result: result})
+			assert.NoError(t, err)
+		}
+
+		expr, err, _ := manager.ReadExpression(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		assert.Equal(t, float64(17), *expr.Result)
+
+		_, err, code = manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "f(2, 3)"}, userID)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("functions are scoped to the user", func(t *testing.T) {
+		_, err, code := manager.AddFunction(ctx, "sq(x) = x*x", userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		_, err, code = manager.AddFunction(ctx, "sq(x) = x^2", userID)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusConflict, code)
+
+		functions, err, code := manager.ReadFunctions(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		if assert.Len(t, functions, 1) {
+			assert.Equal(t, "sq", functions[0].Name)
+			assert.Equal(t, []string{"x"}, functions[0].Params)
+		}
+
+		functions, err, code = manager.ReadFunctions(ctx, userID+1)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, functions)
+
+		_, err, code = manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "sq(2)"}, userID+1)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		err, code = manager.DeleteFunction(ctx, "sq", userID+1)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("recursive function is rejected", func(t *testing.T) {
+		_, err, code := manager.AddFunction(ctx, "h(n) = n * h(n - 1)", userID)
+		var parseErr *task_splitter.ParseError
+		if assert.True(t, errors.As(err, &parseErr)) {
+			assert.Equal(t, task_splitter.CodeRecursiveFunction, parseErr.Code)
+		}
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestExpressionManager_Rational_Integration(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:testdb?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if setupTestDatabase(db) != nil {
+		t.Fatal(err)
+	}
+
+	depsRepo := tasks_repository.NewTaskDepsRepository(db)
+	argsRepo := tasks_repository.NewTaskArgsRepository(db)
+	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
+	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
+
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
+
+	ctx := context.Background()
+	userID := int64(1)
+
+	// Выполняем задачи так же, как это делают агенты в точном режиме
+	runExact := func(t *testing.T) {
+		for {
+			task, err, code := manager.ReadTask(ctx)
+			assert.NoError(t, err)
+			if code == http.StatusNotFound {
+				return
+			}
+			if !assert.True(t, task.Exact) {
+				return
+			}
+
+			operator, _ := operators.Lookup(task.Operation)
+			args := make([]*big.Rat, operator.Arity)
+			for i := range args {
+				args[i], err = operators.ParseExact(*task.ExactArgs[i])
+				assert.NoError(t, err)
+			}
+			exact, err := operator.Exact(args...)
+			assert.NoError(t, err)
+			result, _ := exact.Float64()
+
+			err, _ = manager.CompleteTask(ctx, &models.TaskCompleted{
+				ID: task.ID, Expression: task.Expression, Result: result, ExactResult: operators.FormatExact(exact),
+			})
+			assert.NoError(t, err)
+		}
+	}
+
+	t.Run("rational expression is calculated exactly", func(t *testing.T) {
+		expression := &models.ExpressionAdd{Expression: "(0.1 + 0.2) / 3", Numeric: models.NumericRational}
+
+		id, err, code := manager.AddExpression(ctx, expression, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		runExact(t)
+
+		expr, err, _ := manager.ReadExpression(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		assert.Equal(t, models.NumericRational, expr.Numeric)
+		if assert.NotNil(t, expr.ExactResult) {
+			assert.Equal(t, "1/10", *expr.ExactResult)
+		}
+		assert.Equal(t, 0.1, *expr.Result)
+	})
+
+	t.Run("reference to completed rational expression is exact", func(t *testing.T) {
+		first, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "1 / 3", Numeric: models.NumericRational}, userID)
+		assert.NoError(t, err)
+		runExact(t)
+
+		second, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d * 3", first), Numeric: models.NumericRational}, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+		runExact(t)
+
+		expr, err, _ := manager.ReadExpression(ctx, second)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		if assert.NotNil(t, expr.ExactResult) {
+			assert.Equal(t, "1", *expr.ExactResult)
+		}
+	})
+
+	t.Run("inexact operation is rejected", func(t *testing.T) {
+		expression := &models.ExpressionAdd{Expression: "sqrt(2) * 2", Numeric: models.NumericRational}
+
+		_, err, code := manager.AddExpression(ctx, expression, userID)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("decimal expression is calculated with requested precision", func(t *testing.T) {
+		expression := &models.ExpressionAdd{Expression: "2^0.5 * 1", Numeric: models.NumericDecimal, Precision: 50}
+
+		id, err, code := manager.AddExpression(ctx, expression, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		// Выполняем задачи так же, как это делают агенты в десятичном режиме
+		for {
+			task, err, code := manager.ReadTask(ctx)
+			assert.NoError(t, err)
+			if code == http.StatusNotFound {
+				break
+			}
+			if !assert.Equal(t, 50, task.Precision) {
+				return
+			}
+
+			bits := operators.PrecisionBits(task.Precision + operators.GuardDigits)
+			operator, _ := operators.Lookup(task.Operation)
+			args := make([]*big.Float, operator.Arity)
+			for i := range args {
+				args[i], err = operators.ParseDecimal(*task.ExactArgs[i], bits)
+				assert.NoError(t, err)
+			}
+			decimal, err := operator.Decimal(bits, args...)
+			assert.NoError(t, err)
+			result, _ := decimal.Float64()
+
+			err, _ = manager.CompleteTask(ctx, &models.TaskCompleted{
+				ID: task.ID, Expression: task.Expression, Result: result,
+				ExactResult: operators.FormatDecimalDigits(decimal, task.Precision+operators.GuardDigits),
+			})
+			assert.NoError(t, err)
+		}
+
+		expr, err, _ := manager.ReadExpression(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		assert.Equal(t, models.NumericDecimal, expr.Numeric)
+		if assert.NotNil(t, expr.ExactResult) {
+			assert.Equal(t, "1.4142135623730950488016887242096980785696718753769", *expr.ExactResult)
+		}
+		assert.Equal(t, math.Sqrt2, *expr.Result)
+	})
+
+	// Выполняем задачи так же, как это делают агенты в комплексном режиме
+	runComplex := func(t *testing.T) {
+		for {
+			task, err, code := manager.ReadTask(ctx)
+			assert.NoError(t, err)
+			if code == http.StatusNotFound {
+				return
+			}
+			if !assert.True(t, task.Complex) {
+				return
+			}
+
+			operator, _ := operators.Lookup(task.Operation)
+			args := make([]complex128, operator.Arity)
+			for i := range args {
+				args[i] = complex(*task.Args[i], *task.ImagArgs[i])
+			}
+			value, err := operator.Complex(args...)
+			assert.NoError(t, err)
+
+			err, _ = manager.CompleteTask(ctx, &models.TaskCompleted{
+				ID: task.ID, Expression: task.Expression, Result: real(value), ImagResult: imag(value),
+			})
+			assert.NoError(t, err)
+		}
+	}
+
+	t.Run("complex expression is calculated with imaginary parts", func(t *testing.T) {
+		expression := &models.ExpressionAdd{Expression: "(1+2i)*(3-i)", Numeric: models.NumericComplex}
+
+		id, err, code := manager.AddExpression(ctx, expression, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		runComplex(t)
+
+		expr, err, _ := manager.ReadExpression(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		assert.Equal(t, models.NumericComplex, expr.Numeric)
+		assert.Equal(t, float64(5), *expr.Result)
+		if assert.NotNil(t, expr.ImagResult) {
+			assert.Equal(t, float64(5), *expr.ImagResult)
+		}
+	})
+
+	t.Run("reference to complex expression keeps imaginary part", func(t *testing.T) {
+		first, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "sqrt(-4) * 1", Numeric: models.NumericComplex}, userID)
+		assert.NoError(t, err)
+		// Ссылка на ещё не вычисленное выражение получает результат при его завершении
+		pending, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d + 1", first), Numeric: models.NumericComplex}, userID)
+		assert.NoError(t, err)
+		runComplex(t)
+
+		// Ссылка на вычисленное выражение подставляется числом
+		completed, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d + 1", first), Numeric: models.NumericComplex}, userID)
+		assert.NoError(t, err)
+		runComplex(t)
+
+		for _, id := range []int64{pending, completed} {
+			expr, err, _ := manager.ReadExpression(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, "completed", expr.Status)
+			assert.Equal(t, float64(1), *expr.Result)
+			if assert.NotNil(t, expr.ImagResult) {
+				assert.Equal(t, float64(2), *expr.ImagResult)
+			}
+		}
+	})
+
+	// Выполняем задачи так же, как это делают агенты в интервальном режиме
+	runInterval := func(t *testing.T) {
+		for {
+			task, err, code := manager.ReadTask(ctx)
+			assert.NoError(t, err)
+			if code == http.StatusNotFound {
+				return
+			}
+			if !assert.True(t, task.Interval) {
+				return
+			}
+
+			operator, _ := operators.Lookup(task.Operation)
+			args := make([]operators.Interval, operator.Arity)
+			for i := range args {
+				args[i] = operators.Interval{Lo: *task.Args[i], Hi: *task.UpperArgs[i]}
+			}
+			value, err := operator.Interval(args...)
+			assert.NoError(t, err)
+
+			err, _ = manager.CompleteTask(ctx, &models.TaskCompleted{
+				ID: task.ID, Expression: task.Expression, Result: value.Lo, UpperResult: &value.Hi,
+			})
+			assert.NoError(t, err)
+		}
+	}
+
+	t.Run("interval expression is calculated with bounds", func(t *testing.T) {
+		expression := &models.ExpressionAdd{Expression: "3.0±0.1 * 2.0±0.05 - 1", Numeric: models.NumericInterval}
+
+		id, err, code := manager.AddExpression(ctx, expression, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		runInterval(t)
+
+		expr, err, _ := manager.ReadExpression(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		assert.Equal(t, models.NumericInterval, expr.Numeric)
+		if assert.NotNil(t, expr.LowerResult) && assert.NotNil(t, expr.UpperResult) {
+			assert.InDelta(t, 4.655, *expr.LowerResult, 1e-12)
+			assert.InDelta(t, 5.355, *expr.UpperResult, 1e-12)
+			assert.LessOrEqual(t, *expr.LowerResult, 4.655)
+			assert.GreaterOrEqual(t, *expr.UpperResult, 5.355)
+		}
+		assert.InDelta(t, 5.005, *expr.Result, 1e-12)
+	})
+
+	t.Run("reference to interval expression keeps its bounds", func(t *testing.T) {
+		first, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "3±1 * 1", Numeric: models.NumericInterval}, userID)
+		assert.NoError(t, err)
+		// Ссылка на ещё не вычисленное выражение получает интервал при его завершении
+		pending, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d * 2", first), Numeric: models.NumericInterval}, userID)
+		assert.NoError(t, err)
+		runInterval(t)
+
+		// Ссылка на вычисленное выражение подставляется интервалом
+		completed, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d * 2", first), Numeric: models.NumericInterval}, userID)
+		assert.NoError(t, err)
+		runInterval(t)
+
+		for _, id := range []int64{pending, completed} {
+			expr, err, _ := manager.ReadExpression(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, "completed", expr.Status)
+			if assert.NotNil(t, expr.LowerResult) && assert.NotNil(t, expr.UpperResult) {
+				assert.InDelta(t, 4, *expr.LowerResult, 1e-12)
+				assert.InDelta(t, 8, *expr.UpperResult, 1e-12)
+				assert.LessOrEqual(t, *expr.LowerResult, 4.0)
+				assert.GreaterOrEqual(t, *expr.UpperResult, 8.0)
+			}
+		}
+	})
+}
+
+func TestExpressionManager_References_Integration(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:testdb?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if setupTestDatabase(db) != nil {
+		t.Fatal(err)
+	}
+
+	depsRepo := tasks_repository.NewTaskDepsRepository(db)
+	argsRepo := tasks_repository.NewTaskArgsRepository(db)
+	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
+	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
+
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
+
+	ctx := context.Background()
+	userID := int64(1)
+
+	// Выполняем задачи так же, как это делают агенты
+	runTasks := func(t *testing.T) {
+		for {
+			task, err, code := manager.ReadTask(ctx)
+			assert.NoError(t, err)
+			if code == http.StatusNotFound {
+				return
+			}
+
+			operator, _ := operators.Lookup(task.Operation)
+			args := make([]float64, operator.Arity)
+			for i := range args {
+				args[i] = *task.Args[i]
+			}
+			completed := &models.TaskCompleted{ID: task.ID, Expression: task.Expression}
+			if operator.Random != nil {
+				completed.Result, err = operator.Random(task.Seed, args...)
+			} else {
+				completed.Result, err = operator.Eval(args...)
+			}
+			if err != nil {
+				completed.Error = err.Error()
+			}
+
+			err, _ = manager.CompleteTask(ctx, completed)
+			assert.NoError(t, err)
+		}
+	}
+
+	t.Run("pending reference waits for the referenced expression", func(t *testing.T) {
+		first, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "2*3"}, userID)
+		assert.NoError(t, err)
+
+		second, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d*$%d+1", first, first)}, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+
+		runTasks(t)
+
+		expr, err, _ := manager.ReadExpression(ctx, second)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		assert.Equal(t, float64(37), *expr.Result)
+
+		// Ссылка на вычисленное выражение подставляется как число
+		third, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d/2", second)}, userID)
+		assert.NoError(t, err)
+
+		runTasks(t)
+
+		expr, err, _ = manager.ReadExpression(ctx, third)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(18.5), *expr.Result)
+	})
+
+	t.Run("reference keeps unit of the referenced expression", func(t *testing.T) {
+		first, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "5 km / 2 h in km/h"}, userID)
+		assert.NoError(t, err)
+		pending, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d * 2 h in km", first)}, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, code)
+		runTasks(t)
+
+		completed, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d * 2 h in km", first)}, userID)
+		assert.NoError(t, err)
+		runTasks(t)
+
+		for _, id := range []int64{pending, completed} {
+			expr, err, _ := manager.ReadExpression(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, "completed", expr.Status)
+			assert.Equal(t, "km", expr.Unit)
+			assert.InDelta(t, 5, *expr.Result, 1e-9)
+		}
+
+		_, err, code = manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d + 1 m", first)}, userID)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("shared subexpression is computed once", func(t *testing.T) {
+		id, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "(2+3)*(2+3) - (2+3)/2"}, userID)
+		assert.NoError(t, err)
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks, err, _ := taskRepo.ReadTasksByExpressionID(ctx, tx, id)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 4)
+		_ = tx.Rollback()
+
+		runTasks(t)
+
+		expr, err, _ := manager.ReadExpression(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		assert.Equal(t, 22.5, *expr.Result)
+	})
+
+	t.Run("error of referenced expression fails dependent expression", func(t *testing.T) {
+		first, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "1/0"}, userID)
+		assert.NoError(t, err)
+
+		second, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d+1", first)}, userID)
+		assert.NoError(t, err)
+
+		runTasks(t)
+
+		expr, err, _ := manager.ReadExpression(ctx, second)
+		assert.NoError(t, err)
+		assert.Equal(t, "error", expr.Status)
+		assert.Contains(t, expr.Error, operators.ErrDivisionByZero.Error())
+
+		_, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d+1", first)}, userID)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("integer operations and their domain errors", func(t *testing.T) {
+		id, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "5! // 7 + 2^3 % 5 + gcd(12, 18)"}, userID)
+		assert.NoError(t, err)
+		failed, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "(2-5)! + 1"}, userID)
+		assert.NoError(t, err)
+		byZero, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "7 % (2-2)"}, userID)
+		assert.NoError(t, err)
+
+		runTasks(t)
+
+		expr, err, _ := manager.ReadExpression(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", expr.Status)
+		assert.Equal(t, 26.0, *expr.Result)
+
+		expr, err, _ = manager.ReadExpression(ctx, failed)
+		assert.NoError(t, err)
+		assert.Equal(t, "error", expr.Status)
+		assert.Contains(t, expr.Error, operators.ErrFactorialDomain.Error())
+
+		expr, err, _ = manager.ReadExpression(ctx, byZero)
+		assert.NoError(t, err)
+		assert.Equal(t, "error", expr.Status)
+		assert.Contains(t, expr.Error, operators.ErrDivisionByZero.Error())
+	})
+
+	t.Run("random functions with the same seed give the same result", func(t *testing.T) {
+		expression := "randint(1, 6) + randint(1, 6) + normal(0, 1) * rand()"
+		seed := int64(12345)
+		first, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: expression, Seed: &seed}, userID)
+		assert.NoError(t, err)
+		second, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: expression, Seed: &seed}, userID)
+		assert.NoError(t, err)
+		generated, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: expression}, userID)
+		assert.NoError(t, err)
+		plain, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "2+2"}, userID)
+		assert.NoError(t, err)
+
+		runTasks(t)
+
+		firstExpr, err, _ := manager.ReadExpression(ctx, first)
+		assert.NoError(t, err)
+		secondExpr, err, _ := manager.ReadExpression(ctx, second)
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", firstExpr.Status)
+		assert.Equal(t, seed, *firstExpr.Seed)
+		assert.Equal(t, *firstExpr.Result, *secondExpr.Result)
+
+		generatedExpr, err, _ := manager.ReadExpression(ctx, generated)
+		assert.NoError(t, err)
+		if assert.NotNil(t, generatedExpr.Seed) {
+			assert.True(t, *generatedExpr.Seed >= 0 && *generatedExpr.Seed <= operators.MaxSeed)
+		}
+
+		plainExpr, err, _ := manager.ReadExpression(ctx, plain)
+		assert.NoError(t, err)
+		assert.Nil(t, plainExpr.Seed)
+	})
+
+	t.Run("reference to expression of another user", func(t *testing.T) {
+		first, err, _ := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: "2+2"}, userID)
+		assert.NoError(t, err)
+
+		_, err, code := manager.AddExpression(ctx, &models.ExpressionAdd{Expression: fmt.Sprintf("$%d+1", first)}, userID+1)
+		assert.EqualError(t, err, "невозможно получить выражение другого пользователя")
+		assert.Equal(t, http.StatusForbidden, code)
+
+		runTasks(t)
+	})
 }
 
 func TestExpressionManager_ReadExpressions_Integration(t *testing.T) {
@@ -829,8 +2114,9 @@ func TestExpressionManager_ReadExpressions_Integration(t *testing.T) {
 	argsRepo := tasks_repository.NewTaskArgsRepository(db)
 	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
 	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
 
-	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo)
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
 
 	ctx := context.Background()
 	userID := int64(1)
@@ -897,8 +2183,9 @@ func TestExpressionManager_ReadExpression_Integration(t *testing.T) {
 	argsRepo := tasks_repository.NewTaskArgsRepository(db)
 	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
 	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
 
-	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo)
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
 
 	ctx := context.Background()
 
@@ -959,8 +2246,9 @@ func TestExpressionManager_ReadTask_Integration(t *testing.T) {
 	argsRepo := tasks_repository.NewTaskArgsRepository(db)
 	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
 	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
 
-	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo)
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
 
 	ctx := context.Background()
 
@@ -1109,8 +2397,9 @@ func TestExpressionManager_CompleteTask_Integration(t *testing.T) {
 	argsRepo := tasks_repository.NewTaskArgsRepository(db)
 	taskRepo := tasks_repository.NewTasksRepository(db, depsRepo, argsRepo)
 	exprRepo := expressions_repository.NewExpressionsRepository(db, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db)
 
-	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo)
+	manager := expressions_manager.NewExpressionManager(db, exprRepo, taskRepo, funcRepo)
 
 	ctx := context.Background()
 
@@ -1323,8 +2612,16 @@ func setupTestDatabase(db *sql.DB) error {
 			id INTEGER PRIMARY KEY AUTOINCREMENT, 
 			user_id INTEGER NOT NULL,
 			expression_string TEXT NOT NULL,
+			canonical_string TEXT NOT NULL DEFAULT '',
+			numeric TEXT NOT NULL DEFAULT 'float',
+			unit TEXT NOT NULL DEFAULT '',
 			status TEXT CHECK(status IN ('pending', 'processing', 'completed', 'error')) DEFAULT 'pending',
 			result REAL,
+			exact_result TEXT,
+			imag_result REAL,
+			lower_result REAL,
+			upper_result REAL,
+			seed INTEGER,
 			error TEXT DEFAULT ''
 		);`); err != nil {
 		return err
@@ -1333,9 +2630,18 @@ func setupTestDatabase(db *sql.DB) error {
 		CREATE TABLE tasks(
 			id INTEGER PRIMARY KEY AUTOINCREMENT, 
 			expression_id INTEGER NOT NULL,
-			operation TEXT NOT NULL CHECK(operation IN ('+', '-', '*', '/', '^', 'u-')),
+			operation TEXT NOT NULL CHECK(operation IN ('+', '-', '*', '/', '^', 'u-', 'sqrt', '<', '<=', '>', '>=', '==', '!=', '&&', '||', 'not', 'if', '%', '//', '!', 'gcd', 'lcm', 'isprime', 'rand', 'randint', 'normal')),
 		    result REAL,
-			status TEXT CHECK(status IN ('pending', 'processing', 'completed', 'error')) DEFAULT 'pending',
+			exact INTEGER NOT NULL DEFAULT 0,
+			precision INTEGER NOT NULL DEFAULT 0,
+			exact_result TEXT,
+			complex INTEGER NOT NULL DEFAULT 0,
+			imag_result REAL,
+			interval INTEGER NOT NULL DEFAULT 0,
+			upper_result REAL,
+			iteration INTEGER NOT NULL DEFAULT 0,
+			seed INTEGER NOT NULL DEFAULT 0,
+			status TEXT CHECK(status IN ('pending', 'processing', 'completed', 'skipped', 'error')) DEFAULT 'pending',
 		    
 			FOREIGN KEY (expression_id) REFERENCES expressions(id) ON DELETE CASCADE
 		);`); err != nil {
@@ -1343,24 +2649,65 @@ func setupTestDatabase(db *sql.DB) error {
 	}
 	if _, err := db.Exec(`
 		CREATE TABLE task_args (
-			task_id INTEGER PRIMARY KEY NOT NULL,
-			first REAL,
-			second REAL,
+			task_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			value REAL,
+			exact TEXT,
+			imag REAL,
+			upper REAL,
 			
+			PRIMARY KEY (task_id, position),
 			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
 		);`); err != nil {
 		return err
 	}
 	if _, err := db.Exec(`
 		CREATE TABLE task_deps (
-			task_id INTEGER PRIMARY KEY NOT NULL,
-			first INTEGER,
-			second INTEGER,
+			task_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			dependency INTEGER NOT NULL,
 			
+			PRIMARY KEY (task_id, position),
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE task_guards (
+			task_id INTEGER NOT NULL,
+			condition INTEGER NOT NULL,
+			value INTEGER NOT NULL,
+
+			PRIMARY KEY (task_id, condition),
 			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
 		);`); err != nil {
 		return err
 	}
+	if _, err := db.Exec(`
+		CREATE TABLE expression_vars (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			expression_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			task_id INTEGER,
+			value REAL,
+			estimate INTEGER NOT NULL DEFAULT 0,
+
+			FOREIGN KEY (expression_id) REFERENCES expressions(id) ON DELETE CASCADE
+		);`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE functions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			params TEXT NOT NULL,
+			body TEXT NOT NULL,
+
+			UNIQUE (user_id, name)
+		);`); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1370,6 +2717,9 @@ func clearTestDatabase(db *sql.DB) error {
 	}
 
 	tables := []string{
+		"functions",
+		"expression_vars",
+		"task_guards",
 		"task_deps",
 		"task_args",
 		"tasks",
@@ -2,6 +2,7 @@ package managers
 
 import (
 	"context"
+	"github.com/OinkiePie/calc_3/orchestrator/internal/task_splitter"
 	"github.com/OinkiePie/calc_3/pkg/models"
 )
 
@@ -97,7 +98,7 @@ type ExpressionManagerInterface interface {
 	// Args:
 	//
 	//	ctx: context.Context - Контекст выполнения.
-	//	expressionString: string - Строка с математическим выражением.
+	//	expression: *models.ExpressionAdd - Строка с математическим выражением и значения переменных.
 	//	claims: int64 - ID пользователя-владельца.
 	//
 	// Returns:
@@ -106,9 +107,27 @@ type ExpressionManagerInterface interface {
 	//	error - Ошибка выполнения.
 	//	int - HTTP статус код:
 	//		- 201 Created при успешном выполнении
-	//		- 400 Bad Request при невозможность преобразовать выражение
+	//		- 400 Bad Request при невозможность преобразовать выражение или несвязанной переменной
 	//		- 500 Internal Server Error при ошибках
-	AddExpression(ctx context.Context, expressionString string, claims int64) (int64, error, int)
+	AddExpression(ctx context.Context, expression *models.ExpressionAdd, claims int64) (int64, error, int)
+
+	// ExplainExpression разбирает выражение так же, как AddExpression, но ничего не сохраняет.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения.
+	//	expression: *models.ExpressionAdd - Строка с математическим выражением и значения переменных.
+	//	claims: int64 - ID пользователя.
+	//
+	// Returns:
+	//
+	//	*task_splitter.Plan - Результат разбора выражения: токены, RPN и задачи.
+	//	error - Ошибка выполнения.
+	//	int - HTTP статус код:
+	//		- 200 OK при успешном разборе
+	//		- 400 Bad Request при невозможность преобразовать выражение или несвязанной переменной
+	//		- 500 Internal Server Error при ошибках
+	ExplainExpression(ctx context.Context, expression *models.ExpressionAdd, claims int64) (*task_splitter.Plan, error, int)
 
 	// ReadExpressions получает все выражения пользователя.
 	//
@@ -176,4 +195,56 @@ type ExpressionManagerInterface interface {
 	//		- 200 OK при успешном выполнении
 	//		- 500 Internal Server Error при ошибках
 	CompleteTask(ctx context.Context, taskCompleted *models.TaskCompleted) (error, int)
+
+	// AddFunction добавляет функцию пользователя, например "f(x, y) = x^2 + y^2".
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения.
+	//	definition: string - Объявление функции.
+	//	claims: int64 - ID пользователя-владельца.
+	//
+	// Returns:
+	//
+	//	*models.Function - Добавленная функция.
+	//	error - Ошибка выполнения.
+	//	int - HTTP статус код:
+	//		- 201 Created при успешном выполнении
+	//		- 400 Bad Request при ошибке разбора объявления
+	//		- 409 Conflict если у пользователя уже есть функция с таким именем
+	//		- 500 Internal Server Error при ошибках
+	AddFunction(ctx context.Context, definition string, claims int64) (*models.Function, error, int)
+
+	// ReadFunctions получает все функции пользователя.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения.
+	//	claims: int64 - ID пользователя.
+	//
+	// Returns:
+	//
+	//	[]*models.Function - Функции пользователя.
+	//	error - Ошибка выполнения.
+	//	int - HTTP статус код:
+	//		- 200 OK при успешном выполнении
+	//		- 500 Internal Server Error при ошибках
+	ReadFunctions(ctx context.Context, claims int64) ([]*models.Function, error, int)
+
+	// DeleteFunction удаляет функцию пользователя. Уже добавленные выражения, вызывающие функцию, не затрагиваются.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения.
+	//	name: string - Имя функции.
+	//	claims: int64 - ID пользователя-владельца.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения.
+	//	int - HTTP статус код:
+	//		- 200 OK при успешном удалении
+	//		- 404 Not Found если у пользователя нет функции с таким именем
+	//		- 500 Internal Server Error при ошибках
+	DeleteFunction(ctx context.Context, name string, claims int64) (error, int)
 }
@@ -2,6 +2,7 @@ package managers
 
 import (
 	"context"
+	"github.com/OinkiePie/calc_3/orchestrator/internal/task_splitter"
 	"github.com/OinkiePie/calc_3/pkg/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -39,11 +40,16 @@ type MockExpressionManager struct {
 	mock.Mock
 }
 
-func (m *MockExpressionManager) AddExpression(ctx context.Context, expressionString string, claims int64) (int64, error, int) {
-	args := m.Called(ctx, expressionString, claims)
+func (m *MockExpressionManager) AddExpression(ctx context.Context, expression *models.ExpressionAdd, claims int64) (int64, error, int) {
+	args := m.Called(ctx, expression, claims)
 	return args.Get(0).(int64), args.Error(1), args.Int(2)
 }
 
+func (m *MockExpressionManager) ExplainExpression(ctx context.Context, expression *models.ExpressionAdd, claims int64) (*task_splitter.Plan, error, int) {
+	args := m.Called(ctx, expression, claims)
+	return args.Get(0).(*task_splitter.Plan), args.Error(1), args.Int(2)
+}
+
 func (m *MockExpressionManager) ReadExpressions(ctx context.Context, id int64) ([]*models.Expression, error, int) {
 	args := m.Called(ctx, id)
 	return args.Get(0).([]*models.Expression), args.Error(1), args.Int(2)
@@ -63,3 +69,18 @@ func (m *MockExpressionManager) CompleteTask(ctx context.Context, taskCompleted
 	args := m.Called(ctx, taskCompleted)
 	return args.Error(0), args.Int(1)
 }
+
+func (m *MockExpressionManager) AddFunction(ctx context.Context, definition string, claims int64) (*models.Function, error, int) {
+	args := m.Called(ctx, definition, claims)
+	return args.Get(0).(*models.Function), args.Error(1), args.Int(2)
+}
+
+func (m *MockExpressionManager) ReadFunctions(ctx context.Context, claims int64) ([]*models.Function, error, int) {
+	args := m.Called(ctx, claims)
+	return args.Get(0).([]*models.Function), args.Error(1), args.Int(2)
+}
+
+func (m *MockExpressionManager) DeleteFunction(ctx context.Context, name string, claims int64) (error, int) {
+	args := m.Called(ctx, name, claims)
+	return args.Error(0), args.Int(1)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/OinkiePie/calc_3/orchestrator/internal/managers/user_manager"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories/expressions_repository"
+	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories/functions_repository"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories/session_repository"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories/tasks_repository"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories/user_repository"
@@ -24,6 +25,7 @@ type Providers struct {
 	DepsRepo    repositories.TasksDepsRepositoryInterface   // Репозиторий зависимостей задач
 	TaskRepo    repositories.TasksRepositoryInterface       // Репозиторий задач
 	ExprRepo    repositories.ExpressionsRepositoryInterface // Репозиторий выражений
+	FuncRepo    repositories.FunctionsRepositoryInterface   // Репозиторий функций пользователей
 	ExprManager managers.ExpressionManagerInterface         // Менеджер выражений
 	JWTManager  jwt_manager.JWTManagerInterface             // Менеджер JWT-токенов
 	DB          *database.DataBase                          // Подключение к базе данных
@@ -58,7 +60,8 @@ func NewProviders(ctx context.Context, dbPath string, jwtKey string) (*Providers
 	depsRepo := tasks_repository.NewTaskDepsRepository(db.DB)
 	taskRepo := tasks_repository.NewTasksRepository(db.DB, depsRepo, argsRepo)
 	exprRepo := expressions_repository.NewExpressionsRepository(db.DB, taskRepo)
-	taskManager := expressions_manager.NewExpressionManager(db.DB, exprRepo, taskRepo)
+	funcRepo := functions_repository.NewFunctionsRepository(db.DB)
+	taskManager := expressions_manager.NewExpressionManager(db.DB, exprRepo, taskRepo, funcRepo)
 
 	return &Providers{
 		SessionRepo: sessionRepo,
@@ -68,6 +71,7 @@ func NewProviders(ctx context.Context, dbPath string, jwtKey string) (*Providers
 		DepsRepo:    depsRepo,
 		TaskRepo:    taskRepo,
 		ExprRepo:    exprRepo,
+		FuncRepo:    funcRepo,
 		ExprManager: taskManager,
 		JWTManager:  jwtManager,
 		DB:          db,
@@ -50,9 +50,9 @@ func (r *ExpressionsRepository) CreateExpression(ctx context.Context, tx *sql.Tx
 
 	query := `
 	INSERT INTO expressions 
-    	(user_id, expression_string) 
+    	(user_id, expression_string, canonical_string, numeric, unit, seed) 
     VALUES
-	       (?, ?)
+	       (?, ?, ?, ?, ?, ?)
     RETURNING
     	id`
 
@@ -61,6 +61,10 @@ func (r *ExpressionsRepository) CreateExpression(ctx context.Context, tx *sql.Tx
 		query,
 		expr.UserID,
 		expr.ExpressionString,
+		expr.CanonicalString,
+		expr.Numeric,
+		expr.Unit,
+		expr.Seed,
 	).Scan(&expressionID)
 
 	if err != nil {
@@ -79,23 +83,84 @@ func (r *ExpressionsRepository) CreateExpression(ctx context.Context, tx *sql.Tx
 
 	for i := range expr.Tasks {
 		task := expr.Tasks[i]
-		task.Dependencies = make([]int64, len(task.DependencyIndexes))
+		deps := make([]int64, len(task.DependencyIndexes))
 		for j, depIndex := range task.DependencyIndexes {
-			task.Dependencies[j] = 0
-			if depIndex > 0 && depIndex < len(expr.Tasks) {
-				task.Dependencies[j] = expr.Tasks[depIndex].ID - 1
-			} else {
-				task.Dependencies[j] = -1
+			switch {
+			case depIndex > 0 && depIndex <= len(expr.Tasks):
+				// Индексы зависимостей начинаются с 1
+				deps[j] = expr.Tasks[depIndex-1].ID
+			case j < len(task.Dependencies) && task.Dependencies[j] > 0:
+				// Зависимость от задачи другого выражения уже задана ID
+				deps[j] = task.Dependencies[j]
+			default:
+				deps[j] = -1
 			}
 		}
+		task.Dependencies = deps
 		if err, code := r.taskRepo.UpdateTaskDependencies(ctx, tx, task); err != nil {
 			return 0, err, code
 		}
+
+		// Условия задачи ветви if задаются индексами задач-условий этого же выражения
+		if len(task.Guards) > 0 {
+			for j := range task.Guards {
+				task.Guards[j].Condition = expr.Tasks[task.Guards[j].ConditionIndex-1].ID
+			}
+			if err, code := r.taskRepo.CreateTaskGuards(ctx, tx, task); err != nil {
+				return 0, err, code
+			}
+		}
+	}
+
+	if err, code := r.createExpressionVariables(ctx, tx, expressionID, expr); err != nil {
+		return 0, err, code
 	}
 
 	return expressionID, nil, http.StatusOK
 }
 
+// createExpressionVariables сохраняет именованные промежуточные значения сценария.
+// Индексы задач переменных заменяются на ID созданных задач.
+// Переменные, связанные со ссылкой на другое выражение, сохраняются с ID его корневой задачи.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	expressionID: int64 - ID выражения.
+//	expr: *models.Expression - Выражение с созданными задачами и переменными.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции.
+//	int - HTTP-статус код:
+//	    - 200 OK при успешном создании
+//	    - 500 Internal Server Error при ошибках
+func (r *ExpressionsRepository) createExpressionVariables(ctx context.Context, tx *sql.Tx, expressionID int64, expr *models.Expression) (error, int) {
+	query := `
+	INSERT INTO expression_vars
+	    (expression_id, name, task_id, value, estimate)
+	VALUES
+	    (?, ?, ?, ?, ?)`
+
+	for _, variable := range expr.Variables {
+		var taskID sql.NullInt64
+		if variable.TaskIndex > 0 && variable.TaskIndex <= len(expr.Tasks) {
+			variable.TaskID = expr.Tasks[variable.TaskIndex-1].ID
+		}
+		if variable.TaskID > 0 {
+			// Значение вычисляет задача этого или другого (при ссылке $42) выражения
+			taskID = sql.NullInt64{Int64: variable.TaskID, Valid: true}
+		}
+
+		if _, err := tx.ExecContext(ctx, query, expressionID, variable.Name, taskID, variable.Value, variable.Estimate); err != nil {
+			return fmt.Errorf("не удалось сохранить переменную %s: %w", variable.Name, err), http.StatusInternalServerError
+		}
+	}
+
+	return nil, http.StatusOK
+}
+
 // ReadExpressionByID получает выражение по его ID вместе с задачами.
 //
 // Args:
@@ -117,7 +182,9 @@ func (r *ExpressionsRepository) ReadExpressionByID(ctx context.Context, tx *sql.
 	query := `
 		SELECT
 		    id, status, result, expression_string,
-		    error, user_id
+		    canonical_string, error, user_id,
+		    numeric, exact_result, imag_result,
+		    lower_result, upper_result, unit, seed
 		FROM
 		    expressions
 		WHERE
@@ -128,8 +195,16 @@ func (r *ExpressionsRepository) ReadExpressionByID(ctx context.Context, tx *sql.
 		&expr.Status,
 		&expr.Result,
 		&expr.ExpressionString,
+		&expr.CanonicalString,
 		&expr.Error,
 		&expr.UserID,
+		&expr.Numeric,
+		&expr.ExactResult,
+		&expr.ImagResult,
+		&expr.LowerResult,
+		&expr.UpperResult,
+		&expr.Unit,
+		&expr.Seed,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -168,7 +243,9 @@ func (r *ExpressionsRepository) ReadExpressionsByUserID(ctx context.Context, tx
 	query := `
 		SELECT
 		    id, status, result, expression_string,
-		    error, user_id
+		    canonical_string, error, user_id,
+		    numeric, exact_result, imag_result,
+		    lower_result, upper_result, unit, seed
 		FROM
 		    expressions
 		WHERE
@@ -188,8 +265,16 @@ func (r *ExpressionsRepository) ReadExpressionsByUserID(ctx context.Context, tx
 			&expr.Status,
 			&expr.Result,
 			&expr.ExpressionString,
+			&expr.CanonicalString,
 			&expr.Error,
 			&expr.UserID,
+			&expr.Numeric,
+			&expr.ExactResult,
+			&expr.ImagResult,
+			&expr.LowerResult,
+			&expr.UpperResult,
+			&expr.Unit,
+			&expr.Seed,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("не удалось прочитать выражение: %w", err), http.StatusInternalServerError
@@ -330,3 +415,181 @@ func (r *ExpressionsRepository) UpdateExpressionResult(ctx context.Context, tx *
 	}
 	return nil, http.StatusOK
 }
+
+// UpdateExpressionExactResult обновляет точный результат вычисления выражения.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - ID выражения.
+//	exact: string - Результат вычисления: дробь "num/den" или десятичная запись.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *ExpressionsRepository) UpdateExpressionExactResult(ctx context.Context, tx *sql.Tx, id int64, exact string) (error, int) {
+	query := `
+		UPDATE
+		    expressions
+		SET
+		    exact_result = ?
+		WHERE
+		    id = ?`
+
+	_, err := tx.ExecContext(ctx, query, exact, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить точный результат выражения: %w", err), http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+// UpdateExpressionImagResult обновляет мнимую часть результата выражения в режиме complex.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - ID выражения.
+//	imag: float64 - Мнимая часть результата.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *ExpressionsRepository) UpdateExpressionImagResult(ctx context.Context, tx *sql.Tx, id int64, imag float64) (error, int) {
+	query := `
+		UPDATE
+		    expressions
+		SET
+		    imag_result = ?
+		WHERE
+		    id = ?`
+
+	_, err := tx.ExecContext(ctx, query, imag, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить мнимую часть результата выражения: %w", err), http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+// UpdateExpressionInterval обновляет границы интервала результата выражения в режиме interval.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - ID выражения.
+//	lower: float64 - Нижняя граница результата.
+//	upper: float64 - Верхняя граница результата.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *ExpressionsRepository) UpdateExpressionInterval(ctx context.Context, tx *sql.Tx, id int64, lower, upper float64) (error, int) {
+	query := `
+		UPDATE
+		    expressions
+		SET
+		    lower_result = ?,
+		    upper_result = ?
+		WHERE
+		    id = ?`
+
+	_, err := tx.ExecContext(ctx, query, lower, upper, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить границы результата выражения: %w", err), http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+// ReadExpressionVariables получает именованные промежуточные значения сценария.
+// Для ещё не зафиксированных значений используется текущий результат задачи.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - ID выражения.
+//
+// Returns:
+//
+//	[]*models.ExpressionVariable - Список переменных в порядке присваивания.
+//	error - Ошибка выполнения операции.
+//	int - HTTP статус код:
+//	    - 200 OK при успешном получении
+//	    - 500 Internal Server Error при ошибках
+func (r *ExpressionsRepository) ReadExpressionVariables(ctx context.Context, tx *sql.Tx, id int64) ([]*models.ExpressionVariable, error, int) {
+	var variables []*models.ExpressionVariable
+	query := `
+		SELECT
+		    v.name, COALESCE(v.task_id, 0), COALESCE(v.value, t.result), v.estimate
+		FROM
+		    expression_vars v
+		LEFT JOIN
+		    tasks t ON t.id = v.task_id
+		WHERE
+		    v.expression_id = ?
+		ORDER BY
+		    v.id`
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить переменные выражения: %w", err), http.StatusInternalServerError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		variable := &models.ExpressionVariable{}
+		if err := rows.Scan(&variable.Name, &variable.TaskID, &variable.Value, &variable.Estimate); err != nil {
+			return nil, fmt.Errorf("не удалось прочитать переменную выражения: %w", err), http.StatusInternalServerError
+		}
+		variables = append(variables, variable)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке строк: %w", err), http.StatusInternalServerError
+	}
+
+	return variables, nil, http.StatusOK
+}
+
+// UpdateExpressionVariables фиксирует значения именованных переменных из результатов задач выражения.
+// Обновляются переменные всех выражений, значения которых вычисляют задачи этого выражения
+// (в том числе переменные выражений, ссылающихся на него). Вызывается перед удалением задач выражения.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - ID выражения.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *ExpressionsRepository) UpdateExpressionVariables(ctx context.Context, tx *sql.Tx, id int64) (error, int) {
+	query := `
+		UPDATE
+		    expression_vars
+		SET
+		    value = (SELECT result FROM tasks WHERE tasks.id = expression_vars.task_id)
+		WHERE
+		    value IS NULL AND task_id IN (SELECT id FROM tasks WHERE expression_id = ?)`
+
+	_, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить переменные выражения: %w", err), http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
@@ -35,6 +35,8 @@ func TestCreateExpression_Success(t *testing.T) {
 	expr := &models.Expression{
 		UserID:           1,
 		ExpressionString: "2+2",
+		CanonicalString:  "2 + 2",
+		Numeric:          models.NumericFloat,
 		Tasks: []*models.Task{
 			{
 				Operation:         "+",
@@ -46,7 +48,7 @@ func TestCreateExpression_Success(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 	sqlMock.ExpectQuery(`INSERT INTO expressions`).
-		WithArgs(expr.UserID, expr.ExpressionString).
+		WithArgs(expr.UserID, expr.ExpressionString, expr.CanonicalString, expr.Numeric, expr.Unit, expr.Seed).
 		WillReturnRows(rows)
 
 	taskRepoMock.On("CreateTask", mock.Anything, tx, expr.Tasks[0]).
@@ -65,6 +67,61 @@ func TestCreateExpression_Success(t *testing.T) {
 	taskRepoMock.AssertExpectations(t)
 }
 
+func TestCreateExpression_WithGuards(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	taskRepoMock := new(m.MockTasksRepository)
+
+	repo := expressions_repository.NewExpressionsRepository(db, taskRepoMock)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	// if(x > 0, sqrt(x), 0) при x = 4
+	expr := &models.Expression{
+		UserID:           1,
+		ExpressionString: "if(4 > 0, sqrt(4), 0)",
+		Numeric:          models.NumericFloat,
+		Tasks: []*models.Task{
+			{Operation: ">", Args: []*float64{m.Float64Ptr(4), m.Float64Ptr(0)}, DependencyIndexes: []int{0, 0}},
+			{Operation: "sqrt", Args: []*float64{m.Float64Ptr(4)}, DependencyIndexes: []int{0}, Guards: []models.TaskGuard{{ConditionIndex: 1, Value: true}}},
+			{Operation: "if", Args: []*float64{nil, nil, m.Float64Ptr(0)}, DependencyIndexes: []int{1, 2, 0}},
+		},
+	}
+
+	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+	sqlMock.ExpectQuery(`INSERT INTO expressions`).
+		WithArgs(expr.UserID, expr.ExpressionString, expr.CanonicalString, expr.Numeric, expr.Unit, expr.Seed).
+		WillReturnRows(rows)
+
+	for i, task := range expr.Tasks {
+		taskRepoMock.On("CreateTask", mock.Anything, tx, task).
+			Return(int64(10+i), nil, http.StatusCreated).Once()
+		taskRepoMock.On("UpdateTaskDependencies", mock.Anything, tx, task).
+			Return(nil, http.StatusOK).Once()
+	}
+	taskRepoMock.On("CreateTaskGuards", mock.Anything, tx, expr.Tasks[1]).
+		Return(nil, http.StatusCreated).Once()
+
+	id, err, status := repo.CreateExpression(context.Background(), tx, expr)
+
+	assert.Equal(t, int64(1), id)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []models.TaskGuard{{Condition: 10, ConditionIndex: 1, Value: true}}, expr.Tasks[1].Guards)
+	assert.Equal(t, []int64{10, 11, -1}, expr.Tasks[2].Dependencies)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	taskRepoMock.AssertExpectations(t)
+}
+
 func TestCreateExpression_InsertError(t *testing.T) {
 	db, sqlMock, err := sqlmock.New()
 	if err != nil {
@@ -88,7 +145,7 @@ func TestCreateExpression_InsertError(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(`INSERT INTO expressions`).
-		WithArgs(expr.UserID, expr.ExpressionString).
+		WithArgs(expr.UserID, expr.ExpressionString, expr.CanonicalString, expr.Numeric, expr.Unit, expr.Seed).
 		WillReturnError(fmt.Errorf("database error"))
 
 	id, err, status := repo.CreateExpression(context.Background(), tx, expr)
@@ -129,7 +186,7 @@ func TestCreateExpression_TaskCreationError(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 	sqlMock.ExpectQuery(`INSERT INTO expressions`).
-		WithArgs(expr.UserID, expr.ExpressionString).
+		WithArgs(expr.UserID, expr.ExpressionString, expr.CanonicalString, expr.Numeric, expr.Unit, expr.Seed).
 		WillReturnRows(rows)
 
 	taskRepoMock.On("CreateTask", mock.Anything, tx, expr.Tasks[0]).
@@ -175,7 +232,7 @@ func TestCreateExpression_DependenciesUpdateError(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 	sqlMock.ExpectQuery(`INSERT INTO expressions`).
-		WithArgs(expr.UserID, expr.ExpressionString).
+		WithArgs(expr.UserID, expr.ExpressionString, expr.CanonicalString, expr.Numeric, expr.Unit, expr.Seed).
 		WillReturnRows(rows)
 
 	taskRepoMock.On("CreateTask", mock.Anything, tx, expr.Tasks[0]).
@@ -193,6 +250,75 @@ func TestCreateExpression_DependenciesUpdateError(t *testing.T) {
 	taskRepoMock.AssertExpectations(t)
 }
 
+func TestCreateExpression_WithVariables(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	taskRepoMock := new(m.MockTasksRepository)
+	repo := expressions_repository.NewExpressionsRepository(db, taskRepoMock)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	first := &models.Task{
+		ID:                1,
+		Operation:         "*",
+		Args:              []*float64{m.Float64Ptr(2), m.Float64Ptr(3)},
+		DependencyIndexes: []int{0, 0},
+	}
+	second := &models.Task{
+		ID:                2,
+		Operation:         "+",
+		Args:              []*float64{nil, m.Float64Ptr(1)},
+		DependencyIndexes: []int{1, 0},
+	}
+	expr := &models.Expression{
+		UserID:           1,
+		ExpressionString: "k = 2; a = k*3; a+1",
+		Tasks:            []*models.Task{first, second},
+		Variables: []*models.ExpressionVariable{
+			{Name: "k", Value: m.Float64Ptr(2)},
+			{Name: "a", TaskIndex: 1},
+		},
+	}
+
+	sqlMock.ExpectQuery(`INSERT INTO expressions`).
+		WithArgs(expr.UserID, expr.ExpressionString, expr.CanonicalString, expr.Numeric, expr.Unit, expr.Seed).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	taskRepoMock.On("CreateTask", mock.Anything, tx, first).
+		Run(func(args mock.Arguments) { args.Get(2).(*models.Task).ID = 10 }).
+		Return(int64(10), nil, http.StatusCreated)
+	taskRepoMock.On("CreateTask", mock.Anything, tx, second).
+		Run(func(args mock.Arguments) { args.Get(2).(*models.Task).ID = 11 }).
+		Return(int64(11), nil, http.StatusCreated)
+	taskRepoMock.On("UpdateTaskDependencies", mock.Anything, tx, mock.Anything).
+		Return(nil, http.StatusOK)
+
+	sqlMock.ExpectExec(`INSERT INTO expression_vars`).
+		WithArgs(int64(3), "k", nil, 2.0, false).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectExec(`INSERT INTO expression_vars`).
+		WithArgs(int64(3), "a", int64(10), nil, false).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	id, err, status := repo.CreateExpression(context.Background(), tx, expr)
+
+	assert.Equal(t, int64(3), id)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []int64{10, -1}, second.Dependencies)
+	assert.Equal(t, int64(10), expr.Variables[1].TaskID)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	taskRepoMock.AssertExpectations(t)
+}
+
 func TestReadExpressionByID_Success(t *testing.T) {
 	db, sqlMock, err := sqlmock.New()
 	if err != nil {
@@ -214,12 +340,14 @@ func TestReadExpressionByID_Success(t *testing.T) {
 		Status:           "completed",
 		Result:           m.Float64Ptr(4),
 		ExpressionString: "2+2",
+		CanonicalString:  "2 + 2",
 		UserID:           1,
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "error", "user_id"}).
+	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "canonical_string", "error", "user_id", "numeric", "exact_result", "imag_result", "lower_result", "upper_result", "unit", "seed"}).
 		AddRow(expectedExpr.ID, expectedExpr.Status, expectedExpr.Result,
-			expectedExpr.ExpressionString, "", expectedExpr.UserID)
+			expectedExpr.ExpressionString, expectedExpr.CanonicalString, "", expectedExpr.UserID,
+			models.NumericRational, "4", nil, nil, nil, "", int64(42))
 
 	sqlMock.ExpectQuery(`SELECT.*FROM expressions WHERE id = \?`).
 		WithArgs(expectedExpr.ID).
@@ -237,6 +365,10 @@ func TestReadExpressionByID_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, status)
 	assert.Equal(t, expectedExpr.ID, expr.ID)
 	assert.Equal(t, expectedExpr.ExpressionString, expr.ExpressionString)
+	assert.Equal(t, expectedExpr.CanonicalString, expr.CanonicalString)
+	assert.Equal(t, models.NumericRational, expr.Numeric)
+	assert.Equal(t, "4", *expr.ExactResult)
+	assert.Equal(t, int64(42), *expr.Seed)
 	assert.Len(t, expr.Tasks, 1)
 	assert.Equal(t, expectedTasks[0].ID, expr.Tasks[0].ID)
 
@@ -318,8 +450,8 @@ func TestReadExpressionByID_TasksError(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "error", "user_id"}).
-		AddRow(int64(1), "completed", 4, "2+2", "", int64(1))
+	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "canonical_string", "error", "user_id", "numeric", "exact_result", "imag_result", "lower_result", "upper_result", "unit", "seed"}).
+		AddRow(int64(1), "completed", 4, "2+2", "2 + 2", "", int64(1), models.NumericFloat, nil, nil, nil, nil, "", nil)
 
 	sqlMock.ExpectQuery(`SELECT.*FROM expressions WHERE id = \?`).
 		WithArgs(int64(1)).
@@ -371,11 +503,11 @@ func TestReadExpressionsByUserID_Success(t *testing.T) {
 		},
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "error", "user_id"}).
+	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "canonical_string", "error", "user_id", "numeric", "exact_result", "imag_result", "lower_result", "upper_result", "unit", "seed"}).
 		AddRow(expectedExpressions[0].ID, expectedExpressions[0].Status, expectedExpressions[0].Result,
-			expectedExpressions[0].ExpressionString, "", expectedExpressions[0].UserID).
+			expectedExpressions[0].ExpressionString, "2 + 2", "", expectedExpressions[0].UserID, models.NumericFloat, nil, nil, nil, nil, "", nil).
 		AddRow(expectedExpressions[1].ID, expectedExpressions[1].Status, nil,
-			expectedExpressions[1].ExpressionString, "", expectedExpressions[1].UserID)
+			expectedExpressions[1].ExpressionString, "3 * 3", "", expectedExpressions[1].UserID, models.NumericFloat, nil, nil, nil, nil, "", nil)
 
 	sqlMock.ExpectQuery(`SELECT.*FROM expressions WHERE user_id = \?`).
 		WithArgs(userID).
@@ -418,7 +550,7 @@ func TestReadExpressionsByUserID_NotFound(t *testing.T) {
 
 	userID := int64(1)
 
-	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "error", "user_id"})
+	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "canonical_string", "error", "user_id", "numeric", "exact_result", "imag_result", "lower_result", "upper_result", "unit", "seed"})
 	sqlMock.ExpectQuery(`SELECT.*FROM expressions WHERE user_id = \?`).
 		WithArgs(userID).
 		WillReturnRows(rows)
@@ -514,8 +646,8 @@ func TestReadExpressionsByUserID_TasksError(t *testing.T) {
 	userID := int64(1)
 	exprID := int64(1)
 
-	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "error", "user_id"}).
-		AddRow(exprID, "completed", 4, "2+2", "", userID)
+	rows := sqlmock.NewRows([]string{"id", "status", "result", "expression_string", "canonical_string", "error", "user_id", "numeric", "exact_result", "imag_result", "lower_result", "upper_result", "unit", "seed"}).
+		AddRow(exprID, "completed", 4, "2+2", "2 + 2", "", userID, models.NumericFloat, nil, nil, nil, nil, "", nil)
 
 	sqlMock.ExpectQuery(`SELECT.*FROM expressions WHERE user_id = \?`).
 		WithArgs(userID).
@@ -934,3 +1066,206 @@ func TestUpdateExpressionResult_WithZeroValue(t *testing.T) {
 	assert.Equal(t, http.StatusOK, status)
 	assert.NoError(t, sqlMock.ExpectationsWereMet())
 }
+
+func TestUpdateExpressionExactResult_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := expressions_repository.NewExpressionsRepository(db, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	expressionID := int64(1)
+	exact := "3/10"
+
+	sqlMock.ExpectExec(`UPDATE expressions SET exact_result = \? WHERE id = \?`).
+		WithArgs(exact, expressionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err, status := repo.UpdateExpressionExactResult(context.Background(), tx, expressionID, exact)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateExpressionImagResult_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := expressions_repository.NewExpressionsRepository(db, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	expressionID := int64(1)
+
+	sqlMock.ExpectExec(`UPDATE expressions SET imag_result = \? WHERE id = \?`).
+		WithArgs(float64(-0.5), expressionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err, status := repo.UpdateExpressionImagResult(context.Background(), tx, expressionID, -0.5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateExpressionInterval_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := expressions_repository.NewExpressionsRepository(db, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	expressionID := int64(1)
+
+	sqlMock.ExpectExec(`UPDATE expressions SET lower_result = \?, upper_result = \? WHERE id = \?`).
+		WithArgs(float64(5.5), float64(6.5), expressionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err, status := repo.UpdateExpressionInterval(context.Background(), tx, expressionID, 5.5, 6.5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateExpressionExactResult_DBError(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := expressions_repository.NewExpressionsRepository(db, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectExec(`UPDATE expressions SET exact_result = \? WHERE id = \?`).
+		WithArgs("3/10", int64(1)).
+		WillReturnError(fmt.Errorf("database error"))
+
+	err, status := repo.UpdateExpressionExactResult(context.Background(), tx, 1, "3/10")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "не удалось обновить точный результат выражения")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestReadExpressionVariables_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := expressions_repository.NewExpressionsRepository(db, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"name", "task_id", "value", "estimate"}).
+		AddRow("r", 0, 5.0, false).
+		AddRow("area", 7, nil, false).
+		AddRow("integrate#1.error", 8, 1e-6, true)
+	sqlMock.ExpectQuery(`SELECT (.+) FROM expression_vars v LEFT JOIN tasks t`).
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	variables, err, status := repo.ReadExpressionVariables(context.Background(), tx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	if assert.Len(t, variables, 3) {
+		assert.Equal(t, "r", variables[0].Name)
+		assert.Equal(t, 5.0, *variables[0].Value)
+		assert.Equal(t, int64(7), variables[1].TaskID)
+		assert.Nil(t, variables[1].Value)
+		assert.False(t, variables[1].Estimate)
+		assert.True(t, variables[2].Estimate)
+	}
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestReadExpressionVariables_DBError(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := expressions_repository.NewExpressionsRepository(db, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectQuery(`SELECT (.+) FROM expression_vars`).
+		WithArgs(int64(1)).
+		WillReturnError(errors.New("database error"))
+
+	variables, err, status := repo.ReadExpressionVariables(context.Background(), tx, 1)
+
+	assert.Nil(t, variables)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateExpressionVariables_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := expressions_repository.NewExpressionsRepository(db, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectExec(`UPDATE expression_vars SET value = \(SELECT result FROM tasks`).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err, status := repo.UpdateExpressionVariables(context.Background(), tx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
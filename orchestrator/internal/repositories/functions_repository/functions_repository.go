@@ -0,0 +1,160 @@
+package functions_repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/mattn/go-sqlite3"
+	"net/http"
+	"strings"
+)
+
+// paramsSeparator разделяет имена параметров функции в столбце params.
+const paramsSeparator = ","
+
+// FunctionsRepository предоставляет методы для работы с функциями пользователей в базе данных.
+type FunctionsRepository struct {
+	db *sql.DB // Подключение к базе данных
+}
+
+// NewFunctionsRepository создает новый экземпляр FunctionsRepository.
+//
+// Args:
+//
+//	db: *sql.DB - Подключение к базе данных.
+//
+// Returns:
+//
+//	*FunctionsRepository - Новый экземпляр репозитория функций.
+func NewFunctionsRepository(db *sql.DB) *FunctionsRepository {
+	return &FunctionsRepository{db: db}
+}
+
+// CreateFunction сохраняет функцию пользователя.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	fn: *models.Function - Функция для сохранения.
+//
+// Returns:
+//
+//	int64 - ID созданной функции.
+//	error - Ошибка выполнения операции.
+//	int - HTTP статус код:
+//	    - 201 Created при успешном создании
+//	    - 409 Conflict если у пользователя уже есть функция с таким именем
+//	    - 500 Internal Server Error при ошибках
+func (r *FunctionsRepository) CreateFunction(ctx context.Context, tx *sql.Tx, fn *models.Function) (int64, error, int) {
+	var id int64
+	query := `
+	INSERT INTO functions
+	    (user_id, name, params, body)
+	VALUES
+	    (?, ?, ?, ?)
+	RETURNING
+		id`
+
+	err := tx.QueryRowContext(ctx, query, fn.UserID, fn.Name, strings.Join(fn.Params, paramsSeparator), fn.Body).Scan(&id)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) {
+			if errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintUnique) {
+				return 0, fmt.Errorf("функция %s уже существует", fn.Name), http.StatusConflict
+			}
+		}
+		return 0, fmt.Errorf("не удалось создать функцию: %w", err), http.StatusInternalServerError
+	}
+
+	return id, nil, http.StatusCreated
+}
+
+// ReadFunctionsByUserID получает все функции пользователя.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	userID: int64 - ID пользователя.
+//
+// Returns:
+//
+//	[]*models.Function - Функции пользователя в порядке имен. Пустой список, если функций нет.
+//	error - Ошибка выполнения операции.
+//	int - HTTP статус код:
+//	    - 200 OK при успешном получении
+//	    - 500 Internal Server Error при ошибках
+func (r *FunctionsRepository) ReadFunctionsByUserID(ctx context.Context, tx *sql.Tx, userID int64) ([]*models.Function, error, int) {
+	functions := []*models.Function{}
+	query := `
+		SELECT
+		    id, user_id, name, params, body
+		FROM
+		    functions
+		WHERE
+		    user_id = ?
+		ORDER BY
+		    name
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить функции: %w", err), http.StatusInternalServerError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		fn := &models.Function{}
+		var params string
+		if err := rows.Scan(&fn.ID, &fn.UserID, &fn.Name, &params, &fn.Body); err != nil {
+			return nil, fmt.Errorf("не удалось прочитать функцию: %w", err), http.StatusInternalServerError
+		}
+		if params != "" {
+			fn.Params = strings.Split(params, paramsSeparator)
+		}
+		functions = append(functions, fn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке строк: %w", err), http.StatusInternalServerError
+	}
+
+	return functions, nil, http.StatusOK
+}
+
+// DeleteFunction удаляет функцию пользователя по имени.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	userID: int64 - ID пользователя.
+//	name: string - Имя функции.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции.
+//	int - HTTP статус код:
+//	    - 200 OK при успешном удалении
+//	    - 404 Not Found если у пользователя нет функции с таким именем
+//	    - 500 Internal Server Error при ошибках
+func (r *FunctionsRepository) DeleteFunction(ctx context.Context, userID int64, name string) (error, int) {
+	query := `DELETE FROM functions WHERE user_id = ? AND name = ?`
+
+	result, err := r.db.ExecContext(ctx, query, userID, name)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить функцию: %w", err), http.StatusInternalServerError
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("не удалось удалить функцию: %w", err), http.StatusInternalServerError
+	}
+	if affected == 0 {
+		return fmt.Errorf("функция %s не найдена", name), http.StatusNotFound
+	}
+
+	return nil, http.StatusOK
+}
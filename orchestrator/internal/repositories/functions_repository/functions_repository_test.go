@@ -0,0 +1,216 @@
+package functions_repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/OinkiePie/calc_3/orchestrator/internal/repositories/functions_repository"
+	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestCreateFunction_CorrectFunction_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := functions_repository.NewFunctionsRepository(db)
+	fn := &models.Function{UserID: 1, Name: "f", Params: []string{"x", "y"}, Body: "x^2 + y^2"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO functions`).
+		WithArgs(int64(1), "f", "x,y", "x^2 + y^2").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	tx, _ := db.Begin()
+
+	id, err, code := repo.CreateFunction(context.Background(), tx, fn)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, int64(3), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateFunction_DuplicateName_Conflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := functions_repository.NewFunctionsRepository(db)
+	fn := &models.Function{UserID: 1, Name: "f", Params: []string{"x"}, Body: "x"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO functions`).
+		WithArgs(int64(1), "f", "x", "x").
+		WillReturnError(sqlite3.Error{ExtendedCode: sqlite3.ErrConstraintUnique})
+	tx, _ := db.Begin()
+
+	_, err, code := repo.CreateFunction(context.Background(), tx, fn)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "уже существует")
+	assert.Equal(t, http.StatusConflict, code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateFunction_DatabaseError_InternalError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := functions_repository.NewFunctionsRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO functions`).WillReturnError(errors.New("error"))
+	tx, _ := db.Begin()
+
+	_, err, code := repo.CreateFunction(context.Background(), tx, &models.Function{Name: "f"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "не удалось создать функцию")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadFunctionsByUserID_ExistingFunctions_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := functions_repository.NewFunctionsRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "name", "params", "body"}).
+		AddRow(2, 1, "answer", "", "42").
+		AddRow(1, 1, "f", "x,y", "x^2 + y^2")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT (.+) FROM functions WHERE user_id = \?`).
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+	tx, _ := db.Begin()
+
+	functions, err, code := repo.ReadFunctionsByUserID(context.Background(), tx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, functions, 2) {
+		assert.Nil(t, functions[0].Params)
+		assert.Equal(t, []string{"x", "y"}, functions[1].Params)
+		assert.Equal(t, "x^2 + y^2", functions[1].Body)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadFunctionsByUserID_NoFunctions_EmptyList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := functions_repository.NewFunctionsRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT (.+) FROM functions`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "params", "body"}))
+	tx, _ := db.Begin()
+
+	functions, err, code := repo.ReadFunctionsByUserID(context.Background(), tx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, functions)
+	assert.Empty(t, functions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadFunctionsByUserID_QueryError_InternalError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := functions_repository.NewFunctionsRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT (.+) FROM functions`).WillReturnError(sql.ErrConnDone)
+	tx, _ := db.Begin()
+
+	_, err, code := repo.ReadFunctionsByUserID(context.Background(), tx, 1)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteFunction_ExistingFunction_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := functions_repository.NewFunctionsRepository(db)
+
+	mock.ExpectExec(`DELETE FROM functions WHERE user_id = \? AND name = \?`).
+		WithArgs(int64(1), "f").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err, code := repo.DeleteFunction(context.Background(), 1, "f")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteFunction_UnknownFunction_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := functions_repository.NewFunctionsRepository(db)
+
+	mock.ExpectExec(`DELETE FROM functions`).
+		WithArgs(int64(1), "g").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err, code := repo.DeleteFunction(context.Background(), 1, "g")
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteFunction_DatabaseError_InternalError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := functions_repository.NewFunctionsRepository(db)
+
+	mock.ExpectExec(`DELETE FROM functions`).WillReturnError(errors.New("error"))
+
+	err, code := repo.DeleteFunction(context.Background(), 1, "f")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "не удалось удалить функцию")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
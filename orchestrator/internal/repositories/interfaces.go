@@ -234,6 +234,94 @@ type ExpressionsRepositoryInterface interface {
 	//	    - 200 OK при успешном обновлении
 	//	    - 500 Internal Server Error при ошибках
 	UpdateExpressionResult(ctx context.Context, tx *sql.Tx, id int64, result float64) (error, int)
+
+	// UpdateExpressionExactResult обновляет точный результат вычисления выражения.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - ID выражения.
+	//	exact: string - Результат вычисления: дробь "num/den" или десятичная запись.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateExpressionExactResult(ctx context.Context, tx *sql.Tx, id int64, exact string) (error, int)
+
+	// UpdateExpressionImagResult обновляет мнимую часть результата выражения в режиме complex.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - ID выражения.
+	//	imag: float64 - Мнимая часть результата.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateExpressionImagResult(ctx context.Context, tx *sql.Tx, id int64, imag float64) (error, int)
+
+	// UpdateExpressionInterval обновляет границы интервала результата выражения в режиме interval.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - ID выражения.
+	//	lower: float64 - Нижняя граница результата.
+	//	upper: float64 - Верхняя граница результата.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateExpressionInterval(ctx context.Context, tx *sql.Tx, id int64, lower, upper float64) (error, int)
+
+	// ReadExpressionVariables получает именованные промежуточные значения сценария.
+	// Для ещё не зафиксированных значений используется текущий результат задачи.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - ID выражения.
+	//
+	// Returns:
+	//
+	//	[]*models.ExpressionVariable - Список переменных в порядке присваивания.
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном получении
+	//	    - 500 Internal Server Error при ошибках
+	ReadExpressionVariables(ctx context.Context, tx *sql.Tx, id int64) ([]*models.ExpressionVariable, error, int)
+
+	// UpdateExpressionVariables фиксирует значения именованных переменных из результатов задач выражения.
+	// Обновляются переменные всех выражений, значения которых вычисляют задачи этого выражения
+	// (в том числе переменные выражений, ссылающихся на него). Вызывается перед удалением задач выражения.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - ID выражения.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateExpressionVariables(ctx context.Context, tx *sql.Tx, id int64) (error, int)
 }
 
 type TasksRepositoryInterface interface {
@@ -307,6 +395,25 @@ type TasksRepositoryInterface interface {
 	//	    - 500 Internal Server Error при ошибках
 	ReadUncompletedTasks(ctx context.Context, tx *sql.Tx) ([]*models.Task, error, int)
 
+	// ReadDependentTasks получает невыполненные задачи других выражений, которые зависят от задач указанного выражения.
+	// Такие зависимости возникают при ссылке на результат ещё не вычисленного выражения ($42).
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	expressionID: int64 - ID выражения, от задач которого зависят искомые задачи.
+	//
+	// Returns:
+	//
+	//	[]*models.Task - Список зависимых задач.
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном получении
+	//	    - 404 Not Found если задачи не найдены
+	//	    - 500 Internal Server Error при ошибках
+	ReadDependentTasks(ctx context.Context, tx *sql.Tx, expressionID int64) ([]*models.Task, error, int)
+
 	// UpdateTaskDependencies обновляет зависимости задачи.
 	//
 	// Args:
@@ -341,6 +448,60 @@ type TasksRepositoryInterface interface {
 	//	    - 500 Internal Server Error при ошибках
 	UpdateTaskArguments(ctx context.Context, tx *sql.Tx, id int64, index int, value *float64) (error, int)
 
+	// UpdateTaskExactArgument обновляет точное значение одного из аргументов задачи.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - ID задачи
+	//	index: int - Индекс аргумента (0 или 1).
+	//	value: string - Новое значение аргумента: дробь "num/den" или десятичная запись.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateTaskExactArgument(ctx context.Context, tx *sql.Tx, id int64, index int, value string) (error, int)
+
+	// UpdateTaskImagArgument обновляет мнимую часть одного из аргументов комплексной задачи.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - ID задачи
+	//	index: int - Индекс аргумента (0 или 1).
+	//	value: float64 - Новая мнимая часть аргумента.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateTaskImagArgument(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) (error, int)
+
+	// UpdateTaskUpperArgument обновляет верхнюю границу одного из аргументов интервальной задачи.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - ID задачи
+	//	index: int - Индекс аргумента (0 или 1).
+	//	value: float64 - Новая верхняя граница аргумента.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateTaskUpperArgument(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) (error, int)
+
 	// UpdateTaskStatus обновляет статус задачи.
 	//
 	// Args:
@@ -358,6 +519,56 @@ type TasksRepositoryInterface interface {
 	//	    - 500 Internal Server Error при ошибках
 	UpdateTaskStatus(ctx context.Context, tx *sql.Tx, id int64, status string) (error, int)
 
+	// CreateTaskGuards сохраняет условия выполнения задачи ветви if.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	task: *models.Task - Задача с ID в базе данных и условиями с ID задач-условий.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 201 StatusCreated при успешном создании
+	//	    - 500 Internal Server Error при ошибках
+	CreateTaskGuards(ctx context.Context, tx *sql.Tx, task *models.Task) (error, int)
+
+	// SkipGuardedTasks устанавливает статус 'skipped' ожидающим задачам, условие которых не выполнилось.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	condition: int64 - ID вычисленной задачи-условия.
+	//	value: bool - Истинность ее результата.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	SkipGuardedTasks(ctx context.Context, tx *sql.Tx, condition int64, value bool) (error, int)
+
+	// ReadIterationProgress получает ход вычисления итераций integrate и solve выражения.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	expressionID: int64 - ID выражения.
+	//
+	// Returns:
+	//
+	//	*models.ExpressionProgress - Ход вычисления итераций. nil, если выражение не содержит итераций.
+	//	error - Ошибка выполнения операции
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном получении
+	//	    - 500 Internal Server Error при ошибках
+	ReadIterationProgress(ctx context.Context, tx *sql.Tx, expressionID int64) (*models.ExpressionProgress, error, int)
+
 	// UpdateTaskExpressionID обновляет ID выражения для задачи.
 	//
 	// Args:
@@ -392,6 +603,57 @@ type TasksRepositoryInterface interface {
 	//	    - 500 Internal Server Error при ошибках
 	UpdateTaskResult(ctx context.Context, tx *sql.Tx, result float64, id int64) (error, int)
 
+	// UpdateTaskExactResult обновляет точный результат выполнения задачи.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	exact: string - Новый результат: дробь "num/den" или десятичная запись.
+	//	id: int64 - ID задачи.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateTaskExactResult(ctx context.Context, tx *sql.Tx, exact string, id int64) (error, int)
+
+	// UpdateTaskImagResult обновляет мнимую часть результата выполнения задачи.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	imag: float64 - Новая мнимая часть результата.
+	//	id: int64 - ID задачи.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateTaskImagResult(ctx context.Context, tx *sql.Tx, imag float64, id int64) (error, int)
+
+	// UpdateTaskUpperResult обновляет верхнюю границу результата интервальной задачи.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	upper: float64 - Новая верхняя граница результата.
+	//	id: int64 - ID задачи.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном обновлении
+	//	    - 500 Internal Server Error при ошибках
+	UpdateTaskUpperResult(ctx context.Context, tx *sql.Tx, upper float64, id int64) (error, int)
+
 	// DeleteTasks удаляет все задачи, связанные с указанным выражением.
 	//
 	// Args:
@@ -480,6 +742,48 @@ type TasksArgsRepositoryInterface interface {
 	//	error - Ошибка выполнения операции.
 	ReadTaskArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*float64, error)
 
+	// ReadTaskExactArgs получает точные значения аргументов задачи из базы данных.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - Идентификатор задачи.
+	//
+	// Returns:
+	//
+	//	[]*string - Срез из двух элементов с дробями "num/den" или десятичными записями: [первый аргумент, второй аргумент].
+	//	error - Ошибка выполнения операции.
+	ReadTaskExactArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*string, error)
+
+	// ReadTaskImagArgs получает мнимые части аргументов комплексной задачи из базы данных.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - Идентификатор задачи.
+	//
+	// Returns:
+	//
+	//	[]*float64 - Срез из двух элементов: [мнимая часть первого аргумента, мнимая часть второго аргумента].
+	//	error - Ошибка выполнения операции.
+	ReadTaskImagArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*float64, error)
+
+	// ReadTaskUpperArgs получает верхние границы аргументов интервальной задачи из базы данных.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - Идентификатор задачи.
+	//
+	// Returns:
+	//
+	//	[]*float64 - Срез из двух элементов: [верхняя граница первого аргумента, верхняя граница второго аргумента].
+	//	error - Ошибка выполнения операции.
+	ReadTaskUpperArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*float64, error)
+
 	// UpdateTaskArgs обновляет один из аргументов задачи в базе данных.
 	//
 	// Args:
@@ -494,4 +798,103 @@ type TasksArgsRepositoryInterface interface {
 	//
 	//	error - Ошибка выполнения операции.
 	UpdateTaskArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value *float64) error
+
+	// UpdateTaskExactArgs обновляет точное значение одного из аргументов задачи в базе данных.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - Идентификатор задачи.
+	//	index: int - Индекс аргумента (0 - первый, 1 - второй).
+	//	value: string - Новое значение аргумента: дробь "num/den" или десятичная запись.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции.
+	UpdateTaskExactArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value string) error
+
+	// UpdateTaskImagArgs обновляет мнимую часть одного из аргументов задачи в базе данных.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - Идентификатор задачи.
+	//	index: int - Индекс аргумента (0 - первый, 1 - второй).
+	//	value: float64 - Новая мнимая часть аргумента.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции.
+	UpdateTaskImagArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) error
+
+	// UpdateTaskUpperArgs обновляет верхнюю границу одного из аргументов задачи в базе данных.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	id: int64 - Идентификатор задачи.
+	//	index: int - Индекс аргумента (0 - первый, 1 - второй).
+	//	value: float64 - Новая верхняя граница аргумента.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции.
+	UpdateTaskUpperArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) error
+}
+
+type FunctionsRepositoryInterface interface {
+	// CreateFunction сохраняет функцию пользователя.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	fn: *models.Function - Функция для сохранения.
+	//
+	// Returns:
+	//
+	//	int64 - ID созданной функции.
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 201 Created при успешном создании
+	//	    - 409 Conflict если у пользователя уже есть функция с таким именем
+	//	    - 500 Internal Server Error при ошибках
+	CreateFunction(ctx context.Context, tx *sql.Tx, fn *models.Function) (int64, error, int)
+
+	// ReadFunctionsByUserID получает все функции пользователя.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	tx: *sql.Tx - Транзакция базы данных.
+	//	userID: int64 - ID пользователя.
+	//
+	// Returns:
+	//
+	//	[]*models.Function - Функции пользователя в порядке имен. Пустой список, если функций нет.
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном получении
+	//	    - 500 Internal Server Error при ошибках
+	ReadFunctionsByUserID(ctx context.Context, tx *sql.Tx, userID int64) ([]*models.Function, error, int)
+
+	// DeleteFunction удаляет функцию пользователя по имени.
+	//
+	// Args:
+	//
+	//	ctx: context.Context - Контекст выполнения запроса.
+	//	userID: int64 - ID пользователя.
+	//	name: string - Имя функции.
+	//
+	// Returns:
+	//
+	//	error - Ошибка выполнения операции.
+	//	int - HTTP статус код:
+	//	    - 200 OK при успешном удалении
+	//	    - 404 Not Found если у пользователя нет функции с таким именем
+	//	    - 500 Internal Server Error при ошибках
+	DeleteFunction(ctx context.Context, userID int64, name string) (error, int)
 }
@@ -11,6 +11,10 @@ func Float64Ptr(i float64) *float64 {
 	return &i
 }
 
+func StringPtr(s string) *string {
+	return &s
+}
+
 type MockUserRepository struct {
 	mock.Mock
 }
@@ -88,6 +92,31 @@ func (m *MockExpressionsRepository) UpdateExpressionResult(ctx context.Context,
 	return args.Error(0), args.Int(1)
 }
 
+func (m *MockExpressionsRepository) UpdateExpressionExactResult(ctx context.Context, tx *sql.Tx, id int64, exact string) (error, int) {
+	args := m.Called(ctx, tx, id, exact)
+	return args.Error(0), args.Int(1)
+}
+
+func (m *MockExpressionsRepository) UpdateExpressionImagResult(ctx context.Context, tx *sql.Tx, id int64, imag float64) (error, int) {
+	args := m.Called(ctx, tx, id, imag)
+	return args.Error(0), args.Int(1)
+}
+
+func (m *MockExpressionsRepository) UpdateExpressionInterval(ctx context.Context, tx *sql.Tx, id int64, lower, upper float64) (error, int) {
+	args := m.Called(ctx, tx, id, lower, upper)
+	return args.Error(0), args.Int(1)
+}
+
+func (m *MockExpressionsRepository) ReadExpressionVariables(ctx context.Context, tx *sql.Tx, id int64) ([]*models.ExpressionVariable, error, int) {
+	args := m.Called(ctx, tx, id)
+	return args.Get(0).([]*models.ExpressionVariable), args.Error(1), args.Int(2)
+}
+
+func (m *MockExpressionsRepository) UpdateExpressionVariables(ctx context.Context, tx *sql.Tx, id int64) (error, int) {
+	args := m.Called(ctx, tx, id)
+	return args.Error(0), args.Int(1)
+}
+
 type MockTasksRepository struct {
 	mock.Mock
 }
@@ -112,6 +141,11 @@ func (m *MockTasksRepository) ReadUncompletedTasks(ctx context.Context, tx *sql.
 	return args.Get(0).([]*models.Task), args.Error(1), args.Int(2)
 }
 
+func (m *MockTasksRepository) ReadDependentTasks(ctx context.Context, tx *sql.Tx, expressionID int64) ([]*models.Task, error, int) {
+	args := m.Called(ctx, tx, expressionID)
+	return args.Get(0).([]*models.Task), args.Error(1), args.Int(2)
+}
+
 func (m *MockTasksRepository) UpdateTaskDependencies(ctx context.Context, tx *sql.Tx, task *models.Task) (error, int) {
 	args := m.Called(ctx, tx, task)
 	return args.Error(0), args.Int(1)
@@ -122,11 +156,41 @@ func (m *MockTasksRepository) UpdateTaskArguments(ctx context.Context, tx *sql.T
 	return args.Error(0), args.Int(1)
 }
 
+func (m *MockTasksRepository) UpdateTaskExactArgument(ctx context.Context, tx *sql.Tx, id int64, index int, value string) (error, int) {
+	args := m.Called(ctx, tx, id, index, value)
+	return args.Error(0), args.Int(1)
+}
+
+func (m *MockTasksRepository) UpdateTaskImagArgument(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) (error, int) {
+	args := m.Called(ctx, tx, id, index, value)
+	return args.Error(0), args.Int(1)
+}
+
+func (m *MockTasksRepository) UpdateTaskUpperArgument(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) (error, int) {
+	args := m.Called(ctx, tx, id, index, value)
+	return args.Error(0), args.Int(1)
+}
+
 func (m *MockTasksRepository) UpdateTaskStatus(ctx context.Context, tx *sql.Tx, id int64, status string) (error, int) {
 	args := m.Called(ctx, tx, id, status)
 	return args.Error(0), args.Int(1)
 }
 
+func (m *MockTasksRepository) CreateTaskGuards(ctx context.Context, tx *sql.Tx, task *models.Task) (error, int) {
+	args := m.Called(ctx, tx, task)
+	return args.Error(0), args.Int(1)
+}
+
+func (m *MockTasksRepository) ReadIterationProgress(ctx context.Context, tx *sql.Tx, expressionID int64) (*models.ExpressionProgress, error, int) {
+	args := m.Called(ctx, tx, expressionID)
+	return args.Get(0).(*models.ExpressionProgress), args.Error(1), args.Int(2)
+}
+
+func (m *MockTasksRepository) SkipGuardedTasks(ctx context.Context, tx *sql.Tx, condition int64, value bool) (error, int) {
+	args := m.Called(ctx, tx, condition, value)
+	return args.Error(0), args.Int(1)
+}
+
 func (m *MockTasksRepository) UpdateTaskExpressionID(ctx context.Context, tx *sql.Tx, id, exprId int64) (error, int) {
 	args := m.Called(ctx, tx, id, exprId)
 	return args.Error(0), args.Int(1)
@@ -137,6 +201,21 @@ func (m *MockTasksRepository) UpdateTaskResult(ctx context.Context, tx *sql.Tx,
 	return args.Error(0), args.Int(1)
 }
 
+func (m *MockTasksRepository) UpdateTaskExactResult(ctx context.Context, tx *sql.Tx, exact string, id int64) (error, int) {
+	args := m.Called(ctx, tx, exact, id)
+	return args.Error(0), args.Int(1)
+}
+
+func (m *MockTasksRepository) UpdateTaskImagResult(ctx context.Context, tx *sql.Tx, imag float64, id int64) (error, int) {
+	args := m.Called(ctx, tx, imag, id)
+	return args.Error(0), args.Int(1)
+}
+
+func (m *MockTasksRepository) UpdateTaskUpperResult(ctx context.Context, tx *sql.Tx, upper float64, id int64) (error, int) {
+	args := m.Called(ctx, tx, upper, id)
+	return args.Error(0), args.Int(1)
+}
+
 func (m *MockTasksRepository) DeleteTasks(ctx context.Context, tx *sql.Tx, id int64) (error, int) {
 	args := m.Called(ctx, tx, id)
 	return args.Error(0), args.Int(1)
@@ -156,11 +235,41 @@ func (m *MockArgsRepository) ReadTaskArgs(ctx context.Context, tx *sql.Tx, id in
 	return args.Get(0).([]*float64), args.Error(1)
 }
 
+func (m *MockArgsRepository) ReadTaskExactArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*string, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Get(0).([]*string), args.Error(1)
+}
+
+func (m *MockArgsRepository) ReadTaskImagArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*float64, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Get(0).([]*float64), args.Error(1)
+}
+
+func (m *MockArgsRepository) ReadTaskUpperArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*float64, error) {
+	args := m.Called(ctx, tx, id)
+	return args.Get(0).([]*float64), args.Error(1)
+}
+
 func (m *MockArgsRepository) UpdateTaskArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value *float64) error {
 	args := m.Called(ctx, tx, id, index, value)
 	return args.Error(0)
 }
 
+func (m *MockArgsRepository) UpdateTaskExactArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value string) error {
+	args := m.Called(ctx, tx, id, index, value)
+	return args.Error(0)
+}
+
+func (m *MockArgsRepository) UpdateTaskImagArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) error {
+	args := m.Called(ctx, tx, id, index, value)
+	return args.Error(0)
+}
+
+func (m *MockArgsRepository) UpdateTaskUpperArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) error {
+	args := m.Called(ctx, tx, id, index, value)
+	return args.Error(0)
+}
+
 type MockDepsRepository struct {
 	mock.Mock
 }
@@ -179,3 +288,22 @@ func (m *MockDepsRepository) UpdateTaskDeps(ctx context.Context, tx *sql.Tx, id
 	args := m.Called(ctx, tx, id, deps)
 	return args.Error(0)
 }
+
+type MockFunctionsRepository struct {
+	mock.Mock
+}
+
+func (m *MockFunctionsRepository) CreateFunction(ctx context.Context, tx *sql.Tx, fn *models.Function) (int64, error, int) {
+	args := m.Called(ctx, tx, fn)
+	return args.Get(0).(int64), args.Error(1), args.Int(2)
+}
+
+func (m *MockFunctionsRepository) ReadFunctionsByUserID(ctx context.Context, tx *sql.Tx, userID int64) ([]*models.Function, error, int) {
+	args := m.Called(ctx, tx, userID)
+	return args.Get(0).([]*models.Function), args.Error(1), args.Int(2)
+}
+
+func (m *MockFunctionsRepository) DeleteFunction(ctx context.Context, userID int64, name string) (error, int) {
+	args := m.Called(ctx, userID, name)
+	return args.Error(0), args.Int(1)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/OinkiePie/calc_3/pkg/models"
 	_ "github.com/mattn/go-sqlite3"
+	"strings"
 )
 
 // TaskArgsRepository предоставляет методы для работы с аргументами задач в базе данных.
@@ -26,7 +27,9 @@ func NewTaskArgsRepository(db *sql.DB) *TaskArgsRepository {
 	return &TaskArgsRepository{db: db}
 }
 
-// CreateTaskArgs создает запись аргументов задачи в базе данных.
+// CreateTaskArgs создает записи аргументов задачи в базе данных: по строке на каждую позицию аргумента.
+// Для задач точного и десятичного режимов сохраняются и точные значения аргументов,
+// для комплексных задач - мнимые части аргументов, для интервальных - верхние границы аргументов.
 //
 // Args:
 //
@@ -38,13 +41,22 @@ func NewTaskArgsRepository(db *sql.DB) *TaskArgsRepository {
 //
 //	error - Ошибка выполнения операции.
 func (r *TaskArgsRepository) CreateTaskArgs(ctx context.Context, tx *sql.Tx, task *models.Task) error {
+	if len(task.Args) == 0 {
+		return nil // У задачи нет аргументов (например, rand())
+	}
+	rows := make([]string, len(task.Args))
+	values := make([]any, 0, 6*len(task.Args))
+	for i, arg := range task.Args {
+		rows[i] = "(?, ?, ?, ?, ?, ?)"
+		values = append(values, task.ID, i, arg, argAt(task.ExactArgs, i), argAt(task.ImagArgs, i), argAt(task.UpperArgs, i))
+	}
 	query := `
 	INSERT INTO task_args
-	    (task_id, first, second)
+	    (task_id, position, value, exact, imag, upper)
 	VALUES
-	    (?, ?, ?)`
+	    ` + strings.Join(rows, ", ")
 
-	_, err := tx.ExecContext(ctx, query, task.ID, task.Args[0], task.Args[1])
+	_, err := tx.ExecContext(ctx, query, values...)
 	if err != nil {
 		return fmt.Errorf("не удалось установить аргументы задачи: %w", err)
 	}
@@ -61,19 +73,11 @@ func (r *TaskArgsRepository) CreateTaskArgs(ctx context.Context, tx *sql.Tx, tas
 //
 // Returns:
 //
-//	[]*float64 - Срез из двух элементов: [первый аргумент, второй аргумент].
+//	[]*float64 - Аргументы в порядке позиций (nil - аргумент ещё не вычислен).
 //	error - Ошибка выполнения операции.
 func (r *TaskArgsRepository) ReadTaskArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*float64, error) {
-	args := make([]*float64, 2)
-	query := `
-	SELECT
-	    first, second
-	FROM
-	    task_args
-	WHERE
-	    task_id = ?`
-
-	if err := tx.QueryRowContext(ctx, query, id).Scan(&args[0], &args[1]); err != nil {
+	args, err := readArgs[float64](ctx, tx, id, "value")
+	if err != nil {
 		return []*float64{}, fmt.Errorf("не удалось получить аргументы задачи: %w", err)
 	}
 	return args, nil
@@ -86,28 +90,217 @@ func (r *TaskArgsRepository) ReadTaskArgs(ctx context.Context, tx *sql.Tx, id in
 //	ctx: context.Context - Контекст выполнения запроса.
 //	tx: *sql.Tx - Транзакция базы данных.
 //	id: int64 - Идентификатор задачи.
-//	index: int - Индекс аргумента (0 - первый, 1 - второй).
+//	index: int - Позиция аргумента (начиная с 0).
 //	value: *float64 - Новое значение аргумента.
 //
 // Returns:
 //
 //	error - Ошибка выполнения операции.
 func (r *TaskArgsRepository) UpdateTaskArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value *float64) error {
-	position := map[int]string{
-		0: "first",
-		1: "second",
+	if err := updateArg(ctx, tx, id, index, "value", value); err != nil {
+		return fmt.Errorf("не удалось обновить аргументы задачи: %w", err)
 	}
+	return nil
+}
+
+// ReadTaskExactArgs получает точные значения аргументов задачи из базы данных.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - Идентификатор задачи.
+//
+// Returns:
+//
+//	[]*string - Дроби "num/den" или десятичные записи аргументов в порядке позиций.
+//	error - Ошибка выполнения операции.
+func (r *TaskArgsRepository) ReadTaskExactArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*string, error) {
+	args, err := readArgs[string](ctx, tx, id, "exact")
+	if err != nil {
+		return []*string{}, fmt.Errorf("не удалось получить точные аргументы задачи: %w", err)
+	}
+	return args, nil
+}
+
+// UpdateTaskExactArgs обновляет точное значение одного из аргументов задачи в базе данных.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - Идентификатор задачи.
+//	index: int - Позиция аргумента (начиная с 0).
+//	value: string - Новое значение аргумента: дробь "num/den" или десятичная запись.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции.
+func (r *TaskArgsRepository) UpdateTaskExactArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value string) error {
+	if err := updateArg(ctx, tx, id, index, "exact", value); err != nil {
+		return fmt.Errorf("не удалось обновить точные аргументы задачи: %w", err)
+	}
+	return nil
+}
+
+// ReadTaskImagArgs получает мнимые части аргументов комплексной задачи из базы данных.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - Идентификатор задачи.
+//
+// Returns:
+//
+//	[]*float64 - Мнимые части аргументов в порядке позиций.
+//	error - Ошибка выполнения операции.
+func (r *TaskArgsRepository) ReadTaskImagArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*float64, error) {
+	args, err := readArgs[float64](ctx, tx, id, "imag")
+	if err != nil {
+		return []*float64{}, fmt.Errorf("не удалось получить мнимые части аргументов задачи: %w", err)
+	}
+	return args, nil
+}
+
+// UpdateTaskImagArgs обновляет мнимую часть одного из аргументов задачи в базе данных.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - Идентификатор задачи.
+//	index: int - Позиция аргумента (начиная с 0).
+//	value: float64 - Новая мнимая часть аргумента.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции.
+func (r *TaskArgsRepository) UpdateTaskImagArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) error {
+	if err := updateArg(ctx, tx, id, index, "imag", value); err != nil {
+		return fmt.Errorf("не удалось обновить мнимые части аргументов задачи: %w", err)
+	}
+	return nil
+}
+
+// ReadTaskUpperArgs получает верхние границы аргументов интервальной задачи из базы данных.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - Идентификатор задачи.
+//
+// Returns:
+//
+//	[]*float64 - Верхние границы аргументов в порядке позиций.
+//	error - Ошибка выполнения операции.
+func (r *TaskArgsRepository) ReadTaskUpperArgs(ctx context.Context, tx *sql.Tx, id int64) ([]*float64, error) {
+	args, err := readArgs[float64](ctx, tx, id, "upper")
+	if err != nil {
+		return []*float64{}, fmt.Errorf("не удалось получить верхние границы аргументов задачи: %w", err)
+	}
+	return args, nil
+}
+
+// UpdateTaskUpperArgs обновляет верхнюю границу одного из аргументов задачи в базе данных.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - Идентификатор задачи.
+//	index: int - Позиция аргумента (начиная с 0).
+//	value: float64 - Новая верхняя граница аргумента.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции.
+func (r *TaskArgsRepository) UpdateTaskUpperArgs(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) error {
+	if err := updateArg(ctx, tx, id, index, "upper", value); err != nil {
+		return fmt.Errorf("не удалось обновить верхние границы аргументов задачи: %w", err)
+	}
+	return nil
+}
+
+// readArgs получает значения колонки аргументов задачи в порядке позиций.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - Идентификатор задачи.
+//	column: string - Колонка таблицы task_args (value, exact, imag или upper).
+//
+// Returns:
+//
+//	[]*T - Значения аргументов (nil для NULL). Пустой срез, если у задачи нет аргументов.
+//	error - Ошибка выполнения запроса.
+func readArgs[T any](ctx context.Context, tx *sql.Tx, id int64, column string) ([]*T, error) {
+	query := fmt.Sprintf(`
+	SELECT
+	    %s
+	FROM
+	    task_args
+	WHERE
+	    task_id = ?
+	ORDER BY
+	    position`, column)
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var args []*T
+	for rows.Next() {
+		var arg *T
+		if err := rows.Scan(&arg); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if args == nil {
+		args = []*T{} // У задачи нет аргументов (например, rand())
+	}
+	return args, nil
+}
+
+// updateArg обновляет значение колонки одного аргумента задачи.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - Идентификатор задачи.
+//	index: int - Позиция аргумента.
+//	column: string - Колонка таблицы task_args (value, exact, imag или upper).
+//	value: any - Новое значение.
+//
+// Returns:
+//
+//	error - Ошибка выполнения запроса.
+func updateArg(ctx context.Context, tx *sql.Tx, id int64, index int, column string, value any) error {
 	query := fmt.Sprintf(`
 		UPDATE
 		    task_args
 		SET
 		    %s = ?
 		WHERE
-		    task_id = ?`, position[index])
+		    task_id = ? AND position = ?`, column)
 
-	_, err := tx.ExecContext(ctx, query, value, id)
-	if err != nil {
-		return fmt.Errorf("не удалось обновить аргументы задачи: %w", err)
+	_, err := tx.ExecContext(ctx, query, value, id, index)
+	return err
+}
+
+// argAt возвращает элемент среза или nil, если срез короче (аргументы режима не заданы).
+func argAt[T any](args []*T, i int) *T {
+	if i < len(args) {
+		return args[i]
 	}
 	return nil
 }
@@ -28,7 +28,7 @@ func TestCreateTaskArgs_CorrectArgs_Success(t *testing.T) {
 	repo := tasks_repository.NewTaskArgsRepository(db)
 
 	mock.ExpectExec(`INSERT INTO task_args`).
-		WithArgs(int64(1), m.Float64Ptr(2), m.Float64Ptr(3)).
+		WithArgs(int64(1), 0, m.Float64Ptr(2), nil, nil, nil, int64(1), 1, m.Float64Ptr(3), nil, nil, nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err = repo.CreateTaskArgs(context.Background(), tx, &models.Task{ID: int64(1), Args: []*float64{m.Float64Ptr(2), m.Float64Ptr(3)}})
@@ -37,6 +37,27 @@ func TestCreateTaskArgs_CorrectArgs_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestCreateTaskArgs_NoArgs_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	err = repo.CreateTaskArgs(context.Background(), tx, &models.Task{ID: int64(1), Args: []*float64{}})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCreateTaskArgs_CorrectArgs_InternalError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -53,7 +74,7 @@ func TestCreateTaskArgs_CorrectArgs_InternalError(t *testing.T) {
 	repo := tasks_repository.NewTaskArgsRepository(db)
 
 	mock.ExpectExec(`INSERT INTO task_args`).
-		WithArgs(int64(1), m.Float64Ptr(2), m.Float64Ptr(3)).
+		WithArgs(int64(1), 0, m.Float64Ptr(2), nil, nil, nil, int64(1), 1, m.Float64Ptr(3), nil, nil, nil).
 		WillReturnError(errors.New("error"))
 
 	err = repo.CreateTaskArgs(context.Background(), tx, &models.Task{ID: int64(1), Args: []*float64{m.Float64Ptr(2), m.Float64Ptr(3)}})
@@ -103,8 +124,8 @@ func TestReadTaskArgs_CorrectId_Success(t *testing.T) {
 
 	repo := tasks_repository.NewTaskArgsRepository(db)
 
-	rows := sqlmock.NewRows([]string{"first", "second"}).AddRow(m.Float64Ptr(1), m.Float64Ptr(2))
-	mock.ExpectQuery(`SELECT first, second FROM task_args WHERE task_id = ?`).
+	rows := sqlmock.NewRows([]string{"value"}).AddRow(m.Float64Ptr(1)).AddRow(m.Float64Ptr(2))
+	mock.ExpectQuery(`SELECT value FROM task_args WHERE task_id = \? ORDER BY position`).
 		WithArgs(int64(1)).
 		WillReturnRows(rows)
 
@@ -130,7 +151,7 @@ func TestReadTaskArgs_InternalError(t *testing.T) {
 
 	repo := tasks_repository.NewTaskArgsRepository(db)
 
-	mock.ExpectQuery(`SELECT first, second FROM task_args WHERE task_id = ?`).
+	mock.ExpectQuery(`SELECT value FROM task_args WHERE task_id = \? ORDER BY position`).
 		WithArgs(int64(1)).
 		WillReturnError(errors.New("error"))
 
@@ -182,7 +203,7 @@ func TestUpdateTaskArgs_CorrectArgs_Success(t *testing.T) {
 	repo := tasks_repository.NewTaskArgsRepository(db)
 
 	mock.ExpectExec(`UPDATE task_args`).
-		WithArgs(m.Float64Ptr(3), int64(1)).
+		WithArgs(m.Float64Ptr(3), int64(1), 2).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err = repo.UpdateTaskArgs(context.Background(), tx, int64(1), 2, m.Float64Ptr(3))
@@ -207,7 +228,7 @@ func TestUpdateTaskArgs_CorrectArgs_InternalError(t *testing.T) {
 	repo := tasks_repository.NewTaskArgsRepository(db)
 
 	mock.ExpectExec(`UPDATE task_args`).
-		WithArgs(m.Float64Ptr(3), int64(1)).
+		WithArgs(m.Float64Ptr(3), int64(1), 2).
 		WillReturnError(errors.New("error"))
 
 	err = repo.UpdateTaskArgs(context.Background(), tx, int64(1), 2, m.Float64Ptr(3))
@@ -241,3 +262,301 @@ func TestUpdateTaskArgs_CanceledContext_InternalError(t *testing.T) {
 	assert.Contains(t, err.Error(), "не удалось обновить аргументы задачи")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestCreateTaskArgs_ExactArgs_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	mock.ExpectExec(`INSERT INTO task_args`).
+		WithArgs(int64(1), 0, m.Float64Ptr(0.1), m.StringPtr("1/10"), nil, nil, int64(1), 1, nil, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.CreateTaskArgs(context.Background(), tx, &models.Task{
+		ID:        int64(1),
+		Args:      []*float64{m.Float64Ptr(0.1), nil},
+		Exact:     true,
+		ExactArgs: []*string{m.StringPtr("1/10"), nil},
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadTaskExactArgs_CorrectId_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	rows := sqlmock.NewRows([]string{"exact"}).AddRow("1/10").AddRow(nil)
+	mock.ExpectQuery(`SELECT exact FROM task_args WHERE task_id = \? ORDER BY position`).
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	args, err := repo.ReadTaskExactArgs(context.Background(), tx, int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*string{m.StringPtr("1/10"), nil}, args)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadTaskExactArgs_InternalError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	mock.ExpectQuery(`SELECT exact FROM task_args WHERE task_id = \? ORDER BY position`).
+		WithArgs(int64(1)).
+		WillReturnError(errors.New("error"))
+
+	_, err = repo.ReadTaskExactArgs(context.Background(), tx, int64(1))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "не удалось получить точные аргументы задачи")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTaskExactArgs_CorrectArgs_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	mock.ExpectExec(`UPDATE task_args SET exact = \? WHERE task_id = \? AND position = \?`).
+		WithArgs("1/3", int64(1), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.UpdateTaskExactArgs(context.Background(), tx, int64(1), 1, "1/3")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTaskExactArgs_CorrectArgs_InternalError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	mock.ExpectExec(`UPDATE task_args`).
+		WithArgs("1/3", int64(1), 0).
+		WillReturnError(errors.New("error"))
+
+	err = repo.UpdateTaskExactArgs(context.Background(), tx, int64(1), 0, "1/3")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "не удалось обновить точные аргументы задачи")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateTaskArgs_ComplexArgs_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	mock.ExpectExec(`INSERT INTO task_args`).
+		WithArgs(int64(1), 0, m.Float64Ptr(1), nil, m.Float64Ptr(2), nil, int64(1), 1, nil, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.CreateTaskArgs(context.Background(), tx, &models.Task{
+		ID:       int64(1),
+		Args:     []*float64{m.Float64Ptr(1), nil},
+		Complex:  true,
+		ImagArgs: []*float64{m.Float64Ptr(2), nil},
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadTaskImagArgs_CorrectId_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	rows := sqlmock.NewRows([]string{"imag"}).AddRow(float64(2)).AddRow(nil)
+	mock.ExpectQuery(`SELECT imag FROM task_args WHERE task_id = \? ORDER BY position`).
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	args, err := repo.ReadTaskImagArgs(context.Background(), tx, int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*float64{m.Float64Ptr(2), nil}, args)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTaskImagArgs_CorrectArgs_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	mock.ExpectExec(`UPDATE task_args SET imag = \? WHERE task_id = \? AND position = \?`).
+		WithArgs(float64(-1), int64(1), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.UpdateTaskImagArgs(context.Background(), tx, int64(1), 1, -1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateTaskArgs_IntervalArgs_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	mock.ExpectExec(`INSERT INTO task_args`).
+		WithArgs(int64(1), 0, m.Float64Ptr(2.9), nil, nil, m.Float64Ptr(3.1), int64(1), 1, nil, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.CreateTaskArgs(context.Background(), tx, &models.Task{
+		ID:        int64(1),
+		Args:      []*float64{m.Float64Ptr(2.9), nil},
+		Interval:  true,
+		UpperArgs: []*float64{m.Float64Ptr(3.1), nil},
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadTaskUpperArgs_CorrectId_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	rows := sqlmock.NewRows([]string{"upper"}).AddRow(float64(3.1)).AddRow(nil)
+	mock.ExpectQuery(`SELECT upper FROM task_args WHERE task_id = \? ORDER BY position`).
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	args, err := repo.ReadTaskUpperArgs(context.Background(), tx, int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*float64{m.Float64Ptr(3.1), nil}, args)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTaskUpperArgs_CorrectArgs_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	repo := tasks_repository.NewTaskArgsRepository(db)
+
+	mock.ExpectExec(`UPDATE task_args SET upper = \? WHERE task_id = \? AND position = \?`).
+		WithArgs(float64(3.1), int64(1), 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.UpdateTaskUpperArgs(context.Background(), tx, int64(1), 0, 3.1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
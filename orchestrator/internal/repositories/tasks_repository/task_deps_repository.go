@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/OinkiePie/calc_3/pkg/models"
 	_ "github.com/mattn/go-sqlite3"
+	"strings"
 )
 
 // TaskDepsRepository предоставляет методы для работы с зависимостями задач в базе данных.
@@ -26,7 +27,7 @@ func NewTaskDepsRepository(db *sql.DB) *TaskDepsRepository {
 	return &TaskDepsRepository{db: db}
 }
 
-// CreateTaskDeps создает записи зависимостей задачи в базе данных.
+// CreateTaskDeps создает записи зависимостей задачи в базе данных: по строке на каждую позицию аргумента.
 //
 // Args:
 //
@@ -38,13 +39,22 @@ func NewTaskDepsRepository(db *sql.DB) *TaskDepsRepository {
 //
 //	error - Ошибка выполнения операции.
 func (r *TaskDepsRepository) CreateTaskDeps(ctx context.Context, tx *sql.Tx, task *models.Task) error {
+	if len(task.Dependencies) == 0 {
+		return nil // У задачи нет зависимостей (например, rand())
+	}
+	rows := make([]string, len(task.Dependencies))
+	values := make([]any, 0, 3*len(task.Dependencies))
+	for i, dep := range task.Dependencies {
+		rows[i] = "(?, ?, ?)"
+		values = append(values, task.ID, i, dep)
+	}
 	query := `
 	INSERT INTO task_deps
-	    (task_id, first, second)
+	    (task_id, position, dependency)
 	VALUES
-	    (?, ?, ?)`
+	    ` + strings.Join(rows, ", ")
 
-	_, err := tx.ExecContext(ctx, query, task.ID, task.Dependencies[0], task.Dependencies[1])
+	_, err := tx.ExecContext(ctx, query, values...)
 	if err != nil {
 		return fmt.Errorf("не удалось установить зависимости задачи: %w", err)
 	}
@@ -61,21 +71,39 @@ func (r *TaskDepsRepository) CreateTaskDeps(ctx context.Context, tx *sql.Tx, tas
 //
 // Returns:
 //
-//	[]int64 - Список идентификаторов зависимых задач.
+//	[]int64 - ID задач-зависимостей в порядке позиций аргументов (-1 - аргумент без зависимости).
 //	error - Ошибка выполнения операции.
 func (r *TaskDepsRepository) ReadTaskDeps(ctx context.Context, tx *sql.Tx, id int64) ([]int64, error) {
-	deps := make([]int64, 2)
 	query := `
 	SELECT
-	    first, second
+	    dependency
 	FROM
 	    task_deps
 	WHERE
-	    task_id = ?`
+	    task_id = ?
+	ORDER BY
+	    position`
 
-	if err := tx.QueryRowContext(ctx, query, id).Scan(&deps[0], &deps[1]); err != nil {
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
 		return []int64{}, fmt.Errorf("не удалось получить зависимости задачи: %w", err)
 	}
+	defer rows.Close()
+
+	var deps []int64
+	for rows.Next() {
+		var dep int64
+		if err := rows.Scan(&dep); err != nil {
+			return []int64{}, fmt.Errorf("не удалось получить зависимости задачи: %w", err)
+		}
+		deps = append(deps, dep)
+	}
+	if err := rows.Err(); err != nil {
+		return []int64{}, fmt.Errorf("не удалось получить зависимости задачи: %w", err)
+	}
+	if deps == nil {
+		deps = []int64{} // У задачи нет зависимостей (например, rand())
+	}
 	return deps, nil
 }
 
@@ -86,7 +114,7 @@ func (r *TaskDepsRepository) ReadTaskDeps(ctx context.Context, tx *sql.Tx, id in
 //	ctx: context.Context - Контекст выполнения запроса.
 //	tx: *sql.Tx - Транзакция базы данных.
 //	id: int64 - Идентификатор задачи.
-//	deps: []int64 - Новые зависимости задачи.
+//	deps: []int64 - Новые зависимости задачи в порядке позиций аргументов.
 //
 // Returns:
 //
@@ -96,13 +124,14 @@ func (r *TaskDepsRepository) UpdateTaskDeps(ctx context.Context, tx *sql.Tx, id
     UPDATE
     	task_deps
     SET
-        first = ?,
-        second = ?
+        dependency = ?
 	WHERE
-	    task_id = ?`
-	_, err := tx.ExecContext(ctx, query, deps[0], deps[1], id)
-	if err != nil {
-		return fmt.Errorf("не удалось обновить зависимости задачи: %w", err)
+	    task_id = ? AND position = ?`
+	for i, dep := range deps {
+		_, err := tx.ExecContext(ctx, query, dep, id, i)
+		if err != nil {
+			return fmt.Errorf("не удалось обновить зависимости задачи: %w", err)
+		}
 	}
 	return nil
 }
@@ -26,7 +26,7 @@ func TestCreateTaskDeps_CorrectDeps_Success(t *testing.T) {
 	repo := tasks_repository.NewTaskDepsRepository(db)
 
 	mock.ExpectExec(`INSERT INTO task_deps`).
-		WithArgs(int64(1), int64(2), int64(3)).
+		WithArgs(int64(1), 0, int64(2), int64(1), 1, int64(3)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err = repo.CreateTaskDeps(context.Background(), tx, &models.Task{ID: 1, Dependencies: []int64{2, 3}})
@@ -51,7 +51,7 @@ func TestCreateTaskDeps_CorrectDeps_InternalError(t *testing.T) {
 	repo := tasks_repository.NewTaskDepsRepository(db)
 
 	mock.ExpectExec(`INSERT INTO task_deps`).
-		WithArgs(int64(1), int64(2), int64(3)).
+		WithArgs(int64(1), 0, int64(2), int64(1), 1, int64(3)).
 		WillReturnError(errors.New("error"))
 
 	err = repo.CreateTaskDeps(context.Background(), tx, &models.Task{ID: 1, Dependencies: []int64{2, 3}})
@@ -101,8 +101,8 @@ func TestReadTaskDeps_CorrectId_Success(t *testing.T) {
 
 	repo := tasks_repository.NewTaskDepsRepository(db)
 
-	rows := sqlmock.NewRows([]string{"first", "second"}).AddRow(int64(1), int64(2))
-	mock.ExpectQuery(`SELECT first, second FROM task_deps WHERE task_id = ?`).
+	rows := sqlmock.NewRows([]string{"dependency"}).AddRow(int64(1)).AddRow(int64(2))
+	mock.ExpectQuery(`SELECT dependency FROM task_deps WHERE task_id = \? ORDER BY position`).
 		WithArgs(int64(1)).
 		WillReturnRows(rows)
 
@@ -128,7 +128,7 @@ func TestReadTaskDeps_InternalError(t *testing.T) {
 
 	repo := tasks_repository.NewTaskDepsRepository(db)
 
-	mock.ExpectQuery(`SELECT first, second FROM task_deps WHERE task_id = ?`).
+	mock.ExpectQuery(`SELECT dependency FROM task_deps WHERE task_id = \? ORDER BY position`).
 		WithArgs(int64(1)).
 		WillReturnError(errors.New("error"))
 
@@ -180,7 +180,10 @@ func TestUpdateTaskDeps_CorrectDeps_Success(t *testing.T) {
 	repo := tasks_repository.NewTaskDepsRepository(db)
 
 	mock.ExpectExec(`UPDATE task_deps`).
-		WithArgs(int64(2), int64(3), int64(1)).
+		WithArgs(int64(2), int64(1), 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE task_deps`).
+		WithArgs(int64(3), int64(1), 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err = repo.UpdateTaskDeps(context.Background(), tx, 1, []int64{2, 3})
@@ -205,7 +208,7 @@ func TestUpdateTaskDeps_CorrectDeps_InternalError(t *testing.T) {
 	repo := tasks_repository.NewTaskDepsRepository(db)
 
 	mock.ExpectExec(`UPDATE task_deps`).
-		WithArgs(int64(2), int64(3), int64(1)).
+		WithArgs(int64(2), int64(1), 0).
 		WillReturnError(errors.New("error"))
 
 	err = repo.UpdateTaskDeps(context.Background(), tx, 1, []int64{2, 3})
@@ -51,13 +51,13 @@ func NewTasksRepository(db *sql.DB, dr repositories.TasksDepsRepositoryInterface
 func (r *TasksRepository) CreateTask(ctx context.Context, tx *sql.Tx, task *models.Task) (int64, error, int) {
 	query := `
 	INSERT INTO tasks 
-    	(expression_id, operation) 
+    	(expression_id, operation, exact, precision, complex, interval, iteration, seed) 
     VALUES
-        (?, ?)
+        (?, ?, ?, ?, ?, ?, ?, ?)
     RETURNING
     	id`
 
-	if err := tx.QueryRowContext(ctx, query, task.Expression, task.Operation).Scan(&task.ID); err != nil {
+	if err := tx.QueryRowContext(ctx, query, task.Expression, task.Operation, task.Exact, task.Precision, task.Complex, task.Interval, task.Iteration, task.Seed).Scan(&task.ID); err != nil {
 		return 0, fmt.Errorf("не удалось создать задачу: %w", err), http.StatusInternalServerError
 	}
 
@@ -93,12 +93,12 @@ func (r *TasksRepository) ReadTaskByID(ctx context.Context, tx *sql.Tx, id int64
 	query := `
 	SELECT
 	    id, expression_id, operation,
-	    result, status
+	    result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result
 	FROM
 	    tasks
 	WHERE
 	    id = ?`
-	if err := tx.QueryRowContext(ctx, query, id).Scan(&task.ID, &task.Expression, &task.Operation, &task.Result, &task.Status); err != nil {
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&task.ID, &task.Expression, &task.Operation, &task.Result, &task.Status, &task.Exact, &task.Precision, &task.ExactResult, &task.Complex, &task.ImagResult, &task.Interval, &task.UpperResult); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil, http.StatusNotFound
 		}
@@ -117,6 +117,30 @@ func (r *TasksRepository) ReadTaskByID(ctx context.Context, tx *sql.Tx, id int64
 	}
 	task.Args = args
 
+	if task.HasExactArgs() {
+		exactArgs, err := r.argsRepo.ReadTaskExactArgs(ctx, tx, id)
+		if err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+		task.ExactArgs = exactArgs
+	}
+
+	if task.Complex {
+		imagArgs, err := r.argsRepo.ReadTaskImagArgs(ctx, tx, id)
+		if err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+		task.ImagArgs = imagArgs
+	}
+
+	if task.Interval {
+		upperArgs, err := r.argsRepo.ReadTaskUpperArgs(ctx, tx, id)
+		if err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+		task.UpperArgs = upperArgs
+	}
+
 	return &task, nil, http.StatusOK
 }
 
@@ -142,7 +166,7 @@ func (r *TasksRepository) ReadTasksByExpressionID(ctx context.Context, tx *sql.T
 	query := `
 	SELECT
 	    id, expression_id, operation,
-	    result, status
+	    result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result
 	FROM
 	    tasks
 	WHERE
@@ -156,7 +180,7 @@ func (r *TasksRepository) ReadTasksByExpressionID(ctx context.Context, tx *sql.T
 
 	for rows.Next() {
 		var task models.Task
-		if err := rows.Scan(&task.ID, &task.Expression, &task.Operation, &task.Result, &task.Status); err != nil {
+		if err := rows.Scan(&task.ID, &task.Expression, &task.Operation, &task.Result, &task.Status, &task.Exact, &task.Precision, &task.ExactResult, &task.Complex, &task.ImagResult, &task.Interval, &task.UpperResult); err != nil {
 			return nil, fmt.Errorf("не удалось прочитать задачи: %w", err), http.StatusInternalServerError
 		}
 
@@ -172,6 +196,30 @@ func (r *TasksRepository) ReadTasksByExpressionID(ctx context.Context, tx *sql.T
 		}
 		task.Args = args
 
+		if task.HasExactArgs() {
+			exactArgs, err := r.argsRepo.ReadTaskExactArgs(ctx, tx, task.ID)
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			task.ExactArgs = exactArgs
+		}
+
+		if task.Complex {
+			imagArgs, err := r.argsRepo.ReadTaskImagArgs(ctx, tx, task.ID)
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			task.ImagArgs = imagArgs
+		}
+
+		if task.Interval {
+			upperArgs, err := r.argsRepo.ReadTaskUpperArgs(ctx, tx, task.ID)
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			task.UpperArgs = upperArgs
+		}
+
 		tasks = append(tasks, &task)
 	}
 
@@ -187,6 +235,7 @@ func (r *TasksRepository) ReadTasksByExpressionID(ctx context.Context, tx *sql.T
 }
 
 // ReadUncompletedTasks получает все невыполненные задачи (со статусом 'pending').
+// Задача ветви if не возвращается, пока не вычислены задачи ее условий (см. CreateTaskGuards).
 //
 // Args:
 //
@@ -207,11 +256,14 @@ func (r *TasksRepository) ReadUncompletedTasks(ctx context.Context, tx *sql.Tx)
 	query := `
 	SELECT
 	    id, expression_id, operation,
-	    result, status
+	    result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result, seed
 	FROM
 	    tasks
 	WHERE
-	    status = 'pending'`
+	    status = 'pending' AND NOT EXISTS (
+	        SELECT 1 FROM task_guards g JOIN tasks c ON c.id = g.condition
+	        WHERE g.task_id = tasks.id AND c.status != 'completed'
+	    )`
 
 	rows, err := tx.QueryContext(ctx, query)
 	if err != nil {
@@ -221,7 +273,101 @@ func (r *TasksRepository) ReadUncompletedTasks(ctx context.Context, tx *sql.Tx)
 
 	for rows.Next() {
 		var task models.Task
-		if err := rows.Scan(&task.ID, &task.Expression, &task.Operation, &task.Result, &task.Status); err != nil {
+		if err := rows.Scan(&task.ID, &task.Expression, &task.Operation, &task.Result, &task.Status, &task.Exact, &task.Precision, &task.ExactResult, &task.Complex, &task.ImagResult, &task.Interval, &task.UpperResult, &task.Seed); err != nil {
+			return nil, fmt.Errorf("не удалось прочитать задачи: %w", err), http.StatusInternalServerError
+		}
+
+		deps, err := r.depsRepo.ReadTaskDeps(ctx, tx, task.ID)
+		if err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+		task.Dependencies = deps
+
+		args, err := r.argsRepo.ReadTaskArgs(ctx, tx, task.ID)
+		if err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+		task.Args = args
+
+		if task.HasExactArgs() {
+			exactArgs, err := r.argsRepo.ReadTaskExactArgs(ctx, tx, task.ID)
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			task.ExactArgs = exactArgs
+		}
+
+		if task.Complex {
+			imagArgs, err := r.argsRepo.ReadTaskImagArgs(ctx, tx, task.ID)
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			task.ImagArgs = imagArgs
+		}
+
+		if task.Interval {
+			upperArgs, err := r.argsRepo.ReadTaskUpperArgs(ctx, tx, task.ID)
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			task.UpperArgs = upperArgs
+		}
+
+		tasks = append(tasks, &task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке строк: %w", err), http.StatusInternalServerError
+	}
+
+	if len(tasks) == 0 {
+		return nil, nil, http.StatusNotFound
+	}
+
+	return tasks, nil, http.StatusOK
+}
+
+// ReadDependentTasks получает невыполненные задачи других выражений, которые зависят от задач указанного выражения.
+// Такие зависимости возникают при ссылке на результат ещё не вычисленного выражения ($42).
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	expressionID: int64 - ID выражения, от задач которого зависят искомые задачи.
+//
+// Returns:
+//
+//	[]*models.Task - Список зависимых задач.
+//	error - Ошибка выполнения операции.
+//	int - HTTP статус код:
+//	    - 200 OK при успешном получении
+//	    - 404 Not Found если задачи не найдены
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) ReadDependentTasks(ctx context.Context, tx *sql.Tx, expressionID int64) ([]*models.Task, error, int) {
+	var tasks []*models.Task
+
+	query := `
+	SELECT
+	    t.id, t.expression_id, t.operation,
+	    t.result, t.status, t.exact, t.precision, t.exact_result, t.complex, t.imag_result, t.interval, t.upper_result
+	FROM
+	    tasks t
+	WHERE
+	    t.status = 'pending' AND t.expression_id != ? AND EXISTS (
+	        SELECT 1 FROM task_deps d
+	        WHERE d.task_id = t.id AND d.dependency IN (SELECT id FROM tasks WHERE expression_id = ?)
+	    )`
+
+	rows, err := tx.QueryContext(ctx, query, expressionID, expressionID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить зависимые задачи: %w", err), http.StatusInternalServerError
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task models.Task
+		if err := rows.Scan(&task.ID, &task.Expression, &task.Operation, &task.Result, &task.Status, &task.Exact, &task.Precision, &task.ExactResult, &task.Complex, &task.ImagResult, &task.Interval, &task.UpperResult); err != nil {
 			return nil, fmt.Errorf("не удалось прочитать задачи: %w", err), http.StatusInternalServerError
 		}
 
@@ -237,6 +383,30 @@ func (r *TasksRepository) ReadUncompletedTasks(ctx context.Context, tx *sql.Tx)
 		}
 		task.Args = args
 
+		if task.HasExactArgs() {
+			exactArgs, err := r.argsRepo.ReadTaskExactArgs(ctx, tx, task.ID)
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			task.ExactArgs = exactArgs
+		}
+
+		if task.Complex {
+			imagArgs, err := r.argsRepo.ReadTaskImagArgs(ctx, tx, task.ID)
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			task.ImagArgs = imagArgs
+		}
+
+		if task.Interval {
+			upperArgs, err := r.argsRepo.ReadTaskUpperArgs(ctx, tx, task.ID)
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			task.UpperArgs = upperArgs
+		}
+
 		tasks = append(tasks, &task)
 	}
 
@@ -297,6 +467,78 @@ func (r *TasksRepository) UpdateTaskArguments(ctx context.Context, tx *sql.Tx, i
 	return nil, http.StatusOK
 }
 
+// UpdateTaskExactArgument обновляет точное значение одного из аргументов задачи.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - ID задачи
+//	index: int - Индекс аргумента (0 или 1).
+//	value: string - Новое значение аргумента: дробь "num/den" или десятичная запись.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции.
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) UpdateTaskExactArgument(ctx context.Context, tx *sql.Tx, id int64, index int, value string) (error, int) {
+	err := r.argsRepo.UpdateTaskExactArgs(ctx, tx, id, index, value)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+// UpdateTaskImagArgument обновляет мнимую часть одного из аргументов комплексной задачи.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - ID задачи
+//	index: int - Индекс аргумента (0 или 1).
+//	value: float64 - Новая мнимая часть аргумента.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции.
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) UpdateTaskImagArgument(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) (error, int) {
+	err := r.argsRepo.UpdateTaskImagArgs(ctx, tx, id, index, value)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+// UpdateTaskUpperArgument обновляет верхнюю границу одного из аргументов интервальной задачи.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	id: int64 - ID задачи
+//	index: int - Индекс аргумента (0 или 1).
+//	value: float64 - Новая верхняя граница аргумента.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции.
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) UpdateTaskUpperArgument(ctx context.Context, tx *sql.Tx, id int64, index int, value float64) (error, int) {
+	err := r.argsRepo.UpdateTaskUpperArgs(ctx, tx, id, index, value)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
 // UpdateTaskStatus обновляет статус задачи.
 //
 // Args:
@@ -328,6 +570,105 @@ func (r *TasksRepository) UpdateTaskStatus(ctx context.Context, tx *sql.Tx, id i
 	return nil, http.StatusOK
 }
 
+// CreateTaskGuards сохраняет условия выполнения задачи ветви if (models.Task.Guards).
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	task: *models.Task - Задача с ID в базе данных и условиями с ID задач-условий.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции.
+//	int - HTTP статус код:
+//	    - 201 StatusCreated при успешном создании
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) CreateTaskGuards(ctx context.Context, tx *sql.Tx, task *models.Task) (error, int) {
+	query := `
+	INSERT INTO task_guards
+	    (task_id, condition, value)
+	VALUES
+	    (?, ?, ?)`
+
+	for _, guard := range task.Guards {
+		_, err := tx.ExecContext(ctx, query, task.ID, guard.Condition, guard.Value)
+		if err != nil {
+			return fmt.Errorf("не удалось сохранить условия задачи: %w", err), http.StatusInternalServerError
+		}
+	}
+	return nil, http.StatusCreated
+}
+
+// SkipGuardedTasks устанавливает статус 'skipped' ожидающим задачам, условие которых не выполнилось:
+// истинность результата задачи condition не равна требуемой.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	condition: int64 - ID вычисленной задачи-условия.
+//	value: bool - Истинность ее результата.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) SkipGuardedTasks(ctx context.Context, tx *sql.Tx, condition int64, value bool) (error, int) {
+	query := `
+	UPDATE
+	    tasks
+	SET
+	    status = 'skipped'
+	WHERE
+	    status = 'pending' AND id IN (
+	        SELECT task_id FROM task_guards WHERE condition = ? AND value != ?
+	    )`
+
+	_, err := tx.ExecContext(ctx, query, condition, value)
+	if err != nil {
+		return fmt.Errorf("не удалось пропустить задачи невыбранной ветви: %w", err), http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+// ReadIterationProgress получает ход вычисления итераций integrate и solve выражения: количество задач,
+// завершающих итерации, и количество уже вычисленных или пропущенных из них.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	expressionID: int64 - ID выражения.
+//
+// Returns:
+//
+//	*models.ExpressionProgress - Ход вычисления итераций. nil, если выражение не содержит итераций.
+//	error - Ошибка выполнения операции
+//	int - HTTP статус код:
+//	    - 200 OK при успешном получении
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) ReadIterationProgress(ctx context.Context, tx *sql.Tx, expressionID int64) (*models.ExpressionProgress, error, int) {
+	query := `
+	SELECT
+	    COUNT(*), COALESCE(SUM(status IN ('completed', 'skipped')), 0)
+	FROM
+	    tasks
+	WHERE
+	    expression_id = ? AND iteration > 0`
+
+	var progress models.ExpressionProgress
+	if err := tx.QueryRowContext(ctx, query, expressionID).Scan(&progress.Total, &progress.Done); err != nil {
+		return nil, fmt.Errorf("не удалось получить ход вычисления итераций: %w", err), http.StatusInternalServerError
+	}
+	if progress.Total == 0 {
+		return nil, nil, http.StatusOK
+	}
+	return &progress, nil, http.StatusOK
+}
+
 // UpdateTaskExpressionID обновляет ID выражения для задачи.
 //
 // Args:
@@ -390,6 +731,99 @@ func (r *TasksRepository) UpdateTaskResult(ctx context.Context, tx *sql.Tx, resu
 	return nil, http.StatusOK
 }
 
+// UpdateTaskExactResult обновляет точный результат выполнения задачи.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	exact: string - Новый результат: дробь "num/den" или десятичная запись.
+//	id: int64 - ID задачи.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) UpdateTaskExactResult(ctx context.Context, tx *sql.Tx, exact string, id int64) (error, int) {
+	query := `
+	UPDATE
+	    tasks
+	SET
+	    exact_result = ?
+	WHERE
+	    id = ?`
+
+	_, err := tx.ExecContext(ctx, query, exact, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить точный результат задачи: %w", err), http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+// UpdateTaskImagResult обновляет мнимую часть результата выполнения задачи.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	imag: float64 - Новая мнимая часть результата.
+//	id: int64 - ID задачи.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) UpdateTaskImagResult(ctx context.Context, tx *sql.Tx, imag float64, id int64) (error, int) {
+	query := `
+	UPDATE
+	    tasks
+	SET
+	    imag_result = ?
+	WHERE
+	    id = ?`
+
+	_, err := tx.ExecContext(ctx, query, imag, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить мнимую часть результата задачи: %w", err), http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+// UpdateTaskUpperResult обновляет верхнюю границу результата интервальной задачи.
+//
+// Args:
+//
+//	ctx: context.Context - Контекст выполнения запроса.
+//	tx: *sql.Tx - Транзакция базы данных.
+//	upper: float64 - Новая верхняя граница результата.
+//	id: int64 - ID задачи.
+//
+// Returns:
+//
+//	error - Ошибка выполнения операции
+//	int - HTTP статус код:
+//	    - 200 OK при успешном обновлении
+//	    - 500 Internal Server Error при ошибках
+func (r *TasksRepository) UpdateTaskUpperResult(ctx context.Context, tx *sql.Tx, upper float64, id int64) (error, int) {
+	query := `
+	UPDATE
+	    tasks
+	SET
+	    upper_result = ?
+	WHERE
+	    id = ?`
+
+	_, err := tx.ExecContext(ctx, query, upper, id)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить верхнюю границу результата задачи: %w", err), http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
 // DeleteTasks удаляет все задачи, связанные с указанным выражением.
 //
 // Args:
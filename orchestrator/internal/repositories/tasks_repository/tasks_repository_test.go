@@ -42,7 +42,7 @@ func TestCreateTask_CorrectTask_Success(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 	sqlMock.ExpectQuery(`INSERT INTO tasks`).
-		WithArgs(task.Expression, task.Operation).
+		WithArgs(task.Expression, task.Operation, task.Exact, task.Precision, task.Complex, task.Interval, task.Iteration, task.Seed).
 		WillReturnRows(rows)
 
 	argsRepoMock.On("CreateTaskArgs", mock.Anything, tx, task).Return(nil)
@@ -85,7 +85,7 @@ func TestCreateTask_CorrectTask_InternalError(t *testing.T) {
 	}
 
 	sqlMock.ExpectQuery(`INSERT INTO tasks`).
-		WithArgs(task.Expression, task.Operation).
+		WithArgs(task.Expression, task.Operation, task.Exact, task.Precision, task.Complex, task.Interval, task.Iteration, task.Seed).
 		WillReturnError(errors.New("error"))
 
 	argsRepoMock.On("CreateTaskArgs", mock.Anything, tx, task).Return(nil)
@@ -128,7 +128,7 @@ func TestCreateTask_IncorrectArgs_InternalError(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 	sqlMock.ExpectQuery(`INSERT INTO tasks`).
-		WithArgs(task.Expression, task.Operation).
+		WithArgs(task.Expression, task.Operation, task.Exact, task.Precision, task.Complex, task.Interval, task.Iteration, task.Seed).
 		WillReturnRows(rows)
 
 	argsRepoMock.On("CreateTaskArgs", mock.Anything, tx, task).Return(errors.New("error"))
@@ -171,7 +171,7 @@ func TestCreateTask_IncorrectDeps_InternalError(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 	sqlMock.ExpectQuery(`INSERT INTO tasks`).
-		WithArgs(task.Expression, task.Operation).
+		WithArgs(task.Expression, task.Operation, task.Exact, task.Precision, task.Complex, task.Interval, task.Iteration, task.Seed).
 		WillReturnRows(rows)
 
 	argsRepoMock.On("CreateTaskArgs", mock.Anything, tx, task).Return(nil)
@@ -236,9 +236,9 @@ func TestReadTaskByID_CorrectId_Success(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(int64(1), int64(2), "+", float64(3), "completed")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE id = ?`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(int64(1), int64(2), "+", float64(3), "completed", false, 0, nil, false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE id = ?`).
 		WithArgs(int64(1)).
 		WillReturnRows(rows)
 
@@ -282,7 +282,7 @@ func TestReadTaskByID_UndefinedId_Error(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE id = ?`).
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE id = ?`).
 		WithArgs(int64(1)).
 		WillReturnError(sql.ErrNoRows)
 
@@ -316,7 +316,7 @@ func TestReadTaskByID_CorrectId_InternalError(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE id = ?`).
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE id = ?`).
 		WithArgs(int64(1)).
 		WillReturnError(errors.New("error"))
 
@@ -351,9 +351,9 @@ func TestReadTaskByID_IncorrectArgs_InternalError(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(int64(1), int64(2), "+", float64(3), "completed")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE id = ?`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(int64(1), int64(2), "+", float64(3), "completed", false, 0, nil, false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE id = ?`).
 		WithArgs(int64(1)).
 		WillReturnRows(rows)
 
@@ -388,9 +388,9 @@ func TestReadTaskByID_IncorrectDeps_InternalError(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(int64(1), int64(2), "+", float64(3), "completed")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE id = ?`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(int64(1), int64(2), "+", float64(3), "completed", false, 0, nil, false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE id = ?`).
 		WithArgs(int64(1)).
 		WillReturnRows(rows)
 
@@ -477,10 +477,10 @@ func TestReadTasksByExpressionID_CorrectId_Success(t *testing.T) {
 			Dependencies: []int64{},
 		},
 	}
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(1, expressionID, "+", expectedTasks[0].Result, "completed").
-		AddRow(2, expressionID, "*", expectedTasks[1].Result, "completed")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE expression_id = ?`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(1, expressionID, "+", expectedTasks[0].Result, "completed", false, 0, nil, false, nil, false, nil).
+		AddRow(2, expressionID, "*", expectedTasks[1].Result, "completed", false, 0, nil, false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE expression_id = ?`).
 		WithArgs(int64(2)).
 		WillReturnRows(rows)
 
@@ -522,8 +522,8 @@ func TestReadTasksByExpressionID_UndefinedId_Error(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"})
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE expression_id = ?`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"})
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE expression_id = ?`).
 		WithArgs(int64(0)).
 		WillReturnRows(rows)
 
@@ -554,7 +554,7 @@ func TestReadTasksByExpressionID_CorrectId_Error(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE expression_id = ?`).
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE expression_id = ?`).
 		WithArgs(int64(0)).
 		WillReturnError(errors.New("error"))
 
@@ -587,10 +587,10 @@ func TestReadTasksByExpressionID_IncorrectRows_ReadError(t *testing.T) {
 
 	expressionID := int64(1)
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow("string", expressionID, "+", m.Float64Ptr(3), "completed").
-		AddRow(2, expressionID, "*", m.Float64Ptr(6), "completed")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE expression_id = ?`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow("string", expressionID, "+", m.Float64Ptr(3), "completed", false, 0, nil, false, nil, false, nil).
+		AddRow(2, expressionID, "*", m.Float64Ptr(6), "completed", false, 0, nil, false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE expression_id = ?`).
 		WithArgs(expressionID).
 		WillReturnRows(rows)
 
@@ -624,10 +624,10 @@ func TestReadTasksByExpressionID_IncorrectRows_ProcessingError(t *testing.T) {
 
 	expressionID := int64(1)
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(1, expressionID, "+", m.Float64Ptr(3), "completed")
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(1, expressionID, "+", m.Float64Ptr(3), "completed", false, 0, nil, false, nil, false, nil)
 	rows.RowError(0, errors.New("error"))
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE expression_id = ?`).
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE expression_id = ?`).
 		WithArgs(expressionID).
 		WillReturnRows(rows)
 
@@ -661,10 +661,10 @@ func TestReadTasksByExpressionID_IncorrectArgs_InternalError(t *testing.T) {
 
 	expressionID := int64(1)
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(1, expressionID, "+", m.Float64Ptr(3), "completed").
-		AddRow(2, expressionID, "*", m.Float64Ptr(6), "completed")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE expression_id = ?`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(1, expressionID, "+", m.Float64Ptr(3), "completed", false, 0, nil, false, nil, false, nil).
+		AddRow(2, expressionID, "*", m.Float64Ptr(6), "completed", false, 0, nil, false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE expression_id = ?`).
 		WithArgs(int64(2)).
 		WillReturnRows(rows)
 
@@ -705,10 +705,10 @@ func TestReadTasksByExpressionID_IncorrectDeps_InternalError(t *testing.T) {
 
 	expressionID := int64(1)
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(1, expressionID, "+", m.Float64Ptr(3), "completed").
-		AddRow(2, expressionID, "*", m.Float64Ptr(6), "completed")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE expression_id = ?`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(1, expressionID, "+", m.Float64Ptr(3), "completed", false, 0, nil, false, nil, false, nil).
+		AddRow(2, expressionID, "*", m.Float64Ptr(6), "completed", false, 0, nil, false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE expression_id = ?`).
 		WithArgs(int64(2)).
 		WillReturnRows(rows)
 
@@ -797,10 +797,10 @@ func TestReadUncompletedTasks_CorrectId_Success(t *testing.T) {
 			Dependencies: []int64{},
 		},
 	}
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(1, expressionID, "+", expectedTasks[0].Result, "pending").
-		AddRow(2, expressionID, "*", expectedTasks[1].Result, "pending")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE status = 'pending'`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result", "seed"}).
+		AddRow(1, expressionID, "+", expectedTasks[0].Result, "pending", false, 0, nil, false, nil, false, nil, 0).
+		AddRow(2, expressionID, "*", expectedTasks[1].Result, "pending", false, 0, nil, false, nil, false, nil, 0)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result, seed FROM tasks WHERE status = 'pending'`).
 		WillReturnRows(rows)
 
 	depsRepoMock.On("ReadTaskDeps", mock.Anything, tx, int64(1)).Return([]int64{2, 3}, nil)
@@ -841,8 +841,8 @@ func TestReadUncompletedTasks_UndefinedId_Error(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"})
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE status = 'pending'`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result", "seed"})
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result, seed FROM tasks WHERE status = 'pending'`).
 		WillReturnRows(rows)
 
 	tasks, err, status := repo.ReadUncompletedTasks(context.Background(), tx)
@@ -872,7 +872,7 @@ func TestReadUncompletedTasks_CorrectId_Error(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE status = 'pending'`).
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result, seed FROM tasks WHERE status = 'pending'`).
 		WillReturnError(errors.New("error"))
 
 	tasks, err, status := repo.ReadUncompletedTasks(context.Background(), tx)
@@ -902,10 +902,10 @@ func TestReadUncompletedTasks_IncorrectRows_ReadError(t *testing.T) {
 		t.Fatalf("Ошибка начала транзакции: %v", err)
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow("string", int64(1), "+", m.Float64Ptr(3), "pending").
-		AddRow(2, int64(1), "*", m.Float64Ptr(6), "pending")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE status = 'pending'`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result", "seed"}).
+		AddRow("string", int64(1), "+", m.Float64Ptr(3), "pending", false, 0, nil, false, nil, false, nil, 0).
+		AddRow(2, int64(1), "*", m.Float64Ptr(6), "pending", false, 0, nil, false, nil, false, nil, 0)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result, seed FROM tasks WHERE status = 'pending'`).
 		WillReturnRows(rows)
 
 	tasks, err, status := repo.ReadUncompletedTasks(context.Background(), tx)
@@ -938,10 +938,10 @@ func TestReadUncompletedTasks_IncorrectRows_ProcessingError(t *testing.T) {
 
 	expressionID := int64(1)
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(1, expressionID, "+", m.Float64Ptr(3), "pending")
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result", "seed"}).
+		AddRow(1, expressionID, "+", m.Float64Ptr(3), "pending", false, 0, nil, false, nil, false, nil, 0)
 	rows.RowError(0, errors.New("error"))
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE status = 'pending'`).
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result, seed FROM tasks WHERE status = 'pending'`).
 		WillReturnRows(rows)
 
 	tasks, err, status := repo.ReadUncompletedTasks(context.Background(), tx)
@@ -974,10 +974,10 @@ func TestReadUncompletedTasks_IncorrectArgs_InternalError(t *testing.T) {
 
 	expressionID := int64(1)
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(1, expressionID, "+", m.Float64Ptr(3), "pending").
-		AddRow(2, expressionID, "*", m.Float64Ptr(6), "pending")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE status = 'pending'`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result", "seed"}).
+		AddRow(1, expressionID, "+", m.Float64Ptr(3), "pending", false, 0, nil, false, nil, false, nil, 0).
+		AddRow(2, expressionID, "*", m.Float64Ptr(6), "pending", false, 0, nil, false, nil, false, nil, 0)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result, seed FROM tasks WHERE status = 'pending'`).
 		WillReturnRows(rows)
 
 	depsRepoMock.On("ReadTaskDeps", mock.Anything, tx, int64(1)).Return([]int64{}, nil)
@@ -1017,10 +1017,10 @@ func TestReadUncompletedTasks_IncorrectDeps_InternalError(t *testing.T) {
 
 	expressionID := int64(1)
 
-	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status"}).
-		AddRow(1, expressionID, "+", m.Float64Ptr(3), "pending").
-		AddRow(2, expressionID, "*", m.Float64Ptr(6), "pending")
-	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status FROM tasks WHERE status = 'pending'`).
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result", "seed"}).
+		AddRow(1, expressionID, "+", m.Float64Ptr(3), "pending", false, 0, nil, false, nil, false, nil, 0).
+		AddRow(2, expressionID, "*", m.Float64Ptr(6), "pending", false, 0, nil, false, nil, false, nil, 0)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result, seed FROM tasks WHERE status = 'pending'`).
 		WillReturnRows(rows)
 
 	depsRepoMock.On("ReadTaskDeps", mock.Anything, tx, int64(1)).Return([]int64{2, 3}, nil)
@@ -1069,6 +1069,114 @@ func TestReadUncompletedTasks_CanceledContext_InternalError(t *testing.T) {
 	assert.NoError(t, sqlMock.ExpectationsWereMet())
 }
 
+func TestReadDependentTasks_CorrectId_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	argsRepoMock := new(m.MockArgsRepository)
+	depsRepoMock := new(m.MockDepsRepository)
+
+	repo := tasks_repository.NewTasksRepository(db, depsRepoMock, argsRepoMock)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	expressionID := int64(1)
+	expectedTask := &models.Task{
+		ID:           5,
+		Expression:   2,
+		Operation:    "*",
+		Status:       "pending",
+		Args:         []*float64{nil, m.Float64Ptr(2)},
+		Dependencies: []int64{3, -1},
+	}
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(5, 2, "*", nil, "pending", false, 0, nil, false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT t.id, t.expression_id, t.operation, t.result, t.status, t.exact, t.precision, t.exact_result, t.complex, t.imag_result, t.interval, t.upper_result FROM tasks t WHERE t.status = 'pending' AND t.expression_id != \? AND EXISTS`).
+		WithArgs(expressionID, expressionID).
+		WillReturnRows(rows)
+
+	depsRepoMock.On("ReadTaskDeps", mock.Anything, tx, int64(5)).Return(expectedTask.Dependencies, nil)
+	argsRepoMock.On("ReadTaskArgs", mock.Anything, tx, int64(5)).Return(expectedTask.Args, nil)
+
+	tasks, err, status := repo.ReadDependentTasks(context.Background(), tx, expressionID)
+
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, expectedTask, tasks[0])
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	argsRepoMock.AssertExpectations(t)
+	depsRepoMock.AssertExpectations(t)
+}
+func TestReadDependentTasks_NoDependents_Error(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	argsRepoMock := new(m.MockArgsRepository)
+	depsRepoMock := new(m.MockDepsRepository)
+
+	repo := tasks_repository.NewTasksRepository(db, depsRepoMock, argsRepoMock)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"})
+	sqlMock.ExpectQuery(`SELECT t.id, t.expression_id, t.operation, t.result, t.status, t.exact, t.precision, t.exact_result, t.complex, t.imag_result, t.interval, t.upper_result FROM tasks t WHERE t.status = 'pending' AND t.expression_id != \? AND EXISTS`).
+		WillReturnRows(rows)
+
+	tasks, err, status := repo.ReadDependentTasks(context.Background(), tx, 1)
+
+	assert.Nil(t, tasks)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+func TestReadDependentTasks_CorrectId_Error(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	argsRepoMock := new(m.MockArgsRepository)
+	depsRepoMock := new(m.MockDepsRepository)
+
+	repo := tasks_repository.NewTasksRepository(db, depsRepoMock, argsRepoMock)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectQuery(`SELECT t.id, t.expression_id, t.operation, t.result, t.status, t.exact, t.precision, t.exact_result, t.complex, t.imag_result, t.interval, t.upper_result FROM tasks t WHERE t.status = 'pending' AND t.expression_id != \? AND EXISTS`).
+		WillReturnError(errors.New("error"))
+
+	tasks, err, status := repo.ReadDependentTasks(context.Background(), tx, 1)
+
+	assert.Nil(t, tasks)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "не удалось получить зависимые задачи")
+	assert.Equal(t, http.StatusInternalServerError, status)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
 func TestUpdateTaskDependencies_CorrectTask_Success(t *testing.T) {
 	db, sqlMock, err := sqlmock.New()
 	if err != nil {
@@ -1608,3 +1716,481 @@ func TestDeleteTasks_CanceledContext_InternalError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, status)
 	assert.NoError(t, sqlMock.ExpectationsWereMet())
 }
+
+func TestReadTaskByID_ExactTask_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	argsRepoMock := new(m.MockArgsRepository)
+	depsRepoMock := new(m.MockDepsRepository)
+
+	repo := tasks_repository.NewTasksRepository(db, depsRepoMock, argsRepoMock)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(int64(1), int64(2), "+", float64(0.3), "completed", true, 0, "3/10", false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE id = ?`).
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	argsRepoMock.On("ReadTaskArgs", mock.Anything, tx, int64(1)).Return([]*float64{m.Float64Ptr(0.1), m.Float64Ptr(0.2)}, nil)
+	argsRepoMock.On("ReadTaskExactArgs", mock.Anything, tx, int64(1)).Return([]*string{m.StringPtr("1/10"), m.StringPtr("1/5")}, nil)
+	depsRepoMock.On("ReadTaskDeps", mock.Anything, tx, int64(1)).Return([]int64{-1, -1}, nil)
+
+	task, err, status := repo.ReadTaskByID(context.Background(), tx, int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, task.Exact)
+	assert.Equal(t, "3/10", *task.ExactResult)
+	assert.Equal(t, []*string{m.StringPtr("1/10"), m.StringPtr("1/5")}, task.ExactArgs)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	argsRepoMock.AssertExpectations(t)
+	depsRepoMock.AssertExpectations(t)
+}
+
+func TestReadTaskByID_DecimalTask_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	argsRepoMock := new(m.MockArgsRepository)
+	depsRepoMock := new(m.MockDepsRepository)
+
+	repo := tasks_repository.NewTasksRepository(db, depsRepoMock, argsRepoMock)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(int64(1), int64(2), "^", nil, "pending", false, 50, nil, false, nil, false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE id = ?`).
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	argsRepoMock.On("ReadTaskArgs", mock.Anything, tx, int64(1)).Return([]*float64{m.Float64Ptr(2), m.Float64Ptr(0.5)}, nil)
+	argsRepoMock.On("ReadTaskExactArgs", mock.Anything, tx, int64(1)).Return([]*string{m.StringPtr("2"), m.StringPtr("0.5")}, nil)
+	depsRepoMock.On("ReadTaskDeps", mock.Anything, tx, int64(1)).Return([]int64{-1, -1}, nil)
+
+	task, err, status := repo.ReadTaskByID(context.Background(), tx, int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.False(t, task.Exact)
+	assert.Equal(t, 50, task.Precision)
+	assert.Equal(t, []*string{m.StringPtr("2"), m.StringPtr("0.5")}, task.ExactArgs)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	argsRepoMock.AssertExpectations(t)
+	depsRepoMock.AssertExpectations(t)
+}
+
+func TestUpdateTaskExactArgument_Success(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	argsRepoMock := new(m.MockArgsRepository)
+	repo := tasks_repository.NewTasksRepository(db, nil, argsRepoMock)
+
+	argsRepoMock.On("UpdateTaskExactArgs", mock.Anything, (*sql.Tx)(nil), int64(1), 0, "1/3").Return(nil)
+
+	err, status := repo.UpdateTaskExactArgument(context.Background(), nil, int64(1), 0, "1/3")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	argsRepoMock.AssertExpectations(t)
+}
+
+func TestUpdateTaskExactResult_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectExec(`UPDATE tasks SET exact_result = \? WHERE id = \?`).
+		WithArgs("3/10", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err, status := repo.UpdateTaskExactResult(context.Background(), tx, "3/10", int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateTaskExactResult_InternalError(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectExec(`UPDATE tasks`).
+		WithArgs("3/10", int64(1)).
+		WillReturnError(errors.New("error"))
+
+	err, status := repo.UpdateTaskExactResult(context.Background(), tx, "3/10", int64(1))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "не удалось обновить точный результат задачи")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestReadTaskByID_ComplexTask_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	argsRepoMock := new(m.MockArgsRepository)
+	depsRepoMock := new(m.MockDepsRepository)
+
+	repo := tasks_repository.NewTasksRepository(db, depsRepoMock, argsRepoMock)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(int64(1), int64(2), "*", float64(5), "completed", false, 0, nil, true, float64(5), false, nil)
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE id = ?`).
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	argsRepoMock.On("ReadTaskArgs", mock.Anything, tx, int64(1)).Return([]*float64{m.Float64Ptr(1), m.Float64Ptr(3)}, nil)
+	argsRepoMock.On("ReadTaskImagArgs", mock.Anything, tx, int64(1)).Return([]*float64{m.Float64Ptr(2), m.Float64Ptr(-1)}, nil)
+	depsRepoMock.On("ReadTaskDeps", mock.Anything, tx, int64(1)).Return([]int64{-1, -1}, nil)
+
+	task, err, status := repo.ReadTaskByID(context.Background(), tx, int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, task.Complex)
+	assert.Nil(t, task.ExactArgs)
+	assert.Equal(t, float64(5), *task.ImagResult)
+	assert.Equal(t, []*float64{m.Float64Ptr(2), m.Float64Ptr(-1)}, task.ImagArgs)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	argsRepoMock.AssertExpectations(t)
+	depsRepoMock.AssertExpectations(t)
+}
+
+func TestReadTaskByID_IntervalTask_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	argsRepoMock := new(m.MockArgsRepository)
+	depsRepoMock := new(m.MockDepsRepository)
+
+	repo := tasks_repository.NewTasksRepository(db, depsRepoMock, argsRepoMock)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "expression_id", "operation", "result", "status", "exact", "precision", "exact_result", "complex", "imag_result", "interval", "upper_result"}).
+		AddRow(int64(1), int64(2), "*", float64(5.5), "completed", false, 0, nil, false, nil, true, float64(6.5))
+	sqlMock.ExpectQuery(`SELECT id, expression_id, operation, result, status, exact, precision, exact_result, complex, imag_result, interval, upper_result FROM tasks WHERE id = ?`).
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	argsRepoMock.On("ReadTaskArgs", mock.Anything, tx, int64(1)).Return([]*float64{m.Float64Ptr(2.75), m.Float64Ptr(2)}, nil)
+	argsRepoMock.On("ReadTaskUpperArgs", mock.Anything, tx, int64(1)).Return([]*float64{m.Float64Ptr(3.25), m.Float64Ptr(2)}, nil)
+	depsRepoMock.On("ReadTaskDeps", mock.Anything, tx, int64(1)).Return([]int64{-1, -1}, nil)
+
+	task, err, status := repo.ReadTaskByID(context.Background(), tx, int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, task.Interval)
+	assert.Nil(t, task.ImagArgs)
+	assert.Equal(t, float64(6.5), *task.UpperResult)
+	assert.Equal(t, []*float64{m.Float64Ptr(3.25), m.Float64Ptr(2)}, task.UpperArgs)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	argsRepoMock.AssertExpectations(t)
+	depsRepoMock.AssertExpectations(t)
+}
+
+func TestUpdateTaskImagResult_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectExec(`UPDATE tasks SET imag_result = \? WHERE id = \?`).
+		WithArgs(float64(5), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err, status := repo.UpdateTaskImagResult(context.Background(), tx, 5, int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUpdateTaskUpperResult_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectExec(`UPDATE tasks SET upper_result = \? WHERE id = \?`).
+		WithArgs(float64(6.5), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err, status := repo.UpdateTaskUpperResult(context.Background(), tx, 6.5, int64(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestCreateTaskGuards_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	task := &models.Task{ID: 5, Guards: []models.TaskGuard{{Condition: 3, Value: false}, {Condition: 4, Value: true}}}
+	sqlMock.ExpectExec(`INSERT INTO task_guards`).
+		WithArgs(int64(5), int64(3), false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec(`INSERT INTO task_guards`).
+		WithArgs(int64(5), int64(4), true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err, status := repo.CreateTaskGuards(context.Background(), tx, task)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestCreateTaskGuards_InternalError(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	task := &models.Task{ID: 5, Guards: []models.TaskGuard{{Condition: 3, Value: true}}}
+	sqlMock.ExpectExec(`INSERT INTO task_guards`).
+		WithArgs(int64(5), int64(3), true).
+		WillReturnError(errors.New("error"))
+
+	err, status := repo.CreateTaskGuards(context.Background(), tx, task)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestSkipGuardedTasks_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectExec(`UPDATE tasks SET status = 'skipped'`).
+		WithArgs(int64(3), false).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err, status := repo.SkipGuardedTasks(context.Background(), tx, 3, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestSkipGuardedTasks_InternalError(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectExec(`UPDATE tasks SET status = 'skipped'`).
+		WithArgs(int64(3), true).
+		WillReturnError(errors.New("error"))
+
+	err, status := repo.SkipGuardedTasks(context.Background(), tx, 3, true)
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestReadIterationProgress_Success(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectQuery(`SELECT COUNT\(\*\), COALESCE\(SUM\(status IN \('completed', 'skipped'\)\), 0\) FROM tasks`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "done"}).AddRow(34, 12))
+
+	progress, err, status := repo.ReadIterationProgress(context.Background(), tx, 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, &models.ExpressionProgress{Done: 12, Total: 34}, progress)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestReadIterationProgress_NoIterations(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectQuery(`SELECT COUNT\(\*\)`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "done"}).AddRow(0, 0))
+
+	progress, err, status := repo.ReadIterationProgress(context.Background(), tx, 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, progress)
+}
+
+func TestReadIterationProgress_InternalError(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := tasks_repository.NewTasksRepository(db, nil, nil)
+
+	sqlMock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Ошибка начала транзакции: %v", err)
+	}
+
+	sqlMock.ExpectQuery(`SELECT COUNT\(\*\)`).
+		WithArgs(int64(7)).
+		WillReturnError(errors.New("error"))
+
+	progress, err, status := repo.ReadIterationProgress(context.Background(), tx, 7)
+
+	assert.Nil(t, progress)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
@@ -28,8 +28,12 @@ import (
 //	Защищенные (требуют JWT):
 //	    POST /api/p/delete - Удаление пользователя
 //	    POST /api/p/calculate - Добавление выражения
+//	    POST /api/p/explain - Разбор выражения без сохранения
 //	    GET /api/p/expressions - Получение списка выражений
 //	    GET /api/p/expressions/{id} - Получение выражения по ID
+//	    POST /api/p/define - Добавление функции пользователя
+//	    GET /api/p/functions - Получение списка функций пользователя
+//	    DELETE /api/p/functions/{name} - Удаление функции пользователя
 //
 // Middleware:
 //
@@ -52,8 +56,12 @@ func NewOrchestratorRouter(provider *providers.Providers) *mux.Router {
 	authRouter.HandleFunc("/logout", handler.LogoutUserHandler)
 	authRouter.HandleFunc("/delete", handler.DeleteUserHandler)
 	authRouter.HandleFunc("/calculate", handler.AddExpressionHandler)
+	authRouter.HandleFunc("/explain", handler.ExplainExpressionHandler)
 	authRouter.HandleFunc("/expressions", handler.GetExpressionsHandler)
 	authRouter.HandleFunc("/expressions/{id}", handler.GetExpressionHandler)
+	authRouter.HandleFunc("/define", handler.AddFunctionHandler)
+	authRouter.HandleFunc("/functions", handler.GetFunctionsHandler)
+	authRouter.HandleFunc("/functions/{name}", handler.DeleteFunctionHandler)
 
 	return router
 }
@@ -37,8 +37,12 @@ func TestRoutes_Reachability(t *testing.T) {
 		{http.MethodGet, "/api/p/logout", http.StatusUnauthorized},
 		{http.MethodPost, "/api/p/delete", http.StatusUnauthorized},
 		{http.MethodPost, "/api/p/calculate", http.StatusUnauthorized},
+		{http.MethodPost, "/api/p/explain", http.StatusUnauthorized},
 		{http.MethodGet, "/api/p/expressions", http.StatusUnauthorized},
 		{http.MethodGet, "/api/p/expressions/1", http.StatusUnauthorized},
+		{http.MethodPost, "/api/p/define", http.StatusUnauthorized},
+		{http.MethodGet, "/api/p/functions", http.StatusUnauthorized},
+		{http.MethodDelete, "/api/p/functions/f", http.StatusUnauthorized},
 	}
 
 	for _, tt := range tests {
@@ -71,8 +75,12 @@ func TestRouter_AuthMiddleware(t *testing.T) {
 		{http.MethodGet, "/api/p/logout"},
 		{http.MethodPost, "/api/p/delete"},
 		{http.MethodPost, "/api/p/calculate"},
+		{http.MethodPost, "/api/p/explain"},
 		{http.MethodGet, "/api/p/expressions"},
 		{http.MethodGet, "/api/p/expressions/1"},
+		{http.MethodPost, "/api/p/define"},
+		{http.MethodGet, "/api/p/functions"},
+		{http.MethodDelete, "/api/p/functions/f"},
 	}
 
 	for _, tt := range tests {
@@ -107,8 +115,12 @@ func TestRoute_MethodValidation(t *testing.T) {
 		{http.MethodGet, "/api/p/logout"},
 		{http.MethodPost, "/api/p/delete"},
 		{http.MethodPost, "/api/p/calculate"},
+		{http.MethodPost, "/api/p/explain"},
 		{http.MethodGet, "/api/p/expressions"},
 		{http.MethodGet, "/api/p/expressions/1"},
+		{http.MethodPost, "/api/p/define"},
+		{http.MethodGet, "/api/p/functions"},
+		{http.MethodDelete, "/api/p/functions/f"},
 	}
 
 	for _, tt := range tests {
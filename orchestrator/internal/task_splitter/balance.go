@@ -0,0 +1,155 @@
+package task_splitter
+
+import "github.com/OinkiePie/calc_3/pkg/operators"
+
+// node представляет узел дерева выражения, построенного по RPN.
+type node struct {
+	tok      token   // Токен операнда или операции
+	children []*node // Операнды операции в порядке записи. У операнда детей нет
+}
+
+// buildTree строит дерево выражения по его записи в RPN.
+//
+// Args:
+//
+//	rpn: []token - Выражение в обратной польской нотации.
+//
+// Returns:
+//
+//	*node - Корень дерева или nil, если RPN некорректна (ошибку сообщит rpnToTasks).
+func buildTree(rpn []token) *node {
+	var stack []*node
+	for _, tok := range rpn {
+		arity := tok.args // Вызов функции пользователя принимает все переданные аргументы
+		if tok.kind != tokenCall {
+			operator, ok := operators.Lookup(tok.text)
+			if !ok || tok.kind == tokenReference || tok.kind == tokenNumber {
+				stack = append(stack, &node{tok: tok})
+				continue
+			}
+			arity = operator.ArgCount(tok.args)
+		}
+		if len(stack) < arity {
+			return nil
+		}
+		children := append([]*node(nil), stack[len(stack)-arity:]...)
+		stack = append(stack[:len(stack)-arity], &node{tok: tok, children: children})
+	}
+	if len(stack) != 1 {
+		return nil
+	}
+	return stack[0]
+}
+
+// rpn записывает поддерево в обратной польской нотации.
+//
+// Args:
+//
+//	output: []token - Уже записанные токены.
+//
+// Returns:
+//
+//	[]token - Токены с добавленным поддеревом.
+func (n *node) rpn(output []token) []token {
+	for _, child := range n.children {
+		output = child.rpn(output)
+	}
+	return append(output, n.tok)
+}
+
+// depth возвращает количество операций на самом длинном пути от узла до операнда.
+func (n *node) depth() int {
+	if len(n.children) == 0 {
+		return 0
+	}
+	deepest := 0
+	for _, child := range n.children {
+		deepest = max(deepest, child.depth())
+	}
+	return deepest + 1
+}
+
+// balance перестраивает цепочки одинаковых ассоциативных операций в поддереве в сбалансированные деревья.
+// Порядок операндов сохраняется, поэтому перестановка операндов (коммутативность) не требуется:
+// 1+2+3+4 = (1+2)+(3+4).
+//
+// Returns:
+//
+//	*node - Корень перестроенного поддерева.
+func (n *node) balance() *node {
+	operator, ok := operators.Lookup(n.tok.text)
+	if !ok || !operator.Associative || n.tok.kind != tokenSymbol {
+		for i, child := range n.children {
+			n.children[i] = child.balance()
+		}
+		return n
+	}
+
+	operands := n.chain(n.tok.text)
+	for i, operand := range operands {
+		operands[i] = operand.balance()
+	}
+	return join(n.tok, operands)
+}
+
+// chain собирает операнды цепочки операций symbol, начинающейся в узле, в порядке записи.
+//
+// Args:
+//
+//	symbol: string - Идентификатор операции цепочки.
+//
+// Returns:
+//
+//	[]*node - Операнды цепочки.
+func (n *node) chain(symbol string) []*node {
+	if n.tok.kind != tokenSymbol || n.tok.text != symbol {
+		return []*node{n}
+	}
+	var operands []*node
+	for _, child := range n.children {
+		operands = append(operands, child.chain(symbol)...)
+	}
+	return operands
+}
+
+// join объединяет операнды бинарной ассоциативной операцией в сбалансированное дерево.
+//
+// Args:
+//
+//	tok: token - Токен операции.
+//	operands: []*node - Непустой список операндов.
+//
+// Returns:
+//
+//	*node - Корень дерева глубиной log2(len(operands)).
+func join(tok token, operands []*node) *node {
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	middle := len(operands) / 2
+	return &node{tok: tok, children: []*node{join(tok, operands[:middle]), join(tok, operands[middle:])}}
+}
+
+// balanceRPN перестраивает длинные цепочки ассоциативных операций (+ и *) в сбалансированные деревья,
+// чтобы их задачи вычислялись агентами параллельно: глубина цепочки из n операндов уменьшается
+// с n-1 до log2(n).
+//
+// Args:
+//
+//	rpn: []token - Выражение в обратной польской нотации.
+//
+// Returns:
+//
+//	[]token - Перестроенное выражение в RPN (исходное, если RPN некорректна).
+//	int - Глубина выражения до перестроения.
+//	int - Глубина выражения после перестроения.
+func balanceRPN(rpn []token) ([]token, int, int) {
+	root := buildTree(rpn)
+	if root == nil {
+		return rpn, 0, 0
+	}
+
+	before := root.depth()
+	root = root.balance()
+	return root.rpn(make([]token, 0, len(rpn))), before, root.depth()
+}
@@ -0,0 +1,378 @@
+package task_splitter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/OinkiePie/calc_3/pkg/operators"
+	"github.com/OinkiePie/calc_3/pkg/units"
+)
+
+// Встроенные функции, которые разворачивает оркестратор: их тело вычисляется агентами для значений переменной,
+// а результат собирается задачами обычных операций. Агентам эти функции неизвестны, поэтому их нет в реестре операций.
+const (
+	fnIntegrate = "integrate" // определенный интеграл по формуле Симпсона: integrate(x^2, x, 0, 1, 10)
+	fnSolve     = "solve"     // корень уравнения на отрезке методом бисекции: solve(x^2 - 2, x, 0, 2)
+)
+
+// formArity - количество аргументов integrate и solve.
+var formArity = map[string]int{fnIntegrate: 5, fnSolve: 4}
+
+// isForm сообщает, является ли имя встроенной функцией, которую разворачивает оркестратор (integrate или solve).
+func isForm(name string) bool {
+	_, ok := formArity[name]
+	return ok
+}
+
+// Точность solve: отрезок делится пополам, пока его длина больше solveTolerance * max(1, |lo|, |hi|),
+// но не более solveRounds раз.
+const (
+	solveTolerance = 1e-10
+	solveRounds    = 64
+)
+
+// calculable проверяет, что integrate или solve доступны в режиме вычисления: их результат приближенный,
+// а выбор половины отрезка в solve использует сравнения.
+//
+// Args:
+//
+//	call: token - Токен вызова.
+//
+// Returns:
+//
+//	error - Ошибка разбора CodeInexactOperation в точном режиме, CodeUnsupportedOperation в комплексном
+//	    и интервальном режимах.
+func (s *script) calculable(call token) error {
+	switch {
+	case s.exact:
+		return newParseError(CodeInexactOperation, fmt.Errorf("функция %s не поддерживает точный режим", call.text), call, "")
+	case s.complex:
+		return newParseError(CodeUnsupportedOperation, fmt.Errorf("функция %s не поддерживает комплексный режим", call.text), call, "")
+	case s.interval:
+		return newParseError(CodeUnsupportedOperation, fmt.Errorf("функция %s не поддерживает интервальный режим", call.text), call, "")
+	}
+	return nil
+}
+
+// plain возвращает действительное число, известное при разборе, или false, если значение вычисляется задачей.
+func plain(value *models.Task) (float64, bool) {
+	if value.Result == nil || value.ImagResult != nil || value.UpperResult != nil {
+		return 0, false
+	}
+	return *value.Result, true
+}
+
+// integrate разворачивает определенный интеграл integrate(f, x, a, b, n) по составной формуле Симпсона:
+// отрезок [a, b] делится на n (четное) частей шириной h, тело подставляется в n+1 точку x_k = a + k*h,
+// и агенты вычисляют значения тела параллельно. Интеграл S_n = h/3 * (f_0 + 4*(f_1 + f_3 + ...) + 2*(f_2 + f_4 + ...) + f_n)
+// собирается деревьями сумм (см. aggregate).
+//
+// Оценка погрешности (см. estimate) вычисляется задачами по тем же значениям тела: при n, кратном 4, - по правилу Рунге
+// |S_n - S_{n/2}| / 15, где S_{n/2} - формула Симпсона по четным точкам, иначе - |S_n - T_n|, где T_n - формула трапеций.
+//
+// Args:
+//
+//	l: loop - Вызов.
+//	rpn: []token - Выражение в формате RPN, содержащее вызов.
+//	operands: []*models.Task - Пределы a, b и количество частей n.
+//	dims: []units.Dimension - Размерности аргументов.
+//
+// Returns:
+//
+//	*models.Task - Значение интеграла.
+//	units.Dimension - Размерность значения: произведение размерностей тела и переменной.
+//	error - Ошибка разбора:
+//	    - CodeCalculusBounds, если пределы или количество частей не известны при разборе или недопустимы
+//	    - CodeDimensionMismatch, если пределы имеют разные размерности или размерность тела зависит от точки
+//	    - CodeSeriesLimit, если подстановок тела больше Options.SeriesLimit
+//	    - ошибка в теле
+func (s *script) integrate(l loop, rpn []token, operands []*models.Task, dims []units.Dimension) (*models.Task, units.Dimension, error) {
+	call := rpn[l.call]
+	at := span(rpn[l.body : l.call+1])
+	if err := s.calculable(call); err != nil {
+		return nil, units.Dimension{}, err
+	}
+
+	lo, loKnown := plain(operands[0])
+	hi, hiKnown := plain(operands[1])
+	if !loKnown || !hiKnown {
+		return nil, units.Dimension{}, newParseError(CodeCalculusBounds, fmt.Errorf("пределы %s должны быть числами, известными при разборе", call.text), at, "")
+	}
+	if dims[0] != dims[1] {
+		return nil, units.Dimension{}, newParseError(CodeDimensionMismatch, fmt.Errorf("пределы %s: %w: %s и %s", call.text, units.ErrMismatch, dims[0], dims[1]), at, "")
+	}
+	parts, ok := plain(operands[2])
+	if !ok || !dims[2].Dimensionless() || parts != math.Trunc(parts) || parts < 2 || parts > math.MaxInt32 || math.Mod(parts, 2) != 0 {
+		return nil, units.Dimension{}, newParseError(CodeCalculusBounds, fmt.Errorf("количество частей %s должно быть четным числом не меньше 2", call.text), at, "")
+	}
+	n, xDim := int(parts), dims[0]
+
+	if s.unreachable() {
+		fDim, err := s.probe(l, rpn, operands[0], xDim)
+		return &models.Task{Status: "skipped"}, fDim.Mul(xDim), err
+	}
+	if err := s.reserve(n+1, at); err != nil {
+		return nil, units.Dimension{}, err
+	}
+
+	h := (hi - lo) / float64(n)
+	f := make([]*models.Task, n+1)
+	var fDim units.Dimension
+	for k := range f {
+		x := lo + float64(k)*h
+		if k == n {
+			x = hi // Без ошибки округления последней точки
+		}
+		value, dim, err := s.substitute(l, rpn, literal(x), xDim)
+		if err != nil {
+			return nil, units.Dimension{}, err
+		}
+		if k == 0 {
+			fDim = dim
+		} else if dim != fDim {
+			return nil, units.Dimension{}, newParseError(CodeDimensionMismatch, fmt.Errorf("тело %s: %w: %s и %s", call.text, units.ErrMismatch, fDim, dim), at, "")
+		}
+		s.iterate(value, k+1)
+		f[k] = value
+	}
+
+	// Внутренние точки: нечетные и четные с номерами 4m+2 и 4m
+	var odd, twos, fours []*models.Task
+	for k := 1; k < n; k++ {
+		switch k % 4 {
+		case 1, 3:
+			odd = append(odd, f[k])
+		case 2:
+			twos = append(twos, f[k])
+		default:
+			fours = append(fours, f[k])
+		}
+	}
+	o, e2, e4 := s.total(odd), s.total(twos), s.total(fours)
+
+	simpson := s.weighted(h/3, f[0], f[n], []float64{4, 2, 2}, o, e2, e4)
+	var estimate *models.Task
+	if n%4 == 0 {
+		half := s.weighted(2*h/3, f[0], f[n], []float64{4, 2}, e2, e4)
+		estimate = s.binary(operators.OpDivide, s.unary(operators.FnAbs, s.binary(operators.OpSubtract, simpson, half)), literal(15))
+	} else {
+		trapezoid := s.weighted(h/2, f[0], f[n], []float64{2, 2, 2}, o, e2, e4)
+		estimate = s.unary(operators.FnAbs, s.binary(operators.OpSubtract, simpson, trapezoid))
+	}
+	s.estimate(call.text, "error", estimate)
+
+	return simpson, fDim.Mul(xDim), nil
+}
+
+// solve находит корень уравнения solve(f, x, lo, hi) = 0 на отрезке [lo, hi] методом бисекции. Количество раундов
+// известно при разборе (см. solveTolerance): в каждом раунде тело подставляется в середину текущего отрезка,
+// а задача if выбирает половину, на концах которой значения тела разных знаков. Раунды зависят друг от друга,
+// поэтому агенты вычисляют их последовательно, а задача выбора каждого раунда отмечается номером итерации
+// (см. models.Task.Iteration).
+//
+// Все раунды разворачиваются при разборе: раунд создает задачи тела и еще четыре задачи (середина отрезка,
+// произведение значений, сравнение со знаком и выбор половины), поэтому solve(x^2 - 2, x, 0, 2) в 34 раунда
+// создает 213 задач, а их количество растет пропорционально размеру тела. Поэтому solve учитывает
+// в Options.SeriesLimit не подстановки тела, а все созданные задачи.
+//
+// Сходимость описывают оценки (см. estimate): error - половина длины последнего отрезка, residual - значение тела
+// в найденном корне, bracketed - 1, если значения тела на концах исходного отрезка разных знаков (иначе корень
+// на отрезке может отсутствовать, и результат - один из концов отрезка).
+//
+// Args:
+//
+//	l: loop - Вызов.
+//	rpn: []token - Выражение в формате RPN, содержащее вызов.
+//	operands: []*models.Task - Концы отрезка lo и hi.
+//	dims: []units.Dimension - Размерности концов.
+//
+// Returns:
+//
+//	*models.Task - Корень: середина последнего отрезка.
+//	units.Dimension - Размерность корня (размерность переменной).
+//	error - Ошибка разбора:
+//	    - CodeCalculusBounds, если концы отрезка не известны при разборе или lo >= hi
+//	    - CodeDimensionMismatch, если концы имеют разные размерности или размерность тела зависит от точки
+//	    - CodeSeriesLimit, если задач больше Options.SeriesLimit
+//	    - ошибка в теле
+func (s *script) solve(l loop, rpn []token, operands []*models.Task, dims []units.Dimension) (*models.Task, units.Dimension, error) {
+	call := rpn[l.call]
+	at := span(rpn[l.body : l.call+1])
+	if err := s.calculable(call); err != nil {
+		return nil, units.Dimension{}, err
+	}
+
+	lo, loKnown := plain(operands[0])
+	hi, hiKnown := plain(operands[1])
+	if !loKnown || !hiKnown || lo >= hi {
+		return nil, units.Dimension{}, newParseError(CodeCalculusBounds, fmt.Errorf("концы отрезка %s должны быть числами lo < hi, известными при разборе", call.text), at, "")
+	}
+	if dims[0] != dims[1] {
+		return nil, units.Dimension{}, newParseError(CodeDimensionMismatch, fmt.Errorf("концы отрезка %s: %w: %s и %s", call.text, units.ErrMismatch, dims[0], dims[1]), at, "")
+	}
+	xDim := dims[0]
+
+	if s.unreachable() {
+		_, err := s.probe(l, rpn, operands[0], xDim)
+		return &models.Task{Status: "skipped"}, xDim, err
+	}
+
+	tolerance := solveTolerance * max(1, math.Abs(lo), math.Abs(hi))
+	rounds := min(max(int(math.Ceil(math.Log2((hi-lo)/tolerance))), 1), solveRounds)
+
+	// Ограничение учитывает задачи, а не подстановки: раунды развернуты при разборе, и каждый из них
+	// повторяет все задачи тела
+	created := len(s.tasks)
+	charge := func() error {
+		err := s.reserve(len(s.tasks)-created, at)
+		created = len(s.tasks)
+		return err
+	}
+
+	var fDim units.Dimension
+	evaluate := func(x *models.Task, first bool) (*models.Task, error) {
+		value, dim, err := s.substitute(l, rpn, x, xDim)
+		if err != nil {
+			return nil, err
+		}
+		if first {
+			fDim = dim
+		} else if dim != fDim {
+			return nil, newParseError(CodeDimensionMismatch, fmt.Errorf("тело %s: %w: %s и %s", call.text, units.ErrMismatch, fDim, dim), at, "")
+		}
+		return value, charge()
+	}
+
+	fLo, err := evaluate(literal(lo), true)
+	if err != nil {
+		return nil, units.Dimension{}, err
+	}
+	fHi, err := evaluate(literal(hi), false)
+	if err != nil {
+		return nil, units.Dimension{}, err
+	}
+
+	// Значение тела в левом конце отрезка всегда одного знака с fLo, поэтому хранится только левый конец
+	left, width := literal(lo), hi-lo
+	for round := 1; round <= rounds; round++ {
+		width /= 2
+		mid := s.binary(operators.OpAdd, left, literal(width))
+		fMid, err := evaluate(mid, false)
+		if err != nil {
+			return nil, units.Dimension{}, err
+		}
+		same := s.binary(operators.OpGreater, s.binary(operators.OpMultiply, fMid, fLo), literal(0))
+		left = s.choice(same, mid, left)
+		s.iterate(left, round)
+		if err := charge(); err != nil {
+			return nil, units.Dimension{}, err
+		}
+	}
+	root := s.binary(operators.OpAdd, left, literal(width/2))
+
+	residual, err := evaluate(root, false)
+	if err != nil {
+		return nil, units.Dimension{}, err
+	}
+	s.estimate(call.text, "error", literal(width/2))
+	s.estimate(call.text, "residual", residual)
+	s.estimate(call.text, "bracketed", s.binary(operators.OpLessEqual, s.binary(operators.OpMultiply, fLo, fHi), literal(0)))
+	if err := charge(); err != nil {
+		return nil, units.Dimension{}, err
+	}
+
+	return root, xDim, nil
+}
+
+// unary создает задачу операции одного аргумента (см. operation).
+func (s *script) unary(symbol string, operand *models.Task) *models.Task {
+	operator, _ := operators.Lookup(symbol)
+	return s.operation(operator, []*models.Task{operand})
+}
+
+// binary создает задачу операции двух аргументов (см. operation).
+func (s *script) binary(symbol string, left, right *models.Task) *models.Task {
+	operator, _ := operators.Lookup(symbol)
+	return s.operation(operator, []*models.Task{left, right})
+}
+
+// choice создает задачу if(condition, then, otherwise), значения ветвей которой уже вычисляются другими задачами.
+// Если истинность условия известна при разборе, возвращается выбранное значение (см. choose).
+func (s *script) choice(condition, then, otherwise *models.Task) *models.Task {
+	operands := []*models.Task{condition, then, otherwise}
+	if value, ok := s.choose(operands); ok {
+		return value
+	}
+	operator, _ := operators.Lookup(operators.FnIf)
+	return s.operation(operator, operands)
+}
+
+// total создает дерево задач суммы значений (см. aggregate). nil, если значений нет.
+func (s *script) total(values []*models.Task) *models.Task {
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	}
+	operator, _ := operators.Lookup(operators.FnSum)
+	return s.aggregate(operator, values)
+}
+
+// weighted создает задачи взвешенной суммы квадратурной формулы: scale * (first + last + w_1*s_1 + w_2*s_2 + ...).
+// Отсутствующие (nil) суммы пропускаются.
+//
+// Args:
+//
+//	scale: float64 - Множитель суммы.
+//	first, last: *models.Task - Значения в концах отрезка (с весом 1).
+//	weights: []float64 - Веса сумм.
+//	sums: ...*models.Task - Суммы значений во внутренних точках.
+//
+// Returns:
+//
+//	*models.Task - Значение формулы.
+func (s *script) weighted(scale float64, first, last *models.Task, weights []float64, sums ...*models.Task) *models.Task {
+	terms := []*models.Task{first, last}
+	for i, sum := range sums {
+		if sum != nil {
+			terms = append(terms, s.binary(operators.OpMultiply, literal(weights[i]), sum))
+		}
+	}
+	return s.binary(operators.OpMultiply, literal(scale), s.total(terms))
+}
+
+// iterate отмечает задачу, завершающую итерацию integrate или solve, номером итерации. По отмеченным задачам
+// оркестратор показывает ход вычисления выражения. Числа и задачи других выражений не отмечаются.
+//
+// Args:
+//
+//	value: *models.Task - Значение, вычисленное итерацией.
+//	iteration: int - Номер итерации (начиная с 1).
+func (s *script) iterate(value *models.Task, iteration int) {
+	if value.Result == nil && value.Status != "skipped" && !s.external[value] && value.Iteration == 0 {
+		value.Iteration = iteration
+	}
+}
+
+// estimate сохраняет оценку погрешности или сходимости вызова integrate или solve: значение с именем
+// "integrate#1.error", где 1 - номер вызова функции в выражении. Оценки сохраняются только для вызовов вне тел
+// других вызовов, связывающих переменную: тело вызывается многократно.
+//
+// Args:
+//
+//	fn: string - Имя функции.
+//	name: string - Имя оценки.
+//	value: *models.Task - Значение оценки: число или задача.
+func (s *script) estimate(fn, name string, value *models.Task) {
+	if s.substituting > 0 || s.unreachable() {
+		return
+	}
+	if name == "error" {
+		s.calls[fn]++ // Первая оценка вызова
+	}
+	variable := s.variable(fmt.Sprintf("%s#%d.%s", fn, s.calls[fn], name), value)
+	variable.Estimate = true
+	s.variables = append(s.variables, variable)
+}
@@ -0,0 +1,169 @@
+package task_splitter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/OinkiePie/calc_3/pkg/operators"
+)
+
+// branch описывает ветвь вызова if в RPN инструкции: токены с start по end (включительно) вычисляют
+// значение, выбираемое при истинном (then) или ложном (else) условии.
+type branch struct {
+	start int  // Индекс первого токена ветви
+	end   int  // Индекс последнего токена ветви
+	value bool // Истинность условия, при которой выбирается ветвь
+}
+
+// guard - условие ветви if, задачи которой создаются в данный момент.
+type guard struct {
+	operand   *models.Task // Условие, записанное в if
+	condition *models.Task // Задача условия, от которой зависит выполнение задач ветви
+	value     bool         // Истинность условия, при которой выбирается ветвь
+	implied   bool         // Истинность условия известна при разборе: задачам ветви условие не передается
+	dead      bool         // Ветвь никогда не выбирается: ее задачи не создаются
+}
+
+// branches находит ветви вызовов if в RPN инструкции. Аргументы вызова - поддеревья, записанные
+// в RPN подряд перед токеном if: условие, значение при истине и значение при лжи.
+//
+// Args:
+//
+//	rpn: []token - Выражение в формате RPN.
+//	args: map[int][]int - Начала аргументов вызовов (см. arguments).
+//
+// Returns:
+//
+//	map[int]branch - Ветви по индексу их первого токена.
+func branches(rpn []token, args map[int][]int) map[int]branch {
+	found := make(map[int]branch)
+	for i, starts := range args {
+		if rpn[i].kind == tokenName && rpn[i].text == operators.FnIf && len(starts) == 3 {
+			found[starts[1]] = branch{start: starts[1], end: starts[2] - 1, value: true}
+			found[starts[2]] = branch{start: starts[2], end: i - 1, value: false}
+		}
+	}
+	return found
+}
+
+// truth возвращает истинность числа: любое ненулевое число истинно.
+func truth(value float64) bool {
+	return value != 0
+}
+
+// known возвращает истинность условия, если она известна при разборе: условие - число
+// или условие одной из ветвей, задачи которых создаются в данный момент.
+//
+// Args:
+//
+//	condition: *models.Task - Условие.
+//
+// Returns:
+//
+//	bool - Истинность условия.
+//	bool - true, если истинность известна.
+func (s *script) known(condition *models.Task) (bool, bool) {
+	if condition.Result != nil {
+		return truth(*condition.Result), true
+	}
+	for _, g := range s.guards {
+		if g.operand == condition || g.condition == condition {
+			return g.value, true
+		}
+	}
+	return false, false
+}
+
+// enter начинает ветвь if: задачи, созданные до leave, вычисляют значение ветви и выполняются,
+// только если истинность условия равна value. Ветвь, которая никогда не выбирается, не создает задач.
+// Условие - ссылка на ещё не вычисленное выражение ($42) - заменяется задачей "$42 != 0" этого выражения,
+// так как задачи ветви ожидают только задачи своего выражения.
+//
+// Args:
+//
+//	condition: *models.Task - Условие if.
+//	value: bool - Истинность условия, при которой выбирается ветвь.
+func (s *script) enter(condition *models.Task, value bool) {
+	g := guard{operand: condition, condition: condition, value: value}
+	if known, ok := s.known(condition); ok {
+		g.implied, g.dead = true, known != value
+	} else if s.external[condition] {
+		notEqual, _ := operators.Lookup(operators.OpNotEqual)
+		g.condition = s.operation(notEqual, []*models.Task{condition, literal(0)})
+	}
+	s.guards = append(s.guards, g)
+}
+
+// leave завершает последнюю начатую ветвь if.
+func (s *script) leave() {
+	s.guards = s.guards[:len(s.guards)-1]
+}
+
+// unreachable сообщает, создаются ли задачи в ветви, которая никогда не выбирается.
+func (s *script) unreachable() bool {
+	for _, g := range s.guards {
+		if g.dead {
+			return true
+		}
+	}
+	return false
+}
+
+// taskGuards возвращает условия выполнения задач, создаваемых в данный момент: условия всех ветвей,
+// истинность которых неизвестна при разборе.
+//
+// Returns:
+//
+//	[]models.TaskGuard - Условия с локальными индексами задач-условий.
+func (s *script) taskGuards() []models.TaskGuard {
+	var guards []models.TaskGuard
+	for _, g := range s.guards {
+		if !g.implied {
+			guards = append(guards, models.TaskGuard{ConditionIndex: int(g.condition.ID), Value: g.value})
+		}
+	}
+	return guards
+}
+
+// guardKey дополняет ключ задачи (см. taskKey) условиями ее выполнения: задача ветви if не может заменить
+// одинаковую задачу, которая вычисляется при любом условии.
+//
+// Args:
+//
+//	guards: []models.TaskGuard - Условия выполнения задачи.
+//
+// Returns:
+//
+//	string - Дополнение ключа, например "|g:1=true". Пустое для задачи без условий.
+func guardKey(guards []models.TaskGuard) string {
+	var key strings.Builder
+	for _, g := range guards {
+		fmt.Fprintf(&key, "|g:%d=%t", g.ConditionIndex, g.Value)
+	}
+	return key.String()
+}
+
+// choose выбирает значение if при разборе, если истинность условия известна (см. known).
+// Выбор не считается сверткой: задачи невыбранной ветви не создавались, а выбранное значение вычисляется
+// задачами ветви. Если условие и оба значения - числа, вызов if сворачивается по политике свертки, как и другие операции.
+//
+// Args:
+//
+//	operands: []*models.Task - Условие, значение при истине и значение при лжи.
+//
+// Returns:
+//
+//	*models.Task - Выбранное значение.
+//	bool - true, если значение выбрано.
+func (s *script) choose(operands []*models.Task) (*models.Task, bool) {
+	condition, then, otherwise := operands[0], operands[1], operands[2]
+	value, ok := s.known(condition)
+	if !ok || condition.Result != nil && then.Result != nil && otherwise.Result != nil {
+		return nil, false
+	}
+	if value {
+		return then, true
+	}
+	return otherwise, true
+}
@@ -0,0 +1,107 @@
+package task_splitter
+
+// Виды токенов в описании разбора выражения.
+const (
+	TokenNumber    = "number"    // число
+	TokenName      = "name"      // имя функции или переменной
+	TokenReference = "reference" // ссылка на другое выражение
+	TokenSymbol    = "symbol"    // оператор, скобка или разделитель
+)
+
+// Token представляет лексему выражения в описании разбора.
+type Token struct {
+	Kind   string // Вид токена (number, name, reference, symbol)
+	Text   string // Текст токена. Числа приведены к десятичной записи (единица измерения - через пробел), унарный минус обозначается "u-", вызов функции с переменным числом аргументов или функции пользователя в RPN - "max:4", "f:2"
+	Offset int    // Смещение токена от начала выражения в байтах
+	Length int    // Длина токена в байтах
+}
+
+// exportTokens преобразует внутренние токены разбора в токены описания разбора.
+//
+// Args:
+//
+//	tokens: []token - Токены разбора.
+//
+// Returns:
+//
+//	[]Token - Токены описания разбора.
+func exportTokens(tokens []token) []Token {
+	kinds := map[tokenKind]string{
+		tokenNumber:    TokenNumber,
+		tokenName:      TokenName,
+		tokenReference: TokenReference,
+		tokenSymbol:    TokenSymbol,
+		tokenCall:      TokenName,
+	}
+
+	exported := make([]Token, len(tokens))
+	for i, tok := range tokens {
+		exported[i] = Token{Kind: kinds[tok.kind], Text: tok.rpnText(), Offset: tok.offset, Length: tok.length}
+	}
+	return exported
+}
+
+// levels вычисляет уровень каждой задачи плана: задачи без зависимостей внутри выражения
+// находятся на уровне 1, остальные - на уровень выше самой глубокой зависимости.
+// Задача ветви if зависит и от задач своих условий. Зависимости от задач других выражений не учитываются.
+//
+// Returns:
+//
+//	[]int - Уровни задач в порядке списка задач.
+func (p *Plan) levels() []int {
+	levels := make([]int, len(p.Tasks))
+
+	// Задача может зависеть от задачи с большим индексом, если корень сценария был перенесен в конец
+	var level func(index int) int
+	level = func(index int) int {
+		if levels[index-1] > 0 {
+			return levels[index-1]
+		}
+		deepest := 0
+		for _, dep := range p.Tasks[index-1].DependencyIndexes {
+			if dep > 0 {
+				deepest = max(deepest, level(dep))
+			}
+		}
+		for _, guard := range p.Tasks[index-1].Guards {
+			deepest = max(deepest, level(guard.ConditionIndex))
+		}
+		levels[index-1] = deepest + 1
+		return levels[index-1]
+	}
+
+	for i := range p.Tasks {
+		level(i + 1)
+	}
+	return levels
+}
+
+// Depth возвращает длину критического пути: наибольшее количество задач, которые
+// должны быть вычислены последовательно.
+//
+// Returns:
+//
+//	int - Длина критического пути (0, если задач нет).
+func (p *Plan) Depth() int {
+	depth := 0
+	for _, level := range p.levels() {
+		depth = max(depth, level)
+	}
+	return depth
+}
+
+// Parallelism возвращает максимальную степень параллелизма: наибольшее количество задач одного уровня,
+// которые могут вычисляться агентами одновременно, если каждую задачу запускать сразу после готовности зависимостей.
+//
+// Returns:
+//
+//	int - Максимальное количество одновременно выполнимых задач (0, если задач нет).
+func (p *Plan) Parallelism() int {
+	counts := make(map[int]int)
+	parallelism := 0
+	for _, level := range p.levels() {
+		counts[level]++
+		parallelism = max(parallelism, counts[level])
+	}
+	return parallelism
+}
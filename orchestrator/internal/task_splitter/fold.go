@@ -0,0 +1,144 @@
+package task_splitter
+
+import (
+	"math"
+
+	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/OinkiePie/calc_3/pkg/operators"
+)
+
+// FoldPolicy определяет, какие операции над числами оркестратор вычисляет сам при разборе выражения,
+// не создавая для них задач.
+type FoldPolicy string
+
+// Политики свертки констант.
+const (
+	FoldNone    FoldPolicy = "none"    // все операции отправляются агентам
+	FoldUnary   FoldPolicy = "unary"   // вычисляется только унарный минус числа (-5)
+	FoldLiteral FoldPolicy = "literal" // вычисляются все операции, операнды которых известны
+	FoldCost    FoldPolicy = "cost"    // вычисляются операции, время выполнения которых не превышает порога
+)
+
+// OperationTime возвращает время выполнения операции в миллисекундах по ключу конфигурации (Operator.TimeKey).
+//
+// Args:
+//
+//	key: string - Ключ параметра времени выполнения операции.
+//
+// Returns:
+//
+//	int - Время выполнения операции в миллисекундах.
+//	bool - true, если время выполнения операции задано.
+type OperationTime func(key string) (int, bool)
+
+// foldable возвращает функцию, определяющую, можно ли вычислить операцию при разборе согласно политике свертки.
+// Неизвестная политика считается политикой FoldNone. В точном, десятичном, комплексном и интервальном режимах
+// операции не сворачиваются, так как свертка вычисляет их в float64.
+//
+// Args:
+//
+//	opts: Options - Параметры разбора.
+//
+// Returns:
+//
+//	func(*operators.Operator) bool - true, если операцию над известными операндами можно вычислить при разборе.
+func foldable(opts Options) func(*operators.Operator) bool {
+	if opts.Exact || opts.Precision > 0 || opts.Complex || opts.Interval {
+		return func(*operators.Operator) bool {
+			return false
+		}
+	}
+
+	switch opts.Fold {
+	case FoldUnary:
+		return func(operator *operators.Operator) bool {
+			return operator.Symbol == operators.OpUnaryMinus
+		}
+	case FoldLiteral:
+		return func(*operators.Operator) bool {
+			return true
+		}
+	case FoldCost:
+		return func(operator *operators.Operator) bool {
+			if opts.OperationTime == nil {
+				return false
+			}
+			timeMs, ok := opts.OperationTime(operator.TimeKey)
+			return ok && timeMs <= opts.FoldThreshold
+		}
+	default:
+		return func(*operators.Operator) bool {
+			return false
+		}
+	}
+}
+
+// fold вычисляет операцию при разборе, если все ее операнды - числа и политика свертки это разрешает.
+// Операции, завершающиеся ошибкой или бесконечным результатом, не сворачиваются: их ошибку сообщит агент,
+// как и без свертки. Операции в границах ряда сворачиваются при любой политике: количество подстановок тела
+// должно быть известно при разборе. Функции случайных чисел не сворачиваются никогда: их результат
+// определяется зерном задачи, которое задается при сохранении выражения.
+//
+// Args:
+//
+//	operator: *operators.Operator - Операция.
+//	operands: []*models.Task - Операнды операции.
+//
+// Returns:
+//
+//	float64 - Результат операции.
+//	bool - true, если операция свернута.
+func (s *script) fold(operator *operators.Operator, operands []*models.Task) (float64, bool) {
+	if operator.Random != nil {
+		return 0, false
+	}
+	args := make([]float64, len(operands))
+	for i, operand := range operands {
+		if operand.Result == nil {
+			return 0, false // Операнд вычисляется задачей
+		}
+		if s.constant > 0 && (operand.ImagResult != nil || operand.UpperResult != nil) {
+			return 0, false // Свертка вычисляет только действительные числа
+		}
+		args[i] = *operand.Result
+	}
+	if s.constant == 0 && !s.foldable(operator) {
+		return 0, false
+	}
+
+	value, err := operator.Eval(args...)
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, false
+	}
+	s.folded++
+	return value, true
+}
+
+// uncertain вычисляет при разборе интервал числа с погрешностью (3±0.1), если значение и погрешность - числа.
+// Такие операции не считаются свернутыми: интервал - запись числа, а не результат вычисления.
+// Отрицательная погрешность не сворачивается: ее ошибку сообщит агент.
+//
+// Args:
+//
+//	operator: *operators.Operator - Оператор погрешности.
+//	operands: []*models.Task - Значение и погрешность.
+//
+// Returns:
+//
+//	operators.Interval - Интервал числа.
+//	bool - true, если интервал вычислен.
+func (s *script) uncertain(operator *operators.Operator, operands []*models.Task) (operators.Interval, bool) {
+	args := make([]operators.Interval, len(operands))
+	for i, operand := range operands {
+		if operand.Result == nil {
+			return operators.Interval{}, false // Операнд вычисляется задачей
+		}
+		args[i] = bounds(operand)
+	}
+
+	value, err := operator.Interval(args...)
+	if err != nil || math.IsInf(value.Lo, 0) || math.IsInf(value.Hi, 0) {
+		return operators.Interval{}, false
+	}
+	return value, true
+}
@@ -0,0 +1,262 @@
+package task_splitter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/OinkiePie/calc_3/pkg/operators"
+	"github.com/OinkiePie/calc_3/pkg/units"
+)
+
+// Function описывает функцию пользователя: f(x, y) = x^2 + y^2.
+type Function struct {
+	Params []string // Имена параметров в порядке объявления
+	Body   string   // Тело функции - выражение от параметров: "x^2 + y^2"
+}
+
+// deferred - коды ошибок тела функции, которые зависят от значений и размерностей параметров и проверяются при вызове.
+var deferred = []string{CodeDimensionMismatch, CodeSeriesBounds, CodeCalculusBounds}
+
+// ParseFunction разбирает объявление функции пользователя: "f(x, y) = x^2 + y^2".
+//
+// Тело функции проверяется так же, как выражение: оно может использовать только параметры функции,
+// встроенные функции и функции пользователя из functions. Ссылки на выражения и сценарии в теле не допускаются.
+// Функция не может вызывать сама себя, в том числе через другие функции.
+// Размерности и значения параметров известны только при вызове, поэтому несовместимые размерности
+// и границы рядов, интегралов и уравнений, зависящие от параметров, не проверяются.
+//
+// Args:
+//
+//	definition: string - Объявление функции.
+//	functions: map[string]Function - Уже объявленные функции пользователя.
+//
+// Returns:
+//
+//	string - Имя функции.
+//	Function - Параметры и тело функции.
+//	error - Ошибка разбора (*ParseError) с положением ошибочного фрагмента в объявлении:
+//	    - CodeInvalidFunction при неверной записи имени или параметров функции
+//	    - CodeRecursiveFunction при рекурсивном вызове
+//	    - ошибки разбора тела функции
+func ParseFunction(definition string, functions map[string]Function) (string, Function, error) {
+	tokens, err := lex(definition)
+	if err != nil {
+		return "", Function{}, err
+	}
+
+	index := -1
+	for i, tok := range tokens {
+		if tok.kind == tokenSymbol && tok.text == assignmentOperator {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		at := token{}
+		if len(tokens) > 0 {
+			at = span(tokens)
+		}
+		return "", Function{}, newParseError(CodeInvalidFunction, errors.New("ожидается объявление функции: f(x) = выражение"), at, assignmentOperator)
+	}
+
+	name, params, err := parseSignature(tokens[:index], tokens[index])
+	if err != nil {
+		return "", Function{}, err
+	}
+
+	body := tokens[index+1:]
+	if len(body) == 0 {
+		return "", Function{}, newParseError(CodeInvalidSyntax, errRPN, tokens[index], "выражение")
+	}
+	fn := Function{Params: params, Body: strings.TrimSpace(definition[body[0].offset:])}
+
+	// Тело проверяется вместе с объявляемой функцией, чтобы обнаружить рекурсивные вызовы
+	all := make(map[string]Function, len(functions)+1)
+	for other, function := range functions {
+		all[other] = function
+	}
+	all[name] = fn
+
+	rpn, err := infixToRPN(body, false, all)
+	if err != nil {
+		return "", Function{}, err
+	}
+	if len(rpn) == 0 {
+		return "", Function{}, newParseError(CodeInvalidSyntax, errRPN, span(body), "выражение")
+	}
+
+	s := newScript(Options{Functions: all})
+	for _, param := range params {
+		s.scope[param] = &models.Task{Status: "pending"} // Значение параметра известно только при вызове
+	}
+	s.calling[name] = true
+	if _, _, err := s.rpnToTasks(rpn); err != nil {
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || !slices.Contains(deferred, parseErr.Code) {
+			return "", Function{}, err
+		}
+	}
+
+	return name, fn, nil
+}
+
+// parseSignature разбирает имя и параметры функции в объявлении: "f(x, y)".
+//
+// Args:
+//
+//	head: []token - Токены объявления до знака "=".
+//	assignment: token - Токен знака "=" (положение ошибки, если имени нет).
+//
+// Returns:
+//
+//	string - Имя функции.
+//	[]string - Имена параметров.
+//	error - *ParseError с кодом CodeInvalidFunction, если имя или параметры записаны неверно.
+func parseSignature(head []token, assignment token) (string, []string, error) {
+	invalid := func(err error, tok token, expected string) (string, []string, error) {
+		return "", nil, newParseError(CodeInvalidFunction, err, tok, expected)
+	}
+
+	if len(head) == 0 {
+		return invalid(errors.New("не указано имя функции"), assignment, "имя функции")
+	}
+	if head[0].kind != tokenName || !isName(head[0].text) {
+		return invalid(fmt.Errorf("недопустимое имя функции: %s", head[0].text), head[0], "имя функции")
+	}
+	if len(head) < 3 || head[1].text != operators.ParenLeft || head[len(head)-1].text != operators.ParenRight {
+		return invalid(errors.New("параметры функции записываются в скобках: f(x, y)"), span(head), "")
+	}
+
+	var params []string
+	seen := make(map[string]bool)
+	inner := head[2 : len(head)-1]
+	for i, tok := range inner {
+		if i%2 == 1 {
+			if tok.text != argumentSeparator {
+				return invalid(errInvalidSyntax, tok, argumentSeparator)
+			}
+			continue
+		}
+		if tok.kind != tokenName || !isName(tok.text) {
+			return invalid(fmt.Errorf("недопустимое имя параметра: %s", tok.text), tok, "имя параметра")
+		}
+		if seen[tok.text] {
+			return invalid(fmt.Errorf("параметр %s уже объявлен", tok.text), tok, "")
+		}
+		seen[tok.text] = true
+		params = append(params, tok.text)
+	}
+	if len(inner) > 0 && len(inner)%2 == 0 {
+		// Запятая после последнего параметра
+		return invalid(errInvalidSyntax, inner[len(inner)-1], "имя параметра")
+	}
+
+	return head[0].text, params, nil
+}
+
+// call подставляет тело функции пользователя в выражение: параметры связываются с аргументами вызова,
+// а тело преобразуется в задачи так же, как выражение. Одинаковые вызовы вычисляются общими задачами (см. taskKey).
+//
+// Args:
+//
+//	name: string - Имя функции.
+//	at: token - Фрагмент вызова в выражении (положение ошибок тела функции).
+//	args: []*models.Task - Аргументы вызова.
+//	dims: []units.Dimension - Размерности аргументов.
+//
+// Returns:
+//
+//	*models.Task - Значение вызова: корневая задача тела или число.
+//	units.Dimension - Размерность значения.
+//	error - Ошибка в теле функции (с положением вызова) или CodeRecursiveFunction при рекурсивном вызове.
+func (s *script) call(name string, at token, args []*models.Task, dims []units.Dimension) (*models.Task, units.Dimension, error) {
+	if s.calling[name] {
+		return nil, units.Dimension{}, newParseError(CodeRecursiveFunction, fmt.Errorf("функция %s вызывает сама себя", name), at, "")
+	}
+
+	body, err := s.body(name)
+	if err != nil {
+		return nil, units.Dimension{}, callError(name, at, err)
+	}
+
+	// Тело функции видит только ее параметры
+	fn := s.functions[name]
+	scope := make(map[string]*models.Task, len(fn.Params)+1)
+	dimensions := make(map[string]units.Dimension, len(fn.Params))
+	if s.complex {
+		scope[operators.ImaginaryUnit] = imaginary(1)
+	}
+	for i, param := range fn.Params {
+		scope[param] = args[i]
+		dimensions[param] = dims[i]
+	}
+
+	outerScope, outerDimensions := s.scope, s.dimensions
+	s.scope, s.dimensions = scope, dimensions
+	s.calling[name] = true
+	value, dim, err := s.rpnToTasks(body)
+	delete(s.calling, name)
+	s.scope, s.dimensions = outerScope, outerDimensions
+
+	if err != nil {
+		return nil, units.Dimension{}, callError(name, at, err)
+	}
+	return value, dim, nil
+}
+
+// body возвращает тело функции пользователя в RPN. Тело разбирается при первом вызове функции.
+//
+// Args:
+//
+//	name: string - Имя функции.
+//
+// Returns:
+//
+//	[]token - Тело функции в RPN.
+//	error - Ошибка разбора тела.
+func (s *script) body(name string) ([]token, error) {
+	if rpn, ok := s.bodies[name]; ok {
+		return rpn, nil
+	}
+
+	tokens, err := lex(s.functions[name].Body)
+	if err != nil {
+		return nil, err
+	}
+	rpn, err := infixToRPN(tokens, s.legacy, s.functions)
+	if err != nil {
+		return nil, err
+	}
+	if len(rpn) == 0 {
+		return nil, newParseError(CodeInvalidSyntax, errRPN, token{}, "выражение")
+	}
+	if s.balance {
+		rpn, _, _ = balanceRPN(rpn)
+	}
+
+	s.bodies[name] = rpn
+	return rpn, nil
+}
+
+// callError переносит ошибку разбора тела функции пользователя на ее вызов: положение ошибки
+// в теле функции не совпадает с положением в выражении.
+//
+// Args:
+//
+//	name: string - Имя функции.
+//	at: token - Фрагмент вызова в выражении.
+//	err: error - Ошибка разбора тела.
+//
+// Returns:
+//
+//	error - Ошибка с тем же кодом и положением вызова. Ошибки, не являющиеся *ParseError, возвращаются без изменений.
+func callError(name string, at token, err error) error {
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		return err
+	}
+	return newParseError(parseErr.Code, fmt.Errorf("функция %s: %w", name, err), at, "")
+}
@@ -0,0 +1,437 @@
+package task_splitter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/OinkiePie/calc_3/pkg/ast"
+	"github.com/OinkiePie/calc_3/pkg/operators"
+	"github.com/OinkiePie/calc_3/pkg/units"
+)
+
+// tokenKind определяет вид токена выражения.
+type tokenKind int
+
+const (
+	tokenNumber    tokenKind = iota // число, приведенное к десятичной записи
+	tokenName                       // имя функции или переменной
+	tokenReference                  // ссылка на результат другого выражения: $42
+	tokenSymbol                     // оператор, скобка или разделитель сценария
+	tokenCall                       // вызов функции пользователя. Задается при преобразовании в RPN
+)
+
+// token представляет лексему выражения и ее положение в исходной строке.
+type token struct {
+	kind   tokenKind // Вид токена
+	text   string    // Текст токена. Числа хранятся в десятичной записи, а операторы - в виде идентификатора из реестра
+	digits string    // Точная десятичная запись числа без разделителей разрядов (см. lexNumber). Пуста для остальных токенов
+	offset int       // Смещение токена от начала исходной строки в байтах
+	length int       // Длина токена в исходной строке в байтах
+	args   int       // Количество аргументов вызова функции. Задается при преобразовании в RPN
+}
+
+// rpnText возвращает текст токена в записи RPN: вызов функции с переменным числом аргументов
+// или функции пользователя дополняется количеством аргументов ("max:4", "f:2", см. ast.CallToken).
+func (tok token) rpnText() string {
+	if tok.kind == tokenCall {
+		return ast.CallToken(tok.text, tok.args)
+	}
+	if operator, ok := operators.Lookup(tok.text); ok && operator.Variadic && tok.args > 0 {
+		return ast.CallToken(tok.text, tok.args)
+	}
+	return tok.text
+}
+
+// isCall сообщает, является ли токен вызовом функции: встроенной или функции пользователя.
+func (tok token) isCall() bool {
+	return tok.kind == tokenCall || tok.kind == tokenName && operators.IsFunction(tok.text)
+}
+
+// symbolAliases задает типографские символы операторов, которые принимаются наравне с ASCII-записью.
+var symbolAliases = map[rune]string{
+	'×': operators.OpMultiply, // U+00D7 знак умножения
+	'÷': operators.OpDivide,   // U+00F7 знак деления
+	'−': operators.OpSubtract, // U+2212 знак минуса
+}
+
+// lex разбивает выражение на токены: числа, имена функций и переменных, ссылки, операторы и скобки.
+// Пробельные символы разделяют токены и в результат не попадают.
+//
+// Числа могут быть записаны в экспоненциальной форме (1e-3, 2.5E+10), в шестнадцатеричной (0xFF)
+// или двоичной (0b1010) системе и содержать разделители разрядов (1_000_000). Все они приводятся
+// к десятичной записи числа float64, а их точная запись сохраняется для режимов rational и decimal. Число с суффиксом i (2i, 0.5i) - мнимое: суффикс сохраняется в тексте токена.
+// Символы ×, ÷ и − заменяются операторами *, / и -.
+//
+// За числом может следовать единица измерения (5 km, 3 m/s, 9.8 m/s^2): она входит в токен числа
+// и отделяется от него одним пробелом: "5 km".
+//
+// Args:
+//
+//	expression: string - Строка, содержащая математическое выражение.
+//
+// Returns:
+//
+//	[]token - Токены выражения.
+//	error - *ParseError, если выражение содержит неизвестный символ или неверную запись числа.
+func lex(expression string) ([]token, error) {
+	runes := []rune(expression)
+	var tokens []token
+
+	// Смещения символов в байтах, чтобы положение токенов совпадало с исходной строкой
+	offsets := make([]int, 0, len(runes)+1)
+	for offset := range expression {
+		offsets = append(offsets, offset)
+	}
+	offsets = append(offsets, len(expression))
+
+	newToken := func(kind tokenKind, text string, start, end int) token {
+		return token{kind: kind, text: text, offset: offsets[start], length: offsets[end] - offsets[start]}
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case isDigit(r) || r == '.':
+			text, digits, end, err := lexNumber(runes, i)
+			if err != nil {
+				return nil, newParseError(CodeInvalidNumber, err, newToken(tokenNumber, "", err.start, err.end), "число")
+			}
+			text, end = lexUnit(runes, text, end)
+			number := newToken(tokenNumber, text, i, end)
+			number.digits = digits
+			tokens = append(tokens, number)
+			i = end
+		case r == '+' && startsOperand(tokens) && i+1 < len(runes) && (isDigit(runes[i+1]) || runes[i+1] == '.'):
+			// Знак "+" в начале числа: +5, 2*(+3)
+			text, digits, end, err := lexNumber(runes, i+1)
+			if err != nil {
+				return nil, newParseError(CodeInvalidNumber, err, newToken(tokenNumber, "", err.start, err.end), "число")
+			}
+			text, end = lexUnit(runes, text, end)
+			number := newToken(tokenNumber, text, i, end)
+			number.digits = digits
+			tokens = append(tokens, number)
+			i = end
+		case isNameStart(r):
+			j := i + 1
+			for j < len(runes) && (isNameStart(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			tokens = append(tokens, newToken(tokenName, string(runes[i:j]), i, j))
+			i = j
+		case string(r) == referencePrefix:
+			j := i + 1
+			for j < len(runes) && isDigit(runes[j]) {
+				j++
+			}
+			kind := tokenReference
+			if j == i+1 {
+				kind = tokenSymbol // "$" без ID выражения - синтаксическая ошибка
+			}
+			tokens = append(tokens, newToken(kind, string(runes[i:j]), i, j))
+			i = j
+		default:
+			symbol, length := matchSymbol(runes, i)
+			if symbol == "" {
+				err := fmt.Errorf("недопустимый символ '%c' в позиции %d", r, i+1)
+				return nil, newParseError(CodeInvalidCharacter, err, newToken(tokenSymbol, "", i, i+1), "")
+			}
+			tokens = append(tokens, newToken(tokenSymbol, symbol, i, i+length))
+			i += length
+		}
+	}
+
+	return tokens, nil
+}
+
+// lexNumber считывает число, начинающееся с указанной позиции, и приводит его к десятичной записи.
+//
+// Args:
+//
+//	runes: []rune - Символы выражения.
+//	start: int - Позиция первого символа числа.
+//
+// Returns:
+//
+//	string - Число в десятичной записи float64, для мнимого числа - с суффиксом i.
+//	string - Точная десятичная запись числа без разделителей разрядов и суффикса i, не округленная
+//	    до float64: 0.1000000000000000001, 1e-3, 0xFF -> 255.
+//	int - Позиция символа после числа.
+//	*numberError - Ошибка, если запись числа неверна или число не помещается в float64.
+func lexNumber(runes []rune, start int) (string, string, int, *numberError) {
+	var value float64
+	var digits string
+	end := start
+
+	if runes[start] == '0' && start+1 < len(runes) && strings.ContainsRune("xXbB", runes[start+1]) {
+		// Шестнадцатеричное или двоичное целое число
+		base := 16
+		if runes[start+1] == 'b' || runes[start+1] == 'B' {
+			base = 2
+		}
+		end = start + 2
+		for end < len(runes) && (isBaseDigit(runes[end], base) || runes[end] == '_') {
+			end++
+		}
+		literal := string(runes[start+2 : end])
+		if literal == "" || !validGrouping(literal, base) {
+			return "", "", end, errNumber(runes, start, end)
+		}
+		integer, err := strconv.ParseUint(strings.ReplaceAll(literal, "_", ""), base, 64)
+		if err != nil {
+			return "", "", end, errNumber(runes, start, end)
+		}
+		value = float64(integer)
+		digits = strconv.FormatUint(integer, 10)
+	} else {
+		// Десятичное число, возможно с дробной частью и порядком
+		for end < len(runes) && (isDigit(runes[end]) || runes[end] == '_' || runes[end] == '.') {
+			end++
+		}
+		if end < len(runes) && (runes[end] == 'e' || runes[end] == 'E') {
+			// За буквой e порядка должны следовать цифры (возможно, со знаком): 1e+ и 2e - ошибки
+			exp := end + 1
+			if exp < len(runes) && strings.ContainsRune("+-−", runes[exp]) {
+				exp++
+			}
+			if exp == len(runes) || !isDigit(runes[exp]) {
+				return "", "", exp, errExponent(runes, start, end, exp)
+			}
+			for exp < len(runes) && (isDigit(runes[exp]) || runes[exp] == '_') {
+				exp++
+			}
+			end = exp
+		}
+		literal := strings.ReplaceAll(string(runes[start:end]), "−", "-")
+		if !validGrouping(literal, 10) {
+			return "", "", end, errNumber(runes, start, end)
+		}
+		digits = strings.ReplaceAll(literal, "_", "")
+		var err error
+		value, err = strconv.ParseFloat(digits, 64)
+		if err != nil || math.IsInf(value, 0) {
+			return "", "", end, errNumber(runes, start, end)
+		}
+	}
+
+	text := strconv.FormatFloat(value, 'g', -1, 64)
+	if end < len(runes) && string(runes[end]) == operators.ImaginaryUnit &&
+		(end+1 == len(runes) || !(isNameStart(runes[end+1]) || unicode.IsDigit(runes[end+1]))) {
+		// Суффикс мнимого числа, но не начало имени: 2i, но не 2if
+		return text + operators.ImaginaryUnit, digits, end + 1, nil
+	}
+	return text, digits, end, nil
+}
+
+// lexUnit считывает единицу измерения, следующую за числом, и добавляет ее к записи числа.
+//
+// Единица - обозначения известных единиц (см. units.Lookup), соединенные "*" и "/" без пробелов,
+// с необязательными целыми показателями степени: km, m/s^2, kg*m/s^2. Обозначение, за которым продолжается
+// имя (m2, h_1), единицей не считается. Если за единицей следует "/" или "*" и не обозначение единицы
+// (5 km / 2 h), оператор относится к выражению.
+//
+// Args:
+//
+//	runes: []rune - Символы выражения.
+//	text: string - Запись числа.
+//	start: int - Позиция символа после числа.
+//
+// Returns:
+//
+//	string - Запись числа с единицей через пробел ("5 km") или исходная запись, если единицы нет.
+//	int - Позиция символа после единицы или start, если единицы нет.
+func lexUnit(runes []rune, text string, start int) (string, int) {
+	// atom возвращает позицию после обозначения единицы, начинающегося с позиции i, или i, если его нет
+	atom := func(i int) int {
+		j := i
+		for j < len(runes) && unicode.IsLetter(runes[j]) {
+			j++
+		}
+		if j == i || (j < len(runes) && (isNameStart(runes[j]) || unicode.IsDigit(runes[j]))) {
+			return i
+		}
+		if _, ok := units.Lookup(string(runes[i:j])); !ok {
+			return i
+		}
+		if j+1 < len(runes) && runes[j] == '^' {
+			k := j + 1
+			if runes[k] == '-' {
+				k++
+			}
+			digits := k
+			for k < len(runes) && isDigit(runes[k]) {
+				k++
+			}
+			if k > digits {
+				j = k
+			}
+		}
+		return j
+	}
+
+	begin := start
+	for begin < len(runes) && unicode.IsSpace(runes[begin]) {
+		begin++
+	}
+	end := atom(begin)
+	if end == begin {
+		return text, start
+	}
+	for end+1 < len(runes) && (runes[end] == '*' || runes[end] == '/') {
+		next := atom(end + 1)
+		if next == end+1 {
+			break
+		}
+		end = next
+	}
+	return text + " " + string(runes[begin:end]), end
+}
+
+// numberError представляет ошибку записи числа и положение ее ошибочного фрагмента.
+type numberError struct {
+	message string // Текст ошибки
+	start   int    // Позиция первого символа ошибочного фрагмента
+	end     int    // Позиция символа после ошибочного фрагмента
+}
+
+// Error возвращает текст ошибки записи числа.
+func (e *numberError) Error() string {
+	return e.message
+}
+
+// errNumber формирует ошибку неверной записи числа с указанием его позиции.
+//
+// Args:
+//
+//	runes: []rune - Символы выражения.
+//	start: int - Позиция первого символа числа.
+//	end: int - Позиция символа после числа.
+//
+// Returns:
+//
+//	*numberError - Ошибка с записью числа и его позицией (начиная с 1). Ошибочный фрагмент - все число.
+func errNumber(runes []rune, start, end int) *numberError {
+	return &numberError{
+		message: fmt.Sprintf("неверная запись числа %s в позиции %d", string(runes[start:end]), start+1),
+		start:   start,
+		end:     end,
+	}
+}
+
+// errExponent формирует ошибку порядка числа без цифр (1e+, 2e) с указанием позиции порядка.
+//
+// Args:
+//
+//	runes: []rune - Символы выражения.
+//	start: int - Позиция первого символа числа.
+//	exp: int - Позиция буквы e.
+//	end: int - Позиция символа после буквы e и знака порядка.
+//
+// Returns:
+//
+//	*numberError - Ошибка с записью числа и позицией порядка (начиная с 1). Ошибочный фрагмент - порядок.
+func errExponent(runes []rune, start, exp, end int) *numberError {
+	return &numberError{
+		message: fmt.Sprintf("неверная запись числа %s: нет цифр порядка в позиции %d", string(runes[start:end]), exp+1),
+		start:   exp,
+		end:     end,
+	}
+}
+
+// validGrouping проверяет, что каждый разделитель разрядов "_" стоит между двумя цифрами.
+//
+// Args:
+//
+//	literal: string - Запись числа.
+//	base: int - Основание системы счисления.
+//
+// Returns:
+//
+//	bool - true, если разделители расставлены корректно, иначе false.
+func validGrouping(literal string, base int) bool {
+	runes := []rune(literal)
+	for i, r := range runes {
+		if r != '_' {
+			continue
+		}
+		if i == 0 || i == len(runes)-1 || !isBaseDigit(runes[i-1], base) || !isBaseDigit(runes[i+1], base) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchSymbol находит самый длинный оператор, скобку или разделитель, начинающийся с указанной позиции.
+// Операторы берутся из реестра операций.
+//
+// Args:
+//
+//	runes: []rune - Символы выражения.
+//	i: int - Позиция символа.
+//
+// Returns:
+//
+//	string - Идентификатор оператора или пустая строка, если символ неизвестен.
+//	int - Количество символов выражения, занятых оператором.
+func matchSymbol(runes []rune, i int) (string, int) {
+	if symbol, ok := symbolAliases[runes[i]]; ok {
+		return symbol, 1
+	}
+
+	candidates := []string{operators.ParenLeft, operators.ParenRight, argumentSeparator, statementSeparator, assignmentOperator}
+	for _, op := range operators.All() {
+		if !op.Function && op.Symbol != operators.OpUnaryMinus {
+			candidates = append(candidates, op.Symbol)
+		}
+	}
+
+	rest := string(runes[i:])
+	best := ""
+	for _, candidate := range candidates {
+		if strings.HasPrefix(rest, candidate) && len(candidate) > len(best) {
+			best = candidate
+		}
+	}
+	return best, len([]rune(best))
+}
+
+// startsOperand проверяет, ожидается ли после уже считанных токенов операнд
+// (начало выражения, открывающая скобка, оператор или разделитель). Постфиксный оператор (5!)
+// завершает операнд.
+//
+// Args:
+//
+//	tokens: []token - Уже считанные токены.
+//
+// Returns:
+//
+//	bool - true, если следующий токен должен быть операндом.
+func startsOperand(tokens []token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	prev := tokens[len(tokens)-1]
+	return prev.kind == tokenSymbol && prev.text != operators.ParenRight && !operators.IsPostfix(prev.text)
+}
+
+// isDigit проверяет, является ли символ десятичной цифрой ASCII.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isBaseDigit проверяет, является ли символ цифрой системы счисления с указанным основанием (2, 10 или 16).
+func isBaseDigit(r rune, base int) bool {
+	switch base {
+	case 2:
+		return r == '0' || r == '1'
+	case 16:
+		return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+	default:
+		return isDigit(r)
+	}
+}
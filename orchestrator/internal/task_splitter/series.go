@@ -0,0 +1,258 @@
+package task_splitter
+
+import (
+	"fmt"
+	"maps"
+	"math"
+
+	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/OinkiePie/calc_3/pkg/operators"
+	"github.com/OinkiePie/calc_3/pkg/units"
+)
+
+// loop описывает вызов, связывающий переменную, в RPN инструкции: ряд sum(i, 1, 1000, i^2) записывается
+// токенами "i 1 1000 i 2 ^ sum:4", где i - переменная, 1 и 1000 - границы, а "i 2 ^" - тело,
+// а integrate(x^2, x, 0, 1, 10) - токенами "x 2 ^ x 0 1 10 integrate:5", где тело записано первым.
+// Остальные аргументы вызова вычисляются при разборе, а тело подставляется для значений переменной.
+type loop struct {
+	variable int // Индекс токена переменной
+	body     int // Индекс первого токена тела
+	end      int // Индекс токена, следующего за телом
+	call     int // Индекс токена вызова
+}
+
+// loops находит вызовы, связывающие переменную, в RPN инструкции:
+//   - записи рядов: вызовы sum и prod с четырьмя аргументами (см. operators.IsSeries), первый аргумент которых -
+//     имя, не связанное со значением. Вызов, первый аргумент которого - связанная переменная, остается вызовом
+//     агрегатной функции: sum(x, 1, 2, 3) при заданном x - сумма четырех чисел;
+//   - вызовы integrate и solve (см. isForm), второй аргумент которых - переменная. Переменная скрывает
+//     одноименную переменную выражения.
+//
+// Вызов, тело которого начинается с другого такого вызова, начинается с ним с одного токена:
+// возвращается внешний вызов, а внутренний находится при подстановке тела.
+//
+// Args:
+//
+//	rpn: []token - Выражение в формате RPN.
+//	args: map[int][]int - Начала аргументов вызовов (см. arguments).
+//
+// Returns:
+//
+//	map[int]loop - Вызовы по индексу их первого токена.
+//	error - Ошибка разбора CodeBoundVariable, если переменная integrate или solve не является именем.
+func (s *script) loops(rpn []token, args map[int][]int) (map[int]loop, error) {
+	found := make(map[int]loop)
+	for i, starts := range args {
+		tok := rpn[i]
+		var l loop
+		switch {
+		case tok.kind == tokenName && operators.IsSeries(tok.text, len(starts)):
+			variable := rpn[starts[0]]
+			if starts[1] != starts[0]+1 || variable.kind != tokenName {
+				continue // Первый аргумент - не одно имя
+			}
+			if _, function := operators.Lookup(variable.text); function {
+				continue
+			}
+			if _, bound := s.scope[variable.text]; bound {
+				continue
+			}
+			l = loop{variable: starts[0], body: starts[3], end: i, call: i}
+		case tok.kind == tokenCall && isForm(tok.text):
+			variable := rpn[starts[1]]
+			if starts[2] != starts[1]+1 || variable.kind != tokenName || !isName(variable.text) {
+				err := fmt.Errorf("второй аргумент %s должен быть именем переменной", tok.text)
+				return nil, newParseError(CodeBoundVariable, err, span(rpn[starts[1]:starts[2]]), "имя переменной")
+			}
+			l = loop{variable: starts[1], body: starts[0], end: starts[1], call: i}
+		default:
+			continue
+		}
+
+		start := min(l.variable, l.body)
+		if outer, ok := found[start]; !ok || outer.call < l.call {
+			found[start] = l
+		}
+	}
+	return found, nil
+}
+
+// reserve учитывает подстановки тела в общем ограничении Options.SeriesLimit.
+//
+// Args:
+//
+//	count: int - Количество подстановок.
+//	at: token - Фрагмент вызова в выражении (положение ошибки).
+//
+// Returns:
+//
+//	error - Ошибка разбора CodeSeriesLimit, если подстановок тела в выражении больше ограничения.
+func (s *script) reserve(count int, at token) error {
+	s.expanded += count
+	if s.seriesLimit > 0 && s.expanded > s.seriesLimit {
+		return newParseError(CodeSeriesLimit, fmt.Errorf("выражение превышает %d подстановок тела", s.seriesLimit), at, "")
+	}
+	return nil
+}
+
+// substitute создает задачи тела вызова при значении его переменной. Тело видит переменную
+// вместо одноименной переменной выражения.
+//
+// Args:
+//
+//	l: loop - Вызов.
+//	rpn: []token - Выражение в формате RPN, содержащее вызов.
+//	value: *models.Task - Значение переменной: число или задача.
+//	dim: units.Dimension - Размерность переменной.
+//
+// Returns:
+//
+//	*models.Task - Значение тела: задача или число.
+//	units.Dimension - Размерность значения тела.
+//	error - Ошибка в теле.
+func (s *script) substitute(l loop, rpn []token, value *models.Task, dim units.Dimension) (*models.Task, units.Dimension, error) {
+	variable := rpn[l.variable].text
+	outerScope, outerDimensions := s.scope, s.dimensions
+	s.scope, s.dimensions = maps.Clone(outerScope), maps.Clone(outerDimensions)
+	s.scope[variable], s.dimensions[variable] = value, dim
+	s.substituting++
+	defer func() {
+		s.scope, s.dimensions = outerScope, outerDimensions
+		s.substituting--
+	}()
+
+	return s.rpnToTasks(rpn[l.body:l.end])
+}
+
+// probe разбирает тело вызова, не создавая задач, как в ветви if, которая никогда не выбирается:
+// находит размерность значения тела, когда его задачи не нужны.
+//
+// Args:
+//
+//	l: loop - Вызов.
+//	rpn: []token - Выражение в формате RPN, содержащее вызов.
+//	value: *models.Task - Значение переменной.
+//	dim: units.Dimension - Размерность переменной.
+//
+// Returns:
+//
+//	units.Dimension - Размерность значения тела.
+//	error - Ошибка в теле.
+func (s *script) probe(l loop, rpn []token, value *models.Task, dim units.Dimension) (units.Dimension, error) {
+	s.guards = append(s.guards, guard{dead: true})
+	defer s.leave()
+
+	_, bodyDim, err := s.substitute(l, rpn, value, dim)
+	return bodyDim, err
+}
+
+// expand создает задачи вызова, связывающего переменную: ряда (см. series), интеграла (см. integrate)
+// или корня уравнения (см. solve).
+//
+// Args:
+//
+//	l: loop - Вызов.
+//	rpn: []token - Выражение в формате RPN, содержащее вызов.
+//	operands: []*models.Task - Значения аргументов вызова, кроме тела и переменной.
+//	dims: []units.Dimension - Размерности этих аргументов.
+//
+// Returns:
+//
+//	*models.Task - Значение вызова: задача или число.
+//	units.Dimension - Размерность значения.
+//	error - Ошибка разбора.
+func (s *script) expand(l loop, rpn []token, operands []*models.Task, dims []units.Dimension) (*models.Task, units.Dimension, error) {
+	switch rpn[l.call].text {
+	case fnIntegrate:
+		return s.integrate(l, rpn, operands, dims)
+	case fnSolve:
+		return s.solve(l, rpn, operands, dims)
+	default:
+		return s.series(l, rpn, operands, dims)
+	}
+}
+
+// series разворачивает ряд: тело подставляется для каждого целого значения переменной от нижней границы
+// до верхней, а значения тела суммируются или перемножаются деревом задач (см. aggregate), которое агенты
+// вычисляют параллельно. Пустой ряд (верхняя граница меньше нижней) - число: 0 для суммы и 1 для произведения.
+//
+// Args:
+//
+//	l: loop - Запись ряда.
+//	rpn: []token - Выражение в формате RPN, содержащее запись.
+//	bounds: []*models.Task - Нижняя и верхняя границы.
+//	boundDims: []units.Dimension - Размерности границ.
+//
+// Returns:
+//
+//	*models.Task - Значение ряда: задача или число.
+//	units.Dimension - Размерность значения.
+//	error - Ошибка разбора:
+//	    - CodeSeriesBounds, если граница не является безразмерным целым числом, известным при разборе
+//	    - CodeSeriesLimit, если подстановок тела больше Options.SeriesLimit
+//	    - ошибка в теле ряда
+func (s *script) series(l loop, rpn []token, bounds []*models.Task, boundDims []units.Dimension) (*models.Task, units.Dimension, error) {
+	call := rpn[l.call]
+	at := span(rpn[l.variable : l.call+1])
+	operator, _ := operators.Lookup(call.text)
+
+	var limits [2]int
+	for i, bound := range bounds {
+		plain := bound.ImagResult == nil && bound.UpperResult == nil && boundDims[i].Dimensionless()
+		if bound.Result == nil || !plain || *bound.Result != math.Trunc(*bound.Result) || math.Abs(*bound.Result) > math.MaxInt32 {
+			return nil, units.Dimension{}, newParseError(CodeSeriesBounds,
+				fmt.Errorf("границы ряда %s должны быть целыми числами, известными при разборе", call.text), at, "")
+		}
+		limits[i] = int(*bound.Result)
+	}
+	lo, hi := limits[0], limits[1]
+
+	if hi < lo || s.unreachable() {
+		// Задачи тела не нужны: пустой ряд - число, а ряд ветви, которая никогда не выбирается, не вычисляется
+		bodyDim, err := s.probe(l, rpn, literal(float64(lo)), units.Dimension{})
+		if err != nil {
+			return nil, units.Dimension{}, err
+		}
+		dim := bodyDim
+		if call.text == operators.FnProd {
+			var ok bool
+			if dim, ok = bodyDim.Pow(float64(max(hi-lo+1, 0))); !ok {
+				return nil, units.Dimension{}, newParseError(CodeDimensionMismatch, fmt.Errorf("ряд %s: %w", call.text, units.ErrExponent), at, "")
+			}
+		}
+		switch {
+		case s.unreachable():
+			return &models.Task{Status: "skipped"}, dim, nil
+		case call.text == operators.FnProd:
+			s.folded++ // Значение пустого ряда известно при разборе
+			return literal(1), dim, nil
+		default:
+			s.folded++
+			return literal(0), dim, nil
+		}
+	}
+	if err := s.reserve(hi-lo+1, at); err != nil {
+		return nil, units.Dimension{}, err
+	}
+
+	values := make([]*models.Task, 0, hi-lo+1)
+	dims := make([]units.Dimension, 0, hi-lo+1)
+	for k := lo; k <= hi; k++ {
+		value, dim, err := s.substitute(l, rpn, literal(float64(k)), units.Dimension{})
+		if err != nil {
+			return nil, units.Dimension{}, err
+		}
+		values = append(values, value)
+		dims = append(dims, dim)
+	}
+
+	rule := operator.Dimension
+	if rule == nil {
+		rule = units.Dimensionless
+	}
+	dim, err := rule(dims, nil)
+	if err != nil {
+		return nil, units.Dimension{}, newParseError(CodeDimensionMismatch, fmt.Errorf("ряд %s: %w", call.text, err), at, "")
+	}
+	return s.aggregate(operator, values), dim, nil
+}
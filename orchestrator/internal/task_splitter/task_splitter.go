@@ -2,12 +2,18 @@ package task_splitter
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 	"unicode"
 
+	"github.com/OinkiePie/calc_3/pkg/ast"
+	"github.com/OinkiePie/calc_3/pkg/logger"
 	"github.com/OinkiePie/calc_3/pkg/models"
 	"github.com/OinkiePie/calc_3/pkg/operators"
+	"github.com/OinkiePie/calc_3/pkg/units"
 )
 
 var (
@@ -18,40 +24,904 @@ var (
 	errNotEnoughOperands = errors.New("недостаточно операндов")
 	errUnaryMinus        = errors.New("недостаточно операндов для унарного минуса")
 	errRPN               = errors.New("не удалось преобразовать RPN")
+	errSeparator         = errors.New("запятая вне вызова функции")
+	errEmptyArgument     = errors.New("пустой аргумент функции")
 )
 
+// Коды ошибок разбора выражения. Передаются клиенту в поле code ответа.
+const (
+	CodeInvalidCharacter     = "invalid_character"     // неизвестный символ
+	CodeInvalidNumber        = "invalid_number"        // неверная запись числа
+	CodeInvalidSyntax        = "invalid_syntax"        // токен недопустим в этом месте
+	CodeUnopenedParen        = "unopened_paren"        // закрывающая скобка без открывающей
+	CodeUnclosedParen        = "unclosed_paren"        // открывающая скобка без закрывающей
+	CodeNotEnoughOperands    = "not_enough_operands"   // оператору не хватает операндов
+	CodeUnaryMinus           = "unary_minus_operand"   // унарному минусу не хватает операнда
+	CodeMissingOperator      = "missing_operator"      // операнды не связаны оператором
+	CodeNoOperators          = "no_operators"          // выражение не содержит ни одной операции
+	CodeInvalidName          = "invalid_variable_name" // недопустимое имя переменной
+	CodeUnknownVariable      = "unknown_variable"      // переменная не связана со значением
+	CodeDuplicateVariable    = "duplicate_variable"    // переменная уже определена
+	CodeReferenceUnavailable = "reference_unavailable" // ссылки на выражения запрещены
+	CodeInexactOperation     = "inexact_operation"     // операция не поддерживает точный режим
+	CodeUnsupportedOperation = "unsupported_operation" // операция не поддерживает десятичный, комплексный или интервальный режим
+	CodeImaginaryUnavailable = "imaginary_unavailable" // мнимое число вне комплексного режима
+	CodeIntervalUnavailable  = "interval_unavailable"  // погрешность вне интервального режима
+	CodeUnitUnavailable      = "unit_unavailable"      // единица измерения вне режима float
+	CodeUnknownUnit          = "unknown_unit"          // неизвестная единица измерения
+	CodeDimensionMismatch    = "dimension_mismatch"    // несовместимые размерности операндов
+	CodeArgumentCount        = "argument_count"        // количество аргументов не подходит функции
+	CodeInvalidFunction      = "invalid_function"      // неверное объявление функции пользователя
+	CodeRecursiveFunction    = "recursive_function"    // функция пользователя вызывает сама себя
+	CodeSeriesBounds         = "series_bounds"         // границы ряда не являются целыми числами, известными при разборе
+	CodeSeriesLimit          = "series_limit"          // ряды, интегралы и уравнения выражения превышают допустимое количество подстановок тела
+	CodeBoundVariable        = "bound_variable"        // переменная integrate или solve не является именем
+	CodeCalculusBounds       = "calculus_bounds"       // пределы integrate или solve не известны при разборе или недопустимы
+)
+
+// ParseError описывает ошибку разбора выражения и положение ошибочного фрагмента в исходной строке.
+type ParseError struct {
+	Code     string `json:"code"`               // Код ошибки
+	Message  string `json:"error"`              // Текст ошибки
+	Offset   int    `json:"offset"`             // Смещение ошибочного токена от начала выражения в байтах
+	Length   int    `json:"length"`             // Длина ошибочного токена в байтах
+	Expected string `json:"expected,omitempty"` // Подсказка, какой токен ожидался
+	err      error  // Исходная ошибка
+}
+
+// Error возвращает текст ошибки разбора.
+func (e *ParseError) Error() string {
+	return e.Message
+}
+
+// Unwrap возвращает исходную ошибку, чтобы ее можно было проверить через errors.Is.
+func (e *ParseError) Unwrap() error {
+	return e.err
+}
+
+// newParseError создает ошибку разбора для указанного токена.
+//
+// Args:
+//
+//	code: string - Код ошибки.
+//	err: error - Исходная ошибка с текстом для пользователя.
+//	tok: token - Ошибочный токен.
+//	expected: string - Подсказка, какой токен ожидался (может быть пустой).
+//
+// Returns:
+//
+//	*ParseError - Ошибка разбора.
+func newParseError(code string, err error, tok token, expected string) *ParseError {
+	return &ParseError{
+		Code:     code,
+		Message:  err.Error(),
+		Offset:   tok.offset,
+		Length:   tok.length,
+		Expected: expected,
+		err:      err,
+	}
+}
+
+// span возвращает токен, охватывающий все указанные токены.
+//
+// Args:
+//
+//	tokens: []token - Непустой срез токенов.
+//
+// Returns:
+//
+//	token - Токен от начала самого левого до конца самого правого токена.
+func span(tokens []token) token {
+	start, end := tokens[0].offset, tokens[0].offset+tokens[0].length
+	for _, tok := range tokens[1:] {
+		start = min(start, tok.offset)
+		end = max(end, tok.offset+tok.length)
+	}
+	return token{offset: start, length: end - start}
+}
+
+// statement представляет инструкцию сценария.
+type statement struct {
+	tokens []token // Токены инструкции
+	offset int     // Смещение начала инструкции в байтах
+}
+
+// Разделители сценария.
+const (
+	argumentSeparator  = ","  // разделитель аргументов вызова функции: max(3, 7)
+	statementSeparator = ";"  // разделитель инструкций сценария
+	assignmentOperator = "="  // оператор присваивания имени значения инструкции
+	referencePrefix    = "$"  // префикс ссылки на результат другого выражения: $42
+	conversionKeyword  = "in" // перевод результата в единицу измерения: "5 km / 2 h in km/h"
+)
+
+// aggregateWidth - наибольшее количество аргументов задачи функции с переменным числом аргументов.
+// Вызов с большим количеством аргументов разбивается на дерево задач (см. script.aggregate).
+const aggregateWidth = 8
+
+// Reference описывает выражение, на которое ссылается разбираемое выражение ($42).
+type Reference struct {
+	// Result - Результат выражения, если оно уже вычислено, иначе nil.
+	Result *float64
+	// ExactResult - Точный результат вычисленного выражения: дробь "num/den" или десятичная запись.
+	// nil, если выражение вычислялось в float64.
+	ExactResult *string
+	// ImagResult - Мнимая часть результата вычисленного выражения режима complex. nil для остальных режимов.
+	ImagResult *float64
+	// LowerResult и UpperResult - Границы интервала результата вычисленного выражения режима interval
+	// (Result - середина интервала). nil для остальных режимов.
+	LowerResult *float64
+	UpperResult *float64
+	// Unit - Единица измерения результата выражения (см. Plan.Unit). Пустая, если результат безразмерен.
+	Unit string
+	// TaskID - ID корневой задачи выражения, если оно ещё вычисляется.
+	TaskID int64
+}
+
+// ReferenceResolver возвращает выражение с указанным ID, на которое ссылается разбираемое выражение.
+//
+// Args:
+//
+//	id: int64 - ID выражения, на которое указывает ссылка.
+//
+// Returns:
+//
+//	*Reference - Результат выражения, если оно уже вычислено, или ID его корневой задачи.
+//	error - Ошибка, если ссылка недопустима (выражение не найдено, принадлежит другому пользователю и т.п.).
+type ReferenceResolver func(id int64) (*Reference, error)
+
+// Options задает дополнительные параметры разбора выражения.
+type Options struct {
+	// Variables - Значения именованных переменных, подставляемые в выражение как числовые аргументы.
+	Variables map[string]float64
+	// ResolveReference - Функция получения значений ссылок на другие выражения ($42). Если nil, ссылки запрещены.
+	ResolveReference ReferenceResolver
+	// LegacyPrecedence - Прежние правила приоритета: левоассоциативное возведение в степень
+	// и унарный минус, выталкивающий операторы из стека.
+	LegacyPrecedence bool
+	// BalanceChains - Перестраивать цепочки ассоциативных операций (1+2+3+...) в сбалансированные деревья,
+	// чтобы их задачи вычислялись параллельно.
+	BalanceChains bool
+	// Fold - Политика свертки констант: какие операции над числами вычисляются при разборе без создания задач.
+	Fold FoldPolicy
+	// FoldThreshold - Наибольшее время выполнения операции в миллисекундах, при котором она сворачивается (для FoldCost).
+	FoldThreshold int
+	// OperationTime - Функция получения времени выполнения операции (для FoldCost).
+	OperationTime OperationTime
+	// Exact - Точный режим: задачи вычисляются в рациональной арифметике, а их числовые аргументы
+	// дополнительно передаются дробями "num/den". Операции без точного вычисления (sqrt, sin, ...) запрещены,
+	// а свертка констант отключается.
+	Exact bool
+	// Precision - Точность десятичного режима в значащих цифрах. Если больше 0, задачи вычисляются
+	// в числах произвольной точности, их числовые аргументы дополнительно передаются десятичными записями,
+	// а свертка констант отключается. 0 - десятичный режим выключен.
+	Precision int
+	// Complex - Комплексный режим: задачи вычисляются в комплексных числах, а их числовые аргументы
+	// дополнительно передаются мнимыми частями. Допускаются мнимые числа (2i) и мнимая единица i,
+	// если имя i не задано в Variables. Свертка констант отключается.
+	Complex bool
+	// Interval - Интервальный режим: задачи вычисляют интервалы, гарантированно содержащие результат,
+	// а их числовые аргументы дополнительно передаются верхними границами (Args содержат нижние).
+	// Допускается оператор погрешности: 3±0.1 - интервал [2.9, 3.1]. Числа, не представимые точно в float64 (0.1),
+	// передаются интервалами, содержащими их точное значение. Свертка констант отключается.
+	Interval bool
+	// Functions - Функции пользователя по имени (см. ParseFunction). Вызов функции пользователя заменяется
+	// задачами ее тела, в котором параметры связаны с аргументами вызова.
+	Functions map[string]Function
+	// SeriesLimit - Наибольшее общее количество подстановок тела рядов (sum(i, 1, 1000, i^2)) и integrate в выражении.
+	// solve учитывается количеством созданных задач: его раунды развернуты при разборе, и каждый повторяет задачи тела.
+	// 0 - без ограничения.
+	SeriesLimit int
+}
+
+// Plan представляет результат разбора выражения или сценария.
+type Plan struct {
+	// Tasks - Список задач для вычисления. Последняя задача является корнем: ее результат - результат выражения.
+	Tasks []*models.Task
+	// Variables - Именованные промежуточные значения сценария в порядке присваивания.
+	Variables []*models.ExpressionVariable
+	// Tokens - Токены выражения.
+	Tokens []Token
+	// RPN - Выражения инструкций сценария в обратной польской нотации.
+	RPN [][]Token
+	// Folded - Количество операций, вычисленных при разборе (свернутых). Остальные операции
+	// отправляются агентам: их количество равно len(Tasks).
+	Folded int
+	// Result - Результат выражения, если все его операции свернуты. В этом случае задач нет.
+	Result *float64
+	// AST - Деревья инструкций сценария до перестроения цепочек и свертки.
+	AST ast.Script
+	// Canonical - Каноническая запись выражения (см. ast.Canonical). Равные по записи выражения
+	// ("x + 2*3" и "3 * 2 + x") имеют одинаковую каноническую запись.
+	Canonical string
+	// Unit - Единица измерения результата: заданная после "in" или составленная из основных единиц СИ ("m/s").
+	// Пустая для безразмерного результата.
+	Unit string
+}
+
+// script хранит состояние разбора сценария: созданные задачи и связанные с именами значения.
+// Значение имени - задача-операнд: либо число (Result задан), либо ссылка на задачу по ее локальному индексу.
+//
+// Ссылки на ещё не вычисленные выражения представлены внешними операндами: ID такого операнда -
+// ID корневой задачи другого выражения в базе данных, а не локальный индекс.
+type script struct {
+	tasks     []*models.Task
+	scope     map[string]*models.Task
+	variables []*models.ExpressionVariable
+	resolve   ReferenceResolver
+	refs      map[int64]*models.Task         // Значения ссылок по ID выражения
+	refUnits  map[int64]units.Unit           // Единицы измерения результатов ссылок по ID выражения
+	external  map[*models.Task]bool          // Внешние операнды
+	shared    map[string]*models.Task        // Созданные задачи по ключу операции и операндов (см. taskKey)
+	foldable  func(*operators.Operator) bool // Можно ли вычислить операцию при разборе (см. fold)
+	folded    int                            // Количество свернутых операций
+	exact     bool                           // Точный режим (см. Options.Exact)
+	precision int                            // Точность десятичного режима (см. Options.Precision)
+	complex   bool                           // Комплексный режим (см. Options.Complex)
+	interval  bool                           // Интервальный режим (см. Options.Interval)
+
+	dimensional bool                       // Допускаются единицы измерения (только в режиме float)
+	dimensions  map[string]units.Dimension // Размерности значений имен (переменные из Options безразмерны)
+
+	functions map[string]Function // Функции пользователя (см. Options.Functions)
+	bodies    map[string][]token  // Тела функций пользователя в RPN, разобранные при первом вызове
+	calling   map[string]bool     // Функции пользователя, тела которых подставляются в данный момент
+	legacy    bool                // Прежние правила приоритета (см. Options.LegacyPrecedence)
+	balance   bool                // Перестраивать цепочки операций (см. Options.BalanceChains)
+
+	guards []guard // Условия ветвей if, задачи которых создаются в данный момент (см. enter)
+
+	constant     int            // Количество вычисляемых границ рядов и пределов integrate и solve: операции в них сворачиваются при любой политике свертки
+	seriesLimit  int            // Наибольшее количество подстановок тела (см. Options.SeriesLimit)
+	expanded     int            // Количество выполненных подстановок тела
+	substituting int            // Количество тел, подставляемых в данный момент (см. substitute)
+	calls        map[string]int // Количество вызовов integrate и solve по имени функции (см. estimate)
+}
+
+// newScript создает состояние разбора сценария с параметрами opts. Переменные из opts не связываются.
+//
+// Args:
+//
+//	opts: Options - Параметры разбора.
+//
+// Returns:
+//
+//	*script - Состояние разбора без задач.
+func newScript(opts Options) *script {
+	s := &script{
+		scope:     make(map[string]*models.Task, len(opts.Variables)),
+		resolve:   opts.ResolveReference,
+		refs:      make(map[int64]*models.Task),
+		refUnits:  make(map[int64]units.Unit),
+		external:  make(map[*models.Task]bool),
+		shared:    make(map[string]*models.Task),
+		foldable:  foldable(opts),
+		exact:     opts.Exact,
+		precision: opts.Precision,
+		complex:   opts.Complex,
+		interval:  opts.Interval,
+
+		dimensional: !opts.Exact && opts.Precision == 0 && !opts.Complex && !opts.Interval,
+		dimensions:  make(map[string]units.Dimension),
+
+		functions: opts.Functions,
+		bodies:    make(map[string][]token),
+		calling:   make(map[string]bool),
+		legacy:    opts.LegacyPrecedence,
+		balance:   opts.BalanceChains,
+
+		seriesLimit: opts.SeriesLimit,
+		calls:       make(map[string]int),
+	}
+	if s.complex {
+		s.scope[operators.ImaginaryUnit] = imaginary(1) // Переменные с именем i заменяют мнимую единицу
+	}
+	return s
+}
+
 // ParseExpression разбирает математическое выражение и преобразует его в набор вычислительных задач.
 //
+// Выражение может быть сценарием из нескольких инструкций, разделенных ";", например
+// "r = 5; area = 3.14159*r^2; area*2". Инструкция вида "имя = выражение" связывает имя со значением,
+// которое могут использовать следующие инструкции. Зависимости между инструкциями выражаются через
+// зависимости задач, поэтому независимые инструкции вычисляются параллельно.
+// Значение последней инструкции является результатом выражения.
+//
+// Политика свертки Fold позволяет вычислить операции над числами (2*3) сразу при разборе, не создавая задач.
+// Если свернуты все операции выражения, задач нет, а результат возвращается в Plan.Result.
+//
+// По инструкциям строятся деревья выражений (Plan.AST) и каноническая запись выражения (Plan.Canonical).
+//
+// Если включен параметр BalanceChains, цепочки одинаковых ассоциативных операций (1+2+3+...+1000)
+// перестраиваются в сбалансированные деревья, и глубина их вычисления уменьшается с O(n) до O(log n).
+//
+// Числа могут иметь единицы измерения: "5 km / 2 h + 3 m/s". Размерности операндов проверяются при разборе
+// (сложение метров с секундами - ошибка), а числа переводятся в основные единицы СИ, поэтому задачи вычисляют
+// результат в основных единицах (Plan.Unit). Последняя инструкция может заканчиваться переводом результата
+// в другую единицу той же размерности: "... in km/h" - перевод выполняется делением на значение единицы.
+// Единицы измерения допускаются только в режиме float.
+//
+// Выражение может вызывать функции пользователя из Options.Functions: f(2, 3) + 1. Тело функции
+// подставляется в выражение, поэтому ее задачи не отличаются от задач записанного вручную выражения.
+//
 // Args:
 //
-//	expression: string - Математическое выражение в инфиксной нотации
+//	expression: string - Математическое выражение в инфиксной нотации или сценарий
+//	opts: Options - Параметры разбора (значения переменных)
 //
 // Returns:
 //
-//	[]*models.Task - Список задач для вычисления выражения
-//	error - Ошибка парсинга:
+//	*Plan - Задачи для вычисления выражения и именованные промежуточные значения
+//	error - Ошибка парсинга (*ParseError, кроме ошибок получения ссылок):
+//	    - ошибки из lex при неизвестном символе или неверной записи числа
+//	    - ошибки проверки имен переменных и присваиваний
 //	    - ошибки из infixToRPN при невалидном выражении
-//	    - ошибки из rpnToTasks при создании задач
-func ParseExpression(expression string) ([]*models.Task, error) {
+//	    - ошибки из rpnToTasks при создании задач, несвязанной переменной или несовместимых размерностях
+//	    - ошибки неизвестной единицы измерения или перевода результата в единицу другой размерности
+func ParseExpression(expression string, opts Options) (*Plan, error) {
 
-	expression = strings.ReplaceAll(expression, " ", "")
-
-	rpn, err := infixToRPN(expression)
+	tokens, err := lex(expression)
 	if err != nil {
 		return nil, err
 	}
 
-	var tasks []*models.Task
-	tasks, err = rpnToTasks(rpn)
+	s := newScript(opts)
+	for name, value := range opts.Variables {
+		if !isName(name) {
+			// Имя передано вне выражения, поэтому положение ошибки не указывается
+			return nil, newParseError(CodeInvalidName, fmt.Errorf("недопустимое имя переменной: %s", name), token{}, "имя переменной")
+		}
+		s.scope[name] = literal(value)
+	}
+
+	statements := splitStatements(tokens)
+	if len(statements) > 1 && len(statements[len(statements)-1].tokens) == 0 {
+		statements = statements[:len(statements)-1] // Допускаем ";" после последней инструкции
+	}
+
+	var root *models.Task
+	var dim units.Dimension
+	var body []token
+	var target *token // Единица измерения, в которую переводится результат
+	var unit units.Unit
+	var rpns [][]Token
+	var tree ast.Script
+	for i, statement := range statements {
+		var name *token
+		name, body, err = splitAssignment(statement.tokens)
+		if err != nil {
+			return nil, err
+		}
+		if i == len(statements)-1 {
+			body, target = splitConversion(body)
+		}
+
+		rpn, err := infixToRPN(body, opts.LegacyPrecedence, opts.Functions)
+		if err != nil {
+			return nil, err
+		}
+		if len(rpn) == 0 {
+			// Пустая инструкция или пустые скобки
+			at := token{offset: statement.offset}
+			if len(body) > 0 {
+				at = span(body)
+			}
+			return nil, newParseError(CodeInvalidSyntax, errRPN, at, "выражение")
+		}
+		// Дерево строится по исходной записи инструкции, до перестроения цепочек
+		value, treeErr := ast.FromRPN(texts(rpn))
+
+		if opts.BalanceChains {
+			var before, after int
+			rpn, before, after = balanceRPN(rpn)
+			if after < before {
+				logger.Log.Debugf("Глубина инструкции №%d уменьшена с %d до %d перестроением цепочек операций", i+1, before, after)
+			}
+		}
+		if target != nil {
+			if unit, err = s.parseUnit(*target); err != nil {
+				return nil, err
+			}
+			if unit.Scale != 1 {
+				// Перевод в единицу - деление результата в основных единицах на значение единицы
+				scale := strconv.FormatFloat(unit.Scale, 'g', -1, 64)
+				rpn = append(rpn,
+					token{kind: tokenNumber, text: scale, offset: target.offset, length: target.length},
+					token{kind: tokenSymbol, text: operators.OpDivide, offset: target.offset, length: target.length},
+				)
+			}
+		}
+		rpns = append(rpns, exportTokens(rpn))
+
+		root, dim, err = s.rpnToTasks(rpn)
+		if err != nil {
+			return nil, err
+		}
+		if treeErr != nil {
+			// Корректная RPN всегда образует дерево, поэтому ошибка не ожидается
+			return nil, newParseError(CodeInvalidSyntax, treeErr, span(body), "выражение")
+		}
+
+		statement := ast.Statement{Value: value}
+		if name != nil {
+			if err := s.assign(*name, root, dim); err != nil {
+				return nil, err
+			}
+			statement.Name = name.text
+		}
+		tree = append(tree, statement)
+	}
+
+	plan := &Plan{
+		Variables: s.variables,
+		Tokens:    exportTokens(tokens),
+		RPN:       rpns,
+		Folded:    s.folded,
+		AST:       tree,
+		Canonical: tree.Canonical().String(),
+	}
+	switch {
+	case target != nil:
+		if unit.Dimension != dim {
+			err := fmt.Errorf("результат размерности %s нельзя перевести в %s: %w", dim, target.text, units.ErrMismatch)
+			return nil, newParseError(CodeDimensionMismatch, err, *target, "")
+		}
+		plan.Unit = target.text
+		plan.Canonical += " " + conversionKeyword + " " + target.text
+	case !dim.Dimensionless():
+		plan.Unit = dim.String()
+	}
+	if root.Result != nil && s.folded > 0 {
+		// Все операции выражения свернуты - результат известен без агентов
+		plan.Result = root.Result
+		return plan, nil
+	}
+	if root.Result != nil || s.external[root] {
+		// Результат известен без вычислений или вычисляется другим выражением - агентам нечего считать
+		return nil, newParseError(CodeNoOperators, errOneOperand, span(body), "оператор")
+	}
+	s.moveToEnd(int(root.ID))
+	plan.Tasks = s.tasks
+
+	return plan, nil
+}
+
+// texts возвращает тексты токенов в записи RPN (см. token.rpnText).
+//
+// Args:
+//
+//	tokens: []token - Токены.
+//
+// Returns:
+//
+//	[]string - Тексты токенов в том же порядке.
+func texts(tokens []token) []string {
+	result := make([]string, len(tokens))
+	for i, tok := range tokens {
+		result[i] = tok.rpnText()
+	}
+	return result
+}
+
+// splitStatements разделяет токены сценария на инструкции по разделителю ";".
+//
+// Args:
+//
+//	tokens: []token - Токены сценария.
+//
+// Returns:
+//
+//	[]statement - Инструкции сценария. Пустая инструкция не содержит токенов.
+func splitStatements(tokens []token) []statement {
+	statements := []statement{{}}
+	for _, tok := range tokens {
+		if tok.kind == tokenSymbol && tok.text == statementSeparator {
+			statements = append(statements, statement{offset: tok.offset + tok.length})
+			continue
+		}
+		current := &statements[len(statements)-1]
+		current.tokens = append(current.tokens, tok)
+	}
+	return statements
+}
+
+// splitAssignment выделяет из инструкции имя присваиваемой переменной и выражение.
+//
+// Args:
+//
+//	statement: []token - Токены инструкции сценария.
+//
+// Returns:
+//
+//	*token - Токен имени переменной или nil, если инструкция не является присваиванием.
+//	[]token - Токены выражения инструкции.
+//	error - Ошибка, если имя переменной недопустимо.
+func splitAssignment(statement []token) (*token, []token, error) {
+	index := -1
+	for i, tok := range statement {
+		if tok.kind == tokenSymbol && tok.text == assignmentOperator {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, statement, nil
+	}
+
+	if index != 1 || statement[0].kind != tokenName || !isName(statement[0].text) {
+		var name strings.Builder
+		for _, tok := range statement[:index] {
+			name.WriteString(tok.text)
+		}
+		at := statement[index]
+		if index > 0 {
+			at = span(statement[:index])
+		}
+		err := fmt.Errorf("недопустимое имя переменной: %s", name.String())
+		return nil, nil, newParseError(CodeInvalidName, err, at, "имя переменной")
+	}
+	return &statement[0], statement[index+1:], nil
+}
+
+// splitConversion выделяет из последней инструкции сценария перевод результата в единицу измерения:
+// "5 km / 2 h in m/s". Переводом считается имя "in" вне скобок, за которым следует имя (обозначение единицы).
+//
+// Args:
+//
+//	statement: []token - Токены выражения инструкции.
+//
+// Returns:
+//
+//	[]token - Токены выражения без перевода.
+//	*token - Токен, охватывающий запись единицы ("m/s"), или nil, если перевода нет.
+func splitConversion(statement []token) ([]token, *token) {
+	depth := 0
+	for i, tok := range statement {
+		switch {
+		case tok.kind == tokenSymbol && tok.text == operators.ParenLeft:
+			depth++
+		case tok.kind == tokenSymbol && tok.text == operators.ParenRight:
+			depth--
+		case tok.kind == tokenName && tok.text == conversionKeyword && depth == 0 && i > 0 &&
+			i+1 < len(statement) && statement[i+1].kind == tokenName:
+			target := span(statement[i+1:])
+			target.text = strings.Join(texts(statement[i+1:]), "")
+			return statement[:i], &target
+		}
+	}
+	return statement, nil
+}
 
+// parseUnit разбирает единицу измерения числа или перевода результата.
+//
+// Args:
+//
+//	tok: token - Токен с записью единицы ("km/h"); его положение указывается в ошибке.
+//
+// Returns:
+//
+//	units.Unit - Единица измерения.
+//	error - Ошибка, если единицы измерения недоступны в режиме вычисления или запись единицы неверна.
+func (s *script) parseUnit(tok token) (units.Unit, error) {
+	if !s.dimensional {
+		return units.Unit{}, newParseError(CodeUnitUnavailable, errors.New("единицы измерения допускаются только в режиме float"), tok, "")
+	}
+	unit, err := units.Parse(tok.text)
 	if err != nil {
-		return nil, err
+		return units.Unit{}, newParseError(CodeUnknownUnit, err, tok, "единица измерения")
+	}
+	return unit, nil
+}
+
+// assign связывает имя со значением инструкции и запоминает его как именованное промежуточное значение.
+//
+// Args:
+//
+//	nameToken: token - Токен имени переменной.
+//	value: *models.Task - Значение инструкции: число или ссылка на задачу.
+//	dim: units.Dimension - Размерность значения.
+//
+// Returns:
+//
+//	error - Ошибка, если имя уже связано со значением.
+func (s *script) assign(nameToken token, value *models.Task, dim units.Dimension) error {
+	name := nameToken.text
+	if _, exists := s.scope[name]; exists {
+		return newParseError(CodeDuplicateVariable, fmt.Errorf("переменная %s уже определена", name), nameToken, "")
+	}
+	s.scope[name] = value
+	s.dimensions[name] = dim
+	s.variables = append(s.variables, s.variable(name, value))
+	return nil
+}
+
+// variable создает именованное значение выражения: число, локальный индекс задачи или ID задачи другого выражения.
+//
+// Args:
+//
+//	name: string - Имя значения.
+//	value: *models.Task - Значение: число или задача.
+//
+// Returns:
+//
+//	*models.ExpressionVariable - Именованное значение.
+func (s *script) variable(name string, value *models.Task) *models.ExpressionVariable {
+	variable := &models.ExpressionVariable{Name: name}
+	switch {
+	case value.Result != nil:
+		result := *value.Result
+		variable.Value = &result
+	case s.external[value]:
+		variable.TaskID = value.ID
+	default:
+		variable.TaskIndex = int(value.ID)
+	}
+	return variable
+}
+
+// moveToEnd переставляет задачу с указанным локальным индексом в конец списка задач,
+// чтобы корневая задача сценария всегда была последней. Локальные индексы задач,
+// зависимостей и переменных пересчитываются в соответствии с новым порядком.
+//
+// Args:
+//
+//	index: int - Локальный индекс задачи (начиная с 1).
+func (s *script) moveToEnd(index int) {
+	last := len(s.tasks)
+	if index == last {
+		return
+	}
+
+	remap := func(i int) int {
+		switch {
+		case i == index:
+			return last
+		case i > index:
+			return i - 1
+		default:
+			return i
+		}
+	}
+
+	moved := s.tasks[index-1]
+	s.tasks = append(s.tasks[:index-1], s.tasks[index:]...)
+	s.tasks = append(s.tasks, moved)
+
+	for _, task := range s.tasks {
+		task.ID = int64(remap(int(task.ID)))
+		for i, dep := range task.DependencyIndexes {
+			if dep > 0 {
+				task.DependencyIndexes[i] = remap(dep)
+			}
+		}
+		for i := range task.Guards {
+			task.Guards[i].ConditionIndex = remap(task.Guards[i].ConditionIndex)
+		}
+	}
+	for _, variable := range s.variables {
+		if variable.TaskIndex > 0 {
+			variable.TaskIndex = remap(variable.TaskIndex)
+		}
+	}
+}
+
+// reference возвращает операнд для ссылки на другое выражение ($42).
+// Вычисленное выражение становится числом, ещё не вычисленное - внешним операндом.
+// В точном и десятичном режимах число сохраняет точный результат выражения: $42 = 1/3 остается дробью
+// (или десятичной записью с точностью выражения), а не округляется до float64.
+// В комплексном режиме число сохраняет мнимую часть результата: $42 = sqrt(-4) - число 2i.
+// В интервальном режиме число - интервал результата выражения, а не его середина.
+//
+// Результат выражения с единицей измерения ("km/h") переводится в основные единицы СИ и сохраняет размерность:
+// вычисленное выражение - умножением числа, ещё не вычисленное - задачей умножения на значение единицы.
+//
+// Args:
+//
+//	tok: token - Токен ссылки.
+//
+// Returns:
+//
+//	*models.Task - Операнд ссылки.
+//	units.Dimension - Размерность результата выражения.
+//	error - Ошибка, если ссылки запрещены, выражение недоступно или его единица измерения недоступна
+//	    в режиме вычисления. Ошибки получения выражения возвращаются без изменений.
+func (s *script) reference(tok token) (*models.Task, units.Dimension, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(tok.text, referencePrefix), 10, 64)
+	if err != nil {
+		return nil, units.Dimension{}, newParseError(CodeInvalidSyntax, errInvalidSyntax, tok, "ID выражения")
+	}
+	if operand, ok := s.refs[id]; ok {
+		return s.scaleReference(operand, s.refUnits[id]), s.refUnits[id].Dimension, nil
+	}
+	if s.resolve == nil {
+		return nil, units.Dimension{}, newParseError(CodeReferenceUnavailable, fmt.Errorf("ссылка на выражение %s недоступна", tok.text), tok, "")
+	}
+
+	ref, err := s.resolve(id)
+	if err != nil {
+		return nil, units.Dimension{}, err
+	}
+	unit := units.Unit{Scale: 1}
+	if ref.Unit != "" {
+		if unit, err = s.parseUnit(token{text: ref.Unit, offset: tok.offset, length: tok.length}); err != nil {
+			return nil, units.Dimension{}, err
+		}
+	}
+
+	var operand *models.Task
+	if ref.Result != nil {
+		operand = literal(*ref.Result * unit.Scale)
+		switch {
+		case s.complex:
+			if ref.ImagResult != nil {
+				imag := *ref.ImagResult
+				operand.ImagResult = &imag
+			}
+		case s.interval:
+			if ref.LowerResult != nil && ref.UpperResult != nil {
+				operand = interval(operators.Interval{Lo: *ref.LowerResult, Hi: *ref.UpperResult})
+			}
+		case s.exact:
+			exact := operators.FormatExact(exactValue(*ref.Result, ref.ExactResult))
+			operand.ExactResult = &exact
+		case s.precision > 0:
+			// Дробь выражения режима rational записывается десятичной с запасными цифрами, как при передаче результата задачам
+			digits := s.precision + operators.GuardDigits
+			value := new(big.Float).SetPrec(operators.PrecisionBits(digits)).SetRat(exactValue(*ref.Result, ref.ExactResult))
+			decimal := operators.FormatDecimalDigits(value, digits)
+			operand.ExactResult = &decimal
+		}
+	} else {
+		operand = &models.Task{ID: ref.TaskID, Status: "pending"}
+		s.external[operand] = true
+	}
+	s.refs[id] = operand
+	s.refUnits[id] = unit
+	return s.scaleReference(operand, unit), unit.Dimension, nil
+}
+
+// scaleReference переводит значение ещё не вычисленного выражения в основные единицы СИ задачей умножения
+// на значение единицы. Задача создается при каждом использовании ссылки, чтобы она получала условия ветви if,
+// в которой используется (см. operation). Вычисленное выражение переводится при разборе ссылки.
+//
+// Args:
+//
+//	operand: *models.Task - Операнд ссылки.
+//	unit: units.Unit - Единица измерения результата выражения.
+//
+// Returns:
+//
+//	*models.Task - Значение ссылки в основных единицах СИ.
+func (s *script) scaleReference(operand *models.Task, unit units.Unit) *models.Task {
+	if !s.external[operand] || unit.Scale == 1 {
+		return operand
+	}
+	return s.binary(operators.OpMultiply, operand, literal(unit.Scale))
+}
+
+// taskKey формирует ключ задачи по операции и операндам. Задачи с одинаковым ключом вычисляют
+// одно и то же значение: операнды уже заменены общими задачами, поэтому одинаковые поддеревья
+// выражения получают одинаковые ключи.
+//
+// Args:
+//
+//	operation: string - Идентификатор операции.
+//	operands: []*models.Task - Операнды операции.
+//
+// Returns:
+//
+//	string - Ключ задачи, например "+|v:2|t:1" для 2 + (задача 1).
+func (s *script) taskKey(operation string, operands []*models.Task) string {
+	var key strings.Builder
+	key.WriteString(operation)
+	for _, operand := range operands {
+		switch {
+		case operand.Result != nil:
+			// Биты числа различают 0 и -0. Точная запись различает числа, равные после округления до float64
+			fmt.Fprintf(&key, "|v:%x", math.Float64bits(*operand.Result))
+			if operand.ExactResult != nil {
+				fmt.Fprintf(&key, "x%s", *operand.ExactResult)
+			}
+			if operand.ImagResult != nil {
+				fmt.Fprintf(&key, "i%x", math.Float64bits(*operand.ImagResult))
+			}
+			if operand.UpperResult != nil {
+				fmt.Fprintf(&key, "u%x", math.Float64bits(*operand.UpperResult))
+			}
+		case s.external[operand]:
+			fmt.Fprintf(&key, "|e:%d", operand.ID)
+		default:
+			fmt.Fprintf(&key, "|t:%d", operand.ID)
+		}
+	}
+	return key.String()
+}
+
+// literal создает задачу-операнд для числового значения.
+//
+// Args:
+//
+//	value: float64 - Числовое значение.
+//
+// Returns:
+//
+//	*models.Task - Задача со статусом "completed" и заданным результатом.
+func literal(value float64) *models.Task {
+	return &models.Task{
+		Status: "completed",
+		Result: &value,
+	}
+}
+
+// exactValue возвращает точное значение числа: по его точной записи, если она есть, иначе по десятичной
+// записи float64 (см. operators.ExactFromFloat).
+//
+// Args:
+//
+//	value: float64 - Число.
+//	exact: *string - Точная запись числа: дробь "num/den" или десятичная запись. nil, если ее нет.
+//
+// Returns:
+//
+//	*big.Rat - Рациональное число.
+func exactValue(value float64, exact *string) *big.Rat {
+	if exact != nil {
+		if parsed, ok := new(big.Rat).SetString(*exact); ok {
+			return parsed
+		}
 	}
+	return operators.ExactFromFloat(value)
+}
+
+// imaginary создает задачу-операнд для мнимого числа.
+//
+// Args:
+//
+//	value: float64 - Мнимая часть числа.
+//
+// Returns:
+//
+//	*models.Task - Задача со статусом "completed", нулевым результатом и заданной мнимой частью результата.
+func imaginary(value float64) *models.Task {
+	task := literal(0)
+	task.ImagResult = &value
+	return task
+}
+
+// interval создает задачу-операнд для интервального числа.
+//
+// Args:
+//
+//	value: operators.Interval - Интервал.
+//
+// Returns:
+//
+//	*models.Task - Задача со статусом "completed", нижней границей интервала в результате и верхней - в UpperResult.
+func interval(value operators.Interval) *models.Task {
+	task := literal(value.Lo)
+	task.UpperResult = &value.Hi
+	return task
+}
 
-	return tasks, nil
+// bounds возвращает интервал числового операнда. Число без погрешности - вырожденный интервал.
+//
+// Args:
+//
+//	operand: *models.Task - Задача-операнд с заданным результатом.
+//
+// Returns:
+//
+//	operators.Interval - Интервал значения операнда.
+func bounds(operand *models.Task) operators.Interval {
+	value := operators.Degenerate(*operand.Result)
+	if operand.UpperResult != nil {
+		value.Hi = *operand.UpperResult
+	}
+	return value
 }
 
 // precedence определяет приоритет оператора для правильной вложенности при разбиении на задачи.
+// Приоритет берется из реестра операций.
 //
 // Args:
 //
@@ -60,83 +930,147 @@ func ParseExpression(expression string) ([]*models.Task, error) {
 // Returns:
 //
 //	int - Целое число, представляющее приоритет оператора. Чем больше число, тем выше приоритет.
-//	     Возвращает 0 для неопознанных операторов.
+//	     Возвращает 0 для неопознанных операторов и функций.
 func precedence(op string) int {
-	switch op {
-	case operators.OpAdd, operators.OpSubtract:
-		return 1
-	case operators.OpMultiply, operators.OpDivide:
-		return 2
-	case operators.OpUnaryMinus:
-		return 3
-	case operators.OpPower:
-		return 4
-	default:
-		return 0
+	if operator, ok := operators.Lookup(op); ok && !operator.Function {
+		return operator.Precedence
 	}
+	return 0
 }
 
-// isOperator проверяет, является ли токен строкой, представляющей математический оператор.
+// shouldPop определяет, нужно ли перенести оператор с вершины стека в выходную очередь
+// перед помещением в стек текущего оператора.
 //
 // Args:
 //
-//	token: string - Строка, которую необходимо проверить.
+//	token: string - Текущий оператор.
+//	top: string - Оператор на вершине стека.
+//	legacy: bool - Использовать прежние правила (все операторы левоассоциативны).
 //
 // Returns:
 //
-//	bool - true, если токен является одним из допустимых операторов (+, -, *, /, ^, u-), иначе false.
-func isOperator(token string) bool {
-	switch token {
-	case operators.OpAdd, operators.OpSubtract, operators.OpMultiply, operators.OpDivide, operators.OpPower:
-		return true
-	default:
+//	bool - true, если оператор с вершины стека нужно перенести в выходную очередь.
+func shouldPop(token, top string, legacy bool) bool {
+	if legacy {
+		return precedence(token) <= precedence(top)
+	}
+	if token == operators.OpUnaryMinus {
+		// Префиксный оператор еще не имеет операнда, поэтому ничего не выталкивает
 		return false
 	}
+	if operator, ok := operators.Lookup(token); ok && operator.RightAssoc {
+		return precedence(token) < precedence(top)
+	}
+	return precedence(token) <= precedence(top)
+}
+
+// isOperator проверяет, является ли токен строкой, представляющей бинарный математический оператор.
+//
+// Args:
+//
+//	token: string - Строка, которую необходимо проверить.
+//
+// Returns:
+//
+//	bool - true, если токен является одним из зарегистрированных бинарных операторов (+, -, *, /, ^), иначе false.
+func isOperator(token string) bool {
+	return operators.IsBinary(token)
 }
 
 // isUnaryMinus определяет, следует ли обрабатывать знак минус как унарный (например, "-5") или бинарный (например, "3 - 5").
 //
 // Args:
 //
-//	tokens: []string - Срез строк, представляющий токены выражения.
+//	tokens: []token - Токены выражения.
 //	i: int - Индекс текущего токена в срезе.
 //
 // Returns:
 //
 //	bool - true, если минус должен быть обработан как унарный, иначе false.
-func isUnaryMinus(tokens []string, i int) bool {
+func isUnaryMinus(tokens []token, i int) bool {
 	if i == 0 {
 		return true // Минус в начале выражения - унарный
 	}
 	prevToken := tokens[i-1]
-	return prevToken == operators.ParenLeft || isOperator(prevToken)
+	return prevToken.kind == tokenSymbol &&
+		(prevToken.text == operators.ParenLeft || prevToken.text == argumentSeparator || isOperator(prevToken.text))
+}
+
+// endsOperand проверяет, завершает ли токен, предшествующий i-му, операнд: число, переменную, ссылку,
+// закрывающую скобку или постфиксный оператор.
+//
+// Args:
+//
+//	tokens: []token - Токены выражения.
+//	i: int - Индекс текущего токена в срезе.
+//
+// Returns:
+//
+//	bool - true, если перед токеном записан операнд, иначе false.
+func endsOperand(tokens []token, i int) bool {
+	if i == 0 {
+		return false
+	}
+	prev := tokens[i-1]
+	switch prev.kind {
+	case tokenNumber, tokenReference:
+		return true
+	case tokenName:
+		return !prev.isCall()
+	case tokenSymbol:
+		return prev.text == operators.ParenRight || operators.IsPostfix(prev.text)
+	}
+	return false
 }
 
 // infixToRPN преобразует математическое выражение в инфиксной нотации (обычная запись) в обратную польскую нотацию (RPN).
 // RPN упрощает вычисление выражений с помощью стека.
 //
+// Правоассоциативные операторы (^) не выталкивают из стека операторы того же приоритета,
+// поэтому 2^3^2 = 2^(3^2). Унарный минус является префиксным оператором и ничего не выталкивает
+// из стека, поэтому допустимы 2^-1 и --2, а -2^2 = -(2^2). Постфиксный оператор (!) связывает сильнее
+// всех операторов и сразу переносится в выходную очередь: 2^3! = 2^(3!), -3! = -(3!).
+//
+// Аргументы вызова функции разделяются запятыми: max(3, 7, 1). Количество аргументов сохраняется
+// в токене функции и проверяется по реестру операций или по параметрам функции пользователя.
+// Имя функции пользователя, за которым следует открывающая скобка, становится токеном вызова (tokenCall).
+//
 // Args:
 //
-//	expression: string - Математическое выражение в инфиксной нотации.
+//	tokens: []token - Токены выражения в инфиксной нотации.
+//	legacy: bool - Использовать прежние правила: все операторы левоассоциативны,
+//	    а унарный минус выталкивает операторы как бинарный.
+//	functions: map[string]Function - Функции пользователя.
 //
 // Returns:
 //
-//	[]string - Срез строк, представляющий выражение в обратной польской нотации (RPN).
+//	[]token - Токены выражения в обратной польской нотации (RPN).
 //	error - Ошибка, если выражение не может быть преобразовано.
-func infixToRPN(expression string) ([]string, error) {
+func infixToRPN(tokens []token, legacy bool, functions map[string]Function) ([]token, error) {
+	var output []token // Выходная очередь
+	var stack []token  // Стек операторов
 
-	tokens := tokenize(expression) // Сначала разбиваем на токены
-	var output []string            // Выходная очередь
-	var stack []string             // Стек операторов
+	for i, tok := range tokens {
+		if _, ok := functions[tok.text]; (ok || isForm(tok.text)) && tok.kind == tokenName && !operators.IsFunction(tok.text) &&
+			i+1 < len(tokens) && tokens[i+1].text == operators.ParenLeft {
+			tok.kind = tokenCall // Вызов функции пользователя, integrate или solve
+		}
 
-	for i, token := range tokens {
 		switch {
-		case isNumber(token): // Если число, добавляем в выходную очередь
-			output = append(output, token)
-		case token == operators.ParenLeft: // Если открывающая скобка, помещаем в стек
-			stack = append(stack, token)
-		case token == operators.ParenRight: // Если закрывающая скобка
-			for len(stack) > 0 && stack[len(stack)-1] != operators.ParenLeft {
+		case tok.kind == tokenNumber: // Если число, добавляем в выходную очередь
+			output = append(output, tok)
+		case tok.isCall(): // Если функция, помещаем в стек
+			// За именем функции обязательно должна следовать открывающая скобка
+			if i+1 >= len(tokens) || tokens[i+1].text != operators.ParenLeft {
+				return nil, newParseError(CodeInvalidSyntax, errInvalidSyntax, tok, operators.ParenLeft)
+			}
+			stack = append(stack, tok)
+		case tok.kind == tokenName, tok.kind == tokenReference: // Если переменная или ссылка, добавляем ее в выходную очередь
+			output = append(output, tok)
+		case tok.text == operators.ParenLeft: // Если открывающая скобка, помещаем в стек
+			stack = append(stack, tok)
+		case tok.text == operators.ParenRight: // Если закрывающая скобка
+			for len(stack) > 0 && stack[len(stack)-1].text != operators.ParenLeft {
 				// Переносим операторы из стека в выходную очередь,
 				// пока он не опустеет, или мы не встретим открывающую скобку
 				output = append(output, stack[len(stack)-1])
@@ -145,83 +1079,122 @@ func infixToRPN(expression string) ([]string, error) {
 			if len(stack) == 0 {
 				// Если в стеке не осталось открывающей скобки, это означает, что у нас была
 				// закрывающая скобка, но не было соответствующей открывающей скобки в выражении
-				return nil, errUnopenedParen
+				return nil, newParseError(CodeUnopenedParen, errUnopenedParen, tok, "")
 			}
+			paren := stack[len(stack)-1]
 			stack = stack[:len(stack)-1] // Удаляем открывающую скобку из стека
-		case isOperator(token): // Если оператор
-			if token == "-" && isUnaryMinus(tokens, i) {
-				token = operators.OpUnaryMinus // Помечаем как унарный минус
+			if len(stack) > 0 && stack[len(stack)-1].isCall() {
+				// Если скобка принадлежала вызову функции, переносим функцию в выходную очередь
+				call := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if tokens[i-1].text == argumentSeparator {
+					return nil, newParseError(CodeInvalidSyntax, errEmptyArgument, tok, "аргумент")
+				}
+				if tokens[i-1].text != operators.ParenLeft {
+					call.args = paren.args + 1 // Аргументов на один больше, чем запятых
+				}
+				if err := checkArgs(call, tok, functions); err != nil {
+					return nil, err
+				}
+				output = append(output, call)
+			}
+		case tok.text == argumentSeparator: // Если запятая, завершаем аргумент вызова функции
+			for len(stack) > 0 && stack[len(stack)-1].text != operators.ParenLeft {
+				output = append(output, stack[len(stack)-1])
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) < 2 || !stack[len(stack)-2].isCall() {
+				// Запятая вне скобок или в скобках, не принадлежащих вызову функции
+				return nil, newParseError(CodeInvalidSyntax, errSeparator, tok, "")
+			}
+			if prev := tokens[i-1].text; prev == operators.ParenLeft || prev == argumentSeparator {
+				return nil, newParseError(CodeInvalidSyntax, errEmptyArgument, tok, "аргумент")
 			}
-			for len(stack) > 0 && precedence(token) <= precedence(stack[len(stack)-1]) {
+			stack[len(stack)-1].args++ // Открывающая скобка вызова считает запятые
+		case tok.kind == tokenSymbol && operators.IsPostfix(tok.text): // Если постфиксный оператор
+			if !endsOperand(tokens, i) {
+				return nil, newParseError(CodeInvalidSyntax, errInvalidSyntax, tok, "операнд")
+			}
+			output = append(output, tok) // Операнд уже в выходной очереди
+		case tok.kind == tokenSymbol && isOperator(tok.text): // Если оператор
+			if tok.text == operators.OpSubtract && isUnaryMinus(tokens, i) {
+				tok.text = operators.OpUnaryMinus // Помечаем как унарный минус
+			}
+			for len(stack) > 0 && shouldPop(tok.text, stack[len(stack)-1].text, legacy) {
 				// Переносим операторы из стека в выходную очередь, пока приоритет текущего оператора
-				// меньше или равен приоритету оператора на вершине стека
+				// меньше приоритета оператора на вершине стека (или равен ему для левоассоциативных)
 				output = append(output, stack[len(stack)-1])
 				stack = stack[:len(stack)-1]
 			}
-			stack = append(stack, token) // Помещаем текущий оператор в стек
+			stack = append(stack, tok) // Помещаем текущий оператор в стек
 		default:
-			return nil, errInvalidSyntax
+			return nil, newParseError(CodeInvalidSyntax, errInvalidSyntax, tok, "")
 		}
 	}
 
 	// Переносим все оставшиеся операторы из стека в выходную очередь
 	for len(stack) > 0 {
 		top := stack[len(stack)-1]
-		if top == operators.ParenLeft || top == operators.ParenRight {
-			return nil, errUnclosedParen
+		if top.text == operators.ParenLeft || top.text == operators.ParenRight {
+			return nil, newParseError(CodeUnclosedParen, errUnclosedParen, top, operators.ParenRight)
 		}
 		output = append(output, stack[len(stack)-1])
 		stack = stack[:len(stack)-1]
 	}
 
-	if len(output) == 1 {
-		return nil, errOneOperand
-	}
-
 	return output, nil
 }
 
-// tokenize разбивает входную строку математического выражения на отдельные токены (числа, операторы, скобки).
-// Токены используются для дальнейшей обработки выражения.
+// checkArgs проверяет количество аргументов вызова функции.
 //
 // Args:
 //
-//	expression: string - Строка, содержащая математическое выражение.
+//	call: token - Токен функции с количеством аргументов вызова.
+//	closing: token - Закрывающая скобка вызова.
+//	functions: map[string]Function - Функции пользователя.
 //
 // Returns:
 //
-//	[]string - Срез строк, представляющий токены выражения.
-func tokenize(expression string) []string {
-	var tokens []string
-	var currentNumber string
-
-	for i, r := range expression {
-		s := string(r)
-		// Если символ является цифрой, точкой или знаком "+" в начале числа
-		if unicode.IsDigit(r) || s == operators.Point || (s == "+" && (i == 0 || isOperator(string(expression[i-1])) || expression[i-1] == '(')) {
-			currentNumber += s
-		} else {
-			// Если накопилось число, добавляем его в токены
-			if currentNumber != "" {
-				tokens = append(tokens, currentNumber)
-				currentNumber = ""
-			}
-			// Добавляем текущий символ (оператор или скобку) в токены
-			if s != "" {
-				tokens = append(tokens, s)
-			}
-		}
+//	error - *ParseError с кодом CodeArgumentCount, если количество аргументов не подходит функции.
+func checkArgs(call, closing token, functions map[string]Function) error {
+	var arity int
+	var variadic bool
+	switch {
+	case call.kind == tokenCall && isForm(call.text):
+		arity = formArity[call.text]
+	case call.kind == tokenCall:
+		arity = len(functions[call.text].Params)
+	default:
+		operator, _ := operators.Lookup(call.text)
+		arity, variadic = operator.Arity, operator.Variadic
 	}
 
-	// Если осталось число, добавляем его в токены
-	if currentNumber != "" {
-		tokens = append(tokens, currentNumber)
+	expected := strconv.Itoa(arity)
+	switch {
+	case variadic && call.args < arity:
+		expected = "не меньше " + expected
+	case variadic, call.args == arity:
+		return nil
 	}
+	err := fmt.Errorf("функция %s ожидает аргументов: %s, передано: %d", call.text, expected, call.args)
+	return newParseError(CodeArgumentCount, err, span([]token{call, closing}), "")
+}
 
-	return tokens
+// isNameStart проверяет, может ли символ начинать имя функции или переменной.
+//
+// Args:
+//
+//	r: rune - Символ, который необходимо проверить.
+//
+// Returns:
+//
+//	bool - true, если символ является буквой или подчеркиванием, иначе false.
+func isNameStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
 }
 
-// isNumber проверяет, является ли переданный токен числом.
+// isName проверяет, является ли токен именем переменной: начинается с буквы или подчеркивания
+// и содержит только буквы, цифры и подчеркивания. Имена функций переменными не считаются.
 //
 // Args:
 //
@@ -229,128 +1202,481 @@ func tokenize(expression string) []string {
 //
 // Returns:
 //
-//	bool - true, если токен может быть преобразован в число, иначе false.
-func isNumber(token string) bool {
-	_, err := strconv.ParseFloat(token, 64)
-	return err == nil
+//	bool - true, если токен является именем, иначе false.
+func isName(token string) bool {
+	if token == "" || operators.IsFunction(token) || isForm(token) {
+		return false
+	}
+	for i, r := range token {
+		if !isNameStart(r) && !(i > 0 && unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// arguments находит аргументы операций и вызовов функций в RPN инструкции. Аргументы - поддеревья,
+// записанные в RPN подряд перед токеном операции.
+//
+// Args:
+//
+//	rpn: []token - Выражение в формате RPN.
+//
+// Returns:
+//
+//	map[int][]int - Индексы первых токенов аргументов по индексу токена операции. Если RPN некорректна,
+//	    возвращаются аргументы, найденные до ошибки: ее сообщит rpnToTasks.
+func arguments(rpn []token) map[int][]int {
+	found := make(map[int][]int)
+	var starts []int // Индексы первых токенов поддеревьев, значения которых ещё не использованы
+	for i, tok := range rpn {
+		n := 0
+		switch tok.kind {
+		case tokenCall:
+			n = tok.args
+		case tokenSymbol, tokenName:
+			if operator, ok := operators.Lookup(tok.text); ok {
+				n = operator.ArgCount(tok.args)
+			}
+		}
+		if n > len(starts) {
+			return found
+		}
+
+		start := i
+		if n > 0 {
+			found[i] = append([]int(nil), starts[len(starts)-n:]...)
+			start = starts[len(starts)-n]
+		}
+		starts = append(starts[:len(starts)-n], start)
+	}
+	return found
 }
 
-// rpnToTasks преобразует выражение в обратной польской записи (RPN) в список задач для вычисления.// Использует стековый алгоритм для построения графа зависимостей между операциями.
+// rpnToTasks преобразует выражение инструкции в обратной польской записи (RPN) в задачи для вычисления.
+// Использует стековый алгоритм для построения графа зависимостей между операциями.
+// Созданные задачи добавляются к задачам сценария, их локальные индексы продолжают нумерацию сценария.
 //
 // Args:
 //
-//	rpn: []string - Выражение в формате RPN (массив токенов)
+//	rpn: []token - Выражение в формате RPN (массив токенов)
 //
 // Returns:
 //
-//	[]*models.Task - Список задач, представляющих операции выражения
+//	*models.Task - Значение инструкции: корневая задача или число (задача с заданным результатом)
+//	units.Dimension - Размерность значения инструкции
 //	error - Ошибка преобразования:
 //	    - errNotEnoughOperands: недостаточно операндов для операции
 //	    - errUnaryMinus: отсутствует операнд для унарного минуса
 //	    - errRPN: неверный формат RPN или числового значения
+//	    - ошибка несвязанной переменной
+//	    - ошибка несовместимых размерностей операндов
+//	    - ошибка в теле вызванной функции пользователя или рекурсивный вызов
 //
 // Функция использует стек для отслеживания операндов и операций.
-// При обнаружении оператора, функция извлекает два операнда (или один для унарного минуса) из стека,
-// создает новую задачу с этим оператором и зависимостями, и помещает задачу в стек.
-// Числа преобразуются в задачи с предопределенным статусом "completed" и результатом.
+// При обнаружении операции, функция извлекает из стека столько операндов, сколько указано в реестре операций
+// (для функции с переменным числом аргументов - сколько передано в вызове), создает новую задачу с этим оператором
+// и зависимостями (см. operation, aggregate), и помещает задачу в стек.
+// Числа и переменные, связанные с числами, преобразуются в задачи с предопределенным статусом "completed" и результатом.
+// Переменные, связанные с задачами предыдущих инструкций, помещаются в стек как ссылки на эти задачи.
+// Ссылки на другие выражения ($42) заменяются их результатом или зависимостью от их корневой задачи.
+// Вызовы функций пользователя заменяются задачами их тел (см. call), а записи рядов - задачами
+// подстановок их тел (см. series).
+// Числа с единицами измерения переводятся в основные единицы СИ, а размерность результата каждой операции
+// вычисляется по правилу из реестра операций (Operator.Dimension).
 // Функция возвращает nil и ошибку, если входная строка RPN некорректна.
-func rpnToTasks(rpn []string) ([]*models.Task, error) {
-	var tasks []*models.Task // Срез для хранения созданных задач
-	var stack []*models.Task // Стек для хранения операндов и промежуточных результатов
-	var indexer int          // Счетчик для индексации зависимостей
+func (s *script) rpnToTasks(rpn []token) (*models.Task, units.Dimension, error) {
+	var stack []*models.Task   // Стек для хранения операндов и промежуточных результатов
+	var sources []token        // Фрагменты выражения, соответствующие элементам стека
+	var dims []units.Dimension // Размерности элементов стека
 
-	// Вспомогательная функция для создания новой задачи
-	newTask := func(operator string) models.Task {
-		return models.Task{
-			Operation:         operator,            // Операция
-			Args:              make([]*float64, 2), //  Аргументы
-			Dependencies:      []int64{-1, -1},     //  ID зависимостей
-			DependencyIndexes: make([]int, 2),      //  Индексы зависимостей в срезе tasks
+	// Задачи ветвей if создаются с условием выполнения (см. enter)
+	args := arguments(rpn)
+	split := branches(rpn, args)
+	var ends []int // Индексы последних токенов начатых ветвей
+	defer func() {
+		for range ends {
+			s.leave()
 		}
+	}()
+
+	// Тело ряда, интеграла или уравнения подставляется при вызове (см. expand), а остальные аргументы
+	// вычисляются при разборе
+	loops, err := s.loops(rpn, args)
+	if err != nil {
+		return nil, units.Dimension{}, err
 	}
+	var open []loop // Начатые вызовы, связывающие переменную
+	skip := 0       // Индекс токена, до которого токены тела пропускаются
+	defer func() {
+		for range open {
+			s.constant--
+		}
+	}()
 
 	//  Цикл по токенам RPN
-	for _, token := range rpn {
-		switch token {
-		case operators.OpAdd, operators.OpSubtract, operators.OpMultiply, operators.OpDivide, operators.OpPower:
-			//  Обработка бинарных операторов (+, -, *, /)
-			if len(stack) < 2 {
-				//  Недостаточно операндов на стеке
-				return nil, errNotEnoughOperands
+	for i, tok := range rpn {
+		if i < skip {
+			continue
+		}
+		for len(ends) > 0 && ends[len(ends)-1] < i {
+			s.leave()
+			ends = ends[:len(ends)-1]
+		}
+		if b, ok := split[i]; ok {
+			// Условие на вершине стека, а перед ветвью else - под значением ветви then
+			condition := stack[len(stack)-1]
+			if !b.value {
+				condition = stack[len(stack)-2]
 			}
+			s.enter(condition, b.value)
+			ends = append(ends, b.end)
+		}
 
-			//  Извлекаем операнды из стека
-			operand2 := stack[len(stack)-1]
-			operand1 := stack[len(stack)-2]
-			stack = stack[:len(stack)-2] //  Удаляем операнды из стека
-
-			task := newTask(token) // Создаем новую задачу для оператора
-
-			//  Заполняем аргументы задачи (либо значениями, либо индексами зависимостей)
-			if operand1.Result != nil {
-				val := *operand1.Result
-				task.Args[0] = &val // Используем значение
-			} else {
-				task.DependencyIndexes[0] = int(operand1.ID) //  Устанавливаем индекс зависимости
+		if len(open) > 0 && open[len(open)-1].body == i {
+			// Тело подставляется при вызове: его токены пропускаются
+			skip = open[len(open)-1].end
+			continue
+		}
+		if l, ok := loops[i]; ok {
+			// Переменная связывается при подстановке тела
+			open = append(open, l)
+			s.constant++
+			if l.body == i {
+				skip = l.end
 			}
+			continue
+		}
+		if len(open) > 0 && open[len(open)-1].variable == i {
+			continue
+		}
 
-			if operand2.Result != nil {
-				val := *operand2.Result
-				task.Args[1] = &val //  Используем значение
-			} else {
-				task.DependencyIndexes[1] = int(operand2.ID) //  Устанавливаем индекс зависимости
+		if len(open) > 0 && open[len(open)-1].call == i {
+			l := open[len(open)-1]
+			open = open[:len(open)-1]
+			s.constant--
+			n := len(args[i]) - 2 // Аргументы, кроме тела и переменной
+			if len(stack) < n {
+				return nil, units.Dimension{}, newParseError(CodeNotEnoughOperands, errNotEnoughOperands, tok, "операнд")
 			}
-
-			indexer++ //  Увеличиваем счетчик
-			task.ID = int64(indexer)
-			tasks = append(tasks, &task)               //  Добавляем задачу в срез
-			stack = append(stack, tasks[len(tasks)-1]) //  Помещаем задачу в стек
-
-		case operators.OpUnaryMinus:
-			//  Обработка унарного минуса
-			if len(stack) < 1 {
-				//  Недостаточно операндов на стеке
-				return nil, errUnaryMinus
+			value, dim, err := s.expand(l, rpn, stack[len(stack)-n:], dims[len(dims)-n:])
+			if err != nil {
+				return nil, units.Dimension{}, err
 			}
+			stack = append(stack[:len(stack)-n], value)
+			sources = append(sources[:len(sources)-n], span(rpn[min(l.variable, l.body):l.call+1]))
+			dims = append(dims[:len(dims)-n], dim)
+			continue
+		}
 
-			operand := stack[len(stack)-1]
-			stack = stack[:len(stack)-1] //  Удаляем операнд из стека
-
-			task := newTask(operators.OpUnaryMinus) //  Создаем новую задачу для оператора
+		if tok.kind == tokenCall {
+			// Вызов функции пользователя заменяется задачами ее тела
+			n := tok.args
+			if len(stack) < n {
+				return nil, units.Dimension{}, newParseError(CodeNotEnoughOperands, errNotEnoughOperands, tok, "операнд")
+			}
+			source := span(append([]token{tok}, sources[len(sources)-n:]...))
+			value, dim, err := s.call(tok.text, source, stack[len(stack)-n:], dims[len(dims)-n:])
+			if err != nil {
+				return nil, units.Dimension{}, err
+			}
+			stack = append(stack[:len(stack)-n], value)
+			sources = append(sources[:len(sources)-n], source)
+			dims = append(dims[:len(dims)-n], dim)
+			continue
+		}
 
-			//  Заполняем аргументы задачи (либо значениями, либо индексами зависимостей)
-			if operand.Result != nil {
-				val := *operand.Result
-				task.Args[0] = &val //  Используем значение
-			} else {
-				task.DependencyIndexes[0] = int(operand.ID) // Устанавливаем индекс зависимости
+		symbol := tok.text
+		operator, ok := operators.Lookup(symbol)
+		if !ok {
+			// Обработка ссылок на другие выражения
+			if tok.kind == tokenReference {
+				value, dim, err := s.reference(tok)
+				if err != nil {
+					return nil, units.Dimension{}, err
+				}
+				stack = append(stack, value)
+				sources = append(sources, tok)
+				dims = append(dims, dim)
+				continue
 			}
 
-			indexer++ //  Увеличиваем счетчик
-			task.ID = int64(indexer)
-			tasks = append(tasks, &task)               //  Добавляем задачу в срез
-			stack = append(stack, tasks[len(tasks)-1]) //  Помещаем задачу в стек
+			// Обработка переменных
+			if tok.kind == tokenName {
+				value, bound := s.scope[symbol]
+				if !bound {
+					return nil, units.Dimension{}, newParseError(CodeUnknownVariable, fmt.Errorf("неизвестная переменная: %s", symbol), tok, "")
+				}
+				stack = append(stack, value)
+				sources = append(sources, tok)
+				dims = append(dims, s.dimensions[symbol])
+				continue
+			}
 
-		default:
 			// Обработка чисел (операндов)
-			num, err := strconv.ParseFloat(token, 64)
+			digits, measure, measured := strings.Cut(symbol, " ")
+			digits, imag := strings.CutSuffix(digits, operators.ImaginaryUnit)
+			num, err := strconv.ParseFloat(digits, 64)
 			if err != nil {
 				//  Ошибка при преобразовании токена в число
-				return nil, errRPN
+				return nil, units.Dimension{}, newParseError(CodeInvalidNumber, errRPN, tok, "число")
+			}
+			unit := units.Unit{Scale: 1}
+			if measured {
+				if unit, err = s.parseUnit(token{text: measure, offset: tok.offset, length: tok.length}); err != nil {
+					return nil, units.Dimension{}, err
+				}
+				num *= unit.Scale // Число в основных единицах СИ
+			}
+			dims = append(dims, unit.Dimension)
+			if imag {
+				if !s.complex {
+					return nil, units.Dimension{}, newParseError(CodeImaginaryUnavailable, fmt.Errorf("мнимое число %s допускается только в режиме complex", symbol), tok, "")
+				}
+				stack = append(stack, imaginary(num))
+				sources = append(sources, tok)
+				continue
 			}
 
 			//  Создаем задачу для числа со статусом "completed"
-			stack = append(stack, &models.Task{
-				Status: "completed",
-				Result: &num,
-			})
+			operand := literal(num)
+			switch {
+			case s.exact:
+				// Точное значение по записи числа, а не по float64: 12345678901234567891, 0.1 = 1/10
+				exact := operators.FormatExact(exactValue(num, &tok.digits))
+				operand.ExactResult = &exact
+			case s.precision > 0 && tok.digits != "":
+				// Десятичная запись числа передается агентам без округления до float64
+				decimal := tok.digits
+				operand.ExactResult = &decimal
+			case s.interval:
+				// Число, не представимое точно в float64 (0.1), - интервал, содержащий его точное значение
+				if value := operators.LiteralInterval(num, tok.digits); value.Lo != value.Hi {
+					operand = interval(value)
+				}
+			}
+			stack = append(stack, operand)
+			sources = append(sources, tok)
+			continue
+		}
+
+		//  Обработка операций: извлекаем из стека столько операндов, сколько требует операция
+		arity := operator.ArgCount(tok.args)
+		if len(stack) < arity {
+			//  Недостаточно операндов на стеке
+			if symbol == operators.OpUnaryMinus {
+				return nil, units.Dimension{}, newParseError(CodeUnaryMinus, errUnaryMinus, tok, "операнд")
+			}
+			return nil, units.Dimension{}, newParseError(CodeNotEnoughOperands, errNotEnoughOperands, tok, "операнд")
+		}
+
+		operands := append([]*models.Task(nil), stack[len(stack)-arity:]...)
+		stack = stack[:len(stack)-arity] //  Удаляем операнды из стека
+
+		// Фрагмент операции охватывает оператор и все его операнды
+		source := span(append([]token{tok}, sources[len(sources)-arity:]...))
+		sources = sources[:len(sources)-arity]
+
+		if s.exact && operator.Exact == nil {
+			return nil, units.Dimension{}, newParseError(CodeInexactOperation, fmt.Errorf("операция %s не поддерживает точный режим", symbol), tok, "")
 		}
+		if s.precision > 0 && operator.Decimal == nil {
+			return nil, units.Dimension{}, newParseError(CodeUnsupportedOperation, fmt.Errorf("операция %s не поддерживает десятичный режим", symbol), tok, "")
+		}
+		if s.complex && operator.Complex == nil {
+			return nil, units.Dimension{}, newParseError(CodeUnsupportedOperation, fmt.Errorf("операция %s не поддерживает комплексный режим", symbol), tok, "")
+		}
+		if s.interval && operator.Interval == nil {
+			return nil, units.Dimension{}, newParseError(CodeUnsupportedOperation, fmt.Errorf("операция %s не поддерживает интервальный режим", symbol), tok, "")
+		}
+
+		// Размерность результата по правилу операции: сложение требует одинаковых размерностей и т.п.
+		rule := operator.Dimension
+		if rule == nil {
+			rule = units.Dimensionless
+		}
+		values := make([]*float64, len(operands))
+		for i, operand := range operands {
+			values[i] = operand.Result
+		}
+		dim, err := rule(dims[len(dims)-arity:], values)
+		if err != nil {
+			return nil, units.Dimension{}, newParseError(CodeDimensionMismatch, fmt.Errorf("операция %s: %w", symbol, err), tok, "")
+		}
+		dims = append(dims[:len(dims)-arity], dim)
+
+		if symbol == operators.OpPlusMinus {
+			if !s.interval {
+				return nil, units.Dimension{}, newParseError(CodeIntervalUnavailable, fmt.Errorf("погрешность %s допускается только в режиме interval", symbol), tok, "")
+			}
+			// Число с погрешностью записывается интервалом сразу, как и мнимое число
+			if value, ok := s.uncertain(operator, operands); ok {
+				stack = append(stack, interval(value))
+				sources = append(sources, source)
+				continue
+			}
+		}
+
+		if symbol == operators.FnIf {
+			// Если истинность условия известна, значение if - значение выбранной ветви
+			if value, ok := s.choose(operands); ok {
+				stack = append(stack, value)
+				sources = append(sources, source)
+				continue
+			}
+		}
+
+		stack = append(stack, s.aggregate(operator, operands))
+		sources = append(sources, source)
 	}
 
 	//  Проверка, что в стеке остался только один элемент (корень выражения)
 	if len(stack) != 1 {
-		return nil, errRPN
+		// Лишний операнд не связан с предыдущим оператором
+		return nil, units.Dimension{}, newParseError(CodeMissingOperator, errRPN, sources[1], "оператор")
+	}
+
+	return stack[0], dims[0], nil // Возвращаем значение инструкции и его размерность
+}
+
+// aggregate создает задачи вызова функции с переменным числом аргументов. Вызов с количеством аргументов
+// больше aggregateWidth вычисляется деревом задач, чтобы агенты обрабатывали группы аргументов параллельно:
+// max(a1, ..., a20) = max(max(a1, ..., a8), max(a9, ..., a16), max(a17, ..., a20)), а avg - как сумма,
+// деленная на количество аргументов. Вызовы функций, не допускающих разбиения (median), и остальные операции
+// создаются одной задачей (см. operation).
+//
+// Args:
+//
+//	operator: *operators.Operator - Операция.
+//	operands: []*models.Task - Операнды операции.
+//
+// Returns:
+//
+//	*models.Task - Значение операции: задача или число, если операция свернута.
+func (s *script) aggregate(operator *operators.Operator, operands []*models.Task) *models.Task {
+	if !operator.Variadic || len(operands) <= aggregateWidth {
+		return s.operation(operator, operands)
+	}
+
+	switch {
+	case operator.Symbol == operators.FnAvg:
+		sum, _ := operators.Lookup(operators.FnSum)
+		divide, _ := operators.Lookup(operators.OpDivide)
+		return s.operation(divide, []*models.Task{s.aggregate(sum, operands), literal(float64(len(operands)))})
+	case operator.Associative:
+		groups := make([]*models.Task, 0, (len(operands)+aggregateWidth-1)/aggregateWidth)
+		for start := 0; start < len(operands); start += aggregateWidth {
+			group := operands[start:min(start+aggregateWidth, len(operands))]
+			if len(group) == 1 {
+				groups = append(groups, group[0])
+				continue
+			}
+			groups = append(groups, s.operation(operator, group))
+		}
+		return s.aggregate(operator, groups)
+	default:
+		return s.operation(operator, operands)
+	}
+}
+
+// operation создает задачу операции над операндами. Операция над числами может быть свернута
+// (см. fold), а одинаковые подвыражения вычисляются одной общей задачей (см. taskKey).
+// Задача ветви if выполняется только при выборе ветви (см. enter), а в ветви, которая никогда
+// не выбирается, задачи не создаются.
+//
+// Args:
+//
+//	operator: *operators.Operator - Операция.
+//	operands: []*models.Task - Операнды операции.
+//
+// Returns:
+//
+//	*models.Task - Значение операции: задача или число, если операция свернута.
+//	    В ветви, которая никогда не выбирается, - задача со статусом "skipped", не добавленная в сценарий.
+func (s *script) operation(operator *operators.Operator, operands []*models.Task) *models.Task {
+	// Операция над числами может быть вычислена сразу, согласно политике свертки. Границы рядов и пределы
+	// integrate и solve вычисляются и в ветви, которая никогда не выбирается: по ним проверяется тело
+	if s.constant > 0 || !s.unreachable() {
+		if value, ok := s.fold(operator, operands); ok {
+			return literal(value)
+		}
+	}
+	if s.unreachable() {
+		return &models.Task{Status: "skipped"}
+	}
+
+	// Одинаковые подвыражения вычисляются одной задачей, от которой зависят все использующие их задачи.
+	// В ветви if можно использовать и задачу, выполняемую при части ее условий. Каждый вызов функции
+	// случайных чисел - отдельная задача: rand() + rand() складывает два разных числа
+	key := s.taskKey(operator.Symbol, operands)
+	guards := s.taskGuards()
+	for k := 0; k <= len(guards) && operator.Random == nil; k++ {
+		if existing, ok := s.shared[key+guardKey(guards[:k])]; ok {
+			return existing
+		}
+	}
+	key += guardKey(guards)
+
+	n := len(operands)
+	task := &models.Task{
+		Operation:         operator.Symbol,     // Операция
+		Args:              make([]*float64, n), // Аргументы
+		Dependencies:      make([]int64, n),    // ID зависимостей
+		DependencyIndexes: make([]int, n),      // Индексы зависимостей в срезе tasks
+		Exact:             s.exact,
+		Precision:         s.precision,
+		Complex:           s.complex,
+		Interval:          s.interval,
+		Guards:            guards,
+	}
+	if task.HasExactArgs() {
+		task.ExactArgs = make([]*string, n)
+	}
+	if task.Complex {
+		task.ImagArgs = make([]*float64, n)
+	}
+	if task.Interval {
+		task.UpperArgs = make([]*float64, n)
 	}
 
-	return tasks, nil // Возвращаем созданные задачи
+	// Заполняем аргументы задачи (значениями, индексами зависимостей или ID задач других выражений)
+	for i, operand := range operands {
+		task.Dependencies[i] = -1
+		switch {
+		case operand.Result != nil:
+			val := *operand.Result
+			task.Args[i] = &val // Используем значение
+			switch {
+			case s.exact:
+				exact := operators.FormatExact(exactValue(val, operand.ExactResult))
+				task.ExactArgs[i] = &exact // Точное значение числа по его записи в выражении
+			case s.precision > 0:
+				decimal := strconv.FormatFloat(val, 'g', -1, 64)
+				if operand.ExactResult != nil {
+					decimal = *operand.ExactResult
+				}
+				task.ExactArgs[i] = &decimal // Десятичная запись числа в выражении, не округленная до float64
+			case s.complex:
+				imag := 0.0
+				if operand.ImagResult != nil {
+					imag = *operand.ImagResult
+				}
+				task.ImagArgs[i] = &imag // Мнимая часть числа
+			case s.interval:
+				upper := bounds(operand).Hi
+				task.UpperArgs[i] = &upper // Верхняя граница числа
+			}
+		case s.external[operand]:
+			task.Dependencies[i] = operand.ID // Зависимость от корневой задачи другого выражения
+		default:
+			task.DependencyIndexes[i] = int(operand.ID) // Устанавливаем индекс зависимости
+		}
+	}
+
+	task.ID = int64(len(s.tasks) + 1) // Локальный индекс задачи в сценарии
+	s.tasks = append(s.tasks, task)   // Добавляем задачу в срез
+	if operator.Random == nil {
+		s.shared[key] = task // Запоминаем задачу для одинаковых подвыражений
+	}
+	return task
 }
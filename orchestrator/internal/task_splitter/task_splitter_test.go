@@ -1,12 +1,20 @@
 package task_splitter_test
 
 import (
+	"errors"
 	"github.com/OinkiePie/calc_3/config"
 	"github.com/OinkiePie/calc_3/orchestrator/internal/task_splitter"
 	"github.com/OinkiePie/calc_3/pkg/logger"
+	"github.com/OinkiePie/calc_3/pkg/models"
+	"github.com/OinkiePie/calc_3/pkg/operators"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"log"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -139,7 +147,7 @@ func TestParseExpression(t *testing.T) {
 			expression:  "2 + abc",
 			expectedLen: 0,
 			expectError: true,
-			err:         "неверный синтаксис",
+			err:         "неизвестная переменная: abc",
 		},
 		{
 			name:        "Valid expression: decimal numbers",
@@ -150,9 +158,8 @@ func TestParseExpression(t *testing.T) {
 		{
 			name:        "Valid expression: multiple unary minuses",
 			expression:  "--5 + 3",
-			expectedLen: 0,
-			expectError: true,
-			err:         "недостаточно операндов для унарного минуса",
+			expectedLen: 3,
+			expectError: false,
 		},
 		{
 			name:        "Valid expression: complex expression with multiple operations",
@@ -190,17 +197,1912 @@ func TestParseExpression(t *testing.T) {
 			expectedLen: 3,
 			expectError: false,
 		},
+		{
+			name:        "Function: single call",
+			expression:  "sqrt(16)",
+			expectedLen: 1,
+			expectError: false,
+		},
+		{
+			name:        "Function: calls inside expression",
+			expression:  "sqrt(2) * sin(0.5)",
+			expectedLen: 3,
+			expectError: false,
+		},
+		{
+			name:        "Function: nested calls with unary minus",
+			expression:  "abs(-cos(1 + 2))",
+			expectedLen: 4,
+			expectError: false,
+		},
+		{
+			name:        "Function: unary minus before call",
+			expression:  "-ln(2) ^ 2",
+			expectedLen: 3,
+			expectError: false,
+		},
+		{
+			name:        "Function: missing parentheses",
+			expression:  "sqrt 4",
+			expectedLen: 0,
+			expectError: true,
+			err:         "неверный синтаксис",
+		},
+		{
+			name:        "Function: missing argument",
+			expression:  "exp()",
+			expectedLen: 0,
+			expectError: true,
+			err:         "недостаточно операндов",
+		},
+		{
+			name:        "Function: unknown name",
+			expression:  "foo(1)",
+			expectedLen: 0,
+			expectError: true,
+			err:         "неверный синтаксис",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tasks, err := task_splitter.ParseExpression(tt.expression)
+			plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{})
 			if tt.expectError {
 				assert.Error(t, err)
 				return
 			}
 			assert.NoError(t, err)
-			assert.Equal(t, tt.expectedLen, len(tasks))
+			assert.Equal(t, tt.expectedLen, len(plan.Tasks))
+		})
+	}
+}
+
+func TestParseExpression_Variables(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		variables   map[string]float64
+		expectedLen int
+		expectedArg []float64
+		err         string
+	}{
+		{
+			name:        "Polynomial",
+			expression:  "a*x^2+b",
+			variables:   map[string]float64{"a": 2, "x": 3, "b": 1},
+			expectedLen: 3,
+		},
+		{
+			name:        "Negative value keeps precedence",
+			expression:  "x^2",
+			variables:   map[string]float64{"x": -3},
+			expectedLen: 1,
+			expectedArg: []float64{-3, 2},
+		},
+		{
+			name:        "Binary minus before variable",
+			expression:  "2 - y",
+			variables:   map[string]float64{"y": -4},
+			expectedLen: 1,
+			expectedArg: []float64{2, -4},
+		},
+		{
+			name:        "Names with digits and underscore",
+			expression:  "sqrt(x_1) + x2",
+			variables:   map[string]float64{"x_1": 16, "x2": 1},
+			expectedLen: 2,
+		},
+		{
+			name:       "Unbound variable",
+			expression: "a*x+b",
+			variables:  map[string]float64{"a": 2, "x": 3},
+			err:        "неизвестная переменная: b",
+		},
+		{
+			name:       "Variable shadows function",
+			expression: "sin + 1",
+			variables:  map[string]float64{"sin": 1},
+			err:        "недопустимое имя переменной: sin",
+		},
+		{
+			name:       "Invalid variable name",
+			expression: "1 + 2",
+			variables:  map[string]float64{"1x": 1},
+			err:        "недопустимое имя переменной: 1x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{Variables: tt.variables})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLen, len(plan.Tasks))
+			if tt.expectedArg != nil {
+				for i, arg := range tt.expectedArg {
+					if assert.NotNil(t, plan.Tasks[0].Args[i]) {
+						assert.Equal(t, arg, *plan.Tasks[0].Args[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseExpression_Script(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		expectedLen int
+		variables   []string // имена промежуточных значений в порядке присваивания
+		err         string
+	}{
+		{
+			name:        "Assignments and final statement",
+			expression:  "r = 5; area = 3.14159*r^2; area*2",
+			expectedLen: 3,
+			variables:   []string{"r", "area"},
+		},
+		{
+			name:        "Independent statements",
+			expression:  "a = 1 + 2; b = 3 * 4; a - b",
+			expectedLen: 3,
+			variables:   []string{"a", "b"},
+		},
+		{
+			name:        "Final statement is assignment",
+			expression:  "a = 2 * 3; b = a + 1",
+			expectedLen: 2,
+			variables:   []string{"a", "b"},
+		},
+		{
+			name:        "Trailing separator",
+			expression:  "a = 2 * 3; a + 1;",
+			expectedLen: 2,
+			variables:   []string{"a"},
+		},
+		{
+			name:        "Final statement refers to earlier task",
+			expression:  "a = 2 * 3; b = a + 1; a",
+			expectedLen: 2,
+			variables:   []string{"a", "b"},
+		},
+		{
+			name:       "Redefined variable",
+			expression: "a = 1 + 1; a = 2 + 2; a",
+			err:        "переменная a уже определена",
+		},
+		{
+			name:       "Use before assignment",
+			expression: "a = b + 1; b = 2 + 2",
+			err:        "неизвестная переменная: b",
+		},
+		{
+			name:       "Invalid assignment target",
+			expression: "2 = 1 + 1; 3 * 3",
+			err:        "недопустимое имя переменной: 2",
+		},
+		{
+			name:       "Nothing to calculate",
+			expression: "r = 5; r",
+			err:        "минимум два операнда требуются для расчета",
+		},
+		{
+			name:       "Empty statement",
+			expression: "a = 1 + 1;; a * 2",
+			err:        "не удалось преобразовать RPN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLen, len(plan.Tasks))
+
+			var names []string
+			for _, variable := range plan.Variables {
+				names = append(names, variable.Name)
+			}
+			assert.Equal(t, tt.variables, names)
+		})
+	}
+
+	t.Run("Statements are linked through dependencies", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("r = 5; area = 3.14159*r^2; area*2", task_splitter.Options{})
+		assert.NoError(t, err)
+
+		// r известно сразу, area вычисляется задачей умножения
+		assert.Equal(t, 5.0, *plan.Variables[0].Value)
+		assert.Equal(t, 0, plan.Variables[0].TaskIndex)
+		assert.Nil(t, plan.Variables[1].Value)
+		assert.Equal(t, 2, plan.Variables[1].TaskIndex)
+
+		// Корневая задача последняя и зависит от задачи area
+		root := plan.Tasks[len(plan.Tasks)-1]
+		assert.Equal(t, "*", root.Operation)
+		assert.Equal(t, 2, root.DependencyIndexes[0])
+		assert.Equal(t, 2.0, *root.Args[1])
+	})
+
+	t.Run("Root referring to earlier task is moved to the end", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("a = 2 * 3; b = a + 1; a", task_splitter.Options{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "+", plan.Tasks[0].Operation)
+		assert.Equal(t, "*", plan.Tasks[1].Operation)
+		assert.Equal(t, int64(2), plan.Tasks[1].ID)
+		assert.Equal(t, 2, plan.Tasks[0].DependencyIndexes[0])
+		assert.Equal(t, 2, plan.Variables[0].TaskIndex)
+		assert.Equal(t, 1, plan.Variables[1].TaskIndex)
+	})
+}
+
+func TestParseExpression_References(t *testing.T) {
+	completed := float64(21)
+	resolve := func(id int64) (*task_splitter.Reference, error) {
+		switch id {
+		case 1:
+			return &task_splitter.Reference{Result: &completed}, nil
+		case 2:
+			return &task_splitter.Reference{TaskID: 50}, nil // выражение ещё вычисляется, 50 - ID его корневой задачи
+		}
+		return nil, errors.New("выражение не найдено")
+	}
+
+	t.Run("Completed reference becomes argument", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("$1*2", task_splitter.Options{ResolveReference: resolve})
+		assert.NoError(t, err)
+		if assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, completed, *plan.Tasks[0].Args[0])
+			assert.Equal(t, float64(2), *plan.Tasks[0].Args[1])
+		}
+	})
+
+	t.Run("Pending reference becomes external dependency", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("$2*2+$2", task_splitter.Options{ResolveReference: resolve})
+		assert.NoError(t, err)
+		if assert.Len(t, plan.Tasks, 2) {
+			assert.Nil(t, plan.Tasks[0].Args[0])
+			assert.Equal(t, int64(50), plan.Tasks[0].Dependencies[0])
+			assert.Equal(t, int64(50), plan.Tasks[1].Dependencies[1])
+			assert.Equal(t, 1, plan.Tasks[1].DependencyIndexes[0])
+		}
+	})
+
+	t.Run("Reference assigned to variable", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("x = $2; x+1", task_splitter.Options{ResolveReference: resolve})
+		assert.NoError(t, err)
+		if assert.Len(t, plan.Variables, 1) {
+			assert.Equal(t, int64(50), plan.Variables[0].TaskID)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		expression string
+		options    task_splitter.Options
+		err        string
+	}{
+		{
+			name:       "Reference alone",
+			expression: "$2",
+			options:    task_splitter.Options{ResolveReference: resolve},
+			err:        "минимум два операнда требуются для расчета",
+		},
+		{
+			name:       "Unknown reference",
+			expression: "$3+1",
+			options:    task_splitter.Options{ResolveReference: resolve},
+			err:        "выражение не найдено",
+		},
+		{
+			name:       "References unavailable",
+			expression: "$1+1",
+			err:        "ссылка на выражение $1 недоступна",
+		},
+		{
+			name:       "Reference without ID",
+			expression: "$+1",
+			options:    task_splitter.Options{ResolveReference: resolve},
+			err:        "неверный синтаксис",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := task_splitter.ParseExpression(tt.expression, tt.options)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseExpression_Precedence(t *testing.T) {
+	// Каждое выражение разбирается по текущим и прежним (LEGACY_PRECEDENCE) правилам
+	tests := []struct {
+		expression string
+		want       float64
+		wantErr    string
+		legacy     float64
+		legacyErr  string
+	}{
+		{expression: "2^3^2", want: 512, legacy: 64},
+		{expression: "2^2^3^0", want: 4, legacy: 1},
+		{expression: "(2^3)^2", want: 64, legacy: 64},
+		{expression: "2^(3^2)", want: 512, legacy: 512},
+		{expression: "-2^2", want: -4, legacy: -4},
+		{expression: "(-2)^2", want: 4, legacy: 4},
+		{expression: "2^-1", want: 0.5, legacyErr: "недостаточно операндов"},
+		{expression: "2^-1^2", want: 0.5, legacyErr: "недостаточно операндов"},
+		{expression: "2*-3", want: -6, legacy: -6},
+		{expression: "-2*3", want: -6, legacy: -6},
+		{expression: "--5+3", want: 8, legacyErr: "недостаточно операндов для унарного минуса"},
+		{expression: "-sin(0)^2+1", want: 1, legacy: 1},
+		{expression: "10-4-3", want: 3, legacy: 3},
+		{expression: "64/4/2", want: 8, legacy: 8},
+		{expression: "2^3!", want: 64, legacy: 64},
+		{expression: "-3!+10", want: 4, legacy: 4},
+		{expression: "3!!", want: 720, legacy: 720},
+		{expression: "(1+2)!*2", want: 12, legacy: 12},
+		{expression: "3! - 1", want: 5, legacy: 5},
+		{expression: "3!+1", want: 7, legacy: 7},
+		{expression: "7 + 10 % 4 * 2", want: 11, legacy: 11},
+		{expression: "-7//2", want: -4, legacy: -4},
+		{expression: "100//7%3", want: 2, legacy: 2},
+		{expression: "gcd(12, 18) + lcm(4, 6)", want: 18, legacy: 18},
+		{expression: "3! != 6", want: 0, legacy: 0},
+		{expression: "-", wantErr: "недостаточно операндов для унарного минуса", legacyErr: "недостаточно операндов для унарного минуса"},
+	}
+
+	for _, tt := range tests {
+		for _, legacy := range []bool{false, true} {
+			want, wantErr := tt.want, tt.wantErr
+			name := tt.expression
+			if legacy {
+				want, wantErr = tt.legacy, tt.legacyErr
+				name += " legacy"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{LegacyPrecedence: legacy})
+				if wantErr != "" {
+					assert.EqualError(t, err, wantErr)
+					return
+				}
+				if assert.NoError(t, err) {
+					assert.InDelta(t, want, evaluatePlan(t, plan), 1e-12)
+				}
+			})
+		}
+	}
+}
+
+// evaluatePlan вычисляет задачи плана так же, как это делают агенты, и возвращает результат корневой задачи.
+func evaluatePlan(t *testing.T, plan *task_splitter.Plan) float64 {
+	return evaluateTasks(t, plan)[len(plan.Tasks)]
+}
+
+// evaluateTasks вычисляет задачи плана так же, как это делают агенты, и возвращает их результаты по локальному индексу.
+// Задача вычисляется после своих зависимостей: корневая задача стоит последней, даже если от нее зависят оценки.
+func evaluateTasks(t *testing.T, plan *task_splitter.Plan) []float64 {
+	results := make([]float64, len(plan.Tasks)+1) // Результаты задач по локальному индексу
+	done := make([]bool, len(plan.Tasks)+1)
+	var evaluate func(task *models.Task)
+	evaluate = func(task *models.Task) {
+		if done[task.ID] {
+			return
+		}
+		done[task.ID] = true
+		// Задача невыбранной ветви if пропускается, а ее результат считается нулевым
+		for _, guard := range task.Guards {
+			evaluate(plan.Tasks[guard.ConditionIndex-1])
+			if (results[guard.ConditionIndex] != 0) != guard.Value {
+				return
+			}
+		}
+		operator, ok := operators.Lookup(task.Operation)
+		if !assert.True(t, ok, task.Operation) {
+			return
+		}
+		args := make([]float64, operator.ArgCount(len(task.Args)))
+		for i := range args {
+			if task.Args[i] == nil {
+				evaluate(plan.Tasks[task.DependencyIndexes[i]-1])
+			}
+			args[i] = argument(task, i, results)
+		}
+		var result float64
+		var err error
+		if operator.Random != nil {
+			result, err = operator.Random(task.Seed, args...)
+		} else {
+			result, err = operator.Eval(args...)
+		}
+		assert.NoError(t, err)
+		results[task.ID] = result
+	}
+	for _, task := range plan.Tasks {
+		evaluate(task)
+	}
+	return results
+}
+
+// argument возвращает значение i-го аргумента задачи: число или результат задачи, от которой она зависит.
+func argument(task *models.Task, i int, results []float64) float64 {
+	if task.Args[i] != nil {
+		return *task.Args[i]
+	}
+	return results[task.DependencyIndexes[i]]
+}
+
+func TestParseExpression_Literals(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		operation  string
+		args       []float64
+		err        string
+	}{
+		{name: "Scientific notation", expression: "1e-3 + 2", operation: "+", args: []float64{0.001, 2}},
+		{name: "Scientific notation with sign", expression: "2.5E+2*1", operation: "*", args: []float64{250, 1}},
+		{name: "Hex and binary", expression: "0xFF - 0b1010", operation: "-", args: []float64{255, 10}},
+		{name: "Digit grouping", expression: "1_000_000 / 0xFF_FF", operation: "/", args: []float64{1000000, 65535}},
+		{name: "Separator after prefix", expression: "1_000_000 / 0x_10", err: "неверная запись числа 0x_10 в позиции 13"},
+		{name: "Unicode multiplication", expression: "6 × 7", operation: "*", args: []float64{6, 7}},
+		{name: "Unicode division", expression: "8÷2", operation: "/", args: []float64{8, 2}},
+		{name: "Unicode minus", expression: "5 − 3", operation: "-", args: []float64{5, 3}},
+		{name: "Unicode minus in exponent", expression: "1e−3 + 1", operation: "+", args: []float64{0.001, 1}},
+		{name: "Explicit plus sign", expression: "2*+3", operation: "*", args: []float64{2, 3}},
+		{name: "Unknown character", expression: "2 # 3", err: "недопустимый символ '#' в позиции 3"},
+		{name: "Unknown character after spaces", expression: "  2 + @", err: "недопустимый символ '@' в позиции 7"},
+		{name: "Double separator", expression: "1__0 + 1", err: "неверная запись числа 1__0 в позиции 1"},
+		{name: "Trailing separator", expression: "2 + 1_", err: "неверная запись числа 1_ в позиции 5"},
+		{name: "Hex without digits", expression: "0x + 1", err: "неверная запись числа 0x в позиции 1"},
+		{name: "Several points", expression: "1.2.3 + 1", err: "неверная запись числа 1.2.3 в позиции 1"},
+		{name: "Out of range", expression: "1e999 * 2", err: "неверная запись числа 1e999 в позиции 1"},
+		{name: "Exponent sign without digits", expression: "1e+ 2", err: "неверная запись числа 1e+: нет цифр порядка в позиции 2"},
+		{name: "Exponent without digits", expression: "3 * 2e", err: "неверная запись числа 2e: нет цифр порядка в позиции 6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			if !assert.NoError(t, err) || !assert.Len(t, plan.Tasks, 1) {
+				return
+			}
+			assert.Equal(t, tt.operation, plan.Tasks[0].Operation)
+			for i, arg := range tt.args {
+				if assert.NotNil(t, plan.Tasks[0].Args[i]) {
+					assert.Equal(t, arg, *plan.Tasks[0].Args[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseExpression_ParseError(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		code       string
+		offset     int
+		length     int
+		expected   string
+	}{
+		{name: "Unknown character", expression: "2 # 3", code: task_splitter.CodeInvalidCharacter, offset: 2, length: 1},
+		{name: "Offset is counted in bytes", expression: "2 × #", code: task_splitter.CodeInvalidCharacter, offset: 5, length: 1},
+		{name: "Invalid number", expression: "1 + 1__0", code: task_splitter.CodeInvalidNumber, offset: 4, length: 4, expected: "число"},
+		{name: "Exponent without digits", expression: "1 + 12e-", code: task_splitter.CodeInvalidNumber, offset: 6, length: 2, expected: "число"},
+		{name: "Unclosed paren", expression: "(4+2", code: task_splitter.CodeUnclosedParen, offset: 0, length: 1, expected: ")"},
+		{name: "Unopened paren", expression: "4+2)", code: task_splitter.CodeUnopenedParen, offset: 3, length: 1},
+		{name: "Function without paren", expression: "1 + sin 2", code: task_splitter.CodeInvalidSyntax, offset: 4, length: 3, expected: "("},
+		{name: "Not enough operands", expression: "2 +", code: task_splitter.CodeNotEnoughOperands, offset: 2, length: 1, expected: "операнд"},
+		{name: "Unary minus without operand", expression: "-", code: task_splitter.CodeUnaryMinus, offset: 0, length: 1, expected: "операнд"},
+		{name: "Missing operator", expression: "2 3", code: task_splitter.CodeMissingOperator, offset: 2, length: 1, expected: "оператор"},
+		{name: "Missing operator after operation", expression: "(1+2) 3*4", code: task_splitter.CodeMissingOperator, offset: 6, length: 3, expected: "оператор"},
+		{name: "Unknown variable", expression: "1 + abc", code: task_splitter.CodeUnknownVariable, offset: 4, length: 3},
+		{name: "Duplicate variable", expression: "x = 1; x = 2; x", code: task_splitter.CodeDuplicateVariable, offset: 7, length: 1},
+		{name: "Invalid variable name", expression: "2x = 1; 3+1", code: task_splitter.CodeInvalidName, offset: 0, length: 2, expected: "имя переменной"},
+		{name: "No operators", expression: "x = 1; x", code: task_splitter.CodeNoOperators, offset: 7, length: 1, expected: "оператор"},
+		{name: "Empty statement", expression: "1+1;;2+2", code: task_splitter.CodeInvalidSyntax, offset: 4, length: 0, expected: "выражение"},
+		{name: "Factorial without operand", expression: "2 * !3", code: task_splitter.CodeInvalidSyntax, offset: 4, length: 1, expected: "операнд"},
+		{name: "Arguments of function without parameters", expression: "rand(1) + 1", code: task_splitter.CodeArgumentCount, offset: 0, length: 7},
+		{name: "Reference unavailable", expression: "$1 + 1", code: task_splitter.CodeReferenceUnavailable, offset: 0, length: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{})
+
+			var parseErr *task_splitter.ParseError
+			if assert.ErrorAs(t, err, &parseErr) {
+				assert.Equal(t, tt.code, parseErr.Code)
+				assert.Equal(t, tt.offset, parseErr.Offset)
+				assert.Equal(t, tt.length, parseErr.Length)
+				assert.Equal(t, tt.expected, parseErr.Expected)
+				assert.Equal(t, parseErr.Message, parseErr.Error())
+			}
+		})
+	}
+}
+
+func TestParseExpression_Explain(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		rpn         [][]string
+		depth       int
+		parallelism int
+	}{
+		{name: "Precedence", expression: "2+3*4", rpn: [][]string{{"2", "3", "4", "*", "+"}}, depth: 2, parallelism: 1},
+		{name: "Independent brackets", expression: "(1+2)*(3+4)", rpn: [][]string{{"1", "2", "+", "3", "4", "+", "*"}}, depth: 2, parallelism: 2},
+		{name: "Unary minus", expression: "-2^2", rpn: [][]string{{"2", "2", "^", "u-"}}, depth: 2, parallelism: 1},
+		{name: "Chain", expression: "1+2+3+4+5", rpn: [][]string{{"1", "2", "+", "3", "+", "4", "+", "5", "+"}}, depth: 4, parallelism: 1},
+		{name: "Units and conversion", expression: "5 km / 2 h in km/h", rpn: [][]string{{"5 km", "2 h", "/", "0.2777777777777778", "/"}}, depth: 2, parallelism: 1},
+		{name: "Balanced", expression: "(1+2)+(3+4)+(5+6)+(7+8)", rpn: [][]string{{"1", "2", "+", "3", "4", "+", "+", "5", "6", "+", "+", "7", "8", "+", "+"}}, depth: 4, parallelism: 4},
+		{
+			name:        "Script",
+			expression:  "a = 1+2; b = 3*4; a - b",
+			rpn:         [][]string{{"1", "2", "+"}, {"3", "4", "*"}, {"a", "b", "-"}},
+			depth:       2,
+			parallelism: 2,
+		},
+		{
+			name:        "Script root moved to end",
+			expression:  "x = 2+3; y = x*2; x",
+			rpn:         [][]string{{"2", "3", "+"}, {"x", "2", "*"}, {"x"}},
+			depth:       2,
+			parallelism: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{})
+			assert.NoError(t, err)
+
+			var rpn [][]string
+			for _, statement := range plan.RPN {
+				var texts []string
+				for _, tok := range statement {
+					texts = append(texts, tok.Text)
+				}
+				rpn = append(rpn, texts)
+			}
+			assert.Equal(t, tt.rpn, rpn)
+			assert.Equal(t, tt.depth, plan.Depth())
+			assert.Equal(t, tt.parallelism, plan.Parallelism())
+		})
+	}
+
+	t.Run("Tokens", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("sin(x) × 0x10", task_splitter.Options{Variables: map[string]float64{"x": 1}})
+		assert.NoError(t, err)
+		assert.Equal(t, []task_splitter.Token{
+			{Kind: task_splitter.TokenName, Text: "sin", Offset: 0, Length: 3},
+			{Kind: task_splitter.TokenSymbol, Text: "(", Offset: 3, Length: 1},
+			{Kind: task_splitter.TokenName, Text: "x", Offset: 4, Length: 1},
+			{Kind: task_splitter.TokenSymbol, Text: ")", Offset: 5, Length: 1},
+			{Kind: task_splitter.TokenSymbol, Text: "*", Offset: 7, Length: 2},
+			{Kind: task_splitter.TokenNumber, Text: "16", Offset: 10, Length: 4},
+		}, plan.Tokens)
+	})
+}
+
+func TestParseExpression_BalanceChains(t *testing.T) {
+	longChain := "1"
+	longValue := 1.0
+	for i := 2; i <= 1000; i++ {
+		longChain += "+" + strconv.Itoa(i)
+		longValue += float64(i)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		value      float64
+		depth      int // Глубина без перестроения
+		balanced   int // Глубина с перестроением
+	}{
+		{name: "Addition chain", expression: "1+2+3+4", value: 10, depth: 3, balanced: 2},
+		{name: "Multiplication chain", expression: "1*2*3*4*5*6*7*8", value: 40320, depth: 7, balanced: 3},
+		{name: "Long chain", expression: longChain, value: longValue, depth: 999, balanced: 10},
+		{name: "Brackets are part of the chain", expression: "(1+2)+(3+4)+(5+6)+(7+8)", value: 36, depth: 4, balanced: 3},
+		{name: "Nested chains", expression: "1+2+3*4*5*6+7+8", value: 378, depth: 6, balanced: 4},
+		{name: "Chain inside function", expression: "sqrt(1+2+3+4+5+6+7+8+9+16)", value: math.Sqrt(61), depth: 10, balanced: 5},
+		{name: "Subtraction is not associative", expression: "10-1-2-3", value: 4, depth: 3, balanced: 3},
+		{name: "Power is not associative", expression: "2^1^2^2", value: 2, depth: 3, balanced: 3},
+		{name: "Mixed operators are not reordered", expression: "1+2-3+4", value: 4, depth: 3, balanced: 3},
+		{name: "Script", expression: "a = 1+2+3+4; a*a*a*a", value: 10000, depth: 6, balanced: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{})
+			if !assert.NoError(t, err) {
+				return
+			}
+			balanced, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{BalanceChains: true})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.depth, plain.Depth())
+			assert.Equal(t, tt.balanced, balanced.Depth())
+			assert.LessOrEqual(t, len(balanced.Tasks), len(plain.Tasks)) // Одинаковые половины цепочки вычисляются одной задачей
+			assert.InDelta(t, tt.value, evaluatePlan(t, plain), 1e-9)
+			assert.InDelta(t, tt.value, evaluatePlan(t, balanced), 1e-9)
+		})
+	}
+
+	t.Run("Errors are reported as without balancing", func(t *testing.T) {
+		for _, expression := range []string{"1+2+", "1 2+3", "+"} {
+			_, want := task_splitter.ParseExpression(expression, task_splitter.Options{})
+			_, got := task_splitter.ParseExpression(expression, task_splitter.Options{BalanceChains: true})
+			assert.Equal(t, want, got, expression)
+		}
+	})
+}
+
+func TestParseExpression_CommonSubexpressions(t *testing.T) {
+	variables := map[string]float64{"a": 1, "b": 2}
+
+	tests := []struct {
+		name       string
+		expression string
+		tasks      int
+		value      float64
+	}{
+		{name: "Repeated subtree", expression: "(a+b)*(a+b) - (a+b)/2", tasks: 4, value: 7.5},
+		{name: "Repeated literal operation", expression: "(1+2)*(1+2)", tasks: 2, value: 9},
+		{name: "Nested repeated subtree", expression: "sqrt(a*b+1)+sqrt(a*b+1)", tasks: 4, value: 2 * math.Sqrt(3)},
+		{name: "Operands order matters", expression: "2*3 + 3*2", tasks: 3, value: 12},
+		{name: "Different operations", expression: "(a+b)*(a-b)", tasks: 3, value: -3},
+		{name: "Repeated statements", expression: "x = a+b; y = a+b; x*y", tasks: 2, value: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{Variables: variables})
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Len(t, plan.Tasks, tt.tasks)
+			assert.InDelta(t, tt.value, evaluatePlan(t, plan), 1e-12)
+		})
+	}
+
+	t.Run("Shared task feeds several consumers", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("(a+b)*(a+b) - (a+b)/2", task_splitter.Options{Variables: variables})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		sum, product, quotient, root := plan.Tasks[0], plan.Tasks[1], plan.Tasks[2], plan.Tasks[3]
+		assert.Equal(t, operators.OpAdd, sum.Operation)
+		assert.Equal(t, []int{1, 1}, product.DependencyIndexes)
+		assert.Equal(t, []int{1, 0}, quotient.DependencyIndexes)
+		assert.Equal(t, []int{2, 3}, root.DependencyIndexes)
+	})
+
+	t.Run("Repeated pending reference", func(t *testing.T) {
+		resolve := func(id int64) (*task_splitter.Reference, error) {
+			return &task_splitter.Reference{TaskID: 42}, nil
+		}
+		plan, err := task_splitter.ParseExpression("$7*2 + $7*2", task_splitter.Options{ResolveReference: resolve})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, plan.Tasks, 2)
+		assert.Equal(t, int64(42), plan.Tasks[0].Dependencies[0])
+		assert.Equal(t, []int{1, 1}, plan.Tasks[1].DependencyIndexes)
+	})
+
+	t.Run("Variables share the task", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("x = a+b; y = a+b; x*y", task_splitter.Options{Variables: variables})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, plan.Variables[0].TaskIndex, plan.Variables[1].TaskIndex)
+	})
+	t.Run("Random calls are not shared", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("randint(1,6) + randint(1,6)", task_splitter.Options{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, plan.Tasks, 3)
+		assert.Equal(t, []int{1, 2}, plan.Tasks[2].DependencyIndexes)
+
+		// Переменная хранит одно случайное число
+		plan, err = task_splitter.ParseExpression("x = rand(); x + x", task_splitter.Options{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, plan.Tasks, 2)
+	})
+}
+
+func TestParseExpression_Fold(t *testing.T) {
+	times := map[string]int{"TIME_MULTIPLICATION_MS": 5, "TIME_ADDITION_MS": 100}
+	operationTime := func(key string) (int, bool) {
+		timeMs, ok := times[key]
+		return timeMs, ok
+	}
+	// $1 - ещё не вычисленное выражение: операции над ним свернуть нельзя
+	resolve := func(id int64) (*task_splitter.Reference, error) {
+		return &task_splitter.Reference{TaskID: 42}, nil
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		opts       task_splitter.Options
+		tasks      int
+		folded     int
+	}{
+		{name: "None", expression: "2*3 + $1", opts: task_splitter.Options{Fold: task_splitter.FoldNone}, tasks: 2, folded: 0},
+		{name: "Default is none", expression: "2*3 + $1", tasks: 2, folded: 0},
+		{name: "Literal", expression: "2*3 + $1", opts: task_splitter.Options{Fold: task_splitter.FoldLiteral}, tasks: 1, folded: 1},
+		{name: "Literal subtree", expression: "sqrt(2*8) + (1-3)^2 * $1", opts: task_splitter.Options{Fold: task_splitter.FoldLiteral}, tasks: 2, folded: 4},
+		{name: "Variables are numbers", expression: "x*(1+1) + $1", opts: task_splitter.Options{Fold: task_splitter.FoldLiteral, Variables: map[string]float64{"x": 2}}, tasks: 1, folded: 2},
+		{name: "Unary minus", expression: "-5 + $1", opts: task_splitter.Options{Fold: task_splitter.FoldUnary}, tasks: 1, folded: 1},
+		{name: "Unary does not fold binary", expression: "2*3 + $1", opts: task_splitter.Options{Fold: task_splitter.FoldUnary}, tasks: 2, folded: 0},
+		{
+			name:       "Cost below threshold",
+			expression: "2*3 + (4+5)*$1",
+			opts:       task_splitter.Options{Fold: task_splitter.FoldCost, FoldThreshold: 10, OperationTime: operationTime},
+			tasks:      3,
+			folded:     1,
+		},
+		{
+			name:       "Cost above threshold",
+			expression: "2*3 + (4+5)*$1",
+			opts:       task_splitter.Options{Fold: task_splitter.FoldCost, FoldThreshold: 100, OperationTime: operationTime},
+			tasks:      2,
+			folded:     2,
+		},
+		{name: "Cost without timings", expression: "2*3 + $1", opts: task_splitter.Options{Fold: task_splitter.FoldCost}, tasks: 2, folded: 0},
+		{name: "Errors are left to agents", expression: "1/0 + $1", opts: task_splitter.Options{Fold: task_splitter.FoldLiteral}, tasks: 2, folded: 0},
+		{name: "Random functions are left to agents", expression: "randint(1,6)*2 + $1", opts: task_splitter.Options{Fold: task_splitter.FoldLiteral}, tasks: 3, folded: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.ResolveReference = resolve
+			plan, err := task_splitter.ParseExpression(tt.expression, tt.opts)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Len(t, plan.Tasks, tt.tasks)
+			assert.Equal(t, tt.folded, plan.Folded)
+			assert.Nil(t, plan.Result)
+		})
+	}
+
+	t.Run("Fully folded expression", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("(2*3)*(2*3) - 1", task_splitter.Options{Fold: task_splitter.FoldLiteral})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Empty(t, plan.Tasks)
+		assert.Equal(t, 4, plan.Folded)
+		if assert.NotNil(t, plan.Result) {
+			assert.Equal(t, float64(35), *plan.Result)
+		}
+		assert.Equal(t, 0, plan.Depth())
+	})
+
+	t.Run("Folded variable", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("r = 2*3; r*$1", task_splitter.Options{Fold: task_splitter.FoldLiteral, ResolveReference: resolve})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, plan.Tasks, 1)
+		if assert.Len(t, plan.Variables, 1) && assert.NotNil(t, plan.Variables[0].Value) {
+			assert.Equal(t, float64(6), *plan.Variables[0].Value)
+			assert.Equal(t, 0, plan.Variables[0].TaskIndex)
+		}
+	})
+
+	t.Run("Single number is still an error", func(t *testing.T) {
+		_, err := task_splitter.ParseExpression("42", task_splitter.Options{Fold: task_splitter.FoldLiteral})
+		assert.EqualError(t, err, "минимум два операнда требуются для расчета")
+	})
+}
+
+func TestParseExpression_AST(t *testing.T) {
+	variables := map[string]float64{"a": 1, "b": 2, "c": 3, "x": 4, "y": 5}
+
+	t.Run("Printed expression parses into the same tree", func(t *testing.T) {
+		for _, expression := range []string{
+			"2+3*4", "(2+3)*4", "2^3^2", "(2^3)^2", "-2^2", "(-2)^2", "2^-1+1", "a-(b-c)", "a/(b*c)",
+			"-(2*3)", "2*-3", "x - -y", "sin(x+1)^2", "2^-(1+1)", "1e21+0x10", "r = 5; area = 3*r^2; area*2",
+			"2^3!", "-3!", "(-3)!", "(x+1)!!", "x//y%2", "isprime(x!+1)",
+		} {
+			plan, err := task_splitter.ParseExpression(expression, task_splitter.Options{Variables: variables})
+			if !assert.NoError(t, err, expression) {
+				continue
+			}
+			printed := plan.AST.String()
+			reparsed, err := task_splitter.ParseExpression(printed, task_splitter.Options{Variables: variables})
+			if assert.NoError(t, err, printed) {
+				assert.Equal(t, plan.AST, reparsed.AST, "%s -> %s", expression, printed)
+			}
+		}
+	})
+
+	t.Run("Tree is built before balancing", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("1+2+3+4", task_splitter.Options{BalanceChains: true})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "1 + 2 + 3 + 4", plan.AST.String())
+		}
+	})
+
+	t.Run("Equivalent expressions share the canonical form", func(t *testing.T) {
+		tests := []struct {
+			expressions []string
+			canonical   string
+		}{
+			{expressions: []string{"x+2*3", "3*2+x", "(x)+(3*2)"}, canonical: "x + 2 * 3"},
+			{expressions: []string{"(c+a)+b", "a+(b+c)", "b+c+a"}, canonical: "a + b + c"},
+			{expressions: []string{"0x10 * $1", "$1*16.0"}, canonical: "16 * $1"},
+			{expressions: []string{"k = y*x; k - -1", "k=x*y;k--1"}, canonical: "k = x * y; k - -1"},
+		}
+		resolve := func(id int64) (*task_splitter.Reference, error) {
+			return &task_splitter.Reference{TaskID: 42}, nil
+		}
+		for _, tt := range tests {
+			for _, expression := range tt.expressions {
+				plan, err := task_splitter.ParseExpression(expression, task_splitter.Options{Variables: variables, ResolveReference: resolve})
+				if assert.NoError(t, err, expression) {
+					assert.Equal(t, tt.canonical, plan.Canonical, expression)
+				}
+			}
+		}
+	})
+}
+
+func TestParseExpression_Exact(t *testing.T) {
+	exactArgs := func(task *models.Task) []string {
+		args := make([]string, len(task.ExactArgs))
+		for i, arg := range task.ExactArgs {
+			if arg != nil {
+				args[i] = *arg
+			}
+		}
+		return args
+	}
+
+	t.Run("Literals are passed as fractions", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("0.1 + 0.2 * x", task_splitter.Options{Exact: true, Variables: map[string]float64{"x": 2.5}})
+		if !assert.NoError(t, err) {
+			return
+		}
+		if assert.Len(t, plan.Tasks, 2) {
+			multiply, add := plan.Tasks[0], plan.Tasks[1]
+			assert.True(t, multiply.Exact)
+			assert.Equal(t, []string{"1/5", "5/2"}, exactArgs(multiply))
+			assert.True(t, add.Exact)
+			assert.Equal(t, []string{"1/10", ""}, exactArgs(add))
+			assert.Equal(t, 1, add.DependencyIndexes[1])
+		}
+	})
+
+	t.Run("Literals are not rounded to float64", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("12345678901234567891 - 0x10 + 1e-3 * 0.1", task_splitter.Options{Exact: true})
+		if !assert.NoError(t, err) {
+			return
+		}
+		if assert.Len(t, plan.Tasks, 3) {
+			assert.Equal(t, []string{"12345678901234567891", "16"}, exactArgs(plan.Tasks[0]))
+			assert.Equal(t, []string{"1/1000", "1/10"}, exactArgs(plan.Tasks[1]))
+		}
+	})
+
+	t.Run("Literals equal as float64 are different tasks", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("(12345678901234567890 + x) * (12345678901234567891 + x)", task_splitter.Options{
+			Exact: true, Variables: map[string]float64{"x": 1},
+		})
+		if assert.NoError(t, err) {
+			assert.Len(t, plan.Tasks, 3)
+		}
+	})
+
+	t.Run("Completed reference keeps exact result", func(t *testing.T) {
+		result, exact := 1.0/3, "1/3"
+		resolve := func(id int64) (*task_splitter.Reference, error) {
+			return &task_splitter.Reference{Result: &result, ExactResult: &exact}, nil
+		}
+		plan, err := task_splitter.ParseExpression("$1 * 3", task_splitter.Options{Exact: true, ResolveReference: resolve})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, []string{"1/3", "3"}, exactArgs(plan.Tasks[0]))
+		}
+
+		// Десятичный результат выражения другого режима становится дробью
+		exact = "0.1"
+		plan, err = task_splitter.ParseExpression("$1 * 3", task_splitter.Options{Exact: true, ResolveReference: resolve})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, []string{"1/10", "3"}, exactArgs(plan.Tasks[0]))
+		}
+	})
+
+	t.Run("Float mode tasks are not exact", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("0.1 + 0.2", task_splitter.Options{})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.False(t, plan.Tasks[0].Exact)
+			assert.Nil(t, plan.Tasks[0].ExactArgs)
+		}
+	})
+
+	t.Run("Constants are not folded", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("2*3 + 1", task_splitter.Options{Exact: true, Fold: task_splitter.FoldLiteral})
+		if assert.NoError(t, err) {
+			assert.Len(t, plan.Tasks, 2)
+			assert.Equal(t, 0, plan.Folded)
+		}
+	})
+
+	t.Run("Inexact operation is an error", func(t *testing.T) {
+		_, err := task_splitter.ParseExpression("1 + sqrt(2)", task_splitter.Options{Exact: true})
+		var parseErr *task_splitter.ParseError
+		if assert.True(t, errors.As(err, &parseErr)) {
+			assert.Equal(t, task_splitter.CodeInexactOperation, parseErr.Code)
+			assert.Equal(t, 4, parseErr.Offset)
+		}
+	})
+
+	t.Run("Decimal literals are passed as decimal strings", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("2^0.5 * x", task_splitter.Options{
+			Precision: 50, Fold: task_splitter.FoldLiteral, Variables: map[string]float64{"x": 1e-7},
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 0, plan.Folded)
+		if assert.Len(t, plan.Tasks, 2) {
+			power, multiply := plan.Tasks[0], plan.Tasks[1]
+			assert.False(t, power.Exact)
+			assert.Equal(t, 50, power.Precision)
+			assert.Equal(t, []string{"2", "0.5"}, exactArgs(power))
+			assert.Equal(t, 50, multiply.Precision)
+			assert.Equal(t, []string{"", "1e-07"}, exactArgs(multiply))
+		}
+	})
+
+	t.Run("Decimal literals keep their digits", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("0.12345678901234567890123456789 * 1_000e-3", task_splitter.Options{Precision: 40})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, []string{"0.12345678901234567890123456789", "1000e-3"}, exactArgs(plan.Tasks[0]))
+		}
+	})
+
+	t.Run("Decimal literals equal as float64 are different tasks", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("(0.1 + x) * (0.10000000000000000001 + x)", task_splitter.Options{
+			Precision: 30, Variables: map[string]float64{"x": 1},
+		})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 3) {
+			assert.Equal(t, []string{"0.1", "1"}, exactArgs(plan.Tasks[0]))
+			assert.Equal(t, []string{"0.10000000000000000001", "1"}, exactArgs(plan.Tasks[1]))
+		}
+	})
+
+	t.Run("Completed rational reference in decimal mode", func(t *testing.T) {
+		result, exact := 1.0/3, "1/3"
+		resolve := func(id int64) (*task_splitter.Reference, error) {
+			return &task_splitter.Reference{Result: &result, ExactResult: &exact}, nil
+		}
+		plan, err := task_splitter.ParseExpression("$1 * 3", task_splitter.Options{Precision: 10, ResolveReference: resolve})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, []string{"0.333333333333333", "3"}, exactArgs(plan.Tasks[0]))
+		}
+	})
+}
+
+func TestParseExpression_Complex(t *testing.T) {
+	imagArgs := func(task *models.Task) []float64 {
+		args := make([]float64, len(task.ImagArgs))
+		for i, arg := range task.ImagArgs {
+			if arg != nil {
+				args[i] = *arg
+			}
+		}
+		return args
+	}
+
+	t.Run("Imaginary literals and unit", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("(1+2i)*(3-i)", task_splitter.Options{Complex: true, Fold: task_splitter.FoldLiteral})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 0, plan.Folded)
+		assert.Equal(t, "(1 + 2i) * (3 - i)", plan.AST.String())
+		if assert.Len(t, plan.Tasks, 3) {
+			sum, difference, product := plan.Tasks[0], plan.Tasks[1], plan.Tasks[2]
+			assert.True(t, sum.Complex)
+			assert.Equal(t, []float64{1, 0}, []float64{*sum.Args[0], *sum.Args[1]})
+			assert.Equal(t, []float64{0, 2}, imagArgs(sum))
+			assert.Equal(t, []float64{3, 0}, []float64{*difference.Args[0], *difference.Args[1]})
+			assert.Equal(t, []float64{0, 1}, imagArgs(difference))
+			assert.True(t, product.Complex)
+			assert.Equal(t, []int{1, 2}, product.DependencyIndexes)
+		}
+	})
+
+	t.Run("Variable i replaces imaginary unit", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("i * 2", task_splitter.Options{Complex: true, Variables: map[string]float64{"i": 3}})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, []float64{0, 0}, imagArgs(plan.Tasks[0]))
+		}
+	})
+
+	t.Run("Imaginary number outside complex mode", func(t *testing.T) {
+		_, err := task_splitter.ParseExpression("1 + 2i", task_splitter.Options{})
+		var parseErr *task_splitter.ParseError
+		if assert.True(t, errors.As(err, &parseErr)) {
+			assert.Equal(t, task_splitter.CodeImaginaryUnavailable, parseErr.Code)
+			assert.Equal(t, 4, parseErr.Offset)
+		}
+	})
+
+	t.Run("Completed reference keeps imaginary part", func(t *testing.T) {
+		result, imag := 0.0, 2.0
+		resolve := func(id int64) (*task_splitter.Reference, error) {
+			return &task_splitter.Reference{Result: &result, ImagResult: &imag}, nil
+		}
+		plan, err := task_splitter.ParseExpression("$1 * i", task_splitter.Options{Complex: true, ResolveReference: resolve})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, []float64{2, 1}, imagArgs(plan.Tasks[0]))
+		}
+	})
+
+	t.Run("Suffix i is not taken from names", func(t *testing.T) {
+		_, err := task_splitter.ParseExpression("2if", task_splitter.Options{Complex: true})
+		var parseErr *task_splitter.ParseError
+		if assert.True(t, errors.As(err, &parseErr)) {
+			assert.NotEqual(t, task_splitter.CodeImaginaryUnavailable, parseErr.Code)
+		}
+	})
+}
+
+func TestParseExpression_Interval(t *testing.T) {
+	bounds := func(args []*float64) []float64 {
+		values := make([]float64, len(args))
+		for i, arg := range args {
+			if arg != nil {
+				values[i] = *arg
+			}
+		}
+		return values
+	}
+
+	t.Run("Uncertain literals", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("3.0±0.1 * 2.0±0.05", task_splitter.Options{Interval: true, Fold: task_splitter.FoldLiteral})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 0, plan.Folded)
+		assert.Equal(t, "3±0.1 * 2±0.05", plan.AST.String())
+		if assert.Len(t, plan.Tasks, 1) {
+			product := plan.Tasks[0]
+			assert.True(t, product.Interval)
+			assert.InDeltaSlice(t, []float64{2.9, 1.95}, bounds(product.Args), 1e-12)
+			assert.InDeltaSlice(t, []float64{3.1, 2.05}, bounds(product.UpperArgs), 1e-12)
+			assert.LessOrEqual(t, *product.Args[0], 2.9)
+			assert.GreaterOrEqual(t, *product.UpperArgs[0], 3.1)
+		}
+	})
+
+	t.Run("Numbers without error are degenerate intervals", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("x + 1", task_splitter.Options{Interval: true, Variables: map[string]float64{"x": 2}})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, []float64{2, 1}, bounds(plan.Tasks[0].Args))
+			assert.Equal(t, []float64{2, 1}, bounds(plan.Tasks[0].UpperArgs))
+		}
+	})
+
+	t.Run("Inexact literals enclose their value", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("x * 0.1", task_splitter.Options{Interval: true, Variables: map[string]float64{"x": 2}})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			// 0.1 в float64 больше 0.1, поэтому нижняя граница на единицу последнего разряда меньше
+			assert.Equal(t, []float64{2, math.Nextafter(0.1, 0)}, bounds(plan.Tasks[0].Args))
+			assert.Equal(t, []float64{2, 0.1}, bounds(plan.Tasks[0].UpperArgs))
+		}
+	})
+
+	t.Run("Completed reference keeps its interval", func(t *testing.T) {
+		mid, lower, upper := 3.0, 2.9, 3.1
+		resolve := func(id int64) (*task_splitter.Reference, error) {
+			return &task_splitter.Reference{Result: &mid, LowerResult: &lower, UpperResult: &upper}, nil
+		}
+		plan, err := task_splitter.ParseExpression("$1 * 2", task_splitter.Options{Interval: true, ResolveReference: resolve})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, []float64{2.9, 2}, bounds(plan.Tasks[0].Args))
+			assert.Equal(t, []float64{3.1, 2}, bounds(plan.Tasks[0].UpperArgs))
+		}
+	})
+
+	t.Run("Error of computed value is a task", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("(1 + 2)±0.1", task_splitter.Options{Interval: true})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 2) {
+			assert.Equal(t, operators.OpPlusMinus, plan.Tasks[1].Operation)
+			assert.Equal(t, []int{1, 0}, plan.Tasks[1].DependencyIndexes)
+		}
+	})
+
+	t.Run("Error outside interval mode", func(t *testing.T) {
+		_, err := task_splitter.ParseExpression("1 + 3±0.1", task_splitter.Options{})
+		var parseErr *task_splitter.ParseError
+		if assert.True(t, errors.As(err, &parseErr)) {
+			assert.Equal(t, task_splitter.CodeIntervalUnavailable, parseErr.Code)
+			assert.Equal(t, 5, parseErr.Offset)
+			assert.Equal(t, len("±"), parseErr.Length)
+		}
+	})
+
+	t.Run("Uncertain number alone has no operators", func(t *testing.T) {
+		_, err := task_splitter.ParseExpression("3±0.1", task_splitter.Options{Interval: true})
+		var parseErr *task_splitter.ParseError
+		if assert.True(t, errors.As(err, &parseErr)) {
+			assert.Equal(t, task_splitter.CodeNoOperators, parseErr.Code)
+		}
+	})
+}
+
+func TestParseExpression_Units(t *testing.T) {
+	t.Run("Literals are converted to SI", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("5 km / 2 h + 3 m/s", task_splitter.Options{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "m/s", plan.Unit)
+		assert.Equal(t, "5 km / 2 h + 3 m/s", plan.AST.String())
+		if assert.Len(t, plan.Tasks, 2) {
+			assert.Equal(t, 5000.0, *plan.Tasks[0].Args[0])
+			assert.Equal(t, 7200.0, *plan.Tasks[0].Args[1])
+			assert.Equal(t, 3.0, *plan.Tasks[1].Args[1])
+		}
+	})
+
+	t.Run("Conversion to target unit", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("5 km / 2 h + 3 m/s in km/h", task_splitter.Options{Fold: task_splitter.FoldLiteral})
+		if assert.NoError(t, err) && assert.NotNil(t, plan.Result) {
+			assert.InDelta(t, 13.3, *plan.Result, 1e-12)
+			assert.Equal(t, "km/h", plan.Unit)
+			assert.Equal(t, "3 m/s + 5 km / 2 h in km/h", plan.Canonical)
+		}
+	})
+
+	t.Run("Conversion is a division task", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("2 h * x in min", task_splitter.Options{Variables: map[string]float64{"x": 3}})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 2) {
+			root := plan.Tasks[1]
+			assert.Equal(t, operators.OpDivide, root.Operation)
+			assert.Equal(t, 60.0, *root.Args[1])
+			assert.Equal(t, "min", plan.Unit)
+		}
+	})
+
+	t.Run("Dimensions of script variables", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("d = 5 km; t = 2 h; d / t", task_splitter.Options{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "m/s", plan.Unit)
+		}
+	})
+
+	t.Run("Powers and roots", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("(2 m)^2 + sqrt(16 m^4)", task_splitter.Options{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "m^2", plan.Unit)
+			assert.Equal(t, "(2 m)^2 + sqrt(16 m^4)", plan.AST.String())
+		}
+	})
+
+	t.Run("Dimensionless result has no unit", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("6 km / 3 m", task_splitter.Options{})
+		if assert.NoError(t, err) {
+			assert.Empty(t, plan.Unit)
+		}
+	})
+
+	// $1 - вычисленное выражение 36 km/h, $2 - ещё вычисляемое выражение в km/h, $3 - безразмерное
+	speed := 36.0
+	resolve := func(id int64) (*task_splitter.Reference, error) {
+		switch id {
+		case 1:
+			return &task_splitter.Reference{Result: &speed, Unit: "km/h"}, nil
+		case 2:
+			return &task_splitter.Reference{TaskID: 50, Unit: "km/h"}, nil
+		}
+		return &task_splitter.Reference{Result: &speed}, nil
+	}
+
+	t.Run("Completed reference keeps its unit", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("$1 + 2 m/s", task_splitter.Options{ResolveReference: resolve})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 1) {
+			assert.Equal(t, "m/s", plan.Unit)
+			assert.InDelta(t, 10, *plan.Tasks[0].Args[0], 1e-12)
+		}
+	})
+
+	t.Run("Pending reference is converted to SI by a task", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("$2 * 2 h + $2 * 1 h", task_splitter.Options{ResolveReference: resolve})
+		if assert.NoError(t, err) && assert.Len(t, plan.Tasks, 4) {
+			assert.Equal(t, "m", plan.Unit)
+			scale := plan.Tasks[0]
+			assert.Equal(t, operators.OpMultiply, scale.Operation)
+			assert.Equal(t, int64(50), scale.Dependencies[0])
+			assert.InDelta(t, 1000.0/3600, *scale.Args[1], 1e-12)
+			assert.Equal(t, []int{1, 0}, plan.Tasks[1].DependencyIndexes)
+			assert.Equal(t, []int{1, 0}, plan.Tasks[2].DependencyIndexes)
+		}
+	})
+
+	errorTests := []struct {
+		name       string
+		expression string
+		opts       task_splitter.Options
+		code       string
+		offset     int
+		length     int
+	}{
+		{name: "adding metres to seconds", expression: "5 m + 2 s", code: task_splitter.CodeDimensionMismatch, offset: 4, length: 1},
+		{name: "adding metres to reference in km/h", expression: "$1 + 3 m", opts: task_splitter.Options{ResolveReference: resolve}, code: task_splitter.CodeDimensionMismatch, offset: 3, length: 1},
+		{name: "dimensionless reference", expression: "$3 + 3 m", opts: task_splitter.Options{ResolveReference: resolve}, code: task_splitter.CodeDimensionMismatch, offset: 3, length: 1},
+		{name: "reference with unit outside float mode", expression: "$2 + 1", opts: task_splitter.Options{Exact: true, ResolveReference: resolve}, code: task_splitter.CodeUnitUnavailable, offset: 0, length: 2},
+		{name: "function of dimensional value", expression: "1 + sin(2 m)", code: task_splitter.CodeDimensionMismatch, offset: 4, length: 3},
+		{name: "unknown exponent of dimensional value", expression: "(2 m)^(x + 1)", opts: task_splitter.Options{Variables: map[string]float64{"x": 1}}, code: task_splitter.CodeDimensionMismatch, offset: 5, length: 1},
+		{name: "conversion to other dimension", expression: "2 m * 3 in s", code: task_splitter.CodeDimensionMismatch, offset: 11, length: 1},
+		{name: "unknown target unit", expression: "2 * 3 in foo", code: task_splitter.CodeUnknownUnit, offset: 9, length: 3},
+		{name: "units outside float mode", expression: "2 m + 1 m", opts: task_splitter.Options{Exact: true}, code: task_splitter.CodeUnitUnavailable, offset: 0, length: 3},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := task_splitter.ParseExpression(tt.expression, tt.opts)
+			var parseErr *task_splitter.ParseError
+			if assert.True(t, errors.As(err, &parseErr)) {
+				assert.Equal(t, tt.code, parseErr.Code)
+				assert.Equal(t, tt.offset, parseErr.Offset)
+				assert.Equal(t, tt.length, parseErr.Length)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Aggregates(t *testing.T) {
+	variables := map[string]float64{"x": 2}
+
+	t.Run("One task with all arguments", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("max(3, 7, 1, x*4)", task_splitter.Options{Variables: variables})
+		if !assert.NoError(t, err) || !assert.Len(t, plan.Tasks, 2) {
+			return
+		}
+		root := plan.Tasks[1]
+		assert.Equal(t, operators.FnMax, root.Operation)
+		assert.Len(t, root.Args, 4)
+		assert.Equal(t, []int{0, 0, 0, 1}, root.DependencyIndexes)
+		assert.Equal(t, 8.0, evaluatePlan(t, plan))
+	})
+
+	values := func(n int) (string, []float64) {
+		args := make([]string, n)
+		numbers := make([]float64, n)
+		for i := range args {
+			args[i] = "x*" + strconv.Itoa(i+1)
+			numbers[i] = variables["x"] * float64(i+1)
+		}
+		return strings.Join(args, ", "), numbers
+	}
+
+	tests := []struct {
+		name     string
+		function string
+		n        int
+		tasks    int // Задачи агрегатной функции без задач аргументов
+		depth    int
+		value    float64
+	}{
+		{name: "Sum of many arguments is a reduction tree", function: "sum", n: 20, tasks: 4, depth: 3, value: 420},
+		{name: "Maximum of many arguments", function: "max", n: 9, tasks: 2, depth: 3, value: 18},
+		{name: "Average is a sum divided by count", function: "avg", n: 10, tasks: 4, depth: 4, value: 11},
+		{name: "Median is a single task", function: "median", n: 12, tasks: 1, depth: 2, value: 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, _ := values(tt.n)
+			plan, err := task_splitter.ParseExpression(tt.function+"("+args+")", task_splitter.Options{Variables: variables})
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Len(t, plan.Tasks, tt.n+tt.tasks)
+			assert.Equal(t, tt.depth, plan.Depth())
+			assert.InDelta(t, tt.value, evaluatePlan(t, plan), 1e-12)
+		})
+	}
+
+	t.Run("Literal arguments are folded", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("min(4, 2, 8) + median(1, 3, 2, 10)", task_splitter.Options{Fold: task_splitter.FoldLiteral})
+		if assert.NoError(t, err) && assert.NotNil(t, plan.Result) {
+			assert.Equal(t, 4.5, *plan.Result)
+		}
+	})
+
+	t.Run("Explain and canonical form", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("max(x, 3, x+1) + 1", task_splitter.Options{Variables: variables})
+		if !assert.NoError(t, err) {
+			return
+		}
+		var texts []string
+		for _, tok := range plan.RPN[0] {
+			texts = append(texts, tok.Text)
+		}
+		assert.Equal(t, []string{"x", "3", "x", "1", "+", "max:3", "1", "+"}, texts)
+		assert.Equal(t, "max(x, 3, x + 1) + 1", plan.AST.String())
+		assert.Equal(t, "1 + max(3, x, 1 + x)", plan.Canonical)
+	})
+
+	errorTests := []struct {
+		name       string
+		expression string
+		code       string
+		offset     int
+		length     int
+	}{
+		{name: "Too many arguments", expression: "sqrt(4, 9)", code: task_splitter.CodeArgumentCount, offset: 0, length: 10},
+		{name: "No arguments", expression: "1 + max()", code: task_splitter.CodeArgumentCount, offset: 4, length: 5},
+		{name: "Comma outside call", expression: "1, 2", code: task_splitter.CodeInvalidSyntax, offset: 1, length: 1},
+		{name: "Comma inside brackets", expression: "max((1, 2))", code: task_splitter.CodeInvalidSyntax, offset: 6, length: 1},
+		{name: "Empty argument", expression: "max(1, , 2)", code: task_splitter.CodeInvalidSyntax, offset: 7, length: 1},
+		{name: "Trailing comma", expression: "max(1, 2,)", code: task_splitter.CodeInvalidSyntax, offset: 9, length: 1},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{})
+			var parseErr *task_splitter.ParseError
+			if assert.True(t, errors.As(err, &parseErr)) {
+				assert.Equal(t, tt.code, parseErr.Code)
+				assert.Equal(t, tt.offset, parseErr.Offset)
+				assert.Equal(t, tt.length, parseErr.Length)
+			}
+		})
+	}
+}
+
+func TestParseFunction(t *testing.T) {
+	functions := map[string]task_splitter.Function{
+		"sq": {Params: []string{"x"}, Body: "x*x"},
+		"g":  {Params: []string{"x"}, Body: "f(x) + 1"},
+	}
+
+	t.Run("Definition", func(t *testing.T) {
+		name, fn, err := task_splitter.ParseFunction("hyp(a, b) = sqrt(sq(a) + sq(b))", functions)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "hyp", name)
+			assert.Equal(t, []string{"a", "b"}, fn.Params)
+			assert.Equal(t, "sqrt(sq(a) + sq(b))", fn.Body)
+		}
+	})
+
+	t.Run("Function without parameters", func(t *testing.T) {
+		name, fn, err := task_splitter.ParseFunction("answer() = 42", nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "answer", name)
+			assert.Empty(t, fn.Params)
+		}
+	})
+
+	t.Run("Parameter dimensions are unknown", func(t *testing.T) {
+		_, _, err := task_splitter.ParseFunction("speed(d, t) = d/t + 1 m/s", nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Limits depend on parameters", func(t *testing.T) {
+		_, _, err := task_splitter.ParseFunction("area(a, b) = integrate(x^2, x, a, b, 10)", nil)
+		assert.NoError(t, err)
+		_, _, err = task_splitter.ParseFunction("total(n) = sum(i, 1, n, i)", nil)
+		assert.NoError(t, err)
+	})
+
+	tests := []struct {
+		name       string
+		definition string
+		code       string
+		offset     int
+		length     int
+	}{
+		{name: "No assignment", definition: "f(x)", code: task_splitter.CodeInvalidFunction, offset: 0, length: 4},
+		{name: "No parameter list", definition: "f = 2", code: task_splitter.CodeInvalidFunction, offset: 0, length: 1},
+		{name: "Builtin function name", definition: "sqrt(x) = x", code: task_splitter.CodeInvalidFunction, offset: 0, length: 4},
+		{name: "Integrate name", definition: "integrate(x) = x", code: task_splitter.CodeInvalidFunction, offset: 0, length: 9},
+		{name: "Duplicate parameter", definition: "f(x, x) = x", code: task_splitter.CodeInvalidFunction, offset: 5, length: 1},
+		{name: "Trailing comma", definition: "f(x,) = x", code: task_splitter.CodeInvalidFunction, offset: 3, length: 1},
+		{name: "Empty body", definition: "f(x) =", code: task_splitter.CodeInvalidSyntax, offset: 5, length: 1},
+		{name: "Unknown variable in body", definition: "f(x) = x + y", code: task_splitter.CodeUnknownVariable, offset: 11, length: 1},
+		{name: "Recursion", definition: "f(x) = f(x - 1)", code: task_splitter.CodeRecursiveFunction, offset: 7, length: 7},
+		{name: "Recursion through other function", definition: "f(x) = g(x)", code: task_splitter.CodeRecursiveFunction, offset: 7, length: 3},
+		{name: "Wrong argument count", definition: "f(x) = sq(x, 2)", code: task_splitter.CodeArgumentCount, offset: 7, length: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := task_splitter.ParseFunction(tt.definition, functions)
+			var parseErr *task_splitter.ParseError
+			if assert.True(t, errors.As(err, &parseErr), "error: %v", err) {
+				assert.Equal(t, tt.code, parseErr.Code)
+				assert.Equal(t, tt.offset, parseErr.Offset)
+				assert.Equal(t, tt.length, parseErr.Length)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Functions(t *testing.T) {
+	functions := map[string]task_splitter.Function{
+		"sq":    {Params: []string{"x"}, Body: "x*x"},
+		"hyp":   {Params: []string{"a", "b"}, Body: "sqrt(sq(a) + sq(b))"},
+		"add":   {Params: []string{"a", "b"}, Body: "a + b"},
+		"twice": {Params: []string{"x"}, Body: "sq(x) + sq(x)"},
+		"wave":  {Params: []string{"x"}, Body: "sin(x)"},
+	}
+	opts := task_splitter.Options{Variables: map[string]float64{"y": 3}, Functions: functions}
+
+	t.Run("Body is inlined", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("hyp(y, 4) + 1", opts)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, plan.Tasks, 5) // y*y, 4*4, +, sqrt, +1
+		assert.Equal(t, 6.0, evaluatePlan(t, plan))
+		assert.Equal(t, "hyp(y, 4) + 1", plan.AST.String())
+	})
+
+	t.Run("Identical calls share tasks", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("twice(y) + sq(y)", opts)
+		if assert.NoError(t, err) {
+			assert.Len(t, plan.Tasks, 3)
+			assert.Equal(t, 27.0, evaluatePlan(t, plan))
+		}
+	})
+
+	t.Run("Literal calls are folded", func(t *testing.T) {
+		fold := opts
+		fold.Fold = task_splitter.FoldLiteral
+		plan, err := task_splitter.ParseExpression("hyp(3, 4)", fold)
+		if assert.NoError(t, err) && assert.NotNil(t, plan.Result) {
+			assert.Equal(t, 5.0, *plan.Result)
+		}
+	})
+
+	t.Run("Body does not see expression variables", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("x = 10; add(x, y)", opts)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 13.0, evaluatePlan(t, plan))
+		}
+	})
+
+	t.Run("Units", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("hyp(3 m, 4 m)", opts)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "m", plan.Unit)
+			assert.Equal(t, 5.0, evaluatePlan(t, plan))
+		}
+	})
+
+	t.Run("Explain", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("add(1, y)", opts)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "add:2", plan.RPN[0][2].Text)
+		}
+	})
+
+	errorTests := []struct {
+		name       string
+		expression string
+		opts       task_splitter.Options
+		code       string
+		offset     int
+		length     int
+	}{
+		{name: "Wrong argument count", expression: "1 + sq(1, 2)", opts: opts, code: task_splitter.CodeArgumentCount, offset: 4, length: 8},
+		{name: "Error in body is reported at call", expression: "2 * add(1 m, 2 s)", opts: opts, code: task_splitter.CodeDimensionMismatch, offset: 4, length: 12},
+		{name: "Unsupported operation in body", expression: "wave(1)", opts: task_splitter.Options{Functions: functions, Exact: true}, code: task_splitter.CodeInexactOperation, offset: 0, length: 6},
+		{name: "Unknown function", expression: "cube(2)", opts: opts, code: task_splitter.CodeUnknownVariable, offset: 0, length: 4},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := task_splitter.ParseExpression(tt.expression, tt.opts)
+			var parseErr *task_splitter.ParseError
+			if assert.True(t, errors.As(err, &parseErr), "error: %v", err) {
+				assert.Equal(t, tt.code, parseErr.Code)
+				assert.Equal(t, tt.offset, parseErr.Offset)
+				assert.Equal(t, tt.length, parseErr.Length)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Conditional(t *testing.T) {
+	t.Run("Comparison and boolean operators", func(t *testing.T) {
+		tests := []struct {
+			expression string
+			x          float64
+			value      float64
+		}{
+			{expression: "x > 1 && not(x == 3) || x <= 0", x: 2, value: 1},
+			{expression: "x > 1 && not(x == 3) || x <= 0", x: 3, value: 0},
+			{expression: "x > 1 && not(x == 3) || x <= 0", x: -1, value: 1},
+			{expression: "x + 1 < 4 == 1", x: 2, value: 1},
+			{expression: "x != 2 || x >= 3", x: 2, value: 0},
+		}
+		for _, tt := range tests {
+			plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{Variables: map[string]float64{"x": tt.x}})
+			if assert.NoError(t, err, tt.expression) {
+				assert.Equal(t, tt.value, evaluatePlan(t, plan), "%s, x = %v", tt.expression, tt.x)
+			}
+		}
+	})
+
+	t.Run("Branch tasks are guarded by condition", func(t *testing.T) {
+		for x, value := range map[float64]float64{9: 3, -4: 0} {
+			plan, err := task_splitter.ParseExpression("if(x > 0, sqrt(x), 0)", task_splitter.Options{Variables: map[string]float64{"x": x}})
+			if !assert.NoError(t, err) || !assert.Len(t, plan.Tasks, 3) {
+				return
+			}
+			assert.Equal(t, operators.OpGreater, plan.Tasks[0].Operation)
+			assert.Empty(t, plan.Tasks[0].Guards)
+			assert.Equal(t, []models.TaskGuard{{ConditionIndex: 1, Value: true}}, plan.Tasks[1].Guards)
+			assert.Equal(t, operators.FnIf, plan.Tasks[2].Operation)
+			assert.Empty(t, plan.Tasks[2].Guards)
+			assert.Equal(t, 3, plan.Depth())
+			assert.Equal(t, value, evaluatePlan(t, plan))
+		}
+	})
+
+	t.Run("Nested branches", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("if(x > 0, if(x > 0, x*2, x/0), if(x < 2, x*3, 1))", task_splitter.Options{Variables: map[string]float64{"x": -2}})
+		if !assert.NoError(t, err) || !assert.Len(t, plan.Tasks, 6) {
+			return
+		}
+		// Условие внутреннего if совпадает с условием внешнего: ветвь x/0 не выбирается никогда
+		assert.Equal(t, []models.TaskGuard{{ConditionIndex: 1, Value: true}}, plan.Tasks[1].Guards)
+		assert.Equal(t, []models.TaskGuard{{ConditionIndex: 1, Value: false}}, plan.Tasks[2].Guards)
+		assert.Equal(t, []models.TaskGuard{{ConditionIndex: 1, Value: false}, {ConditionIndex: 3, Value: true}}, plan.Tasks[3].Guards)
+		assert.Equal(t, -6.0, evaluatePlan(t, plan))
+	})
+
+	t.Run("Known condition selects branch", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("if(1, x*2, 1/0) + if(0, sqrt(x), 5)", task_splitter.Options{Variables: map[string]float64{"x": 4}})
+		if !assert.NoError(t, err) || !assert.Len(t, plan.Tasks, 2) {
+			return
+		}
+		assert.Empty(t, plan.Tasks[0].Guards)
+		assert.Equal(t, 13.0, evaluatePlan(t, plan))
+
+		plan, err = task_splitter.ParseExpression("if(2 > 1, 3, 1/0)", task_splitter.Options{Fold: task_splitter.FoldLiteral})
+		if assert.NoError(t, err) && assert.NotNil(t, plan.Result) {
+			assert.Equal(t, 3.0, *plan.Result)
+			assert.Equal(t, 1, plan.Folded)
+		}
+	})
+
+	t.Run("Common subexpressions", func(t *testing.T) {
+		// Задача без условия используется в ветви, а задача ветви вне ее - нет
+		plan, err := task_splitter.ParseExpression("x*2 + if(x > 1, x*2, 0)", task_splitter.Options{Variables: map[string]float64{"x": 3}})
+		if assert.NoError(t, err) {
+			assert.Len(t, plan.Tasks, 4)
+			assert.Equal(t, 12.0, evaluatePlan(t, plan))
+		}
+		plan, err = task_splitter.ParseExpression("if(x > 1, x*2, 0) + x*2", task_splitter.Options{Variables: map[string]float64{"x": 0}})
+		if assert.NoError(t, err) {
+			assert.Len(t, plan.Tasks, 5)
+			assert.Equal(t, 0.0, evaluatePlan(t, plan))
+		}
+	})
+
+	t.Run("Reference condition", func(t *testing.T) {
+		resolve := func(id int64) (*task_splitter.Reference, error) { return &task_splitter.Reference{TaskID: 7}, nil }
+		plan, err := task_splitter.ParseExpression("if($42, x*2, 0)", task_splitter.Options{Variables: map[string]float64{"x": 1}, ResolveReference: resolve})
+		if !assert.NoError(t, err) || !assert.Len(t, plan.Tasks, 3) {
+			return
+		}
+		// Задачи ветви ожидают задачу своего выражения "$42 != 0"
+		assert.Equal(t, operators.OpNotEqual, plan.Tasks[0].Operation)
+		assert.Equal(t, int64(7), plan.Tasks[0].Dependencies[0])
+		assert.Equal(t, []models.TaskGuard{{ConditionIndex: 1, Value: true}}, plan.Tasks[1].Guards)
+		assert.Equal(t, []int64{7, -1, -1}, plan.Tasks[2].Dependencies)
+	})
+
+	t.Run("Units", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("if(2 m > 1 m, 3 m, 4 m) in cm", task_splitter.Options{Fold: task_splitter.FoldLiteral})
+		if assert.NoError(t, err) && assert.NotNil(t, plan.Result) {
+			assert.InDelta(t, 300.0, *plan.Result, 1e-9)
+		}
+	})
+
+	errorTests := []struct {
+		name       string
+		expression string
+		opts       task_splitter.Options
+		code       string
+	}{
+		{name: "Comparison of different dimensions", expression: "2 m > 3 s", code: task_splitter.CodeDimensionMismatch},
+		{name: "Condition with dimension", expression: "if(2 m, 1, 0)", code: task_splitter.CodeDimensionMismatch},
+		{name: "Branches of different dimensions", expression: "if(1, 1 m, 1 s)", code: task_splitter.CodeDimensionMismatch},
+		{name: "Boolean operator with dimension", expression: "1 m && 1", code: task_splitter.CodeDimensionMismatch},
+		{name: "Complex mode", expression: "if(1, i, 2)", opts: task_splitter.Options{Complex: true}, code: task_splitter.CodeUnsupportedOperation},
+		{name: "Interval mode", expression: "1 < 2", opts: task_splitter.Options{Interval: true}, code: task_splitter.CodeUnsupportedOperation},
+		{name: "Argument count", expression: "if(1, 2)", code: task_splitter.CodeArgumentCount},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := task_splitter.ParseExpression(tt.expression, tt.opts)
+			var parseErr *task_splitter.ParseError
+			if assert.True(t, errors.As(err, &parseErr), err) {
+				assert.Equal(t, tt.code, parseErr.Code)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Series(t *testing.T) {
+	t.Run("Body is expanded into a reduction tree", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("sum(i, 1, 1000, i^2)", task_splitter.Options{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		// 1000 задач тела, 125 + 16 + 2 + 1 задач дерева суммы
+		assert.Len(t, plan.Tasks, 1144)
+		assert.Equal(t, 5, plan.Depth())
+		assert.Equal(t, 333833500.0, evaluatePlan(t, plan))
+		assert.Equal(t, "sum(i, 1, 1000, i^2)", plan.Canonical)
+	})
+
+	tests := []struct {
+		name       string
+		expression string
+		value      float64
+	}{
+		{name: "Product", expression: "prod(k, 1, 10, k)", value: 3628800},
+		{name: "Bounds are folded", expression: "sum(i, n - 1, 2*n, i)", value: 20},
+		{name: "Body uses variables", expression: "sum(i, 1, 4, n*i + 1)", value: 34},
+		{name: "Nested series", expression: "sum(i, 1, 3, sum(j, 1, i, i*j))", value: 25},
+		{name: "Bound first argument is an aggregate", expression: "sum(n, 1, 2, n)", value: 9},
+		{name: "Inside a branch", expression: "if(n > 2, prod(k, 1, 3, k + n), 0)", value: 120},
+		{name: "Branch tasks are guarded", expression: "if(n < 2, sum(i, 1, 3, i*n), n*2)", value: 6},
+		{name: "Branch that is never chosen is not expanded", expression: "2 * if(0, sum(i, 1, 1000000000, i), n)", value: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{Variables: map[string]float64{"n": 3}})
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.value, evaluatePlan(t, plan))
+			}
+		})
+	}
+
+	t.Run("Empty range", func(t *testing.T) {
+		for expression, value := range map[string]float64{"sum(i, 1, 0, i)": 0, "prod(i, 5, 4, i)": 1} {
+			plan, err := task_splitter.ParseExpression(expression, task_splitter.Options{})
+			if assert.NoError(t, err, expression) && assert.NotNil(t, plan.Result, expression) {
+				assert.Equal(t, value, *plan.Result, expression)
+				assert.Empty(t, plan.Tasks, expression)
+			}
+		}
+	})
+
+	t.Run("Literal body is folded", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("sum(i, 1, 100, i)", task_splitter.Options{Fold: task_splitter.FoldLiteral})
+		if assert.NoError(t, err) && assert.NotNil(t, plan.Result) {
+			assert.Equal(t, 5050.0, *plan.Result)
+		}
+	})
+
+	t.Run("Units", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("prod(i, 1, 3, 2 m)", task_splitter.Options{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "m^3", plan.Unit)
+			assert.Equal(t, 8.0, evaluatePlan(t, plan))
+		}
+		plan, err = task_splitter.ParseExpression("sum(i, 1, 3, i * 1 km) in m", task_splitter.Options{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 6000.0, evaluatePlan(t, plan))
+		}
+	})
+
+	errorTests := []struct {
+		name       string
+		expression string
+		opts       task_splitter.Options
+		code       string
+	}{
+		{name: "Bound is not an integer", expression: "sum(i, 1, 2.5, i)", code: task_splitter.CodeSeriesBounds},
+		{name: "Bound with dimension", expression: "sum(i, 1, 3 m, i)", code: task_splitter.CodeSeriesBounds},
+		{name: "Bound is not known", expression: "sum(i, 1, $42, i)", opts: task_splitter.Options{
+			ResolveReference: func(int64) (*task_splitter.Reference, error) { return &task_splitter.Reference{TaskID: 7}, nil },
+		}, code: task_splitter.CodeSeriesBounds},
+		{name: "Limit", expression: "sum(i, 1, 1001, i)", opts: task_splitter.Options{SeriesLimit: 1000}, code: task_splitter.CodeSeriesLimit},
+		{name: "Limit of nested series", expression: "sum(i, 1, 40, sum(j, 1, 25, i*j))", opts: task_splitter.Options{SeriesLimit: 1000}, code: task_splitter.CodeSeriesLimit},
+		{name: "Sum of different dimensions", expression: "sum(i, 1, 2, (1 m)^i)", code: task_splitter.CodeDimensionMismatch},
+		{name: "Unknown variable in body", expression: "sum(i, 1, 3, x*i)", code: task_splitter.CodeUnknownVariable},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := task_splitter.ParseExpression(tt.expression, tt.opts)
+			var parseErr *task_splitter.ParseError
+			if assert.True(t, errors.As(err, &parseErr), err) {
+				assert.Equal(t, tt.code, parseErr.Code)
+			}
+		})
+	}
+}
+
+// estimates вычисляет задачи плана и возвращает значения его оценок integrate и solve по имени.
+func estimates(t *testing.T, plan *task_splitter.Plan) map[string]float64 {
+	results := evaluateTasks(t, plan)
+	values := make(map[string]float64)
+	for _, variable := range plan.Variables {
+		if !variable.Estimate {
+			continue
+		}
+		if variable.Value != nil {
+			values[variable.Name] = *variable.Value
+		} else {
+			values[variable.Name] = results[variable.TaskIndex]
+		}
+	}
+	return values
+}
+
+func TestParseExpression_Calculus(t *testing.T) {
+	t.Run("Integral is split into evaluations at Simpson points", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("integrate(x^3, x, 0, 2, 10)", task_splitter.Options{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.InDelta(t, 4, evaluatePlan(t, plan), 1e-12) // Формула Симпсона точна для кубических многочленов
+
+		// Каждая из 11 точек - итерация
+		var iterations []int
+		for _, task := range plan.Tasks {
+			if task.Iteration > 0 {
+				iterations = append(iterations, task.Iteration)
+			}
+		}
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, iterations)
+
+		values := estimates(t, plan)
+		assert.Len(t, values, 1)
+		assert.InDelta(t, 0.04, values["integrate#1.error"], 1e-9) // |S - T| = h^2/12 * (f'(2) - f'(0)) для n, не кратного 4
+	})
+
+	t.Run("Runge error estimate", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("integrate(x^4, x, 0, 1, 8)", task_splitter.Options{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		value := evaluatePlan(t, plan)
+		assert.InDelta(t, 0.2, value, 1e-4)
+		assert.InDelta(t, math.Abs(value-0.2), estimates(t, plan)["integrate#1.error"], 1e-6)
+	})
+
+	t.Run("Root is found by bisection rounds", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("solve(x^2 - n, x, 0, 2)", task_splitter.Options{Variables: map[string]float64{"n": 2}})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.InDelta(t, math.Sqrt2, evaluatePlan(t, plan), 1e-9)
+
+		// Раунды вычисляются последовательно: задача выбора каждого раунда - итерация
+		rounds := 0
+		for _, task := range plan.Tasks {
+			if task.Iteration > 0 {
+				rounds++
+				assert.Equal(t, rounds, task.Iteration)
+				assert.Equal(t, "if", task.Operation)
+			}
+		}
+		assert.Equal(t, 34, rounds) // 2 / 2^34 < 1e-10 * 2
+
+		values := estimates(t, plan)
+		assert.InDelta(t, 0, values["solve#1.error"], 1e-10)
+		assert.InDelta(t, 0, values["solve#1.residual"], 1e-9)
+		assert.Equal(t, 1.0, values["solve#1.bracketed"])
+	})
+
+	t.Run("Tasks count against the series limit", func(t *testing.T) {
+		// 34 раунда по 6 задач (2 задачи тела, середина, произведение, сравнение, выбор), 4 задачи тела на концах,
+		// корень, 2 задачи невязки и 2 задачи bracketed
+		plan, err := task_splitter.ParseExpression("solve(x^2 - 2, x, 0, 2)", task_splitter.Options{SeriesLimit: 213})
+		if assert.NoError(t, err) {
+			assert.Len(t, plan.Tasks, 213)
+		}
+
+		_, err = task_splitter.ParseExpression("solve(x^2 - 2, x, 0, 2)", task_splitter.Options{SeriesLimit: 212})
+		var parseErr *task_splitter.ParseError
+		if assert.True(t, errors.As(err, &parseErr), err) {
+			assert.Equal(t, task_splitter.CodeSeriesLimit, parseErr.Code)
+		}
+	})
+
+	t.Run("Root outside the range", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("solve(x - 5, x, 0, 1)", task_splitter.Options{})
+		if assert.NoError(t, err) {
+			assert.InDelta(t, 1, evaluatePlan(t, plan), 1e-9)
+			assert.Equal(t, 0.0, estimates(t, plan)["solve#1.bracketed"])
+		}
+	})
+
+	tests := []struct {
+		name       string
+		expression string
+		value      float64
+	}{
+		{name: "Variable hides expression variable", expression: "x + integrate(x, x, 0, 2, 2)", value: 5},
+		{name: "Body uses variables", expression: "integrate(n*x, x, 0, n, 4)", value: 13.5},
+		{name: "Limits are folded", expression: "integrate(1, t, n - 3, 2*n, 2)", value: 6},
+		{name: "Series in body", expression: "integrate(sum(i, 1, 2, i*t), t, 0, 1, 2)", value: 1.5},
+		{name: "Nested", expression: "integrate(integrate(x*y, y, 0, 1, 2), x, 0, 2, 2)", value: 1},
+		{name: "Solve in series", expression: "sum(k, 1, 2, solve(t - k, t, 0, 4))", value: 3},
+		{name: "Branch that is never chosen is not expanded", expression: "2 * if(0, integrate(t, t, 0, 1, 2000000000), n)", value: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := task_splitter.ParseExpression(tt.expression, task_splitter.Options{Variables: map[string]float64{"n": 3, "x": 3}})
+			if assert.NoError(t, err) {
+				assert.InDelta(t, tt.value, evaluatePlan(t, plan), 1e-9)
+			}
+		})
+	}
+
+	t.Run("Estimates of nested calls are not recorded", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("integrate(integrate(x*y, y, 0, 1, 2), x, 0, 2, 2)", task_splitter.Options{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{"integrate#1.error"}, slices.Collect(maps.Keys(estimates(t, plan))))
+		}
+	})
+
+	t.Run("Units", func(t *testing.T) {
+		plan, err := task_splitter.ParseExpression("integrate(2 m/s, t, 0 s, 3 s, 2)", task_splitter.Options{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "m", plan.Unit)
+			assert.InDelta(t, 6, evaluatePlan(t, plan), 1e-12)
+		}
+	})
+
+	errorTests := []struct {
+		name       string
+		expression string
+		opts       task_splitter.Options
+		code       string
+	}{
+		{name: "Variable is not a name", expression: "integrate(x, 2*x, 0, 1, 2)", code: task_splitter.CodeBoundVariable},
+		{name: "Odd number of parts", expression: "integrate(x, x, 0, 1, 3)", code: task_splitter.CodeCalculusBounds},
+		{name: "Limit is not known", expression: "integrate(x, x, 0, $42, 2)", opts: task_splitter.Options{
+			ResolveReference: func(int64) (*task_splitter.Reference, error) { return &task_splitter.Reference{TaskID: 7}, nil },
+		}, code: task_splitter.CodeCalculusBounds},
+		{name: "Empty range", expression: "solve(x, x, 1, 1)", code: task_splitter.CodeCalculusBounds},
+		{name: "Limits of different dimensions", expression: "solve(x, x, 0 m, 1 s)", code: task_splitter.CodeDimensionMismatch},
+		{name: "Wrong argument count", expression: "solve(x, x, 0)", code: task_splitter.CodeArgumentCount},
+		{name: "Limit", expression: "integrate(x, x, 0, 1, 1000)", opts: task_splitter.Options{SeriesLimit: 1000}, code: task_splitter.CodeSeriesLimit},
+		{name: "Exact mode", expression: "integrate(x, x, 0, 1, 2)", opts: task_splitter.Options{Exact: true}, code: task_splitter.CodeInexactOperation},
+		{name: "Complex mode", expression: "solve(x, x, 0, 1)", opts: task_splitter.Options{Complex: true}, code: task_splitter.CodeUnsupportedOperation},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := task_splitter.ParseExpression(tt.expression, tt.opts)
+			var parseErr *task_splitter.ParseError
+			if assert.True(t, errors.As(err, &parseErr), err) {
+				assert.Equal(t, tt.code, parseErr.Code)
+			}
 		})
 	}
 }
@@ -0,0 +1,64 @@
+package ast
+
+// Node представляет узел дерева математического выражения.
+//
+// Дерево не зависит от способа вычисления выражения: оркестратор разбивает его на задачи,
+// а пакет ast позволяет напечатать выражение (Format) и привести его к канонической форме (Canonical).
+type Node interface {
+	// String возвращает запись поддерева с минимальным количеством скобок.
+	String() string
+	node()
+}
+
+// Number представляет числовой литерал: действительный (2.5), мнимый (2.5i) или с единицей измерения (2.5 km).
+type Number struct {
+	Value float64 // Значение числа
+	Imag  bool    // Признак мнимого литерала: значение числа - мнимая часть
+	Unit  string  // Единица измерения числа в исходной записи ("m/s"). Пустая для безразмерного числа
+}
+
+// Ident представляет имя переменной или ссылку на результат другого выражения ($42).
+type Ident struct {
+	Name string // Имя переменной или ссылка
+}
+
+// Unary представляет унарную операцию (унарный минус).
+type Unary struct {
+	Op      string // Идентификатор операции из реестра операций
+	Operand Node   // Операнд
+}
+
+// Binary представляет бинарную инфиксную операцию (+, -, *, /, ^, ±).
+type Binary struct {
+	Op    string // Идентификатор операции из реестра операций
+	Left  Node   // Левый операнд
+	Right Node   // Правый операнд
+}
+
+// Call представляет вызов функции: sqrt(x).
+type Call struct {
+	Func string // Имя функции из реестра операций
+	Args []Node // Аргументы функции
+}
+
+func (*Number) node() {}
+func (*Ident) node()  {}
+func (*Unary) node()  {}
+func (*Binary) node() {}
+func (*Call) node()   {}
+
+func (n *Number) String() string { return Format(n) }
+func (n *Ident) String() string  { return Format(n) }
+func (n *Unary) String() string  { return Format(n) }
+func (n *Binary) String() string { return Format(n) }
+func (n *Call) String() string   { return Format(n) }
+
+// Statement представляет инструкцию сценария: выражение, значение которого может быть связано с именем.
+type Statement struct {
+	Name  string // Имя, с которым связано значение инструкции. Пустое, если инструкция не является присваиванием
+	Value Node   // Выражение инструкции
+}
+
+// Script представляет сценарий из инструкций, разделенных ";".
+// Результатом сценария является значение последней инструкции.
+type Script []Statement
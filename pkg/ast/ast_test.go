@@ -0,0 +1,153 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/OinkiePie/calc_3/pkg/ast"
+	"github.com/OinkiePie/calc_3/pkg/operators"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromRPN(t *testing.T) {
+	t.Run("builds typed nodes", func(t *testing.T) {
+		node, err := ast.FromRPN([]string{"2", "x", "u-", "sqrt", "+", "$42", "^"})
+		if !assert.NoError(t, err) {
+			return
+		}
+		expected := &ast.Binary{
+			Op: operators.OpPower,
+			Left: &ast.Binary{
+				Op:    operators.OpAdd,
+				Left:  &ast.Number{Value: 2},
+				Right: &ast.Call{Func: operators.FnSqrt, Args: []ast.Node{&ast.Unary{Op: operators.OpUnaryMinus, Operand: &ast.Ident{Name: "x"}}}},
+			},
+			Right: &ast.Ident{Name: "$42"},
+		}
+		assert.Equal(t, expected, node)
+	})
+
+	t.Run("variadic call", func(t *testing.T) {
+		assert.Equal(t, "max:3", ast.CallToken(operators.FnMax, 3))
+		node, err := ast.FromRPN([]string{"1", "x", "2", ast.CallToken(operators.FnMax, 3)})
+		if assert.NoError(t, err) {
+			assert.Equal(t, &ast.Call{Func: operators.FnMax, Args: []ast.Node{
+				&ast.Number{Value: 1}, &ast.Ident{Name: "x"}, &ast.Number{Value: 2},
+			}}, node)
+		}
+	})
+
+	t.Run("user function call", func(t *testing.T) {
+		node, err := ast.FromRPN([]string{"2", "x", ast.CallToken("f", 2), "1", "+"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "f(2, x) + 1", ast.Format(node))
+		}
+	})
+
+	errorTests := []struct {
+		name string
+		rpn  []string
+		err  error
+	}{
+		{name: "empty", rpn: nil, err: ast.ErrEmptyExpression},
+		{name: "not enough operands", rpn: []string{"2", "+"}, err: ast.ErrNotEnoughOperands},
+		{name: "missing operator", rpn: []string{"2", "3"}, err: ast.ErrMissingOperator},
+		{name: "not enough user function arguments", rpn: []string{"2", "f:2"}, err: ast.ErrNotEnoughOperands},
+		{name: "not enough function arguments", rpn: []string{"2", "sum:3"}, err: ast.ErrNotEnoughOperands},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ast.FromRPN(tt.rpn)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		rpn      []string
+		expected string
+	}{
+		{name: "precedence", rpn: []string{"2", "3", "4", "*", "+"}, expected: "2 + 3 * 4"},
+		{name: "parentheses", rpn: []string{"2", "3", "+", "4", "*"}, expected: "(2 + 3) * 4"},
+		{name: "left associative", rpn: []string{"a", "b", "-", "c", "-"}, expected: "a - b - c"},
+		{name: "right operand of left associative", rpn: []string{"a", "b", "c", "-", "-"}, expected: "a - (b - c)"},
+		{name: "right associative power", rpn: []string{"2", "3", "2", "^", "^"}, expected: "2^3^2"},
+		{name: "left operand of power", rpn: []string{"2", "3", "^", "2", "^"}, expected: "(2^3)^2"},
+		{name: "unary minus below power", rpn: []string{"2", "2", "^", "u-"}, expected: "-2^2"},
+		{name: "negated base", rpn: []string{"2", "u-", "2", "^"}, expected: "(-2)^2"},
+		{name: "unary minus after operator", rpn: []string{"2", "1", "u-", "^"}, expected: "2^-1"},
+		{name: "negated sum", rpn: []string{"a", "b", "+", "u-"}, expected: "-(a + b)"},
+		{name: "function call", rpn: []string{"x", "1", "+", "sin", "2", "^"}, expected: "sin(x + 1)^2"},
+		{name: "normalized numbers", rpn: []string{"1e21", "0.50", "+"}, expected: "1e+21 + 0.5"},
+		{name: "imaginary literal", rpn: []string{"1", "2.50i", "+"}, expected: "1 + 2.5i"},
+		{name: "plus minus", rpn: []string{"3", "0.1", "±", "2", "^"}, expected: "3±0.1^2"},
+		{name: "unit literals", rpn: []string{"5 km", "2 h", "/", "3 m/s", "+"}, expected: "5 km / 2 h + 3 m/s"},
+		{name: "unit literal as base", rpn: []string{"5 m", "2", "^", "3 m^2", "+"}, expected: "(5 m)^2 + 3 m^2"},
+		{name: "factorial in exponent", rpn: []string{"2", "3", "!", "^", "1", "-"}, expected: "2^3! - 1"},
+		{name: "negated factorial", rpn: []string{"3", "!", "u-"}, expected: "-3!"},
+		{name: "factorial of negation", rpn: []string{"3", "u-", "!"}, expected: "(-3)!"},
+		{name: "factorial of sum", rpn: []string{"n", "1", "+", "!", "!"}, expected: "(n + 1)!!"},
+		{name: "integer operators", rpn: []string{"7", "2", "//", "10", "4", "%", "+"}, expected: "7 // 2 + 10 % 4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ast.FromRPN(tt.rpn)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, ast.Format(node))
+				assert.Equal(t, tt.expected, node.String())
+			}
+		})
+	}
+
+	t.Run("negative number", func(t *testing.T) {
+		node := &ast.Binary{Op: operators.OpPower, Left: &ast.Number{Value: -2}, Right: &ast.Number{Value: -1}}
+		assert.Equal(t, "(-2)^-1", ast.Format(node))
+	})
+
+	t.Run("script", func(t *testing.T) {
+		script := ast.Script{
+			{Name: "r", Value: &ast.Number{Value: 5}},
+			{Value: &ast.Binary{Op: operators.OpMultiply, Left: &ast.Ident{Name: "r"}, Right: &ast.Number{Value: 2}}},
+		}
+		assert.Equal(t, "r = 5; r * 2", script.String())
+	})
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name     string
+		rpn      []string
+		expected string
+	}{
+		{name: "sorted commutative operands", rpn: []string{"x", "3", "*"}, expected: "3 * x"},
+		{name: "numbers, names, then compound operands", rpn: []string{"x", "sin", "x", "2", "*", "*"}, expected: "2 * x * sin(x)"},
+		{name: "flattened chain", rpn: []string{"c", "a", "b", "+", "+"}, expected: "a + b + c"},
+		{name: "non commutative order is kept", rpn: []string{"b", "a", "-"}, expected: "b - a"},
+		{name: "nested sorting", rpn: []string{"y", "x", "+", "2", "/"}, expected: "(x + y) / 2"},
+		{name: "negated number", rpn: []string{"5", "u-", "u-", "x", "+"}, expected: "5 + x"},
+		{name: "negative zero", rpn: []string{"0", "u-", "x", "*"}, expected: "0 * x"},
+		{name: "real numbers before imaginary", rpn: []string{"2i", "3", "+", "i", "-"}, expected: "3 + 2i - i"},
+		{name: "negated imaginary", rpn: []string{"2i", "u-", "1", "+"}, expected: "1 + -2i"},
+		{name: "sorted function arguments", rpn: []string{"x", "3", "y", "2", "*", "max:3"}, expected: "max(3, x, 2 * y)"},
+		{name: "series arguments are not sorted", rpn: []string{"i", "1", "10", "i", "2", "^", "sum:4"}, expected: "sum(i, 1, 10, i^2)"},
+		{name: "aggregate of four arguments is sorted", rpn: []string{"3", "x", "1", "2", "sum:4"}, expected: "sum(1, 2, 3, x)"},
+		{name: "units are kept", rpn: []string{"5 m", "5 km", "+", "2 km", "u-", "+"}, expected: "-2 km + 5 km + 5 m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ast.FromRPN(tt.rpn)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, ast.Format(ast.Canonical(node)))
+			}
+		})
+	}
+
+	t.Run("source tree is not modified", func(t *testing.T) {
+		node, _ := ast.FromRPN([]string{"x", "3", "*"})
+		ast.Canonical(node)
+		assert.Equal(t, "x * 3", ast.Format(node))
+	})
+}
@@ -0,0 +1,150 @@
+package ast
+
+import (
+	"sort"
+
+	"github.com/OinkiePie/calc_3/pkg/operators"
+)
+
+// Canonical приводит дерево выражения к канонической форме, чтобы равные по записи выражения
+// ("x + 2*3" и "3 * 2 + x") имели одинаковую запись:
+//   - операнды коммутативных операций (+, *) упорядочиваются: сначала числа по возрастанию, затем имена,
+//     затем остальные операнды по их записи ("2 * x * sin(x)"), а цепочки одинаковых
+//     ассоциативных операций выравниваются: (c + a) + b и a + (b + c) записываются как a + b + c;
+//   - аргументы функций, не зависящих от порядка аргументов (max, sum, ...), упорядочиваются так же,
+//     кроме записей рядов sum(i, 1, 10, i^2) и prod(k, 1, 20, k);
+//   - унарный минус числа заменяется отрицательным числом, а -0 - нулем (мнимый литерал остается мнимым,
+//     а единица измерения числа сохраняется).
+//
+// Исходное дерево не изменяется.
+//
+// Args:
+//
+//	n: Node - Корень дерева выражения.
+//
+// Returns:
+//
+//	Node - Корень канонического дерева.
+func Canonical(n Node) Node {
+	switch n := n.(type) {
+	case *Number:
+		if n.Value == 0 {
+			return &Number{Value: 0, Imag: n.Imag, Unit: n.Unit}
+		}
+		return &Number{Value: n.Value, Imag: n.Imag, Unit: n.Unit}
+	case *Ident:
+		return &Ident{Name: n.Name}
+	case *Unary:
+		operand := Canonical(n.Operand)
+		if number, ok := operand.(*Number); ok && n.Op == operators.OpUnaryMinus {
+			return Canonical(&Number{Value: -number.Value, Imag: number.Imag, Unit: number.Unit})
+		}
+		return &Unary{Op: n.Op, Operand: operand}
+	case *Binary:
+		operator, ok := operators.Lookup(n.Op)
+		if !ok || !operator.Commutative {
+			return &Binary{Op: n.Op, Left: Canonical(n.Left), Right: Canonical(n.Right)}
+		}
+
+		operands := []Node{n.Left, n.Right}
+		if operator.Associative {
+			operands = chain(n, n.Op)
+		}
+		for i, operand := range operands {
+			operands[i] = Canonical(operand)
+		}
+		sort.SliceStable(operands, func(i, j int) bool {
+			return less(operands[i], operands[j])
+		})
+
+		root := operands[0]
+		for _, operand := range operands[1:] {
+			root = &Binary{Op: n.Op, Left: root, Right: operand}
+		}
+		return root
+	case *Call:
+		args := make([]Node, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = Canonical(arg)
+		}
+		if operators.IsSeries(n.Func, len(n.Args)) {
+			// Запись ряда sum(i, 1, 10, i^2): аргументы различны по смыслу и не упорядочиваются.
+			// Количество аргументов проверяется первым: вызов rand() не имеет аргументов
+			if _, variable := n.Args[0].(*Ident); variable {
+				return &Call{Func: n.Func, Args: args}
+			}
+		}
+		if operator, ok := operators.Lookup(n.Func); ok && operator.Commutative {
+			sort.SliceStable(args, func(i, j int) bool {
+				return less(args[i], args[j])
+			})
+		}
+		return &Call{Func: n.Func, Args: args}
+	}
+	return n
+}
+
+// Canonical приводит все инструкции сценария к канонической форме (см. Canonical).
+func (s Script) Canonical() Script {
+	canonical := make(Script, len(s))
+	for i, statement := range s {
+		canonical[i] = Statement{Name: statement.Name, Value: Canonical(statement.Value)}
+	}
+	return canonical
+}
+
+// chain собирает операнды цепочки операций op, начинающейся в узле, в порядке записи.
+//
+// Args:
+//
+//	n: Node - Узел дерева.
+//	op: string - Идентификатор операции цепочки.
+//
+// Returns:
+//
+//	[]Node - Операнды цепочки.
+func chain(n Node, op string) []Node {
+	binary, ok := n.(*Binary)
+	if !ok || binary.Op != op {
+		return []Node{n}
+	}
+	return append(chain(binary.Left, op), chain(binary.Right, op)...)
+}
+
+// less задает порядок операндов коммутативной операции: действительные числа по возрастанию (равные - по единице
+// измерения), затем мнимые, затем имена по алфавиту, затем остальные узлы по их записи.
+//
+// Args:
+//
+//	a: Node - Первый операнд.
+//	b: Node - Второй операнд.
+//
+// Returns:
+//
+//	bool - true, если операнд a должен стоять перед операндом b.
+func less(a, b Node) bool {
+	rank := func(n Node) int {
+		switch n.(type) {
+		case *Number:
+			return 0
+		case *Ident:
+			return 1
+		default:
+			return 2
+		}
+	}
+	if rank(a) != rank(b) {
+		return rank(a) < rank(b)
+	}
+	if x, ok := a.(*Number); ok {
+		y := b.(*Number)
+		if x.Imag != y.Imag {
+			return !x.Imag
+		}
+		if x.Value == y.Value {
+			return x.Unit < y.Unit
+		}
+		return x.Value < y.Value
+	}
+	return Format(a) < Format(b)
+}
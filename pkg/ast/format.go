@@ -0,0 +1,168 @@
+package ast
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/OinkiePie/calc_3/pkg/operators"
+)
+
+// atom - приоритет операндов, которые никогда не заключаются в скобки (числа, имена, вызовы функций).
+const atom = math.MaxInt
+
+// Format записывает дерево выражения в инфиксной нотации с минимальным количеством скобок.
+// Полученная строка разбирается в то же дерево.
+//
+// Бинарные операторы отделяются пробелами, кроме возведения в степень и погрешности: "2 * (x + 1)^2 + 3±0.1".
+//
+// Args:
+//
+//	n: Node - Корень дерева выражения.
+//
+// Returns:
+//
+//	string - Запись выражения.
+func Format(n Node) string {
+	var b strings.Builder
+	write(&b, n)
+	return b.String()
+}
+
+// String записывает сценарий: инструкции разделяются "; ", а присваивания записываются как "имя = выражение".
+func (s Script) String() string {
+	statements := make([]string, len(s))
+	for i, statement := range s {
+		statements[i] = Format(statement.Value)
+		if statement.Name != "" {
+			statements[i] = statement.Name + " = " + statements[i]
+		}
+	}
+	return strings.Join(statements, "; ")
+}
+
+// write записывает поддерево в построитель строки.
+//
+// Args:
+//
+//	b: *strings.Builder - Построитель строки.
+//	n: Node - Корень поддерева.
+func write(b *strings.Builder, n Node) {
+	switch n := n.(type) {
+	case *Number:
+		if math.Signbit(n.Value) && n.Value != 0 {
+			b.WriteString("-")
+		}
+		b.WriteString(strconv.FormatFloat(math.Abs(n.Value), 'g', -1, 64))
+		if n.Imag {
+			b.WriteString(operators.ImaginaryUnit)
+		}
+		if n.Unit != "" {
+			b.WriteString(" " + n.Unit)
+		}
+	case *Ident:
+		b.WriteString(n.Name)
+	case *Unary:
+		if operators.IsPostfix(n.Op) {
+			// Постфиксный оператор записывается после операнда: 5!, (-3)!, (n + 1)!
+			writeOperand(b, n.Operand, precedence(n.Operand) < precedence(n))
+			b.WriteString(n.Op)
+			return
+		}
+		b.WriteString(unarySymbol(n.Op))
+		writeOperand(b, n.Operand, precedence(n.Operand) < precedence(n))
+	case *Binary:
+		operator, _ := operators.Lookup(n.Op)
+		p := precedence(n)
+
+		left := precedence(n.Left)
+		writeOperand(b, n.Left, left < p || (left == p && operator.RightAssoc))
+
+		if n.Op == operators.OpPower || n.Op == operators.OpPlusMinus {
+			b.WriteString(n.Op)
+		} else {
+			b.WriteString(" " + n.Op + " ")
+		}
+
+		// Унарный минус допускается сразу после оператора (2^-1, 2 * -3) и не требует скобок
+		right := precedence(n.Right)
+		writeOperand(b, n.Right, !isUnary(n.Right) && (right < p || (right == p && !operator.RightAssoc)))
+	case *Call:
+		b.WriteString(n.Func + "(")
+		for i, arg := range n.Args {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			write(b, arg)
+		}
+		b.WriteString(")")
+	}
+}
+
+// writeOperand записывает операнд, при необходимости заключая его в скобки.
+//
+// Args:
+//
+//	b: *strings.Builder - Построитель строки.
+//	n: Node - Операнд.
+//	paren: bool - Заключить операнд в скобки.
+func writeOperand(b *strings.Builder, n Node, paren bool) {
+	if paren {
+		b.WriteString(operators.ParenLeft)
+	}
+	write(b, n)
+	if paren {
+		b.WriteString(operators.ParenRight)
+	}
+}
+
+// precedence возвращает приоритет узла: приоритет его операции или atom для чисел, имен и вызовов функций.
+// Отрицательное число имеет приоритет унарного минуса, так как записывается с ним. Число с единицей измерения
+// имеет тот же приоритет: в основании степени оно заключается в скобки, иначе показатель отнесется к единице: (5 m)^2.
+//
+// Args:
+//
+//	n: Node - Узел дерева.
+//
+// Returns:
+//
+//	int - Приоритет узла.
+func precedence(n Node) int {
+	var symbol string
+	switch n := n.(type) {
+	case *Unary:
+		symbol = n.Op
+	case *Binary:
+		symbol = n.Op
+	case *Number:
+		if !isUnary(n) && n.Unit == "" {
+			return atom
+		}
+		symbol = operators.OpUnaryMinus
+	default:
+		return atom
+	}
+	if operator, ok := operators.Lookup(symbol); ok {
+		return operator.Precedence
+	}
+	return atom
+}
+
+// isUnary проверяет, начинается ли запись узла с унарного минуса (унарный минус или отрицательное число).
+func isUnary(n Node) bool {
+	switch n := n.(type) {
+	case *Unary:
+		return n.Op == operators.OpUnaryMinus
+	case *Number:
+		return math.Signbit(n.Value) && n.Value != 0
+	}
+	return false
+}
+
+// unarySymbol возвращает запись унарной операции в выражении: унарный минус записывается как "-".
+func unarySymbol(op string) string {
+	if op == operators.OpUnaryMinus {
+		return operators.OpSubtract
+	}
+	return op
+}
@@ -0,0 +1,122 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/OinkiePie/calc_3/pkg/operators"
+)
+
+// Ошибки построения дерева выражения по RPN.
+var (
+	ErrEmptyExpression   = errors.New("пустое выражение")
+	ErrNotEnoughOperands = errors.New("недостаточно операндов")
+	ErrMissingOperator   = errors.New("операнды не связаны оператором")
+)
+
+// argCountSeparator отделяет в записи RPN количество аргументов вызова функции с переменным числом аргументов
+// или функции пользователя.
+const argCountSeparator = ":"
+
+// CallToken возвращает запись вызова функции с переменным числом аргументов или функции пользователя в RPN: "max:4", "f:2".
+//
+// Args:
+//
+//	fn: string - Имя функции.
+//	args: int - Количество аргументов вызова.
+//
+// Returns:
+//
+//	string - Токен вызова.
+func CallToken(fn string, args int) string {
+	return fn + argCountSeparator + strconv.Itoa(args)
+}
+
+// FromRPN строит дерево выражения по его записи в обратной польской нотации.
+//
+// Токен, начинающийся с цифры или точки, считается числом (с суффиксом "i" - мнимым, с единицей измерения
+// через пробел - "5 km" - именованной величиной), токен из реестра
+// операций - операцией, остальные токены - именами переменных или ссылками. Вызов функции с переменным числом
+// аргументов записывается вместе с количеством аргументов (см. CallToken), как и вызов функции пользователя,
+// которой нет в реестре: "f:2".
+//
+// Args:
+//
+//	rpn: []string - Токены выражения в обратной польской нотации. Унарный минус обозначается "u-".
+//
+// Returns:
+//
+//	Node - Корень дерева выражения.
+//	error - Ошибка, если запись пуста, число записано неверно или количество операндов не соответствует операциям.
+func FromRPN(rpn []string) (Node, error) {
+	var stack []Node
+	for _, tok := range rpn {
+		if tok == "" {
+			continue
+		}
+
+		if tok[0] == '.' || (tok[0] >= '0' && tok[0] <= '9') {
+			digits, unit, _ := strings.Cut(tok, " ")
+			digits, imag := strings.CutSuffix(digits, operators.ImaginaryUnit)
+			value, err := strconv.ParseFloat(digits, 64)
+			if err != nil {
+				return nil, fmt.Errorf("неверная запись числа %s: %w", tok, err)
+			}
+			stack = append(stack, &Number{Value: value, Imag: imag, Unit: unit})
+			continue
+		}
+
+		name, count, counted := strings.Cut(tok, argCountSeparator)
+		operator, ok := operators.Lookup(name)
+		if !ok && counted {
+			// Вызов функции пользователя
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("неверное количество аргументов %s", tok)
+			}
+			if len(stack) < n {
+				return nil, fmt.Errorf("%w для функции %s", ErrNotEnoughOperands, name)
+			}
+			args := append([]Node(nil), stack[len(stack)-n:]...)
+			stack = append(stack[:len(stack)-n], &Call{Func: name, Args: args})
+			continue
+		}
+		if !ok {
+			stack = append(stack, &Ident{Name: tok})
+			continue
+		}
+		arity := operator.Arity
+		if counted && operator.Variadic {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < operator.Arity {
+				return nil, fmt.Errorf("неверное количество аргументов %s", tok)
+			}
+			arity = n
+		}
+		if len(stack) < arity {
+			return nil, fmt.Errorf("%w для операции %s", ErrNotEnoughOperands, name)
+		}
+		args := append([]Node(nil), stack[len(stack)-arity:]...)
+		stack = stack[:len(stack)-arity]
+
+		switch {
+		case operator.Function:
+			stack = append(stack, &Call{Func: name, Args: args})
+		case operator.Arity == 1:
+			stack = append(stack, &Unary{Op: tok, Operand: args[0]})
+		default:
+			stack = append(stack, &Binary{Op: tok, Left: args[0], Right: args[1]})
+		}
+	}
+
+	switch len(stack) {
+	case 0:
+		return nil, ErrEmptyExpression
+	case 1:
+		return stack[0], nil
+	default:
+		return nil, ErrMissingOperator
+	}
+}
@@ -6,12 +6,18 @@ import (
 	"fmt"
 	"github.com/OinkiePie/calc_3/config"
 	"github.com/OinkiePie/calc_3/pkg/logger"
+	"github.com/OinkiePie/calc_3/pkg/operators"
 	_ "github.com/mattn/go-sqlite3"
 	"log"
 	"os"
+	"strings"
 	"time"
 )
 
+// taskStatuses - Список допустимых статусов задач для ограничения CHECK колонки tasks.status.
+// Статус 'skipped' получают задачи невыбранной ветви if: они не вычисляются.
+const taskStatuses = "'pending', 'processing', 'completed', 'skipped', 'error'"
+
 // DataBase представляет обёртку для работы с базой данных SQLite.
 //
 // Fields:
@@ -97,14 +103,29 @@ func (db *DataBase) createTables() error {
 
 		// Создание таблицы задач
 		//
-		// Содержит отдельные операции для вычисления выражений
+		// Содержит отдельные операции для вычисления выражений.
+		// canonical_string - каноническая запись выражения для поиска одинаковых выражений и отображения.
+		// numeric - режим вычисления ('float', 'rational', 'decimal', 'complex' или 'interval'), exact_result - точный результат
+		// в виде дроби "num/den" или десятичной записи (строкой, чтобы REAL не округлял его до float64),
+		// imag_result - мнимая часть результата в режиме 'complex', lower_result и upper_result - границы
+		// интервала результата в режиме 'interval' (в result хранится его середина),
+		// unit - единица измерения результата (пустая для безразмерного результата),
+		// seed - зерно функций случайных чисел выражения (NULL, если выражение их не содержит)
 		expressionsTable = `
 		CREATE TABLE IF NOT EXISTS expressions(
 			id INTEGER PRIMARY KEY AUTOINCREMENT, 
 			user_id INTEGER NOT NULL,
 			expression_string TEXT NOT NULL,
+			canonical_string TEXT NOT NULL DEFAULT '',
+			numeric TEXT NOT NULL DEFAULT 'float',
+			unit TEXT NOT NULL DEFAULT '',
+			seed INTEGER,
 			status TEXT CHECK(status IN ('pending', 'processing', 'completed', 'error')) DEFAULT 'pending',
 			result REAL,
+			exact_result TEXT,
+			imag_result REAL,
+			lower_result REAL,
+			upper_result REAL,
 			error TEXT DEFAULT '',	
 		    
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
@@ -113,37 +134,123 @@ func (db *DataBase) createTables() error {
 		// Создание таблицы аргументов задач
 		//
 		// Хранит числовые аргументы для задач
+		//
+		// Список допустимых операций формируется из реестра операций.
+		// exact - признак вычисления в точной рациональной арифметике, precision - точность десятичной задачи
+		// в значащих цифрах (0 для остальных задач), exact_result - точный результат "num/den" или десятичная запись,
+		// complex - признак вычисления в комплексных числах, imag_result - мнимая часть результата,
+		// interval - признак интервальной задачи (result - нижняя граница результата), upper_result - верхняя граница,
+		// iteration - номер итерации integrate или solve, которую завершает задача (0 - задача не завершает итерацию),
+		// seed - зерно задачи функции случайных чисел (0 для остальных задач).
+		// Список допустимых статусов задается taskStatuses
 		tasksTable = `
 		CREATE TABLE IF NOT EXISTS tasks(
 			id INTEGER PRIMARY KEY AUTOINCREMENT, 
 			expression_id INTEGER NOT NULL,
-			operation TEXT NOT NULL CHECK(operation IN ('+', '-', '*', '/', '^', 'u-')),
+			operation TEXT NOT NULL %s,
 		    result REAL,
-			status TEXT CHECK(status IN ('pending', 'processing', 'completed', 'error')) DEFAULT 'pending',
+			exact INTEGER NOT NULL DEFAULT 0,
+			precision INTEGER NOT NULL DEFAULT 0,
+			exact_result TEXT,
+			complex INTEGER NOT NULL DEFAULT 0,
+			imag_result REAL,
+			interval INTEGER NOT NULL DEFAULT 0,
+			upper_result REAL,
+			iteration INTEGER NOT NULL DEFAULT 0,
+			seed INTEGER NOT NULL DEFAULT 0,
+			status TEXT %s DEFAULT 'pending',
 		    
 			FOREIGN KEY (expression_id) REFERENCES expressions(id) ON DELETE CASCADE
 		);`
 
-		// Создание таблицы зависимостей задач
+		// Создание таблицы аргументов задач
 		//
-		// Содержит связи между задачами для построения графа вычислений
+		// Хранит аргументы задач: по строке на позицию аргумента, поэтому задача может иметь любое
+		// количество аргументов (max(3, 7, 1, 9)).
+		// value - значение аргумента (нижняя граница для интервальных задач), exact - точное значение "num/den"
+		// или десятичная запись для задач точного и десятичного режимов, imag - мнимая часть аргумента
+		// комплексной задачи, upper - верхняя граница аргумента интервальной задачи
 		tasksArgsTable = `
 		CREATE TABLE IF NOT EXISTS task_args (
-			task_id INTEGER PRIMARY KEY NOT NULL,
-			first REAL,
-			second REAL,
-			
+			task_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			value REAL,
+			exact TEXT,
+			imag REAL,
+			upper REAL,
+
+			PRIMARY KEY (task_id, position),
 			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
 		);`
 
+		// Создание таблицы зависимостей задач
+		//
+		// Содержит связи между задачами для построения графа вычислений: dependency - ID задачи,
+		// результат которой становится аргументом с позицией position (-1 - аргумент без зависимости).
+		// Одна задача может быть зависимостью нескольких задач (общие подвыражения вычисляются один раз),
+		// поэтому строки зависимостей принадлежат задаче-потребителю
 		tasksDependenciesTable = `
 		CREATE TABLE IF NOT EXISTS task_deps (
-			task_id INTEGER PRIMARY KEY NOT NULL,
-			first INTEGER,
-			second INTEGER,
-			
+			task_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			dependency INTEGER NOT NULL,
+
+			PRIMARY KEY (task_id, position),
 			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
 		);`
+
+		// Создание таблицы условий выполнения задач
+		//
+		// Содержит условия задач ветвей if: задача выполняется, только если истинность результата
+		// задачи condition (ненулевой результат - истина) равна value. Иначе задача получает статус 'skipped'
+		tasksGuardsTable = `
+		CREATE TABLE IF NOT EXISTS task_guards (
+			task_id INTEGER NOT NULL,
+			condition INTEGER NOT NULL,
+			value INTEGER NOT NULL,
+
+			PRIMARY KEY (task_id, condition),
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);`
+
+		// Создание таблицы переменных выражений
+		//
+		// Хранит именованные промежуточные значения сценариев и оценки погрешности integrate и solve (estimate = 1).
+		// task_id не является внешним ключом, так как задачи удаляются после вычисления выражения
+		expressionVarsTable = `
+		CREATE TABLE IF NOT EXISTS expression_vars (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			expression_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			task_id INTEGER,
+			value REAL,
+			estimate INTEGER NOT NULL DEFAULT 0,
+
+			FOREIGN KEY (expression_id) REFERENCES expressions(id) ON DELETE CASCADE
+		);`
+
+		// Создание таблицы функций пользователей
+		//
+		// Хранит функции, определенные пользователем: f(x, y) = x^2 + y^2.
+		// Параметры хранятся через запятую, тело - исходной записью выражения
+		functionsTable = `
+		CREATE TABLE IF NOT EXISTS functions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			params TEXT NOT NULL,
+			body TEXT NOT NULL,
+
+			UNIQUE (user_id, name),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);`
+
+		// Создание индекса канонических записей выражений
+		//
+		// Ускоряет поиск одинаковых выражений пользователя
+		expressionsCanonicalIndex = `
+		CREATE INDEX IF NOT EXISTS expressions_canonical
+		ON expressions (user_id, canonical_string);`
 	)
 
 	if _, err := db.DB.ExecContext(db.ctx, usersTable); err != nil {
@@ -158,10 +265,61 @@ func (db *DataBase) createTables() error {
 		return fmt.Errorf("failed to create expressions table: %w", err)
 	}
 
-	if _, err := db.DB.ExecContext(db.ctx, tasksTable); err != nil {
+	// Базы данных, созданные до появления колонок, дополняются ими
+	for _, column := range [][2]string{
+		{"canonical_string", "TEXT NOT NULL DEFAULT ''"},
+		{"numeric", "TEXT NOT NULL DEFAULT 'float'"},
+		{"exact_result", "TEXT"},
+		{"imag_result", "REAL"},
+		{"lower_result", "REAL"},
+		{"upper_result", "REAL"},
+		{"unit", "TEXT NOT NULL DEFAULT ''"},
+		{"seed", "INTEGER"},
+	} {
+		if err := db.addColumn("expressions", column[0], column[1]); err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.DB.ExecContext(db.ctx, expressionsCanonicalIndex); err != nil {
+		return fmt.Errorf("failed to create expressions canonical index: %w", err)
+	}
+
+	// Ограничения CHECK колонок operation и status: списки операций и статусов растут вместе с системой
+	checks := []string{"CHECK(operation IN (" + operationsList() + "))", "CHECK(status IN (" + taskStatuses + "))"}
+	tasks := fmt.Sprintf(tasksTable, checks[0], checks[1])
+	if _, err := db.DB.ExecContext(db.ctx, tasks); err != nil {
 		return fmt.Errorf("failed to create tasks table: %w", err)
 	}
 
+	for _, column := range [][2]string{
+		{"exact", "INTEGER NOT NULL DEFAULT 0"},
+		{"precision", "INTEGER NOT NULL DEFAULT 0"},
+		{"exact_result", "TEXT"},
+		{"complex", "INTEGER NOT NULL DEFAULT 0"},
+		{"imag_result", "REAL"},
+		{"interval", "INTEGER NOT NULL DEFAULT 0"},
+		{"upper_result", "REAL"},
+		{"iteration", "INTEGER NOT NULL DEFAULT 0"},
+		{"seed", "INTEGER NOT NULL DEFAULT 0"},
+	} {
+		if err := db.addColumn("tasks", column[0], column[1]); err != nil {
+			return err
+		}
+	}
+
+	// Базы данных, созданные с прежними списками операций или статусов, отклоняют новые значения:
+	// таблица задач пересоздается с текущими ограничениями
+	if err := db.rebuildTasks(tasks, checks); err != nil {
+		return err
+	}
+
+	// Базы данных, созданные до появления задач с любым количеством аргументов, хранят аргументы
+	// и зависимости в колонках first и second: их строки переносятся в таблицы с позициями
+	if err := db.migrateTaskArgs(tasksArgsTable, tasksDependenciesTable); err != nil {
+		return err
+	}
+
 	if _, err := db.DB.ExecContext(db.ctx, tasksArgsTable); err != nil {
 		return fmt.Errorf("failed to create tasks args table: %w", err)
 	}
@@ -170,9 +328,230 @@ func (db *DataBase) createTables() error {
 		return fmt.Errorf("failed to create tasks deps table: %w", err)
 	}
 
+	if _, err := db.DB.ExecContext(db.ctx, tasksGuardsTable); err != nil {
+		return fmt.Errorf("failed to create tasks guards table: %w", err)
+	}
+
+	if _, err := db.DB.ExecContext(db.ctx, expressionVarsTable); err != nil {
+		return fmt.Errorf("failed to create expression vars table: %w", err)
+	}
+
+	if err := db.addColumn("expression_vars", "estimate", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	if _, err := db.DB.ExecContext(db.ctx, functionsTable); err != nil {
+		return fmt.Errorf("failed to create functions table: %w", err)
+	}
+
 	return nil
 }
 
+// addColumn добавляет колонку в существующую таблицу, если ее ещё нет.
+//
+// Args:
+//
+//	table: string - Имя таблицы.
+//	column: string - Имя колонки.
+//	definition: string - Тип и ограничения колонки.
+//
+// Returns:
+//
+//	error - Ошибка, если получить колонки таблицы или добавить колонку не удалось.
+func (db *DataBase) addColumn(table, column, definition string) error {
+	exists, err := db.hasColumn(table, column)
+	if err != nil || exists {
+		return err
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := db.DB.ExecContext(db.ctx, query); err != nil {
+		return fmt.Errorf("failed to add column %s to %s table: %w", column, table, err)
+	}
+	return nil
+}
+
+// hasColumn проверяет, есть ли в таблице колонка. Для несуществующей таблицы возвращает false.
+//
+// Args:
+//
+//	table: string - Имя таблицы.
+//	column: string - Имя колонки.
+//
+// Returns:
+//
+//	bool - true, если колонка есть.
+//	error - Ошибка, если получить колонки таблицы не удалось.
+func (db *DataBase) hasColumn(table, column string) (bool, error) {
+	var exists bool
+	err := db.DB.QueryRowContext(db.ctx,
+		"SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?", table, column,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+	return exists, nil
+}
+
+// migrateTaskArgs переносит аргументы и зависимости задач из прежних таблиц с колонками first и second
+// в таблицы с позицией аргумента. Второй аргумент переносится только для операций с двумя аргументами.
+// Если таблицы уже имеют новую схему или ещё не созданы, ничего не делает.
+//
+// Args:
+//
+//	argsTable: string - Запрос создания таблицы аргументов в новой схеме.
+//	depsTable: string - Запрос создания таблицы зависимостей в новой схеме.
+//
+// Returns:
+//
+//	error - Ошибка, если перенос не удался. Перенос выполняется в транзакции и при ошибке откатывается.
+func (db *DataBase) migrateTaskArgs(argsTable, depsTable string) error {
+	legacy, err := db.hasColumn("task_args", "first")
+	if err != nil || !legacy {
+		return err
+	}
+	// Колонки режимов могли не успеть появиться в прежней таблице
+	for _, column := range []string{"first_exact", "second_exact", "first_imag", "second_imag", "first_upper", "second_upper"} {
+		definition := "REAL"
+		if strings.HasSuffix(column, "_exact") {
+			definition = "TEXT"
+		}
+		if err := db.addColumn("task_args", column, definition); err != nil {
+			return err
+		}
+	}
+
+	var binary []string
+	for _, op := range operators.All() {
+		if op.Arity >= 2 {
+			binary = append(binary, "'"+strings.ReplaceAll(op.Symbol, "'", "''")+"'")
+		}
+	}
+	if len(binary) == 0 {
+		binary = append(binary, "NULL")
+	}
+	second := "(SELECT operation FROM tasks WHERE id = task_id) IN (" + strings.Join(binary, ", ") + ")"
+
+	tx, err := db.DB.BeginTx(db.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin task args migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"ALTER TABLE task_args RENAME TO task_args_legacy",
+		"ALTER TABLE task_deps RENAME TO task_deps_legacy",
+		argsTable,
+		depsTable,
+		"INSERT INTO task_args (task_id, position, value, exact, imag, upper) " +
+			"SELECT task_id, 0, first, first_exact, first_imag, first_upper FROM task_args_legacy",
+		"INSERT INTO task_args (task_id, position, value, exact, imag, upper) " +
+			"SELECT task_id, 1, second, second_exact, second_imag, second_upper FROM task_args_legacy WHERE " + second,
+		"INSERT INTO task_deps (task_id, position, dependency) " +
+			"SELECT task_id, 0, COALESCE(first, -1) FROM task_deps_legacy",
+		"INSERT INTO task_deps (task_id, position, dependency) " +
+			"SELECT task_id, 1, COALESCE(second, -1) FROM task_deps_legacy WHERE " + second,
+		"DROP TABLE task_args_legacy",
+		"DROP TABLE task_deps_legacy",
+	} {
+		if _, err := tx.ExecContext(db.ctx, query); err != nil {
+			return fmt.Errorf("failed to migrate task args: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// rebuildTasks пересоздает таблицу задач, если ее ограничения CHECK отличаются от текущих.
+// SQLite не изменяет ограничения существующей таблицы, поэтому строки переносятся в новую таблицу,
+// прежняя таблица удаляется, а новая переименовывается. Внешние ключи на время переноса отключаются,
+// иначе удаление прежней таблицы удалило бы аргументы, зависимости и условия задач.
+//
+// Args:
+//
+//	tasksTable: string - Запрос создания таблицы задач в текущей схеме.
+//	checks: []string - Ограничения CHECK, которые должна содержать таблица задач.
+//
+// Returns:
+//
+//	error - Ошибка, если перенос не удался. Перенос выполняется в транзакции и при ошибке откатывается.
+func (db *DataBase) rebuildTasks(tasksTable string, checks []string) error {
+	conn, err := db.DB.Conn(db.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild tasks table: %w", err)
+	}
+	defer conn.Close()
+
+	var stored string
+	err = conn.QueryRowContext(db.ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'tasks'").Scan(&stored)
+	if err != nil {
+		return fmt.Errorf("failed to read tasks table schema: %w", err)
+	}
+	current := true
+	for _, check := range checks {
+		current = current && strings.Contains(stored, check)
+	}
+	if current {
+		return nil
+	}
+
+	if _, err := conn.ExecContext(db.ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("failed to rebuild tasks table: %w", err)
+	}
+	defer conn.ExecContext(db.ctx, "PRAGMA foreign_keys = ON")
+
+	tx, err := conn.BeginTx(db.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tasks table rebuild: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(db.ctx, strings.Replace(tasksTable, "tasks(", "tasks_new(", 1)); err != nil {
+		return fmt.Errorf("failed to rebuild tasks table: %w", err)
+	}
+	rows, err := tx.QueryContext(db.ctx, "SELECT name FROM pragma_table_info('tasks_new')")
+	if err != nil {
+		return fmt.Errorf("failed to rebuild tasks table: %w", err)
+	}
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to rebuild tasks table: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	rows.Close()
+
+	// Все колонки таблицы уже добавлены в прежнюю таблицу (см. addColumn)
+	list := strings.Join(columns, ", ")
+	for _, query := range []string{
+		"INSERT INTO tasks_new (" + list + ") SELECT " + list + " FROM tasks",
+		"DROP TABLE tasks",
+		"ALTER TABLE tasks_new RENAME TO tasks",
+	} {
+		if _, err := tx.ExecContext(db.ctx, query); err != nil {
+			return fmt.Errorf("failed to rebuild tasks table: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// operationsList формирует список идентификаторов зарегистрированных операций
+// для ограничения CHECK колонки tasks.operation.
+//
+// Returns:
+//
+//	string - Список строковых литералов SQL через запятую, например "'+', '-'".
+func operationsList() string {
+	symbols := operators.Symbols()
+	quoted := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		quoted[i] = "'" + strings.ReplaceAll(symbol, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
 // CloseDB закрывает подключение к базе данных.
 //
 // Returns:
@@ -211,7 +590,7 @@ func (db *DataBase) DeleteDB() error {
 //
 //	error - Ошибка, если очистка какой-либо таблицы не удалась.
 func (db *DataBase) ClearDB() error {
-	tables := []string{"users", "expressions", "tasks", "task_args", "task_deps", "sessions"}
+	tables := []string{"users", "expressions", "tasks", "task_args", "task_deps", "task_guards", "expression_vars", "sessions", "functions"}
 
 	// Временное отключение внешних ключей
 	_, err := db.DB.ExecContext(db.ctx, "PRAGMA foreign_keys = OFF")
@@ -2,15 +2,19 @@ package database_test
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/OinkiePie/calc_3/config"
 	"github.com/OinkiePie/calc_3/pkg/database"
 	"github.com/OinkiePie/calc_3/pkg/logger"
+	"github.com/OinkiePie/calc_3/pkg/operators"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -40,13 +44,191 @@ func TestDatabaseIntegration(t *testing.T) {
 		require.NoError(t, err)
 		defer db.CloseDB()
 
-		tables := []string{"users", "sessions", "expressions", "tasks"}
+		tables := []string{"users", "sessions", "expressions", "tasks", "task_guards", "expression_vars", "functions"}
 		for _, table := range tables {
 			_, err := db.DB.ExecContext(ctx, fmt.Sprintf("SELECT 1 FROM %s LIMIT 1", table))
 			assert.NoError(t, err, "table %s should exist", table)
 		}
 	})
 
+	t.Run("Operation constraint follows registry", func(t *testing.T) {
+		db, err := database.NewDB(ctx, ":memory:")
+		require.NoError(t, err)
+		defer db.CloseDB()
+
+		_, err = db.DB.ExecContext(ctx, "INSERT INTO users(login, pas) VALUES('test', 'pass')")
+		require.NoError(t, err)
+		_, err = db.DB.ExecContext(ctx, "INSERT INTO expressions(user_id, expression_string) VALUES(1, 'sqrt(4)')")
+		require.NoError(t, err)
+
+		for _, op := range operators.Symbols() {
+			_, err = db.DB.ExecContext(ctx, "INSERT INTO tasks(expression_id, operation) VALUES(1, ?)", op)
+			assert.NoError(t, err, "operation %s should be allowed", op)
+		}
+
+		_, err = db.DB.ExecContext(ctx, "INSERT INTO tasks(expression_id, operation) VALUES(1, 'unknown')")
+		assert.Error(t, err)
+	})
+
+	t.Run("New columns are added to existing database", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "old.db")
+		old, err := sql.Open("sqlite3", dbPath)
+		require.NoError(t, err)
+		_, err = old.Exec(`CREATE TABLE expressions(
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			expression_string TEXT NOT NULL,
+			status TEXT DEFAULT 'pending',
+			result REAL,
+			error TEXT DEFAULT ''
+		)`)
+		require.NoError(t, err)
+		_, err = old.Exec("INSERT INTO expressions(user_id, expression_string) VALUES(1, '2+2')")
+		require.NoError(t, err)
+		require.NoError(t, old.Close())
+
+		db, err := database.NewDB(ctx, dbPath)
+		require.NoError(t, err)
+		defer db.CloseDB()
+
+		var canonical, numeric string
+		var seed sql.NullInt64
+		err = db.DB.QueryRowContext(ctx, "SELECT canonical_string, numeric, seed FROM expressions WHERE id = 1").Scan(&canonical, &numeric, &seed)
+		assert.NoError(t, err)
+		assert.Equal(t, "", canonical)
+		assert.Equal(t, "float", numeric)
+		assert.False(t, seed.Valid)
+	})
+
+	t.Run("Legacy task args are moved to positions", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "legacy.db")
+		old, err := sql.Open("sqlite3", dbPath)
+		require.NoError(t, err)
+		for _, query := range []string{
+			`CREATE TABLE tasks(id INTEGER PRIMARY KEY AUTOINCREMENT, expression_id INTEGER NOT NULL, operation TEXT NOT NULL,
+				result REAL, status TEXT DEFAULT 'pending')`,
+			`CREATE TABLE task_args(task_id INTEGER PRIMARY KEY NOT NULL, first REAL, second REAL)`,
+			`CREATE TABLE task_deps(task_id INTEGER PRIMARY KEY NOT NULL, first INTEGER, second INTEGER)`,
+			"INSERT INTO tasks(expression_id, operation) VALUES(1, 'sqrt'), (1, '+')",
+			"INSERT INTO task_args VALUES(1, 4, NULL), (2, NULL, 3)",
+			"INSERT INTO task_deps VALUES(1, -1, -1), (2, 1, -1)",
+		} {
+			_, err = old.Exec(query)
+			require.NoError(t, err)
+		}
+		require.NoError(t, old.Close())
+
+		db, err := database.NewDB(ctx, dbPath)
+		require.NoError(t, err)
+		defer db.CloseDB()
+
+		var count int
+		require.NoError(t, db.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM task_args WHERE task_id = 1").Scan(&count))
+		assert.Equal(t, 1, count, "unary task keeps only its first argument")
+
+		var value float64
+		require.NoError(t, db.DB.QueryRowContext(ctx, "SELECT value FROM task_args WHERE task_id = 2 AND position = 1").Scan(&value))
+		assert.Equal(t, 3.0, value)
+
+		var dependency int64
+		require.NoError(t, db.DB.QueryRowContext(ctx, "SELECT dependency FROM task_deps WHERE task_id = 2 AND position = 0").Scan(&dependency))
+		assert.Equal(t, int64(1), dependency)
+
+		var iteration int
+		var seed int64
+		require.NoError(t, db.DB.QueryRowContext(ctx, "SELECT iteration, seed FROM tasks WHERE id = 1").Scan(&iteration, &seed))
+		assert.Equal(t, 0, iteration)
+		assert.Equal(t, int64(0), seed)
+
+		_, err = db.DB.ExecContext(ctx, "SELECT 1 FROM task_args_legacy")
+		assert.Error(t, err, "legacy table is dropped")
+	})
+
+	t.Run("Tasks table of baseline database accepts new operations", func(t *testing.T) {
+		db, err := database.NewDB(ctx, baselineDB(t))
+		require.NoError(t, err)
+		defer db.CloseDB()
+
+		_, err = db.DB.ExecContext(ctx, "INSERT INTO tasks(expression_id, operation) VALUES(1, 'sin')")
+		assert.NoError(t, err)
+		_, err = db.DB.ExecContext(ctx, "INSERT INTO tasks(expression_id, operation) VALUES(1, 'unknown')")
+		assert.Error(t, err)
+
+		// Строки прежней таблицы и связанные с ними аргументы сохраняются
+		var operation string
+		require.NoError(t, db.DB.QueryRowContext(ctx, "SELECT operation FROM tasks WHERE id = 1").Scan(&operation))
+		assert.Equal(t, "+", operation)
+		var count int
+		require.NoError(t, db.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM task_args WHERE task_id = 1").Scan(&count))
+		assert.Equal(t, 2, count)
+
+		_, err = db.DB.ExecContext(ctx, "SELECT 1 FROM tasks_new")
+		assert.Error(t, err, "temporary table is renamed")
+	})
+
+	t.Run("Operation constraint of baseline database follows registry", func(t *testing.T) {
+		dbPath := baselineDB(t)
+		db, err := database.NewDB(ctx, dbPath)
+		require.NoError(t, err)
+
+		for _, op := range operators.Symbols() {
+			_, err = db.DB.ExecContext(ctx, "INSERT INTO tasks(expression_id, operation) VALUES(1, ?)", op)
+			assert.NoError(t, err, "operation %s should be allowed", op)
+		}
+		require.NoError(t, db.CloseDB())
+
+		// Перенесенная база данных открывается повторно без потери задач
+		db, err = database.NewDB(ctx, dbPath)
+		require.NoError(t, err)
+		defer db.CloseDB()
+		var count int
+		require.NoError(t, db.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks").Scan(&count))
+		assert.Equal(t, len(operators.Symbols())+1, count)
+	})
+
+	t.Run("Status constraint of existing database allows skipped tasks", func(t *testing.T) {
+		db, err := database.NewDB(ctx, baselineDB(t))
+		require.NoError(t, err)
+		defer db.CloseDB()
+
+		_, err = db.DB.ExecContext(ctx, "UPDATE tasks SET status = 'skipped' WHERE id = 1")
+		assert.NoError(t, err)
+		_, err = db.DB.ExecContext(ctx, "UPDATE tasks SET status = 'unknown' WHERE id = 1")
+		assert.Error(t, err)
+	})
+
+	t.Run("Status constraint is migrated when operations are current", func(t *testing.T) {
+		// База данных создана с актуальным списком операций, но до появления статуса 'skipped'
+		quoted := make([]string, 0, len(operators.Symbols()))
+		for _, op := range operators.Symbols() {
+			quoted = append(quoted, "'"+op+"'")
+		}
+		dbPath := filepath.Join(t.TempDir(), "statuses.db")
+		old, err := sql.Open("sqlite3", dbPath)
+		require.NoError(t, err)
+		for _, query := range []string{
+			`CREATE TABLE tasks(
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				expression_id INTEGER NOT NULL,
+				operation TEXT NOT NULL CHECK(operation IN (` + strings.Join(quoted, ", ") + `)),
+				result REAL,
+				status TEXT CHECK(status IN ('pending', 'processing', 'completed', 'error')) DEFAULT 'pending'
+			)`,
+			"INSERT INTO tasks(expression_id, operation) VALUES(1, 'if')",
+		} {
+			_, err = old.Exec(query)
+			require.NoError(t, err)
+		}
+		require.NoError(t, old.Close())
+
+		db, err := database.NewDB(ctx, dbPath)
+		require.NoError(t, err)
+		defer db.CloseDB()
+
+		_, err = db.DB.ExecContext(ctx, "UPDATE tasks SET status = 'skipped' WHERE id = 1")
+		assert.NoError(t, err)
+	})
+
 	t.Run("ClearDB", func(t *testing.T) {
 		db, err := database.NewDB(ctx, ":memory:")
 		require.NoError(t, err)
@@ -83,3 +265,49 @@ func TestNewDBErrors(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+// baselineDB создает файл базы данных со схемой первой версии калькулятора и одной задачей 1+2.
+func baselineDB(t *testing.T) string {
+	dbPath := filepath.Join(t.TempDir(), "baseline.db")
+	old, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	defer old.Close()
+
+	for _, query := range []string{
+		`CREATE TABLE users(id INTEGER PRIMARY KEY AUTOINCREMENT, login TEXT UNIQUE NOT NULL, pas TEXT NOT NULL)`,
+		`CREATE TABLE expressions(
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			expression_string TEXT NOT NULL,
+			status TEXT CHECK(status IN ('pending', 'processing', 'completed', 'error')) DEFAULT 'pending',
+			result REAL,
+			error TEXT DEFAULT '',
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE tasks(
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			expression_id INTEGER NOT NULL,
+			operation TEXT NOT NULL CHECK(operation IN ('+', '-', '*', '/', '^', 'u-')),
+			result REAL,
+			status TEXT CHECK(status IN ('pending', 'processing', 'completed', 'error')) DEFAULT 'pending',
+			FOREIGN KEY (expression_id) REFERENCES expressions(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE task_args(
+			task_id INTEGER PRIMARY KEY NOT NULL, first REAL, second REAL,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE task_deps(
+			task_id INTEGER PRIMARY KEY NOT NULL, first INTEGER, second INTEGER,
+			FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		)`,
+		"INSERT INTO users(login, pas) VALUES('test', 'pass')",
+		"INSERT INTO expressions(user_id, expression_string) VALUES(1, '1+2')",
+		"INSERT INTO tasks(expression_id, operation) VALUES(1, '+')",
+		"INSERT INTO task_args VALUES(1, 1, 2)",
+		"INSERT INTO task_deps VALUES(1, -1, -1)",
+	} {
+		_, err = old.Exec(query)
+		require.NoError(t, err)
+	}
+	return dbPath
+}
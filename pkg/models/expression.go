@@ -1,5 +1,14 @@
 package models
 
+// Режимы вычисления выражения.
+const (
+	NumericFloat    = "float"    // вычисления с числами float64 (по умолчанию)
+	NumericRational = "rational" // точные вычисления с рациональными дробями
+	NumericDecimal  = "decimal"  // вычисления с десятичными числами произвольной точности
+	NumericComplex  = "complex"  // вычисления с комплексными числами
+	NumericInterval = "interval" // интервальные вычисления с гарантированными границами погрешности
+)
+
 // Expression представляет структуру арифметического выражения.
 type Expression struct {
 	UserID int64
@@ -13,8 +22,53 @@ type Expression struct {
 	Tasks []*Task
 	// ExpressionString - Исходное выражение в виде строки.
 	ExpressionString string
+	// CanonicalString - Каноническая запись выражения: равные по записи выражения имеют одинаковую каноническую запись.
+	CanonicalString string
+	// Unit - Единица измерения результата ("m/s", "km/h"). Пустая, если результат безразмерен.
+	Unit string
 	// Error - Описание ошибки если выражение невозможно выполнить.
 	Error string
+	// Variables - Именованные промежуточные значения сценария.
+	Variables []*ExpressionVariable
+	// Numeric - Режим вычисления выражения (NumericFloat, NumericRational, NumericDecimal, NumericComplex или NumericInterval).
+	Numeric string
+	// ExactResult - Точный результат выражения: дробь "num/den" в режиме NumericRational или десятичная запись
+	// в режиме NumericDecimal. Может быть nil, если выражение вычисляется в режиме NumericFloat или не вычислено.
+	ExactResult *string
+	// ImagResult - Мнимая часть результата выражения в режиме NumericComplex (действительная часть хранится в Result).
+	// Может быть nil, если выражение вычисляется в другом режиме или не вычислено.
+	ImagResult *float64
+	// LowerResult и UpperResult - Границы интервала, содержащего результат выражения в режиме NumericInterval
+	// (в Result хранится середина интервала). Могут быть nil, если выражение вычисляется в другом режиме или не вычислено.
+	LowerResult *float64
+	UpperResult *float64
+	// Progress - Ход вычисления итераций integrate и solve. nil, если выражение не содержит итераций или уже вычислено.
+	Progress *ExpressionProgress
+	// Seed - Зерно функций случайных чисел выражения, из которого выводятся зерна его задач.
+	// nil, если выражение не содержит функций случайных чисел и зерно не задано пользователем.
+	Seed *int64
+}
+
+// ExpressionProgress описывает ход вычисления итераций integrate и solve выражения (см. Task.Iteration).
+type ExpressionProgress struct {
+	// Done - Количество завершенных итераций.
+	Done int
+	// Total - Общее количество итераций.
+	Total int
+}
+
+// ExpressionVariable представляет именованное промежуточное значение сценария (например, "r = 5").
+type ExpressionVariable struct {
+	// Name - Имя переменной.
+	Name string
+	// Value - Значение переменной. Может быть nil, если задача, вычисляющая значение, ещё не завершена.
+	Value *float64
+	// TaskIndex - Локальный индекс задачи выражения (начиная с 1), вычисляющей значение. 0, если значение известно сразу.
+	TaskIndex int
+	// TaskID - ID задачи, вычисляющей значение. 0, если значение известно сразу.
+	TaskID int64
+	// Estimate - Признак оценки погрешности или сходимости integrate или solve ("integrate#1.error").
+	Estimate bool
 }
 
 // ExpressionResponse представляет структуру для отправки информации о выражении в HTTP-ответе.
@@ -25,10 +79,51 @@ type ExpressionResponse struct {
 	Status string `json:"status"`
 	// Status - Статус выражения.
 	ExpressionString string `json:"expression"`
+	// CanonicalString - Каноническая запись выражения. Если пуста (выражение создано до ее появления), то поле не включается в JSON-ответ (omitempty).
+	CanonicalString string `json:"canonical,omitempty"`
 	// Result - Указатель на результат вычисления выражения. Если nil, то поле не включается в JSON-ответ (omitempty).
 	Result *float64 `json:"result,omitempty"` //omitempty - если result nil, то не выводить его
+	// Unit - Единица измерения результата. Если результат безразмерен, то поле не включается в JSON-ответ (omitempty).
+	Unit string `json:"unit,omitempty"`
 	// Error - Описание ошибки если выражение невозможно выполнить. Если nil, то поле не включается в JSON-ответ (omitempty).
 	Error string `json:"error,omitempty"` //omitempty - если result nil, то не выводить его
+	// Variables - Именованные промежуточные значения сценария. Если их нет, то поле не включается в JSON-ответ (omitempty).
+	Variables []VariableResponse `json:"variables,omitempty"`
+	// Numeric - Режим вычисления. Указывается только для режимов rational, decimal, complex и interval (omitempty).
+	Numeric string `json:"numeric,omitempty"`
+	// Exact - Точный результат в виде несократимой дроби "num/den". Если nil, то поле не включается в JSON-ответ (omitempty).
+	Exact *string `json:"exact,omitempty"`
+	// Decimal - Точный результат в виде десятичной дроби или результат десятичного режима. Если пуст, то поле не включается в JSON-ответ (omitempty).
+	Decimal string `json:"decimal,omitempty"`
+	// Imag - Мнимая часть результата в режиме complex (действительная часть - в поле result). Если nil, то поле не включается в JSON-ответ (omitempty).
+	Imag *float64 `json:"imag,omitempty"`
+	// Complex - Комплексный результат в виде "a+bi" (см. operators.FormatComplex). Если пуст, то поле не включается в JSON-ответ (omitempty).
+	Complex string `json:"complex,omitempty"`
+	// Interval - Границы [lo, hi] интервала, гарантированно содержащего результат, в режиме interval
+	// (в поле result - середина интервала). Если пуст, то поле не включается в JSON-ответ (omitempty).
+	Interval []float64 `json:"interval,omitempty"`
+	// Estimates - Оценки погрешности и сходимости integrate и solve. Если их нет, то поле не включается в JSON-ответ (omitempty).
+	Estimates []VariableResponse `json:"estimates,omitempty"`
+	// Progress - Ход вычисления итераций integrate и solve. Если nil, то поле не включается в JSON-ответ (omitempty).
+	Progress *ProgressResponse `json:"progress,omitempty"`
+	// Seed - Зерно функций случайных чисел. Если nil, то поле не включается в JSON-ответ (omitempty).
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+// ProgressResponse представляет ход вычисления итераций выражения в HTTP-ответе.
+type ProgressResponse struct {
+	// Done - Количество завершенных итераций.
+	Done int `json:"done"`
+	// Total - Общее количество итераций.
+	Total int `json:"total"`
+}
+
+// VariableResponse представляет именованное промежуточное значение сценария в HTTP-ответе.
+type VariableResponse struct {
+	// Name - Имя переменной.
+	Name string `json:"name"`
+	// Value - Значение переменной. Если nil (значение ещё не вычислено), то поле не включается в JSON-ответ (omitempty).
+	Value *float64 `json:"value,omitempty"`
 }
 
 // ExpressionAdd представляет структуру для получения математического выражения из HTTP-запроса.
@@ -36,4 +131,81 @@ type ExpressionResponse struct {
 type ExpressionAdd struct {
 	// Expression - Математическое выражение в виде строки.
 	Expression string `json:"expression"`
+	// Variables - Значения переменных, используемых в выражении. Необязательное поле.
+	Variables map[string]float64 `json:"variables,omitempty"`
+	// Numeric - Режим вычисления: "float" (по умолчанию), "rational", "decimal", "complex" или "interval". Необязательное поле.
+	Numeric string `json:"numeric,omitempty"`
+	// Precision - Точность режима "decimal" в значащих цифрах. Необязательное поле, по умолчанию 34.
+	Precision int `json:"precision,omitempty"`
+	// Seed - Зерно функций случайных чисел: выражение с тем же зерном вычисляется одинаково.
+	// Необязательное поле, по умолчанию зерно выбирается оркестратором.
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+// ExplainResponse представляет результат разбора выражения без сохранения в HTTP-ответе.
+type ExplainResponse struct {
+	// Canonical - Каноническая запись выражения.
+	Canonical string `json:"canonical"`
+	// Tokens - Токены выражения.
+	Tokens []TokenResponse `json:"tokens"`
+	// RPN - Выражения инструкций сценария в обратной польской нотации.
+	RPN [][]string `json:"rpn"`
+	// Tasks - Задачи, которые будут отправлены агентам. Последняя задача является корнем выражения.
+	Tasks []ExplainTaskResponse `json:"tasks"`
+	// Depth - Длина критического пути: наибольшее количество задач, вычисляемых последовательно.
+	Depth int `json:"depth"`
+	// Parallelism - Наибольшее количество задач, которые могут вычисляться одновременно.
+	Parallelism int `json:"parallelism"`
+	// Folded - Количество операций, вычисленных оркестратором при разборе (свернутых).
+	Folded int `json:"folded"`
+	// Dispatched - Количество задач, которые будут отправлены агентам.
+	Dispatched int `json:"dispatched"`
+	// Result - Результат выражения, если все его операции свернуты. Если nil, то поле не включается в JSON-ответ (omitempty).
+	Result *float64 `json:"result,omitempty"`
+	// Unit - Единица измерения результата. Если результат безразмерен, то поле не включается в JSON-ответ (omitempty).
+	Unit string `json:"unit,omitempty"`
+}
+
+// TokenResponse представляет токен выражения в HTTP-ответе.
+type TokenResponse struct {
+	// Kind - Вид токена (number, name, reference, symbol).
+	Kind string `json:"kind"`
+	// Text - Текст токена.
+	Text string `json:"text"`
+	// Offset - Смещение токена от начала выражения в байтах.
+	Offset int `json:"offset"`
+	// Length - Длина токена в байтах.
+	Length int `json:"length"`
+}
+
+// ExplainTaskResponse представляет задачу разобранного выражения в HTTP-ответе.
+type ExplainTaskResponse struct {
+	// ID - Локальный индекс задачи в выражении (начиная с 1).
+	ID int64 `json:"id"`
+	// Operation - Операция задачи.
+	Operation string `json:"operation"`
+	// Args - Аргументы задачи, известные при разборе. nil, если аргумент вычисляется другой задачей.
+	Args []*float64 `json:"args"`
+	// Dependencies - Локальные индексы задач, вычисляющих аргументы. 0, если аргумент не зависит от задачи выражения.
+	Dependencies []int `json:"dependencies"`
+	// ExternalDependencies - ID задач других выражений, вычисляющих аргументы (ссылки $42). 0, если зависимости нет.
+	// Если таких зависимостей нет, то поле не включается в JSON-ответ (omitempty).
+	ExternalDependencies []int64 `json:"external_dependencies,omitempty"`
+	// ExactArgs - Точные значения известных аргументов: дроби "num/den" в точном режиме
+	// или десятичные записи в десятичном режиме.
+	ExactArgs []*string `json:"exact_args,omitempty"`
+	// ImagArgs - Мнимые части известных аргументов (только в режиме complex).
+	ImagArgs []*float64 `json:"imag_args,omitempty"`
+	// UpperArgs - Верхние границы известных аргументов (только в режиме interval).
+	UpperArgs []*float64 `json:"upper_args,omitempty"`
+	// Guards - Условия выполнения задачи ветви if. Если условий нет, то поле не включается в JSON-ответ (omitempty).
+	Guards []ExplainGuardResponse `json:"guards,omitempty"`
+}
+
+// ExplainGuardResponse представляет условие выполнения задачи ветви if в описании разбора.
+type ExplainGuardResponse struct {
+	// Condition - Локальный индекс задачи-условия.
+	Condition int `json:"condition"`
+	// Value - Истинность условия, при которой выполняется задача.
+	Value bool `json:"value"`
 }
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Function представляет функцию пользователя, например "f(x, y) = x^2 + y^2".
+type Function struct {
+	// ID - Уникальный идентификатор функции.
+	ID int64
+	// UserID - ID пользователя-владельца. Функции доступны только в выражениях своего владельца.
+	UserID int64
+	// Name - Имя функции.
+	Name string
+	// Params - Имена параметров в порядке объявления.
+	Params []string
+	// Body - Тело функции - выражение от параметров.
+	Body string
+}
+
+// Definition возвращает объявление функции: "f(x, y) = x^2 + y^2".
+func (f *Function) Definition() string {
+	return fmt.Sprintf("%s(%s) = %s", f.Name, strings.Join(f.Params, ", "), f.Body)
+}
+
+// FunctionAdd представляет структуру для получения объявления функции из HTTP-запроса.
+type FunctionAdd struct {
+	// Definition - Объявление функции: "f(x, y) = x^2 + y^2".
+	Definition string `json:"definition"`
+}
+
+// FunctionResponse представляет структуру для отправки информации о функции в HTTP-ответе.
+type FunctionResponse struct {
+	// Name - Имя функции.
+	Name string `json:"name"`
+	// Params - Имена параметров.
+	Params []string `json:"params"`
+	// Body - Тело функции.
+	Body string `json:"body"`
+	// Definition - Объявление функции.
+	Definition string `json:"definition"`
+}
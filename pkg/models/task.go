@@ -16,10 +16,61 @@ type Task struct {
 	Result *float64
 	// Expression - ID выражения, к которому принадлежит данная задача.
 	Expression int64
+	// Exact - Признак вычисления задачи в точной рациональной арифметике.
+	Exact bool
+	// Precision - Точность десятичной задачи в значащих цифрах. 0, если задача не десятичная.
+	Precision int
+	// Complex - Признак вычисления задачи в комплексных числах. Действительные части аргументов
+	// и результата хранятся в Args и Result, мнимые - в ImagArgs и ImagResult.
+	Complex bool
+	// ImagArgs - Мнимые части аргументов. Заполняются только для комплексных задач.
+	ImagArgs []*float64
+	// ImagResult - Мнимая часть результата задачи. Может быть nil, если задача не комплексная, не вычислена
+	// или ее результат действителен.
+	ImagResult *float64
+	// Interval - Признак интервальной задачи. Нижние границы аргументов и результата хранятся в Args и Result,
+	// верхние - в UpperArgs и UpperResult.
+	Interval bool
+	// UpperArgs - Верхние границы аргументов. Заполняются только для интервальных задач.
+	UpperArgs []*float64
+	// UpperResult - Верхняя граница результата интервальной задачи. Может быть nil, если задача не интервальная
+	// или не вычислена.
+	UpperResult *float64
+	// ExactArgs - Точные значения аргументов: дроби "num/den" для точных задач или десятичные записи
+	// для десятичных задач. Заполняются только для таких задач (см. HasExactArgs).
+	ExactArgs []*string
+	// ExactResult - Точный результат задачи: дробь "num/den" или десятичная запись.
+	// Может быть nil, если задача вычисляется в float64 или не вычислена.
+	ExactResult *string
+	// Guards - Условия выполнения задачи ветви if (см. TaskGuard). Задача выполняется, только если выполнены
+	// все условия, иначе получает статус "skipped" и не отправляется агентам.
+	Guards []TaskGuard
+	// Iteration - Номер итерации integrate или solve (начиная с 1), которую завершает задача. По таким задачам
+	// показывается ход вычисления выражения (см. ExpressionProgress). 0, если задача не завершает итерацию.
+	Iteration int
+	// Seed - Зерно задачи функции случайных чисел (см. operators.TaskSeed). 0 для остальных задач.
+	Seed int64
 
 	DependencyIndexes []int
 }
 
+// TaskGuard описывает условие выполнения задачи ветви if: задача вычисляет значение ветви и нужна,
+// только если истинность результата задачи-условия (результат не равен 0) совпадает с Value.
+type TaskGuard struct {
+	// Condition - ID задачи-условия.
+	Condition int64
+	// ConditionIndex - Локальный индекс задачи-условия в выражении (начиная с 1). Задается при разборе выражения.
+	ConditionIndex int
+	// Value - Истинность условия, при которой выполняется задача.
+	Value bool
+}
+
+// HasExactArgs сообщает, передаются ли аргументы и результат задачи строками: дробями для точных задач
+// или десятичными записями для десятичных задач.
+func (t *Task) HasExactArgs() bool {
+	return t.Exact || t.Precision > 0
+}
+
 // TaskResponse представляет структуру для отправки информации о задаче в HTTP-ответе.
 type TaskResponse struct {
 	// ID - Уникальный идентификатор задачи.
@@ -33,6 +84,24 @@ type TaskResponse struct {
 	Expression int64 `json:"expression"`
 	// Error - Указывает на невыполниасть задачи
 	Error string `json:"error,omitempty"`
+	// Exact - Признак вычисления задачи в точной рациональной арифметике.
+	Exact bool `json:"exact,omitempty"`
+	// Precision - Точность десятичной задачи в значащих цифрах. 0, если задача не десятичная.
+	Precision int `json:"precision,omitempty"`
+	// ExactArgs - Точные значения аргументов: дроби "num/den" (только для точных задач).
+	ExactArgs []*string `json:"exact_args,omitempty"`
+	// DecimalArgs - Десятичные записи аргументов (только для десятичных задач).
+	DecimalArgs []*string `json:"decimal_args,omitempty"`
+	// Complex - Признак вычисления задачи в комплексных числах.
+	Complex bool `json:"complex,omitempty"`
+	// ImagArgs - Мнимые части аргументов (только для комплексных задач).
+	ImagArgs []*float64 `json:"imag_args,omitempty"`
+	// Interval - Признак интервальной задачи: Args содержат нижние границы аргументов.
+	Interval bool `json:"interval,omitempty"`
+	// UpperArgs - Верхние границы аргументов (только для интервальных задач).
+	UpperArgs []*float64 `json:"upper_args,omitempty"`
+	// Seed - Зерно задачи функции случайных чисел (только для таких задач).
+	Seed int64 `json:"seed,omitempty"`
 }
 
 // TaskCompleted представляет структуру для получения информации о завершенной задаче из HTTP-запроса.
@@ -44,6 +113,12 @@ type TaskCompleted struct {
 	ID int64 `json:"id"`
 	// Result - Результат вычисления задачи.
 	Result float64 `json:"result"`
+	// ExactResult - Точный результат задачи: дробь "num/den" или десятичная запись. Пуст, если задача вычислялась в float64.
+	ExactResult string `json:"exact_result,omitempty"`
+	// ImagResult - Мнимая часть результата комплексной задачи.
+	ImagResult float64 `json:"imag_result,omitempty"`
+	// UpperResult - Верхняя граница результата интервальной задачи (нижняя - в Result). nil, если задача не интервальная.
+	UpperResult *float64 `json:"upper_result,omitempty"`
 	// Error - Указывает на невыполнимость задачи
 	Error string `json:"error,omitempty"`
 }
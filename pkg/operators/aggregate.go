@@ -0,0 +1,271 @@
+package operators
+
+import (
+	"math/big"
+	"sort"
+)
+
+// Агрегатные функции принимают любое количество аргументов, не меньшее Arity: max(3, 7, 1, 9).
+const (
+	FnSum    = "sum"    // сумма аргументов
+	FnMin    = "min"    // наименьший аргумент
+	FnMax    = "max"    // наибольший аргумент
+	FnAvg    = "avg"    // среднее арифметическое
+	FnMedian = "median" // медиана
+	FnProd   = "prod"   // произведение аргументов
+)
+
+// SeriesArgs - количество аргументов записи ряда: sum(i, 1, 1000, i^2) - сумма i^2 при i от 1 до 1000.
+const SeriesArgs = 4
+
+// IsSeries сообщает, может ли вызов функции быть записью ряда: вызов sum или prod с SeriesArgs аргументами.
+// Вызов является записью ряда, если его первый аргумент - имя переменной ряда.
+//
+// Args:
+//
+//	symbol: string - Имя функции.
+//	args: int - Количество аргументов вызова.
+//
+// Returns:
+//
+//	bool - true, если вызов может быть записью ряда.
+func IsSeries(symbol string, args int) bool {
+	return (symbol == FnSum || symbol == FnProd) && args == SeriesArgs
+}
+
+// middle возвращает индексы средних элементов упорядоченного набора из n элементов.
+// При нечетном n индексы совпадают.
+func middle(n int) (int, int) {
+	return (n - 1) / 2, n / 2
+}
+
+// floatSum вычисляет сумму аргументов.
+func floatSum(args ...float64) (float64, error) {
+	sum := 0.0
+	for _, arg := range args {
+		sum += arg
+	}
+	return sum, nil
+}
+
+// floatProd вычисляет произведение аргументов.
+func floatProd(args ...float64) (float64, error) {
+	prod := 1.0
+	for _, arg := range args {
+		prod *= arg
+	}
+	return prod, nil
+}
+
+// floatExtremum возвращает функцию наименьшего или наибольшего аргумента.
+//
+// Args:
+//
+//	pick: func(x, y float64) float64 - math.Min или math.Max.
+//
+// Returns:
+//
+//	func(args ...float64) (float64, error) - Функция Operator.Eval.
+func floatExtremum(pick func(x, y float64) float64) func(args ...float64) (float64, error) {
+	return func(args ...float64) (float64, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = pick(result, arg)
+		}
+		return result, nil
+	}
+}
+
+// floatAvg вычисляет среднее арифметическое аргументов.
+func floatAvg(args ...float64) (float64, error) {
+	sum, _ := floatSum(args...)
+	return sum / float64(len(args)), nil
+}
+
+// floatMedian вычисляет медиану аргументов: средний элемент упорядоченного набора
+// или среднее двух средних элементов при четном количестве аргументов.
+func floatMedian(args ...float64) (float64, error) {
+	sorted := append([]float64(nil), args...)
+	sort.Float64s(sorted)
+	lo, hi := middle(len(sorted))
+	return sorted[lo] + (sorted[hi]-sorted[lo])/2, nil
+}
+
+// exactSum вычисляет точную сумму аргументов.
+func exactSum(args ...*big.Rat) (*big.Rat, error) {
+	sum := new(big.Rat)
+	for _, arg := range args {
+		sum.Add(sum, arg)
+	}
+	return sum, nil
+}
+
+// exactProd вычисляет точное произведение аргументов.
+func exactProd(args ...*big.Rat) (*big.Rat, error) {
+	prod := new(big.Rat).SetInt64(1)
+	for _, arg := range args {
+		prod.Mul(prod, arg)
+	}
+	return prod, nil
+}
+
+// exactExtremum возвращает функцию наименьшего (sign = -1) или наибольшего (sign = 1) рационального аргумента.
+func exactExtremum(sign int) func(args ...*big.Rat) (*big.Rat, error) {
+	return func(args ...*big.Rat) (*big.Rat, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			if arg.Cmp(result) == sign {
+				result = arg
+			}
+		}
+		return new(big.Rat).Set(result), nil
+	}
+}
+
+// exactAvg вычисляет точное среднее арифметическое аргументов.
+func exactAvg(args ...*big.Rat) (*big.Rat, error) {
+	sum, _ := exactSum(args...)
+	return sum.Quo(sum, new(big.Rat).SetInt64(int64(len(args)))), nil
+}
+
+// exactMedian вычисляет точную медиану аргументов.
+func exactMedian(args ...*big.Rat) (*big.Rat, error) {
+	sorted := append([]*big.Rat(nil), args...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	lo, hi := middle(len(sorted))
+	return exactAvg(sorted[lo], sorted[hi])
+}
+
+// decimalSum вычисляет сумму аргументов с точностью prec бит.
+func decimalSum(prec uint, args ...*big.Float) (*big.Float, error) {
+	sum := new(big.Float).SetPrec(prec)
+	for _, arg := range args {
+		sum.Add(sum, arg)
+	}
+	return decimalResult(sum, prec)
+}
+
+// decimalProd вычисляет произведение аргументов с точностью prec бит.
+func decimalProd(prec uint, args ...*big.Float) (*big.Float, error) {
+	prod := new(big.Float).SetPrec(prec).SetInt64(1)
+	for _, arg := range args {
+		prod.Mul(prod, arg)
+	}
+	return decimalResult(prod, prec)
+}
+
+// decimalExtremum возвращает функцию наименьшего (sign = -1) или наибольшего (sign = 1) аргумента.
+func decimalExtremum(sign int) func(prec uint, args ...*big.Float) (*big.Float, error) {
+	return func(prec uint, args ...*big.Float) (*big.Float, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			if arg.Cmp(result) == sign {
+				result = arg
+			}
+		}
+		return new(big.Float).SetPrec(prec).Set(result), nil
+	}
+}
+
+// decimalAvg вычисляет среднее арифметическое аргументов с точностью prec бит.
+func decimalAvg(prec uint, args ...*big.Float) (*big.Float, error) {
+	sum, err := decimalSum(prec, args...)
+	if err != nil {
+		return nil, err
+	}
+	return sum.Quo(sum, new(big.Float).SetInt64(int64(len(args)))), nil
+}
+
+// decimalMedian вычисляет медиану аргументов с точностью prec бит.
+func decimalMedian(prec uint, args ...*big.Float) (*big.Float, error) {
+	sorted := append([]*big.Float(nil), args...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	lo, hi := middle(len(sorted))
+	return decimalAvg(prec, sorted[lo], sorted[hi])
+}
+
+// complexSum вычисляет сумму комплексных аргументов.
+func complexSum(args ...complex128) (complex128, error) {
+	var sum complex128
+	for _, arg := range args {
+		sum += arg
+	}
+	return sum, nil
+}
+
+// complexProd вычисляет произведение комплексных аргументов.
+func complexProd(args ...complex128) (complex128, error) {
+	prod := complex(1, 0)
+	for _, arg := range args {
+		prod *= arg
+	}
+	return prod, nil
+}
+
+// complexAvg вычисляет среднее арифметическое комплексных аргументов.
+func complexAvg(args ...complex128) (complex128, error) {
+	sum, _ := complexSum(args...)
+	return sum / complex(float64(len(args)), 0), nil
+}
+
+// intervalSum вычисляет сумму интервалов. Каждое сложение расширяет границы наружу,
+// поэтому ошибка округления не накапливается внутрь интервала.
+func intervalSum(args ...Interval) (Interval, error) {
+	sum := args[0]
+	for _, arg := range args[1:] {
+		sum = outward(sum.Lo+arg.Lo, sum.Hi+arg.Hi)
+	}
+	return sum, nil
+}
+
+// intervalProd вычисляет произведение интервалов последовательным умножением (см. intervalMultiply).
+func intervalProd(args ...Interval) (Interval, error) {
+	prod := args[0]
+	for _, arg := range args[1:] {
+		prod, _ = intervalMultiply(prod, arg)
+	}
+	return prod, nil
+}
+
+// intervalExtremum возвращает функцию наименьшего или наибольшего из интервалов. Функция монотонна
+// по каждому аргументу, поэтому ее границы - значения функции на границах аргументов.
+//
+// Args:
+//
+//	pick: func(x, y float64) float64 - math.Min или math.Max.
+//
+// Returns:
+//
+//	func(args ...Interval) (Interval, error) - Функция Operator.Interval.
+func intervalExtremum(pick func(x, y float64) float64) func(args ...Interval) (Interval, error) {
+	return func(args ...Interval) (Interval, error) {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = Interval{Lo: pick(result.Lo, arg.Lo), Hi: pick(result.Hi, arg.Hi)}
+		}
+		return result, nil
+	}
+}
+
+// intervalAvg вычисляет среднее арифметическое интервалов.
+func intervalAvg(args ...Interval) (Interval, error) {
+	sum, _ := intervalSum(args...)
+	return intervalDivide(sum, Degenerate(float64(len(args))))
+}
+
+// intervalMedian вычисляет медиану интервалов. Медиана монотонна по каждому аргументу,
+// поэтому ее границы - медианы нижних и верхних границ аргументов.
+func intervalMedian(args ...Interval) (Interval, error) {
+	lower := make([]float64, len(args))
+	upper := make([]float64, len(args))
+	for i, arg := range args {
+		lower[i], upper[i] = arg.Lo, arg.Hi
+	}
+	lo, _ := floatMedian(lower...)
+	hi, _ := floatMedian(upper...)
+	if len(args)%2 == 1 {
+		return Interval{Lo: lo, Hi: hi}, nil
+	}
+	// Среднее двух средних элементов вычисляется с округлением
+	return outward(lo, hi), nil
+}
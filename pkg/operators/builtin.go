@@ -0,0 +1,276 @@
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+
+	"github.com/OinkiePie/calc_3/pkg/units"
+)
+
+// Ошибки вычисления встроенных операций.
+// Текст ошибки передается оркестратору и сохраняется в выражении.
+var (
+	ErrDivisionByZero = errors.New("деление на ноль")
+	ErrNegativeSqrt   = errors.New("корень из отрицательного числа")
+	ErrNonPositiveLog = errors.New("логарифм неположительного числа")
+	ErrExactExponent  = fmt.Errorf("в точном режиме показатель степени должен быть целым числом не больше %d по модулю", MaxExactExponent)
+)
+
+// unary оборачивает функцию одного аргумента без ошибок в сигнатуру Operator.Eval.
+func unary(fn func(float64) float64) func(args ...float64) (float64, error) {
+	return func(args ...float64) (float64, error) {
+		return fn(args[0]), nil
+	}
+}
+
+func init() {
+	// Бинарные операторы
+	Register(&Operator{
+		Symbol: OpAdd, Arity: 2, Precedence: 5, Associative: true, Commutative: true, TimeKey: "TIME_ADDITION_MS", Dimension: units.Same,
+		Eval:  func(args ...float64) (float64, error) { return args[0] + args[1], nil },
+		Exact: func(args ...*big.Rat) (*big.Rat, error) { return new(big.Rat).Add(args[0], args[1]), nil },
+		Decimal: func(prec uint, args ...*big.Float) (*big.Float, error) {
+			return decimalResult(new(big.Float).SetPrec(prec).Add(args[0], args[1]), prec)
+		},
+		Complex: func(args ...complex128) (complex128, error) { return args[0] + args[1], nil },
+		Interval: func(args ...Interval) (Interval, error) {
+			return outward(args[0].Lo+args[1].Lo, args[0].Hi+args[1].Hi), nil
+		},
+	})
+	Register(&Operator{
+		Symbol: OpSubtract, Arity: 2, Precedence: 5, TimeKey: "TIME_SUBTRACTION_MS", Dimension: units.Same,
+		Eval:  func(args ...float64) (float64, error) { return args[0] - args[1], nil },
+		Exact: func(args ...*big.Rat) (*big.Rat, error) { return new(big.Rat).Sub(args[0], args[1]), nil },
+		Decimal: func(prec uint, args ...*big.Float) (*big.Float, error) {
+			return decimalResult(new(big.Float).SetPrec(prec).Sub(args[0], args[1]), prec)
+		},
+		Complex: func(args ...complex128) (complex128, error) { return args[0] - args[1], nil },
+		Interval: func(args ...Interval) (Interval, error) {
+			return outward(args[0].Lo-args[1].Hi, args[0].Hi-args[1].Lo), nil
+		},
+	})
+	Register(&Operator{
+		Symbol: OpMultiply, Arity: 2, Precedence: 6, Associative: true, Commutative: true, TimeKey: "TIME_MULTIPLICATION_MS", Dimension: units.Product,
+		Eval:  func(args ...float64) (float64, error) { return args[0] * args[1], nil },
+		Exact: func(args ...*big.Rat) (*big.Rat, error) { return new(big.Rat).Mul(args[0], args[1]), nil },
+		Decimal: func(prec uint, args ...*big.Float) (*big.Float, error) {
+			return decimalResult(new(big.Float).SetPrec(prec).Mul(args[0], args[1]), prec)
+		},
+		Complex:  func(args ...complex128) (complex128, error) { return args[0] * args[1], nil },
+		Interval: intervalMultiply,
+	})
+	Register(&Operator{
+		Symbol: OpDivide, Arity: 2, Precedence: 6, TimeKey: "TIME_DIVISION_MS", Dimension: units.Quotient,
+		Eval: func(args ...float64) (float64, error) {
+			if args[1] == 0 {
+				return 0, ErrDivisionByZero
+			}
+			return args[0] / args[1], nil
+		},
+		Exact: func(args ...*big.Rat) (*big.Rat, error) {
+			if args[1].Sign() == 0 {
+				return nil, ErrDivisionByZero
+			}
+			return new(big.Rat).Quo(args[0], args[1]), nil
+		},
+		Decimal: func(prec uint, args ...*big.Float) (*big.Float, error) {
+			if args[1].Sign() == 0 {
+				return nil, ErrDivisionByZero
+			}
+			return decimalResult(new(big.Float).SetPrec(prec).Quo(args[0], args[1]), prec)
+		},
+		Complex: func(args ...complex128) (complex128, error) {
+			if args[1] == 0 {
+				return 0, ErrDivisionByZero
+			}
+			return args[0] / args[1], nil
+		},
+		Interval: intervalDivide,
+	})
+	Register(&Operator{
+		Symbol: OpPower, Arity: 2, Precedence: 8, RightAssoc: true, TimeKey: "TIME_POWER_MS", Dimension: units.Power,
+		Eval:     func(args ...float64) (float64, error) { return math.Pow(args[0], args[1]), nil },
+		Exact:    exactPower,
+		Decimal:  decimalPower,
+		Complex:  complexPower,
+		Interval: intervalPower,
+	})
+
+	// Унарный минус
+	Register(&Operator{
+		Symbol: OpUnaryMinus, Arity: 1, Precedence: 7, TimeKey: "TIME_UNARY_MINUS_MS", Dimension: units.Same,
+		Eval:  func(args ...float64) (float64, error) { return -args[0], nil },
+		Exact: func(args ...*big.Rat) (*big.Rat, error) { return new(big.Rat).Neg(args[0]), nil },
+		Decimal: func(prec uint, args ...*big.Float) (*big.Float, error) {
+			return new(big.Float).SetPrec(prec).Neg(args[0]), nil
+		},
+		Complex: func(args ...complex128) (complex128, error) { return -args[0], nil },
+		Interval: func(args ...Interval) (Interval, error) {
+			return Interval{Lo: -args[0].Hi, Hi: -args[0].Lo}, nil
+		},
+	})
+
+	// Функции одного аргумента
+	Register(&Operator{Symbol: FnSin, Arity: 1, Function: true, TimeKey: "TIME_SIN_MS", Eval: unary(math.Sin), Decimal: decimalTrig(FnSin), Complex: complexUnary(cmplx.Sin), Interval: intervalTrig(FnSin)})
+	Register(&Operator{Symbol: FnCos, Arity: 1, Function: true, TimeKey: "TIME_COS_MS", Eval: unary(math.Cos), Decimal: decimalTrig(FnCos), Complex: complexUnary(cmplx.Cos), Interval: intervalTrig(FnCos)})
+	Register(&Operator{Symbol: FnTan, Arity: 1, Function: true, TimeKey: "TIME_TAN_MS", Eval: unary(math.Tan), Decimal: decimalTrig(FnTan), Complex: complexUnary(cmplx.Tan), Interval: intervalTan})
+	Register(&Operator{
+		Symbol: FnSqrt, Arity: 1, Function: true, TimeKey: "TIME_SQRT_MS", Dimension: units.Root,
+		Eval: func(args ...float64) (float64, error) {
+			if args[0] < 0 {
+				return 0, ErrNegativeSqrt
+			}
+			return math.Sqrt(args[0]), nil
+		},
+		Decimal: func(prec uint, args ...*big.Float) (*big.Float, error) {
+			if args[0].Sign() < 0 {
+				return nil, ErrNegativeSqrt
+			}
+			return new(big.Float).SetPrec(prec).Sqrt(args[0]), nil
+		},
+		Complex: complexUnary(cmplx.Sqrt),
+		Interval: func(args ...Interval) (Interval, error) {
+			if args[0].Lo < 0 {
+				return Interval{}, ErrNegativeSqrt
+			}
+			return outward(math.Sqrt(args[0].Lo), math.Sqrt(args[0].Hi)), nil
+		},
+	})
+	Register(&Operator{
+		Symbol: FnLn, Arity: 1, Function: true, TimeKey: "TIME_LN_MS",
+		Eval: func(args ...float64) (float64, error) {
+			if args[0] <= 0 {
+				return 0, ErrNonPositiveLog
+			}
+			return math.Log(args[0]), nil
+		},
+		Decimal:  decimalLog(0),
+		Complex:  complexLog(cmplx.Log),
+		Interval: intervalLog(math.Log),
+	})
+	Register(&Operator{
+		Symbol: FnLog, Arity: 1, Function: true, TimeKey: "TIME_LOG_MS",
+		Eval: func(args ...float64) (float64, error) {
+			if args[0] <= 0 {
+				return 0, ErrNonPositiveLog
+			}
+			return math.Log10(args[0]), nil
+		},
+		Decimal:  decimalLog(10),
+		Complex:  complexLog(cmplx.Log10),
+		Interval: intervalLog(math.Log10),
+	})
+	Register(&Operator{
+		Symbol: FnAbs, Arity: 1, Function: true, TimeKey: "TIME_ABS_MS", Dimension: units.Same, Eval: unary(math.Abs),
+		Exact: func(args ...*big.Rat) (*big.Rat, error) { return new(big.Rat).Abs(args[0]), nil },
+		Decimal: func(prec uint, args ...*big.Float) (*big.Float, error) {
+			return new(big.Float).SetPrec(prec).Abs(args[0]), nil
+		},
+		Complex:  func(args ...complex128) (complex128, error) { return complex(cmplx.Abs(args[0]), 0), nil },
+		Interval: intervalAbs,
+	})
+	Register(&Operator{Symbol: FnExp, Arity: 1, Function: true, TimeKey: "TIME_EXP_MS", Eval: unary(math.Exp), Decimal: decimalUnary(bigExp), Complex: complexUnary(cmplx.Exp), Interval: intervalMonotone(math.Exp)})
+
+	// Агрегатные функции с переменным числом аргументов
+	Register(&Operator{
+		Symbol: FnSum, Arity: 1, Variadic: true, Function: true, Associative: true, Commutative: true, TimeKey: "TIME_AGGREGATE_MS", Dimension: units.Same,
+		Eval: floatSum, Exact: exactSum, Decimal: decimalSum, Complex: complexSum, Interval: intervalSum,
+	})
+	Register(&Operator{
+		Symbol: FnMin, Arity: 1, Variadic: true, Function: true, Associative: true, Commutative: true, TimeKey: "TIME_AGGREGATE_MS", Dimension: units.Same,
+		Eval: floatExtremum(math.Min), Exact: exactExtremum(-1), Decimal: decimalExtremum(-1), Interval: intervalExtremum(math.Min),
+	})
+	Register(&Operator{
+		Symbol: FnMax, Arity: 1, Variadic: true, Function: true, Associative: true, Commutative: true, TimeKey: "TIME_AGGREGATE_MS", Dimension: units.Same,
+		Eval: floatExtremum(math.Max), Exact: exactExtremum(1), Decimal: decimalExtremum(1), Interval: intervalExtremum(math.Max),
+	})
+	Register(&Operator{
+		Symbol: FnAvg, Arity: 1, Variadic: true, Function: true, Commutative: true, TimeKey: "TIME_AGGREGATE_MS", Dimension: units.Same,
+		Eval: floatAvg, Exact: exactAvg, Decimal: decimalAvg, Complex: complexAvg, Interval: intervalAvg,
+	})
+	Register(&Operator{
+		Symbol: FnMedian, Arity: 1, Variadic: true, Function: true, Commutative: true, TimeKey: "TIME_AGGREGATE_MS", Dimension: units.Same,
+		Eval: floatMedian, Exact: exactMedian, Decimal: decimalMedian, Interval: intervalMedian,
+	})
+	Register(&Operator{
+		Symbol: FnProd, Arity: 1, Variadic: true, Function: true, Associative: true, Commutative: true, TimeKey: "TIME_AGGREGATE_MS", Dimension: units.Product,
+		Eval: floatProd, Exact: exactProd, Decimal: decimalProd, Complex: complexProd, Interval: intervalProd,
+	})
+
+	// Оператор погрешности связывает сильнее степени: 2±0.1^2 = (2±0.1)^2
+	Register(&Operator{
+		Symbol: OpPlusMinus, Arity: 2, Precedence: 9, TimeKey: "TIME_ADDITION_MS", Dimension: units.Same,
+		Eval:     func(args ...float64) (float64, error) { return args[0], nil },
+		Interval: intervalPlusMinus,
+	})
+
+	// Операторы сравнения и логические операторы связывают слабее арифметических: x + 1 > 2 && y < 3
+	registerComparison(OpLess, 4, false, func(sign int) bool { return sign < 0 })
+	registerComparison(OpLessEqual, 4, false, func(sign int) bool { return sign <= 0 })
+	registerComparison(OpGreater, 4, false, func(sign int) bool { return sign > 0 })
+	registerComparison(OpGreaterEqual, 4, false, func(sign int) bool { return sign >= 0 })
+	registerComparison(OpEqual, 3, true, func(sign int) bool { return sign == 0 })
+	registerComparison(OpNotEqual, 3, true, func(sign int) bool { return sign != 0 })
+
+	and, andExact, andDecimal := logical(func(truth ...bool) bool { return truth[0] && truth[1] })
+	Register(&Operator{
+		Symbol: OpAnd, Arity: 2, Precedence: 2, Associative: true, Commutative: true, TimeKey: "TIME_COMPARISON_MS",
+		Eval: and, Exact: andExact, Decimal: andDecimal,
+	})
+	or, orExact, orDecimal := logical(func(truth ...bool) bool { return truth[0] || truth[1] })
+	Register(&Operator{
+		Symbol: OpOr, Arity: 2, Precedence: 1, Associative: true, Commutative: true, TimeKey: "TIME_COMPARISON_MS",
+		Eval: or, Exact: orExact, Decimal: orDecimal,
+	})
+	not, notExact, notDecimal := logical(func(truth ...bool) bool { return !truth[0] })
+	Register(&Operator{
+		Symbol: FnNot, Arity: 1, Function: true, TimeKey: "TIME_COMPARISON_MS",
+		Eval: not, Exact: notExact, Decimal: notDecimal,
+	})
+
+	// Выбор значения по условию. Оркестратор не отправляет агентам задачи невыбранного значения,
+	// поэтому оно передается задаче if нулем
+	Register(&Operator{
+		Symbol: FnIf, Arity: 3, Function: true, TimeKey: "TIME_COMPARISON_MS", Dimension: units.Select,
+		Eval: ifEval, Exact: ifExact, Decimal: ifDecimal,
+	})
+
+	// Целочисленные операторы имеют приоритет умножения: 7 + 10 % 4 = 7 + (10 % 4).
+	// Факториал связывает сильнее всех операторов: 2^3! = 2^(3!), -3! = -(3!)
+	mod, modExact, modDecimal := floorDivision(true)
+	Register(&Operator{
+		Symbol: OpMod, Arity: 2, Precedence: 6, TimeKey: "TIME_INTEGER_MS", Dimension: units.Same,
+		Eval: mod, Exact: modExact, Decimal: modDecimal,
+	})
+	floorDiv, floorDivExact, floorDivDecimal := floorDivision(false)
+	Register(&Operator{
+		Symbol: OpFloorDiv, Arity: 2, Precedence: 6, TimeKey: "TIME_INTEGER_MS", Dimension: units.Quotient,
+		Eval: floorDiv, Exact: floorDivExact, Decimal: floorDivDecimal,
+	})
+	Register(&Operator{
+		Symbol: OpFactorial, Arity: 1, Precedence: 10, Postfix: true, TimeKey: "TIME_INTEGER_MS",
+		Eval: factorialEval, Exact: factorialExact, Decimal: factorialDecimal,
+	})
+	gcd, gcdExact, gcdDecimal := integerFunction(intGcd)
+	Register(&Operator{
+		Symbol: FnGcd, Arity: 2, Variadic: true, Function: true, Associative: true, Commutative: true, TimeKey: "TIME_INTEGER_MS",
+		Eval: gcd, Exact: gcdExact, Decimal: gcdDecimal,
+	})
+	lcm, lcmExact, lcmDecimal := integerFunction(intLcm)
+	Register(&Operator{
+		Symbol: FnLcm, Arity: 2, Variadic: true, Function: true, Associative: true, Commutative: true, TimeKey: "TIME_INTEGER_MS",
+		Eval: lcm, Exact: lcmExact, Decimal: lcmDecimal,
+	})
+	Register(&Operator{
+		Symbol: FnIsPrime, Arity: 1, Function: true, TimeKey: "TIME_INTEGER_MS",
+		Eval: isPrimeEval, Exact: isPrimeExact, Decimal: isPrimeDecimal,
+	})
+
+	// Функции случайных чисел вычисляются только в режиме float
+	Register(&Operator{Symbol: FnRand, Arity: 0, Function: true, TimeKey: "TIME_RANDOM_MS", Random: randEval})
+	Register(&Operator{Symbol: FnRandInt, Arity: 2, Function: true, TimeKey: "TIME_RANDOM_MS", Random: randIntEval})
+	Register(&Operator{Symbol: FnNormal, Arity: 2, Function: true, TimeKey: "TIME_RANDOM_MS", Dimension: units.Same, Random: normalEval})
+}
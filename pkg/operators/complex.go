@@ -0,0 +1,98 @@
+package operators
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"strconv"
+)
+
+// ImaginaryUnit - имя мнимой единицы и суффикс мнимых чисел (2i) в режиме комплексных вычислений.
+const ImaginaryUnit = "i"
+
+// ErrZeroLog - ошибка логарифма нуля в комплексном режиме вычислений.
+var ErrZeroLog = errors.New("логарифм нуля")
+
+// FormatComplex записывает комплексное число в виде "a+bi": действительная часть, затем мнимая со знаком.
+// Нулевая часть опускается (3, -2i), а единичный коэффициент при i записывается явно (1+1i),
+// поэтому запись однозначна и одинакова для равных чисел.
+//
+// Args:
+//
+//	value: complex128 - Комплексное число.
+//
+// Returns:
+//
+//	string - Запись числа.
+func FormatComplex(value complex128) string {
+	format := func(part float64) string {
+		if part == 0 {
+			part = 0 // -0 записывается как 0
+		}
+		return strconv.FormatFloat(part, 'g', -1, 64)
+	}
+
+	re, im := real(value), imag(value)
+	switch {
+	case im == 0:
+		return format(re)
+	case re == 0:
+		return format(im) + ImaginaryUnit
+	case im < 0 || math.IsNaN(im):
+		return format(re) + format(im) + ImaginaryUnit
+	default:
+		return format(re) + "+" + format(im) + ImaginaryUnit
+	}
+}
+
+// principal заменяет мнимую часть -0 нулем. Функции пакета cmplx различают берега разреза по знаку
+// мнимой части, а унарный минус дает -0 (-(4+0i) = -4-0i), поэтому без замены sqrt(-4) было бы -2i.
+// Числа на разрезе относятся к верхнему берегу: sqrt(-4) = 2i, ln(-1) = πi.
+//
+// Args:
+//
+//	value: complex128 - Аргумент функции.
+//
+// Returns:
+//
+//	complex128 - Аргумент с неотрицательным нулем в мнимой части.
+func principal(value complex128) complex128 {
+	if imag(value) == 0 {
+		return complex(real(value), 0)
+	}
+	return value
+}
+
+// complexUnary оборачивает функцию одного аргумента без ошибок в сигнатуру Operator.Complex.
+func complexUnary(fn func(complex128) complex128) func(args ...complex128) (complex128, error) {
+	return func(args ...complex128) (complex128, error) {
+		return fn(principal(args[0])), nil
+	}
+}
+
+// complexLog возвращает функцию логарифма комплексного числа: fn - cmplx.Log или cmplx.Log10.
+func complexLog(fn func(complex128) complex128) func(args ...complex128) (complex128, error) {
+	return func(args ...complex128) (complex128, error) {
+		if args[0] == 0 {
+			return 0, ErrZeroLog
+		}
+		return fn(principal(args[0])), nil
+	}
+}
+
+// complexPower возводит комплексное число в комплексную степень.
+//
+// Args:
+//
+//	args: ...complex128 - Основание и показатель степени.
+//
+// Returns:
+//
+//	complex128 - Главное значение степени.
+//	error - ErrDivisionByZero при возведении нуля в степень с отрицательной действительной частью.
+func complexPower(args ...complex128) (complex128, error) {
+	if args[0] == 0 && real(args[1]) < 0 {
+		return 0, ErrDivisionByZero
+	}
+	return cmplx.Pow(principal(args[0]), args[1]), nil
+}
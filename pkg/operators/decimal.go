@@ -0,0 +1,318 @@
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Параметры десятичного режима вычислений с произвольной точностью.
+const (
+	DefaultPrecision = 34   // Точность по умолчанию в значащих цифрах (как у decimal128)
+	MaxPrecision     = 1000 // Наибольшая точность в значащих цифрах
+	GuardDigits      = 5    // Дополнительные цифры промежуточных результатов, уменьшающие накопление ошибок округления
+	guardBits        = 64   // Дополнительные биты внутренних вычислений функций
+)
+
+// Ошибки десятичного режима вычислений.
+var (
+	ErrDecimalOverflow = errors.New("переполнение")
+	ErrNegativeBase    = errors.New("отрицательное основание степени с нецелым показателем")
+)
+
+// PrecisionBits возвращает количество бит мантиссы big.Float, достаточное для digits значащих десятичных цифр.
+//
+// Args:
+//
+//	digits: int - Количество значащих десятичных цифр.
+//
+// Returns:
+//
+//	uint - Точность в битах.
+func PrecisionBits(digits int) uint {
+	return uint(math.Ceil(float64(digits)*math.Log2(10))) + 1
+}
+
+// ParseDecimal разбирает десятичную запись числа ("1.5", "-2e-30") или дробь "num/den" с точностью prec бит.
+//
+// Args:
+//
+//	s: string - Запись числа.
+//	prec: uint - Точность в битах.
+//
+// Returns:
+//
+//	*big.Float - Число, округленное до prec бит.
+//	error - Ошибка, если запись неверна.
+func ParseDecimal(s string, prec uint) (*big.Float, error) {
+	value, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("неверная запись числа: %s", s)
+	}
+	return new(big.Float).SetPrec(prec).SetRat(value), nil
+}
+
+// FormatDecimalDigits записывает число с digits значащими цифрами, опуская незначащие нули:
+// 2^0.5 с 10 цифрами -> "1.414213562". Очень большие и очень маленькие числа записываются с порядком ("1e+100").
+//
+// Args:
+//
+//	value: *big.Float - Число.
+//	digits: int - Количество значащих цифр.
+//
+// Returns:
+//
+//	string - Запись числа.
+func FormatDecimalDigits(value *big.Float, digits int) string {
+	text := value.Text('g', digits)
+	if text == "-0" {
+		text = "0"
+	}
+	return text
+}
+
+// RoundDecimal округляет десятичную запись числа до digits значащих цифр.
+//
+// Args:
+//
+//	s: string - Запись числа (см. ParseDecimal).
+//	digits: int - Количество значащих цифр.
+//
+// Returns:
+//
+//	string - Округленная запись числа.
+//	error - Ошибка, если запись неверна.
+func RoundDecimal(s string, digits int) (string, error) {
+	value, err := ParseDecimal(s, PrecisionBits(digits)+guardBits)
+	if err != nil {
+		return "", err
+	}
+	return FormatDecimalDigits(value, digits), nil
+}
+
+// decimalResult проверяет, что результат вычисления конечен, и округляет его до prec бит.
+func decimalResult(value *big.Float, prec uint) (*big.Float, error) {
+	if value.IsInf() {
+		return nil, ErrDecimalOverflow
+	}
+	return new(big.Float).SetPrec(prec).Set(value), nil
+}
+
+// decimalPower возводит число в степень с точностью prec бит.
+// Целые показатели вычисляются последовательным возведением в квадрат, остальные - как exp(y*ln(x)).
+//
+// Args:
+//
+//	prec: uint - Точность результата в битах.
+//	args: ...*big.Float - Основание и показатель степени.
+//
+// Returns:
+//
+//	*big.Float - Результат возведения в степень.
+//	error - ErrDivisionByZero при возведении нуля в отрицательную степень, ErrNegativeBase
+//	        при отрицательном основании и нецелом показателе, ErrDecimalOverflow при переполнении.
+func decimalPower(prec uint, args ...*big.Float) (*big.Float, error) {
+	base, exponent := args[0], args[1]
+	if exponent.IsInt() {
+		if n, accuracy := exponent.Int64(); accuracy == big.Exact {
+			if n < 0 && base.Sign() == 0 {
+				return nil, ErrDivisionByZero
+			}
+			return decimalResult(intPower(base, n, prec+guardBits), prec)
+		}
+	}
+
+	switch base.Sign() {
+	case 0:
+		if exponent.Sign() < 0 {
+			return nil, ErrDivisionByZero
+		}
+		return new(big.Float).SetPrec(prec), nil
+	case -1:
+		return nil, ErrNegativeBase
+	}
+
+	p := prec + guardBits + uint(max(0, exponent.MantExp(nil)))
+	logarithm := new(big.Float).SetPrec(p).Mul(exponent, bigLn(base, p))
+	return decimalResult(bigExp(logarithm, p), prec)
+}
+
+// intPower возводит число в целую степень n последовательным возведением в квадрат.
+func intPower(base *big.Float, n int64, prec uint) *big.Float {
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+	result := new(big.Float).SetPrec(prec).SetInt64(1)
+	square := new(big.Float).SetPrec(prec).Set(base)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result.Mul(result, square)
+		}
+		square.Mul(square, square)
+	}
+	if negative {
+		result.Quo(new(big.Float).SetPrec(prec).SetInt64(1), result)
+	}
+	return result
+}
+
+// bigExp вычисляет экспоненту числа с точностью prec бит.
+// Аргумент делится на 2^k, чтобы ряд Тейлора сходился быстро, а результат k раз возводится в квадрат.
+func bigExp(x *big.Float, prec uint) *big.Float {
+	k := max(0, x.MantExp(nil)+8)
+	p := prec + uint(k)
+	r := new(big.Float).SetPrec(p).SetMantExp(x, -k)
+
+	sum := new(big.Float).SetPrec(p).SetInt64(1)
+	term := new(big.Float).SetPrec(p).SetInt64(1)
+	for i := int64(1); ; i++ {
+		term.Mul(term, r)
+		term.Quo(term, new(big.Float).SetPrec(p).SetInt64(i))
+		if term.Sign() == 0 || term.MantExp(nil) < sum.MantExp(nil)-int(p) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	for ; k > 0 && !sum.IsInf(); k-- {
+		sum.Mul(sum, sum)
+	}
+	return sum
+}
+
+// bigLn вычисляет натуральный логарифм положительного числа с точностью prec бит.
+// Число представляется как m * 2^e (0.5 <= m < 1), и ln(x) = ln(m) + e*ln(2).
+// Числа из [0.5, 2) не раскладываются, чтобы не терять точность при x, близком к 1.
+func bigLn(x *big.Float, prec uint) *big.Float {
+	p := prec + guardBits
+	if x.MantExp(nil) <= 1 && x.MantExp(nil) >= 0 {
+		return lnNearOne(new(big.Float).SetPrec(p).Set(x), p)
+	}
+	m := new(big.Float).SetPrec(p)
+	e := x.MantExp(m)
+
+	result := lnNearOne(m, p)
+	if e != 0 {
+		ln2 := lnNearOne(new(big.Float).SetPrec(p).SetInt64(2), p)
+		result.Add(result, ln2.Mul(ln2, new(big.Float).SetPrec(p).SetInt64(int64(e))))
+	}
+	return result
+}
+
+// lnNearOne вычисляет ln(m) рядом 2*atanh(z), z = (m-1)/(m+1). Ряд быстро сходится при m, близком к 1.
+func lnNearOne(m *big.Float, prec uint) *big.Float {
+	one := new(big.Float).SetPrec(prec).SetInt64(1)
+	z := new(big.Float).SetPrec(prec).Sub(m, one)
+	z.Quo(z, new(big.Float).SetPrec(prec).Add(m, one))
+	z2 := new(big.Float).SetPrec(prec).Mul(z, z)
+
+	sum := new(big.Float).SetPrec(prec).Set(z)
+	power := new(big.Float).SetPrec(prec).Set(z)
+	for i := int64(3); z.Sign() != 0; i += 2 {
+		power.Mul(power, z2)
+		term := new(big.Float).SetPrec(prec).Quo(power, new(big.Float).SetPrec(prec).SetInt64(i))
+		if term.Sign() == 0 || term.MantExp(nil) < sum.MantExp(nil)-int(prec) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	return sum.Mul(sum, new(big.Float).SetPrec(prec).SetInt64(2))
+}
+
+// bigPi вычисляет число пи по формуле Мэчина: pi = 16*atan(1/5) - 4*atan(1/239).
+func bigPi(prec uint) *big.Float {
+	atanInv := func(n int64) *big.Float {
+		x := new(big.Float).SetPrec(prec).Quo(new(big.Float).SetPrec(prec).SetInt64(1), new(big.Float).SetPrec(prec).SetInt64(n))
+		x2 := new(big.Float).SetPrec(prec).Mul(x, x)
+		sum := new(big.Float).SetPrec(prec).Set(x)
+		power := new(big.Float).SetPrec(prec).Set(x)
+		for i := int64(3); ; i += 2 {
+			power.Mul(power, x2)
+			term := new(big.Float).SetPrec(prec).Quo(power, new(big.Float).SetPrec(prec).SetInt64(i))
+			if term.MantExp(nil) < sum.MantExp(nil)-int(prec) {
+				break
+			}
+			if (i/2)%2 == 1 {
+				sum.Sub(sum, term)
+			} else {
+				sum.Add(sum, term)
+			}
+		}
+		return sum
+	}
+
+	pi := atanInv(5)
+	pi.Mul(pi, new(big.Float).SetPrec(prec).SetInt64(16))
+	return pi.Sub(pi, atanInv(239).Mul(atanInv(239), new(big.Float).SetPrec(prec).SetInt64(4)))
+}
+
+// bigSinCos вычисляет синус и косинус числа с точностью prec бит рядами Тейлора
+// после приведения аргумента к отрезку [-pi, pi].
+func bigSinCos(x *big.Float, prec uint) (*big.Float, *big.Float) {
+	p := prec + guardBits + uint(max(0, x.MantExp(nil)))
+	twoPi := bigPi(p)
+	twoPi.Mul(twoPi, new(big.Float).SetPrec(p).SetInt64(2))
+
+	turns := new(big.Float).SetPrec(p).Quo(x, twoPi)
+	turns.Add(turns, new(big.Float).SetPrec(p).SetFloat64(0.5))
+	whole, _ := turns.Int(nil)
+	if turns.Sign() < 0 && !turns.IsInt() {
+		whole.Sub(whole, big.NewInt(1))
+	}
+	r := new(big.Float).SetPrec(p).SetInt(whole)
+	r.Sub(x, r.Mul(r, twoPi))
+	r2 := new(big.Float).SetPrec(p).Mul(r, r)
+
+	series := func(first *big.Float, start int64) *big.Float {
+		sum := new(big.Float).SetPrec(p).Set(first)
+		term := new(big.Float).SetPrec(p).Set(first)
+		for i := start; term.Sign() != 0; i += 2 {
+			term.Mul(term, r2)
+			term.Quo(term, new(big.Float).SetPrec(p).SetInt64(-i*(i+1)))
+			if term.Sign() == 0 || term.MantExp(nil) < sum.MantExp(nil)-int(p) {
+				break
+			}
+			sum.Add(sum, term)
+		}
+		return sum
+	}
+
+	return series(r, 2), series(new(big.Float).SetPrec(p).SetInt64(1), 1)
+}
+
+// decimalUnary оборачивает функцию одного аргумента без ошибок в сигнатуру Operator.Decimal.
+func decimalUnary(fn func(x *big.Float, prec uint) *big.Float) func(prec uint, args ...*big.Float) (*big.Float, error) {
+	return func(prec uint, args ...*big.Float) (*big.Float, error) {
+		return decimalResult(fn(args[0], prec+guardBits), prec)
+	}
+}
+
+// decimalLog возвращает функцию логарифма по основанию base (0 - натуральный логарифм).
+func decimalLog(base int64) func(prec uint, args ...*big.Float) (*big.Float, error) {
+	return func(prec uint, args ...*big.Float) (*big.Float, error) {
+		if args[0].Sign() <= 0 {
+			return nil, ErrNonPositiveLog
+		}
+		p := prec + guardBits
+		result := bigLn(args[0], p)
+		if base != 0 {
+			result.Quo(result, bigLn(new(big.Float).SetPrec(p).SetInt64(base), p))
+		}
+		return decimalResult(result, prec)
+	}
+}
+
+// decimalTrig возвращает тригонометрическую функцию fn (FnSin, FnCos или FnTan).
+func decimalTrig(fn string) func(prec uint, args ...*big.Float) (*big.Float, error) {
+	return decimalUnary(func(x *big.Float, prec uint) *big.Float {
+		sin, cos := bigSinCos(x, prec)
+		switch fn {
+		case FnSin:
+			return sin
+		case FnCos:
+			return cos
+		}
+		return sin.Quo(sin, cos)
+	})
+}
@@ -0,0 +1,114 @@
+package operators
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxExactExponent - наибольший по модулю показатель степени в точном режиме.
+// Ограничивает длину числителя и знаменателя результата.
+const MaxExactExponent = 1024
+
+// DecimalDigits - количество знаков после запятой в десятичной записи точного результата.
+const DecimalDigits = 20
+
+// exactPower возводит рациональное число в целую степень.
+//
+// Args:
+//
+//	args: ...*big.Rat - Основание и показатель степени.
+//
+// Returns:
+//
+//	*big.Rat - Результат возведения в степень.
+//	error - ErrExactExponent, если показатель не целый или слишком велик; ErrDivisionByZero при возведении нуля в отрицательную степень.
+func exactPower(args ...*big.Rat) (*big.Rat, error) {
+	base, exponent := args[0], args[1]
+	if !exponent.IsInt() || !exponent.Num().IsInt64() {
+		return nil, ErrExactExponent
+	}
+	n := exponent.Num().Int64()
+	if n > MaxExactExponent || n < -MaxExactExponent {
+		return nil, ErrExactExponent
+	}
+	if n < 0 {
+		if base.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		base, n = new(big.Rat).Inv(base), -n
+	}
+
+	power := big.NewInt(n)
+	num := new(big.Int).Exp(base.Num(), power, nil)
+	den := new(big.Int).Exp(base.Denom(), power, nil)
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// ParseExact разбирает точную запись рационального числа: "num/den" или целое "num".
+//
+// Args:
+//
+//	s: string - Запись числа.
+//
+// Returns:
+//
+//	*big.Rat - Число.
+//	error - Ошибка, если запись неверна или знаменатель равен нулю.
+func ParseExact(s string) (*big.Rat, error) {
+	value, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, ".eE") {
+		return nil, fmt.Errorf("неверная запись дроби: %s", s)
+	}
+	return value, nil
+}
+
+// FormatExact записывает рациональное число в виде несократимой дроби "num/den" или целого "num".
+//
+// Args:
+//
+//	value: *big.Rat - Число.
+//
+// Returns:
+//
+//	string - Запись числа.
+func FormatExact(value *big.Rat) string {
+	return value.RatString()
+}
+
+// ExactFromFloat преобразует число float64 в рациональное по его кратчайшей десятичной записи,
+// поэтому 0.1 становится дробью 1/10, а не ближайшей к 0.1 двоичной дробью.
+//
+// Args:
+//
+//	value: float64 - Конечное число.
+//
+// Returns:
+//
+//	*big.Rat - Рациональное число.
+func ExactFromFloat(value float64) *big.Rat {
+	exact, _ := new(big.Rat).SetString(strconv.FormatFloat(value, 'g', -1, 64))
+	return exact
+}
+
+// FormatDecimal записывает рациональное число десятичной дробью с DecimalDigits знаками
+// после запятой (последний знак округляется), опуская незначащие нули: 1/3 -> "0.33333333333333333333", 3/10 -> "0.3".
+//
+// Args:
+//
+//	value: *big.Rat - Число.
+//
+// Returns:
+//
+//	string - Десятичная запись числа.
+func FormatDecimal(value *big.Rat) string {
+	decimal := value.FloatString(DecimalDigits)
+	if strings.Contains(decimal, ".") {
+		decimal = strings.TrimRight(strings.TrimRight(decimal, "0"), ".")
+	}
+	if decimal == "-0" {
+		decimal = "0"
+	}
+	return decimal
+}
@@ -0,0 +1,376 @@
+package operators
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Целочисленные операторы. Над целыми аргументами они вычисляются точно (big.Int),
+// над дробными - по тем же формулам с округлением частного вниз.
+const (
+	OpMod       = "%"  // остаток от деления, знак которого совпадает со знаком делителя: -7 % 3 = 2
+	OpFloorDiv  = "//" // деление с округлением частного вниз: -7 // 2 = -4
+	OpFactorial = "!"  // факториал (постфиксный оператор): 5! = 120
+)
+
+// Целочисленные функции.
+const (
+	FnGcd     = "gcd"     // наибольший общий делитель: gcd(12, 18) = 6
+	FnLcm     = "lcm"     // наименьшее общее кратное: lcm(4, 6) = 12
+	FnIsPrime = "isprime" // простота числа: 1 для простого числа, иначе 0
+)
+
+// MaxFloatFactorial - наибольший аргумент факториала, результат которого представим в float64.
+// Факториал большего числа в режиме float равен +Inf.
+const MaxFloatFactorial = 170
+
+// MaxExactFactorial - наибольший аргумент факториала в точном и десятичном режимах.
+// Ограничивает длину результата.
+const MaxExactFactorial = 10000
+
+// primeRounds - количество раундов теста Миллера-Рабина в isprime. Для чисел меньше 2^64 тест точен.
+const primeRounds = 20
+
+// Ошибки вычисления целочисленных операций.
+var (
+	ErrFactorialDomain = errors.New("факториал определен только для неотрицательных целых чисел")
+	ErrFactorialLimit  = fmt.Errorf("в точном и десятичном режимах факториал вычисляется для чисел не больше %d", MaxExactFactorial)
+	ErrIntegerArgument = errors.New("аргументы gcd и lcm должны быть целыми числами")
+)
+
+// floatInt возвращает целое значение числа float64.
+//
+// Args:
+//
+//	value: float64 - Число.
+//
+// Returns:
+//
+//	*big.Int - Целое значение.
+//	bool - false, если число дробное или бесконечное.
+func floatInt(value float64) (*big.Int, bool) {
+	if math.IsInf(value, 0) || math.IsNaN(value) || value != math.Trunc(value) {
+		return nil, false
+	}
+	integer, _ := big.NewFloat(value).Int(nil)
+	return integer, true
+}
+
+// intFloat преобразует целое число в ближайшее число float64.
+func intFloat(value *big.Int) float64 {
+	result, _ := new(big.Float).SetInt(value).Float64()
+	return result
+}
+
+// decimalInt возвращает целое значение числа произвольной точности.
+//
+// Args:
+//
+//	value: *big.Float - Число.
+//
+// Returns:
+//
+//	*big.Int - Целое значение.
+//	bool - false, если число дробное или бесконечное.
+func decimalInt(value *big.Float) (*big.Int, bool) {
+	if value.IsInf() || !value.IsInt() {
+		return nil, false
+	}
+	integer, _ := value.Int(nil)
+	return integer, true
+}
+
+// intMod вычисляет остаток от деления целых чисел с округлением частного вниз: знак остатка совпадает
+// со знаком делителя.
+//
+// Args:
+//
+//	a: *big.Int - Делимое.
+//	b: *big.Int - Делитель.
+//
+// Returns:
+//
+//	*big.Int - Остаток.
+//	error - ErrDivisionByZero, если делитель равен нулю.
+func intMod(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	r := new(big.Int).Mod(a, b) // Остаток Евклида неотрицателен
+	if b.Sign() < 0 && r.Sign() != 0 {
+		r.Add(r, b)
+	}
+	return r, nil
+}
+
+// intFloorDiv вычисляет частное целых чисел с округлением вниз.
+//
+// Args:
+//
+//	a: *big.Int - Делимое.
+//	b: *big.Int - Делитель.
+//
+// Returns:
+//
+//	*big.Int - Частное.
+//	error - ErrDivisionByZero, если делитель равен нулю.
+func intFloorDiv(a, b *big.Int) (*big.Int, error) {
+	r, err := intMod(a, b)
+	if err != nil {
+		return nil, err
+	}
+	q := new(big.Int).Sub(a, r)
+	return q.Quo(q, b), nil
+}
+
+// intFactorial вычисляет факториал неотрицательного целого числа.
+//
+// Args:
+//
+//	n: *big.Int - Число.
+//	limit: int64 - Наибольшее допустимое число.
+//
+// Returns:
+//
+//	*big.Int - Факториал.
+//	error - ErrFactorialDomain для отрицательного числа; ErrFactorialLimit для числа больше limit.
+func intFactorial(n *big.Int, limit int64) (*big.Int, error) {
+	if n.Sign() < 0 {
+		return nil, ErrFactorialDomain
+	}
+	if !n.IsInt64() || n.Int64() > limit {
+		return nil, ErrFactorialLimit
+	}
+	return new(big.Int).MulRange(1, n.Int64()), nil
+}
+
+// intGcd вычисляет наибольший общий делитель целых чисел. Результат неотрицателен, gcd(0, 0) = 0.
+func intGcd(args ...*big.Int) *big.Int {
+	result := new(big.Int)
+	for _, arg := range args {
+		result.GCD(nil, nil, result, new(big.Int).Abs(arg))
+	}
+	return result
+}
+
+// intLcm вычисляет наименьшее общее кратное целых чисел. Результат неотрицателен и равен нулю,
+// если один из аргументов равен нулю.
+func intLcm(args ...*big.Int) *big.Int {
+	result := big.NewInt(1)
+	for _, arg := range args {
+		if arg.Sign() == 0 {
+			return new(big.Int)
+		}
+		gcd := intGcd(result, arg)
+		result.Mul(result, new(big.Int).Quo(new(big.Int).Abs(arg), gcd))
+	}
+	return result
+}
+
+// intPrime сообщает, является ли целое число простым.
+func intPrime(n *big.Int) bool {
+	return n.Cmp(big.NewInt(2)) >= 0 && n.ProbablyPrime(primeRounds)
+}
+
+// floorDivision возвращает вычислители оператора % или // в режимах float, точном и десятичном.
+// Целые аргументы делятся точно (см. intMod и intFloorDiv), дробные - с округлением частного вниз:
+// a // b = floor(a / b), a % b = a - b * (a // b).
+//
+// Args:
+//
+//	remainder: bool - true для остатка от деления (%), false для частного (//).
+//
+// Returns:
+//
+//	func(args ...float64) (float64, error) - Функция Operator.Eval.
+//	func(args ...*big.Rat) (*big.Rat, error) - Функция Operator.Exact.
+//	func(prec uint, args ...*big.Float) (*big.Float, error) - Функция Operator.Decimal.
+func floorDivision(remainder bool) (
+	func(args ...float64) (float64, error),
+	func(args ...*big.Rat) (*big.Rat, error),
+	func(prec uint, args ...*big.Float) (*big.Float, error),
+) {
+	divide := intFloorDiv
+	if remainder {
+		divide = intMod
+	}
+
+	eval := func(args ...float64) (float64, error) {
+		a, b := args[0], args[1]
+		if b == 0 {
+			return 0, ErrDivisionByZero
+		}
+		x, integral := floatInt(a)
+		y, integralDivisor := floatInt(b)
+		if integral && integralDivisor {
+			result, err := divide(x, y)
+			if err != nil {
+				return 0, err
+			}
+			return intFloat(result), nil
+		}
+
+		r := math.Mod(a, b)
+		if r != 0 && (r < 0) != (b < 0) {
+			r += b
+		}
+		if remainder {
+			return r, nil
+		}
+		return math.Round((a - r) / b), nil
+	}
+	exact := func(args ...*big.Rat) (*big.Rat, error) {
+		a, b := args[0], args[1]
+		if b.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		// Знаменатель дроби положителен, поэтому деление Евклида числителя на знаменатель округляет вниз
+		quotient := new(big.Rat).Quo(a, b)
+		floor := new(big.Int).Div(quotient.Num(), quotient.Denom())
+		if !remainder {
+			return new(big.Rat).SetInt(floor), nil
+		}
+		product := new(big.Rat).Mul(b, new(big.Rat).SetInt(floor))
+		return product.Sub(a, product), nil
+	}
+	decimal := func(prec uint, args ...*big.Float) (*big.Float, error) {
+		a, b := args[0], args[1]
+		if b.Sign() == 0 {
+			return nil, ErrDivisionByZero
+		}
+		x, integral := decimalInt(a)
+		y, integralDivisor := decimalInt(b)
+		if integral && integralDivisor {
+			result, err := divide(x, y)
+			if err != nil {
+				return nil, err
+			}
+			return decimalResult(new(big.Float).SetPrec(prec).SetInt(result), prec)
+		}
+
+		quotient := new(big.Float).SetPrec(prec).Quo(a, b)
+		floor, _ := quotient.Int(nil) // Округление к нулю
+		if quotient.Sign() < 0 && !quotient.IsInt() {
+			floor.Sub(floor, big.NewInt(1))
+		}
+		floorValue := new(big.Float).SetPrec(prec).SetInt(floor)
+		if !remainder {
+			return decimalResult(floorValue, prec)
+		}
+		product := new(big.Float).SetPrec(prec).Mul(b, floorValue)
+		return decimalResult(product.Sub(a, product), prec)
+	}
+	return eval, exact, decimal
+}
+
+// factorialEval вычисляет факториал в режиме float. Факториал числа больше MaxFloatFactorial равен +Inf.
+func factorialEval(args ...float64) (float64, error) {
+	n, ok := floatInt(args[0])
+	if !ok || n.Sign() < 0 {
+		return 0, ErrFactorialDomain
+	}
+	if args[0] > MaxFloatFactorial {
+		return math.Inf(1), nil
+	}
+	result, err := intFactorial(n, MaxFloatFactorial)
+	if err != nil {
+		return 0, err
+	}
+	return intFloat(result), nil
+}
+
+// factorialExact вычисляет факториал в точном режиме.
+func factorialExact(args ...*big.Rat) (*big.Rat, error) {
+	if !args[0].IsInt() {
+		return nil, ErrFactorialDomain
+	}
+	result, err := intFactorial(args[0].Num(), MaxExactFactorial)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt(result), nil
+}
+
+// factorialDecimal вычисляет факториал в десятичном режиме с точностью prec бит.
+func factorialDecimal(prec uint, args ...*big.Float) (*big.Float, error) {
+	n, ok := decimalInt(args[0])
+	if !ok {
+		return nil, ErrFactorialDomain
+	}
+	result, err := intFactorial(n, MaxExactFactorial)
+	if err != nil {
+		return nil, err
+	}
+	return decimalResult(new(big.Float).SetPrec(prec).SetInt(result), prec)
+}
+
+// integerFunction возвращает вычислители функции над целыми аргументами в режимах float, точном и десятичном.
+//
+// Args:
+//
+//	fn: func(args ...*big.Int) *big.Int - Функция над целыми числами.
+//
+// Returns:
+//
+//	func(args ...float64) (float64, error) - Функция Operator.Eval.
+//	func(args ...*big.Rat) (*big.Rat, error) - Функция Operator.Exact.
+//	func(prec uint, args ...*big.Float) (*big.Float, error) - Функция Operator.Decimal.
+//	Вычислители возвращают ErrIntegerArgument, если один из аргументов дробный.
+func integerFunction(fn func(args ...*big.Int) *big.Int) (
+	func(args ...float64) (float64, error),
+	func(args ...*big.Rat) (*big.Rat, error),
+	func(prec uint, args ...*big.Float) (*big.Float, error),
+) {
+	eval := func(args ...float64) (float64, error) {
+		integers := make([]*big.Int, len(args))
+		for i, arg := range args {
+			integer, ok := floatInt(arg)
+			if !ok {
+				return 0, ErrIntegerArgument
+			}
+			integers[i] = integer
+		}
+		return intFloat(fn(integers...)), nil
+	}
+	exact := func(args ...*big.Rat) (*big.Rat, error) {
+		integers := make([]*big.Int, len(args))
+		for i, arg := range args {
+			if !arg.IsInt() {
+				return nil, ErrIntegerArgument
+			}
+			integers[i] = arg.Num()
+		}
+		return new(big.Rat).SetInt(fn(integers...)), nil
+	}
+	decimal := func(prec uint, args ...*big.Float) (*big.Float, error) {
+		integers := make([]*big.Int, len(args))
+		for i, arg := range args {
+			integer, ok := decimalInt(arg)
+			if !ok {
+				return nil, ErrIntegerArgument
+			}
+			integers[i] = integer
+		}
+		return decimalResult(new(big.Float).SetPrec(prec).SetInt(fn(integers...)), prec)
+	}
+	return eval, exact, decimal
+}
+
+// isPrimeEval проверяет простоту числа в режиме float. Дробные числа и числа меньше 2 не являются простыми.
+func isPrimeEval(args ...float64) (float64, error) {
+	n, ok := floatInt(args[0])
+	return boolean(ok && intPrime(n)), nil
+}
+
+// isPrimeExact проверяет простоту числа в точном режиме.
+func isPrimeExact(args ...*big.Rat) (*big.Rat, error) {
+	return ratBoolean(args[0].IsInt() && intPrime(args[0].Num())), nil
+}
+
+// isPrimeDecimal проверяет простоту числа в десятичном режиме.
+func isPrimeDecimal(prec uint, args ...*big.Float) (*big.Float, error) {
+	n, ok := decimalInt(args[0])
+	return floatBoolean(ok && intPrime(n), prec), nil
+}
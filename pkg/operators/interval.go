@@ -0,0 +1,269 @@
+package operators
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// OpPlusMinus - оператор погрешности: 3±0.1 - интервал [2.9, 3.1]. Доступен только в интервальном режиме.
+const OpPlusMinus = "±"
+
+// Ошибки интервальных вычислений.
+var (
+	ErrNegativeError = errors.New("погрешность не может быть отрицательной")
+	ErrTangentPole   = errors.New("интервал содержит полюс тангенса")
+)
+
+// Interval представляет замкнутый числовой интервал [Lo, Hi], гарантированно содержащий точное значение.
+type Interval struct {
+	Lo float64 // Нижняя граница
+	Hi float64 // Верхняя граница
+}
+
+// Degenerate возвращает вырожденный интервал [value, value].
+func Degenerate(value float64) Interval {
+	return Interval{Lo: value, Hi: value}
+}
+
+// LiteralInterval возвращает интервал, содержащий число с десятичной записью literal. Если запись точно
+// представима в float64 (3, 0.5), интервал вырожденный, иначе он расширяется на единицу последнего разряда
+// в сторону точного значения: 0.1 в float64 больше 0.1, поэтому интервал 0.1 - [0.1 - ulp, 0.1].
+//
+// Args:
+//
+//	value: float64 - Ближайшее к записи число float64.
+//	literal: string - Десятичная запись числа ("0.1", "1e-3"). Если запись неверна, число считается точным.
+//
+// Returns:
+//
+//	Interval - Интервал, содержащий точное значение записи.
+func LiteralInterval(value float64, literal string) Interval {
+	exact, ok := new(big.Rat).SetString(literal)
+	if !ok || math.IsInf(value, 0) {
+		return Degenerate(value)
+	}
+	switch new(big.Rat).SetFloat64(value).Cmp(exact) {
+	case 1:
+		return Interval{Lo: math.Nextafter(value, math.Inf(-1)), Hi: value}
+	case -1:
+		return Interval{Lo: value, Hi: math.Nextafter(value, math.Inf(1))}
+	}
+	return Degenerate(value)
+}
+
+// Mid возвращает середину интервала.
+func (iv Interval) Mid() float64 {
+	return iv.Lo + (iv.Hi-iv.Lo)/2
+}
+
+// outward расширяет интервал на одну единицу последнего разряда в каждую сторону, чтобы он содержал
+// точный результат несмотря на округление границ при вычислении в float64.
+//
+// Args:
+//
+//	lo: float64 - Вычисленная нижняя граница.
+//	hi: float64 - Вычисленная верхняя граница.
+//
+// Returns:
+//
+//	Interval - Расширенный интервал.
+func outward(lo, hi float64) Interval {
+	return Interval{Lo: math.Nextafter(lo, math.Inf(-1)), Hi: math.Nextafter(hi, math.Inf(1))}
+}
+
+// hull возвращает наименьший интервал, содержащий все значения, с расширением наружу.
+func hull(values ...float64) Interval {
+	lo, hi := values[0], values[0]
+	for _, value := range values[1:] {
+		lo, hi = math.Min(lo, value), math.Max(hi, value)
+	}
+	return outward(lo, hi)
+}
+
+// contains проверяет, содержит ли интервал точку offset + k*period для некоторого целого k.
+func (iv Interval) contains(offset, period float64) bool {
+	k := math.Ceil((iv.Lo - offset) / period)
+	return offset+k*period <= iv.Hi
+}
+
+// intervalMonotone оборачивает возрастающую функцию одного аргумента в сигнатуру Operator.Interval.
+func intervalMonotone(fn func(float64) float64) func(args ...Interval) (Interval, error) {
+	return func(args ...Interval) (Interval, error) {
+		return outward(fn(args[0].Lo), fn(args[0].Hi)), nil
+	}
+}
+
+// intervalMultiply умножает интервалы: результат ограничен произведениями их границ.
+func intervalMultiply(args ...Interval) (Interval, error) {
+	a, b := args[0], args[1]
+	return hull(a.Lo*b.Lo, a.Lo*b.Hi, a.Hi*b.Lo, a.Hi*b.Hi), nil
+}
+
+// intervalDivide делит интервалы.
+//
+// Args:
+//
+//	args: ...Interval - Делимое и делитель.
+//
+// Returns:
+//
+//	Interval - Частное.
+//	error - ErrDivisionByZero, если делитель содержит ноль.
+func intervalDivide(args ...Interval) (Interval, error) {
+	a, b := args[0], args[1]
+	if b.Lo <= 0 && b.Hi >= 0 {
+		return Interval{}, ErrDivisionByZero
+	}
+	return hull(a.Lo/b.Lo, a.Lo/b.Hi, a.Hi/b.Lo, a.Hi/b.Hi), nil
+}
+
+// intervalPower возводит интервал в степень. Целая степень (вырожденный целый показатель) допускает
+// отрицательное основание, остальные показатели - только неотрицательное. При положительном основании
+// x^y монотонна по каждому аргументу, поэтому границы результата достигаются в углах.
+//
+// Args:
+//
+//	args: ...Interval - Основание и показатель степени.
+//
+// Returns:
+//
+//	Interval - Результат возведения в степень.
+//	error - ErrNegativeBase при отрицательном основании и нецелом показателе;
+//	        ErrDivisionByZero при возведении интервала, содержащего ноль, в отрицательную степень.
+func intervalPower(args ...Interval) (Interval, error) {
+	base, exponent := args[0], args[1]
+	if exponent.Lo == exponent.Hi && exponent.Lo == math.Trunc(exponent.Lo) {
+		n := exponent.Lo
+		switch {
+		case n == 0:
+			return Degenerate(1), nil
+		case n < 0:
+			power, err := intervalPower(base, Degenerate(-n))
+			if err != nil {
+				return Interval{}, err
+			}
+			return intervalDivide(Degenerate(1), power)
+		case math.Mod(n, 2) == 1 || base.Lo >= 0:
+			// Нечетная степень и степень неотрицательного основания возрастают
+			return outward(math.Pow(base.Lo, n), math.Pow(base.Hi, n)), nil
+		case base.Hi <= 0:
+			// Четная степень неположительного основания убывает
+			return outward(math.Pow(base.Hi, n), math.Pow(base.Lo, n)), nil
+		default:
+			// Четная степень интервала, содержащего ноль
+			return Interval{Lo: 0, Hi: math.Nextafter(math.Max(math.Pow(base.Lo, n), math.Pow(base.Hi, n)), math.Inf(1))}, nil
+		}
+	}
+
+	if base.Lo < 0 {
+		return Interval{}, ErrNegativeBase
+	}
+	if base.Lo == 0 && exponent.Lo < 0 {
+		return Interval{}, ErrDivisionByZero
+	}
+	return hull(
+		math.Pow(base.Lo, exponent.Lo), math.Pow(base.Lo, exponent.Hi),
+		math.Pow(base.Hi, exponent.Lo), math.Pow(base.Hi, exponent.Hi),
+	), nil
+}
+
+// intervalTrig возвращает функцию синуса или косинуса интервала. Если интервал содержит точку максимума
+// (минимума) функции, верхняя (нижняя) граница результата равна 1 (-1).
+//
+// Args:
+//
+//	fn: string - FnSin или FnCos.
+//
+// Returns:
+//
+//	func(args ...Interval) (Interval, error) - Функция интервала.
+func intervalTrig(fn string) func(args ...Interval) (Interval, error) {
+	eval, peak := math.Sin, math.Pi/2 // Максимумы синуса: π/2 + 2kπ
+	if fn == FnCos {
+		eval, peak = math.Cos, 0 // Максимумы косинуса: 2kπ
+	}
+	return func(args ...Interval) (Interval, error) {
+		iv := args[0]
+		if iv.Hi-iv.Lo >= 2*math.Pi {
+			return Interval{Lo: -1, Hi: 1}, nil
+		}
+		result := hull(eval(iv.Lo), eval(iv.Hi))
+		if iv.contains(peak, 2*math.Pi) {
+			result.Hi = 1
+		}
+		if iv.contains(peak+math.Pi, 2*math.Pi) {
+			result.Lo = -1
+		}
+		result.Lo, result.Hi = math.Max(result.Lo, -1), math.Min(result.Hi, 1)
+		return result, nil
+	}
+}
+
+// intervalTan вычисляет тангенс интервала, не содержащего полюсов π/2 + kπ.
+//
+// Args:
+//
+//	args: ...Interval - Аргумент функции.
+//
+// Returns:
+//
+//	Interval - Тангенс интервала.
+//	error - ErrTangentPole, если интервал содержит полюс тангенса.
+func intervalTan(args ...Interval) (Interval, error) {
+	iv := args[0]
+	if iv.Hi-iv.Lo >= math.Pi || iv.contains(math.Pi/2, math.Pi) {
+		return Interval{}, ErrTangentPole
+	}
+	return outward(math.Tan(iv.Lo), math.Tan(iv.Hi)), nil
+}
+
+// intervalAbs вычисляет модуль интервала.
+func intervalAbs(args ...Interval) (Interval, error) {
+	iv := args[0]
+	switch {
+	case iv.Lo >= 0:
+		return iv, nil
+	case iv.Hi <= 0:
+		return Interval{Lo: -iv.Hi, Hi: -iv.Lo}, nil
+	default:
+		return Interval{Lo: 0, Hi: math.Max(-iv.Lo, iv.Hi)}, nil
+	}
+}
+
+// intervalPlusMinus строит интервал по значению и погрешности: a±r = [a.Lo - r.Hi, a.Hi + r.Hi].
+//
+// Args:
+//
+//	args: ...Interval - Значение и погрешность.
+//
+// Returns:
+//
+//	Interval - Интервал значения.
+//	error - ErrNegativeError, если погрешность может быть отрицательной.
+func intervalPlusMinus(args ...Interval) (Interval, error) {
+	value, radius := args[0], args[1]
+	if radius.Lo < 0 {
+		return Interval{}, ErrNegativeError
+	}
+	return outward(value.Lo-radius.Hi, value.Hi+radius.Hi), nil
+}
+
+// intervalLog возвращает логарифм интервала положительных чисел.
+//
+// Args:
+//
+//	fn: func(float64) float64 - Возрастающая функция логарифма (math.Log или math.Log10).
+//
+// Returns:
+//
+//	func(args ...Interval) (Interval, error) - Функция интервала, возвращающая ErrNonPositiveLog,
+//	если интервал содержит неположительные числа.
+func intervalLog(fn func(float64) float64) func(args ...Interval) (Interval, error) {
+	return func(args ...Interval) (Interval, error) {
+		if args[0].Lo <= 0 {
+			return Interval{}, ErrNonPositiveLog
+		}
+		return outward(fn(args[0].Lo), fn(args[0].Hi)), nil
+	}
+}
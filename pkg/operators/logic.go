@@ -0,0 +1,160 @@
+package operators
+
+import (
+	"math/big"
+
+	"github.com/OinkiePie/calc_3/pkg/units"
+)
+
+// Операторы сравнения и логические операторы. Результат - 1 (истина) или 0 (ложь),
+// а любое ненулевое число считается истинным.
+const (
+	OpLess         = "<"  // меньше
+	OpLessEqual    = "<=" // меньше или равно
+	OpGreater      = ">"  // больше
+	OpGreaterEqual = ">=" // больше или равно
+	OpEqual        = "==" // равно
+	OpNotEqual     = "!=" // не равно
+	OpAnd          = "&&" // логическое И
+	OpOr           = "||" // логическое ИЛИ
+)
+
+// Логические функции.
+const (
+	FnNot = "not" // логическое НЕ: not(x)
+	FnIf  = "if"  // выбор значения по условию: if(условие, значение при истине, значение при лжи)
+)
+
+// boolean возвращает числовое значение логического результата: 1 для истины и 0 для лжи.
+func boolean(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// ratBoolean возвращает рациональное значение логического результата.
+func ratBoolean(value bool) *big.Rat {
+	return new(big.Rat).SetFloat64(boolean(value))
+}
+
+// floatBoolean возвращает значение логического результата произвольной точности.
+func floatBoolean(value bool, prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec).SetFloat64(boolean(value))
+}
+
+// comparison возвращает вычислители оператора сравнения в режимах float, точном и десятичном.
+// holds получает результат сравнения первого аргумента со вторым (-1, 0 или +1) и сообщает, выполняется ли сравнение.
+//
+// Args:
+//
+//	holds: func(sign int) bool - Условие на результат сравнения первого аргумента со вторым.
+//
+// Returns:
+//
+//	func(args ...float64) (float64, error) - Функция Operator.Eval.
+//	func(args ...*big.Rat) (*big.Rat, error) - Функция Operator.Exact.
+//	func(prec uint, args ...*big.Float) (*big.Float, error) - Функция Operator.Decimal.
+func comparison(holds func(sign int) bool) (
+	func(args ...float64) (float64, error),
+	func(args ...*big.Rat) (*big.Rat, error),
+	func(prec uint, args ...*big.Float) (*big.Float, error),
+) {
+	eval := func(args ...float64) (float64, error) {
+		sign := 0
+		switch {
+		case args[0] < args[1]:
+			sign = -1
+		case args[0] > args[1]:
+			sign = 1
+		}
+		return boolean(holds(sign)), nil
+	}
+	exact := func(args ...*big.Rat) (*big.Rat, error) {
+		return ratBoolean(holds(args[0].Cmp(args[1]))), nil
+	}
+	decimal := func(prec uint, args ...*big.Float) (*big.Float, error) {
+		return floatBoolean(holds(args[0].Cmp(args[1])), prec), nil
+	}
+	return eval, exact, decimal
+}
+
+// registerComparison регистрирует оператор сравнения.
+//
+// Args:
+//
+//	symbol: string - Идентификатор оператора.
+//	precedence: int - Приоритет оператора.
+//	commutative: bool - Признак коммутативности (== и !=).
+//	holds: func(sign int) bool - Условие на результат сравнения аргументов (см. comparison).
+func registerComparison(symbol string, precedence int, commutative bool, holds func(sign int) bool) {
+	eval, exact, decimal := comparison(holds)
+	Register(&Operator{
+		Symbol: symbol, Arity: 2, Precedence: precedence, Commutative: commutative, TimeKey: "TIME_COMPARISON_MS", Dimension: units.Compare,
+		Eval: eval, Exact: exact, Decimal: decimal,
+	})
+}
+
+// logical возвращает вычислители логического оператора в режимах float, точном и десятичном.
+//
+// Args:
+//
+//	combine: func(truth ...bool) bool - Логическая функция над истинностью аргументов.
+//
+// Returns:
+//
+//	func(args ...float64) (float64, error) - Функция Operator.Eval.
+//	func(args ...*big.Rat) (*big.Rat, error) - Функция Operator.Exact.
+//	func(prec uint, args ...*big.Float) (*big.Float, error) - Функция Operator.Decimal.
+func logical(combine func(truth ...bool) bool) (
+	func(args ...float64) (float64, error),
+	func(args ...*big.Rat) (*big.Rat, error),
+	func(prec uint, args ...*big.Float) (*big.Float, error),
+) {
+	eval := func(args ...float64) (float64, error) {
+		truth := make([]bool, len(args))
+		for i, arg := range args {
+			truth[i] = arg != 0
+		}
+		return boolean(combine(truth...)), nil
+	}
+	exact := func(args ...*big.Rat) (*big.Rat, error) {
+		truth := make([]bool, len(args))
+		for i, arg := range args {
+			truth[i] = arg.Sign() != 0
+		}
+		return ratBoolean(combine(truth...)), nil
+	}
+	decimal := func(prec uint, args ...*big.Float) (*big.Float, error) {
+		truth := make([]bool, len(args))
+		for i, arg := range args {
+			truth[i] = arg.Sign() != 0
+		}
+		return floatBoolean(combine(truth...), prec), nil
+	}
+	return eval, exact, decimal
+}
+
+// ifEval выбирает второй аргумент при истинном первом и третий - при ложном.
+func ifEval(args ...float64) (float64, error) {
+	if args[0] != 0 {
+		return args[1], nil
+	}
+	return args[2], nil
+}
+
+// ifExact выбирает значение по условию в точном режиме.
+func ifExact(args ...*big.Rat) (*big.Rat, error) {
+	if args[0].Sign() != 0 {
+		return args[1], nil
+	}
+	return args[2], nil
+}
+
+// ifDecimal выбирает значение по условию в десятичном режиме.
+func ifDecimal(_ uint, args ...*big.Float) (*big.Float, error) {
+	if args[0].Sign() != 0 {
+		return args[1], nil
+	}
+	return args[2], nil
+}
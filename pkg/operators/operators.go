@@ -1,5 +1,13 @@
 package operators
 
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/OinkiePie/calc_3/pkg/units"
+)
+
 // Математические операторы.
 // Используются оркестратором и агентом.
 const (
@@ -13,3 +21,224 @@ const (
 	ParenLeft    = "("
 	ParenRight   = ")"
 )
+
+// Встроенные математические функции.
+// Каждая функция принимает ровно один аргумент и вычисляется агентом как отдельная задача.
+// Функции с переменным числом аргументов (sum, max, ...) описаны в aggregate.go.
+const (
+	FnSin  = "sin"  // синус (радианы)
+	FnCos  = "cos"  // косинус (радианы)
+	FnTan  = "tan"  // тангенс (радианы)
+	FnSqrt = "sqrt" // квадратный корень
+	FnLn   = "ln"   // натуральный логарифм
+	FnLog  = "log"  // десятичный логарифм
+	FnAbs  = "abs"  // модуль числа
+	FnExp  = "exp"  // экспонента
+)
+
+// Operator описывает операцию, которую умеют разбирать оркестратор и вычислять агенты.
+//
+// Все слои приложения (разбиение выражения на задачи, вычисление на агенте,
+// ограничения схемы базы данных) читают сведения об операциях только из реестра,
+// поэтому добавление новой операции сводится к вызову Register.
+type Operator struct {
+	// Symbol - Идентификатор операции. Совпадает с токеном выражения (кроме унарного минуса)
+	// и сохраняется в колонке tasks.operation.
+	Symbol string
+	// Arity - Количество аргументов операции. Для операции с переменным числом аргументов - наименьшее количество.
+	Arity int
+	// Variadic - Признак функции с переменным числом аргументов (sum, max, ...): вызов может передать
+	// любое количество аргументов, не меньшее Arity.
+	Variadic bool
+	// Precedence - Приоритет оператора. Чем больше число, тем выше приоритет. Для функций не используется.
+	Precedence int
+	// RightAssoc - Признак правой ассоциативности оператора.
+	RightAssoc bool
+	// Postfix - Признак постфиксного оператора одного аргумента, записываемого после операнда: 5!.
+	// Постфиксный оператор должен иметь наибольший приоритет.
+	Postfix bool
+	// Associative - Признак ассоциативности операции: (a op b) op c = a op (b op c).
+	// Цепочки таких операций могут перестраиваться в сбалансированные деревья.
+	// Для функции с переменным числом аргументов - f(a, b, c, d) = f(f(a, b), f(c, d)): вызов с большим
+	// количеством аргументов может вычисляться деревом задач над группами аргументов.
+	Associative bool
+	// Commutative - Признак коммутативности операции: a op b = b op a. Для функции с переменным числом
+	// аргументов - независимость результата от порядка аргументов.
+	// Операнды таких операций упорядочиваются при построении канонической записи выражения.
+	Commutative bool
+	// Function - Признак того, что операция записывается как вызов функции: name(...).
+	Function bool
+	// Eval - Вычисляет результат операции над аргументами. nil для функций случайных чисел (см. Random).
+	Eval func(args ...float64) (float64, error)
+	// Random - Вычисляет результат функции случайных чисел над аргументами по зерну задачи (см. TaskSeed).
+	// Такие операции недетерминированы: их задачи не объединяются как одинаковые подвыражения
+	// и не вычисляются при разборе. nil для остальных операций.
+	Random func(seed int64, args ...float64) (float64, error)
+	// Exact - Вычисляет точный результат операции над рациональными аргументами.
+	// nil, если результат операции в общем случае иррационален (корень, логарифм, тригонометрия):
+	// такие операции недоступны в точном режиме вычислений.
+	Exact func(args ...*big.Rat) (*big.Rat, error)
+	// Decimal - Вычисляет результат операции над числами произвольной точности с точностью prec бит.
+	// nil, если операция недоступна в десятичном режиме вычислений.
+	Decimal func(prec uint, args ...*big.Float) (*big.Float, error)
+	// Complex - Вычисляет результат операции над комплексными аргументами.
+	// nil, если операция недоступна в режиме комплексных вычислений.
+	Complex func(args ...complex128) (complex128, error)
+	// Interval - Вычисляет интервал, гарантированно содержащий результат операции над любыми значениями
+	// из интервалов аргументов. nil, если операция недоступна в интервальном режиме вычислений.
+	Interval func(args ...Interval) (Interval, error)
+	// Dimension - Вычисляет размерность результата по размерностям аргументов при проверке единиц измерения.
+	// nil - операция допускает только безразмерные аргументы и возвращает безразмерный результат (units.Dimensionless).
+	Dimension units.Rule
+	// TimeKey - Ключ параметра конфигурации (секция math), задающего время выполнения операции.
+	TimeKey string
+}
+
+var (
+	registry = map[string]*Operator{} // Зарегистрированные операции по идентификатору
+	order    []string                 // Идентификаторы в порядке регистрации
+)
+
+// Register добавляет операцию в реестр.
+// Повторная регистрация операции с тем же идентификатором приводит к панике,
+// так как реестр заполняется только при инициализации пакетов.
+//
+// Args:
+//
+//	op: *Operator - Описание операции.
+func Register(op *Operator) {
+	if op.Symbol == "" || (op.Eval == nil) == (op.Random == nil) {
+		panic("operators: операция должна иметь идентификатор и одну функцию вычисления (Eval или Random)")
+	}
+	if _, exists := registry[op.Symbol]; exists {
+		panic(fmt.Sprintf("operators: операция %q уже зарегистрирована", op.Symbol))
+	}
+	registry[op.Symbol] = op
+	order = append(order, op.Symbol)
+}
+
+// Lookup возвращает описание операции по ее идентификатору.
+//
+// Args:
+//
+//	symbol: string - Идентификатор операции.
+//
+// Returns:
+//
+//	*Operator - Описание операции.
+//	bool - true, если операция зарегистрирована.
+func Lookup(symbol string) (*Operator, bool) {
+	op, ok := registry[symbol]
+	return op, ok
+}
+
+// All возвращает все зарегистрированные операции в порядке регистрации.
+//
+// Returns:
+//
+//	[]*Operator - Срез описаний операций.
+func All() []*Operator {
+	ops := make([]*Operator, 0, len(order))
+	for _, symbol := range order {
+		ops = append(ops, registry[symbol])
+	}
+	return ops
+}
+
+// Symbols возвращает отсортированный список идентификаторов всех операций.
+//
+// Returns:
+//
+//	[]string - Идентификаторы операций.
+func Symbols() []string {
+	symbols := append([]string(nil), order...)
+	sort.Strings(symbols)
+	return symbols
+}
+
+// IsFunction проверяет, является ли токен именем зарегистрированной функции.
+//
+// Args:
+//
+//	token: string - Строка, которую необходимо проверить.
+//
+// Returns:
+//
+//	bool - true, если токен является именем функции, иначе false.
+func IsFunction(token string) bool {
+	op, ok := registry[token]
+	return ok && op.Function
+}
+
+// IsBinary проверяет, является ли токен бинарным инфиксным оператором (+, -, *, /, ^, ±).
+//
+// Args:
+//
+//	token: string - Строка, которую необходимо проверить.
+//
+// Returns:
+//
+//	bool - true, если токен является бинарным оператором, иначе false.
+func IsBinary(token string) bool {
+	op, ok := registry[token]
+	return ok && !op.Function && op.Arity == 2
+}
+
+// IsPostfix проверяет, является ли токен постфиксным оператором (!).
+//
+// Args:
+//
+//	token: string - Строка, которую необходимо проверить.
+//
+// Returns:
+//
+//	bool - true, если токен является постфиксным оператором, иначе false.
+func IsPostfix(token string) bool {
+	op, ok := registry[token]
+	return ok && op.Postfix
+}
+
+// Arity возвращает количество аргументов операции или 0, если операция не зарегистрирована.
+//
+// Args:
+//
+//	symbol: string - Идентификатор операции.
+//
+// Returns:
+//
+//	int - Количество аргументов.
+func Arity(symbol string) int {
+	if op, ok := registry[symbol]; ok {
+		return op.Arity
+	}
+	return 0
+}
+
+// ArgCount возвращает количество аргументов, которые принимает вызов операции.
+//
+// Args:
+//
+//	given: int - Количество переданных аргументов.
+//
+// Returns:
+//
+//	int - given для функции с переменным числом аргументов, но не меньше Arity; иначе Arity.
+func (op *Operator) ArgCount(given int) int {
+	if op.Variadic && given > op.Arity {
+		return given
+	}
+	return op.Arity
+}
+
+// IsUnary проверяет, принимает ли операция ровно один аргумент (унарный минус или функция).
+//
+// Args:
+//
+//	op: string - Идентификатор операции.
+//
+// Returns:
+//
+//	bool - true, если у операции один аргумент, иначе false.
+func IsUnary(op string) bool {
+	return Arity(op) == 1
+}
@@ -0,0 +1,784 @@
+package operators_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/OinkiePie/calc_3/pkg/operators"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("builtin operators are registered", func(t *testing.T) {
+		for _, symbol := range []string{
+			operators.OpAdd, operators.OpSubtract, operators.OpMultiply, operators.OpDivide,
+			operators.OpPower, operators.OpUnaryMinus, operators.FnSqrt, operators.FnSin,
+		} {
+			op, ok := operators.Lookup(symbol)
+			assert.True(t, ok, "operator %s should be registered", symbol)
+			assert.NotEmpty(t, op.TimeKey)
+		}
+	})
+
+	t.Run("arity and kind", func(t *testing.T) {
+		assert.Equal(t, 2, operators.Arity(operators.OpPower))
+		assert.Equal(t, 1, operators.Arity(operators.OpUnaryMinus))
+		assert.Equal(t, 0, operators.Arity("unknown"))
+		assert.True(t, operators.IsBinary(operators.OpDivide))
+		assert.False(t, operators.IsBinary(operators.OpUnaryMinus))
+		assert.True(t, operators.IsFunction(operators.FnLn))
+		assert.False(t, operators.IsFunction(operators.OpAdd))
+		assert.True(t, operators.IsUnary(operators.FnAbs))
+	})
+
+	t.Run("evaluators", func(t *testing.T) {
+		op, _ := operators.Lookup(operators.OpDivide)
+		_, err := op.Eval(1, 0)
+		assert.ErrorIs(t, err, operators.ErrDivisionByZero)
+
+		op, _ = operators.Lookup(operators.FnSqrt)
+		result, err := op.Eval(9)
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, result)
+	})
+
+	t.Run("argument count", func(t *testing.T) {
+		op, _ := operators.Lookup(operators.FnMax)
+		assert.True(t, op.Variadic)
+		assert.Equal(t, 5, op.ArgCount(5))
+		assert.Equal(t, op.Arity, op.ArgCount(0))
+
+		op, _ = operators.Lookup(operators.OpAdd)
+		assert.Equal(t, 2, op.ArgCount(5))
+	})
+
+	t.Run("register", func(t *testing.T) {
+		operators.Register(&operators.Operator{
+			Symbol: "test_double", Arity: 1, Function: true, TimeKey: "TIME_TEST_MS",
+			Eval: func(args ...float64) (float64, error) { return args[0] * 2, nil },
+		})
+		assert.True(t, operators.IsFunction("test_double"))
+		assert.Contains(t, operators.Symbols(), "test_double")
+
+		assert.Panics(t, func() {
+			operators.Register(&operators.Operator{
+				Symbol: operators.OpAdd, Arity: 2,
+				Eval: func(args ...float64) (float64, error) { return 0, nil },
+			})
+		})
+		assert.Panics(t, func() {
+			operators.Register(&operators.Operator{Symbol: "test_none", Arity: 1, Function: true})
+		})
+	})
+}
+
+func TestExact(t *testing.T) {
+	rat := func(s string) *big.Rat {
+		value, err := operators.ParseExact(s)
+		require.NoError(t, err)
+		return value
+	}
+
+	t.Run("evaluators", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			symbol   string
+			args     []string
+			expected string
+			err      error
+		}{
+			{"add", operators.OpAdd, []string{"1/10", "1/5"}, "3/10", nil},
+			{"subtract", operators.OpSubtract, []string{"1/2", "1/3"}, "1/6", nil},
+			{"multiply", operators.OpMultiply, []string{"2/3", "3/4"}, "1/2", nil},
+			{"divide", operators.OpDivide, []string{"1", "3"}, "1/3", nil},
+			{"divide by zero", operators.OpDivide, []string{"1", "0"}, "", operators.ErrDivisionByZero},
+			{"power", operators.OpPower, []string{"2/3", "3"}, "8/27", nil},
+			{"negative power", operators.OpPower, []string{"2", "-2"}, "1/4", nil},
+			{"zero to negative power", operators.OpPower, []string{"0", "-1"}, "", operators.ErrDivisionByZero},
+			{"fractional power", operators.OpPower, []string{"4", "1/2"}, "", operators.ErrExactExponent},
+			{"huge power", operators.OpPower, []string{"2", "100000"}, "", operators.ErrExactExponent},
+			{"unary minus", operators.OpUnaryMinus, []string{"1/3"}, "-1/3", nil},
+			{"abs", operators.FnAbs, []string{"-5/2"}, "5/2", nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				op, ok := operators.Lookup(tt.symbol)
+				require.True(t, ok)
+				require.NotNil(t, op.Exact)
+
+				args := make([]*big.Rat, len(tt.args))
+				for i, arg := range tt.args {
+					args[i] = rat(arg)
+				}
+				result, err := op.Exact(args...)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, operators.FormatExact(result))
+			})
+		}
+	})
+
+	t.Run("transcendental functions have no exact evaluator", func(t *testing.T) {
+		for _, symbol := range []string{operators.FnSqrt, operators.FnSin, operators.FnLn} {
+			op, _ := operators.Lookup(symbol)
+			assert.Nil(t, op.Exact, symbol)
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		assert.Equal(t, "-7/2", operators.FormatExact(rat("-14/4")))
+		for _, s := range []string{"0.5", "1e3", "1/0", "x"} {
+			_, err := operators.ParseExact(s)
+			assert.Error(t, err, s)
+		}
+	})
+
+	t.Run("from float", func(t *testing.T) {
+		assert.Equal(t, "1/10", operators.FormatExact(operators.ExactFromFloat(0.1)))
+		assert.Equal(t, "-5/4", operators.FormatExact(operators.ExactFromFloat(-1.25)))
+		assert.Equal(t, "1000000000000000000000", operators.FormatExact(operators.ExactFromFloat(1e21)))
+	})
+
+	t.Run("decimal", func(t *testing.T) {
+		assert.Equal(t, "0.3", operators.FormatDecimal(rat("3/10")))
+		assert.Equal(t, "0.33333333333333333333", operators.FormatDecimal(rat("1/3")))
+		assert.Equal(t, "-0.66666666666666666667", operators.FormatDecimal(rat("-2/3")))
+		assert.Equal(t, "42", operators.FormatDecimal(rat("42")))
+		assert.Equal(t, "0", operators.FormatDecimal(rat("-1/1000000000000000000000000")))
+	})
+}
+
+func TestDecimal(t *testing.T) {
+	const digits = 50
+	prec := operators.PrecisionBits(digits + operators.GuardDigits)
+
+	t.Run("evaluators", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			symbol   string
+			args     []string
+			expected string
+			err      error
+		}{
+			{"add", operators.OpAdd, []string{"0.1", "0.2"}, "0.3", nil},
+			{"divide", operators.OpDivide, []string{"1", "7"}, "0.14285714285714285714285714285714285714285714285714", nil},
+			{"divide by zero", operators.OpDivide, []string{"1", "0"}, "", operators.ErrDivisionByZero},
+			{"square root of two", operators.OpPower, []string{"2", "0.5"}, "1.4142135623730950488016887242096980785696718753769", nil},
+			{"integer power", operators.OpPower, []string{"-2", "-3"}, "-0.125", nil},
+			{"negative base", operators.OpPower, []string{"-2", "0.5"}, "", operators.ErrNegativeBase},
+			{"zero to negative power", operators.OpPower, []string{"0", "-1"}, "", operators.ErrDivisionByZero},
+			{"overflow", operators.OpPower, []string{"10", "1e12"}, "", operators.ErrDecimalOverflow},
+			{"sqrt", operators.FnSqrt, []string{"2"}, "1.4142135623730950488016887242096980785696718753769", nil},
+			{"negative sqrt", operators.FnSqrt, []string{"-1"}, "", operators.ErrNegativeSqrt},
+			{"exp", operators.FnExp, []string{"1"}, "2.7182818284590452353602874713526624977572470937", nil},
+			{"ln", operators.FnLn, []string{"10"}, "2.3025850929940456840179914546843642076011014886288", nil},
+			{"ln of non-positive", operators.FnLn, []string{"0"}, "", operators.ErrNonPositiveLog},
+			{"log", operators.FnLog, []string{"1000"}, "3", nil},
+			{"sin", operators.FnSin, []string{"1"}, "0.84147098480789650665250232163029899962256306079837", nil},
+			{"cos", operators.FnCos, []string{"100"}, "0.86231887228768393410193851395084253551008400853551", nil},
+			{"tan", operators.FnTan, []string{"-1"}, "-1.5574077246549022305069748074583601730872507723815", nil},
+			{"abs", operators.FnAbs, []string{"-2.5"}, "2.5", nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				op, ok := operators.Lookup(tt.symbol)
+				require.True(t, ok)
+				require.NotNil(t, op.Decimal)
+
+				args := make([]*big.Float, len(tt.args))
+				for i, arg := range tt.args {
+					value, err := operators.ParseDecimal(arg, prec)
+					require.NoError(t, err)
+					args[i] = value
+				}
+				result, err := op.Decimal(prec, args...)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, operators.FormatDecimalDigits(result, digits))
+			})
+		}
+	})
+
+	t.Run("parse and round", func(t *testing.T) {
+		_, err := operators.ParseDecimal("1.2.3", prec)
+		assert.Error(t, err)
+
+		third, err := operators.ParseDecimal("1/3", prec)
+		require.NoError(t, err)
+		assert.Equal(t, "0.3333333333", operators.FormatDecimalDigits(third, 10))
+
+		rounded, err := operators.RoundDecimal("2.71828182845904523536", 5)
+		require.NoError(t, err)
+		assert.Equal(t, "2.7183", rounded)
+		assert.Equal(t, "0", operators.FormatDecimalDigits(new(big.Float).Neg(new(big.Float)), 5))
+	})
+}
+
+func TestComplex(t *testing.T) {
+	t.Run("evaluators", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			symbol   string
+			args     []complex128
+			expected complex128
+			err      error
+		}{
+			{"multiply", operators.OpMultiply, []complex128{1 + 2i, 3 - 1i}, 5 + 5i, nil},
+			{"divide", operators.OpDivide, []complex128{1, 1i}, -1i, nil},
+			{"divide by zero", operators.OpDivide, []complex128{1i, 0}, 0, operators.ErrDivisionByZero},
+			{"sqrt of negative", operators.FnSqrt, []complex128{-4}, 2i, nil},
+			{"sqrt of negated number", operators.FnSqrt, []complex128{complex(-4, math.Copysign(0, -1))}, 2i, nil},
+			{"ln of negated number", operators.FnLn, []complex128{complex(-1, math.Copysign(0, -1))}, complex(0, math.Pi), nil},
+			{"power of i", operators.OpPower, []complex128{1i, 2}, -1, nil},
+			{"zero to negative power", operators.OpPower, []complex128{0, -1}, 0, operators.ErrDivisionByZero},
+			{"abs", operators.FnAbs, []complex128{3 + 4i}, 5, nil},
+			{"ln of zero", operators.FnLn, []complex128{0}, 0, operators.ErrZeroLog},
+			{"unary minus", operators.OpUnaryMinus, []complex128{1 - 1i}, -1 + 1i, nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				op, ok := operators.Lookup(tt.symbol)
+				require.True(t, ok)
+				require.NotNil(t, op.Complex)
+
+				result, err := op.Complex(tt.args...)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+					return
+				}
+				require.NoError(t, err)
+				assert.InDelta(t, real(tt.expected), real(result), 1e-12)
+				assert.InDelta(t, imag(tt.expected), imag(result), 1e-12)
+			})
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		for value, expected := range map[complex128]string{
+			5 + 5i:                            "5+5i",
+			1 - 0.5i:                          "1-0.5i",
+			-2i:                               "-2i",
+			1i:                                "1i",
+			3:                                 "3",
+			0:                                 "0",
+			complex(-1, math.Copysign(0, -1)): "-1",
+		} {
+			assert.Equal(t, expected, operators.FormatComplex(value))
+		}
+	})
+}
+
+func TestInterval(t *testing.T) {
+	t.Run("evaluators", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			symbol   string
+			args     []operators.Interval
+			expected operators.Interval
+			err      error
+		}{
+			{"plus minus", operators.OpPlusMinus, []operators.Interval{{Lo: 3, Hi: 3}, {Lo: 0.5, Hi: 0.5}}, operators.Interval{Lo: 2.5, Hi: 3.5}, nil},
+			{"negative error", operators.OpPlusMinus, []operators.Interval{{Lo: 3, Hi: 3}, {Lo: -1, Hi: -1}}, operators.Interval{}, operators.ErrNegativeError},
+			{"add", operators.OpAdd, []operators.Interval{{Lo: 1, Hi: 2}, {Lo: 3, Hi: 4}}, operators.Interval{Lo: 4, Hi: 6}, nil},
+			{"subtract", operators.OpSubtract, []operators.Interval{{Lo: 1, Hi: 2}, {Lo: 3, Hi: 4}}, operators.Interval{Lo: -3, Hi: -1}, nil},
+			{"multiply", operators.OpMultiply, []operators.Interval{{Lo: 2.9, Hi: 3.1}, {Lo: 1.95, Hi: 2.05}}, operators.Interval{Lo: 5.655, Hi: 6.355}, nil},
+			{"multiply by signed", operators.OpMultiply, []operators.Interval{{Lo: -1, Hi: 2}, {Lo: -3, Hi: 1}}, operators.Interval{Lo: -6, Hi: 3}, nil},
+			{"divide", operators.OpDivide, []operators.Interval{{Lo: 1, Hi: 2}, {Lo: 4, Hi: 8}}, operators.Interval{Lo: 0.125, Hi: 0.5}, nil},
+			{"divide by interval with zero", operators.OpDivide, []operators.Interval{{Lo: 1, Hi: 2}, {Lo: -1, Hi: 1}}, operators.Interval{}, operators.ErrDivisionByZero},
+			{"even power of interval with zero", operators.OpPower, []operators.Interval{{Lo: -2, Hi: 1}, {Lo: 2, Hi: 2}}, operators.Interval{Lo: 0, Hi: 4}, nil},
+			{"even power of negative interval", operators.OpPower, []operators.Interval{{Lo: -3, Hi: -2}, {Lo: 2, Hi: 2}}, operators.Interval{Lo: 4, Hi: 9}, nil},
+			{"odd power", operators.OpPower, []operators.Interval{{Lo: -2, Hi: 1}, {Lo: 3, Hi: 3}}, operators.Interval{Lo: -8, Hi: 1}, nil},
+			{"negative integer power", operators.OpPower, []operators.Interval{{Lo: 2, Hi: 4}, {Lo: -1, Hi: -1}}, operators.Interval{Lo: 0.25, Hi: 0.5}, nil},
+			{"fractional power", operators.OpPower, []operators.Interval{{Lo: 4, Hi: 9}, {Lo: 0.5, Hi: 0.5}}, operators.Interval{Lo: 2, Hi: 3}, nil},
+			{"fractional power of negative", operators.OpPower, []operators.Interval{{Lo: -4, Hi: 9}, {Lo: 0.5, Hi: 0.5}}, operators.Interval{}, operators.ErrNegativeBase},
+			{"unary minus", operators.OpUnaryMinus, []operators.Interval{{Lo: 1, Hi: 2}}, operators.Interval{Lo: -2, Hi: -1}, nil},
+			{"sin over maximum", operators.FnSin, []operators.Interval{{Lo: 1, Hi: 2}}, operators.Interval{Lo: math.Sin(1), Hi: 1}, nil},
+			{"cos over minimum", operators.FnCos, []operators.Interval{{Lo: 3, Hi: 4}}, operators.Interval{Lo: -1, Hi: math.Cos(4)}, nil},
+			{"sin of full period", operators.FnSin, []operators.Interval{{Lo: 0, Hi: 7}}, operators.Interval{Lo: -1, Hi: 1}, nil},
+			{"tan", operators.FnTan, []operators.Interval{{Lo: 0, Hi: 1}}, operators.Interval{Lo: 0, Hi: math.Tan(1)}, nil},
+			{"tan over pole", operators.FnTan, []operators.Interval{{Lo: 1, Hi: 2}}, operators.Interval{}, operators.ErrTangentPole},
+			{"sqrt", operators.FnSqrt, []operators.Interval{{Lo: 4, Hi: 9}}, operators.Interval{Lo: 2, Hi: 3}, nil},
+			{"sqrt of negative", operators.FnSqrt, []operators.Interval{{Lo: -1, Hi: 9}}, operators.Interval{}, operators.ErrNegativeSqrt},
+			{"ln of interval with zero", operators.FnLn, []operators.Interval{{Lo: 0, Hi: 1}}, operators.Interval{}, operators.ErrNonPositiveLog},
+			{"abs", operators.FnAbs, []operators.Interval{{Lo: -3, Hi: 2}}, operators.Interval{Lo: 0, Hi: 3}, nil},
+			{"exp", operators.FnExp, []operators.Interval{{Lo: 0, Hi: 1}}, operators.Interval{Lo: 1, Hi: math.E}, nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				op, ok := operators.Lookup(tt.symbol)
+				require.True(t, ok)
+				require.NotNil(t, op.Interval)
+
+				result, err := op.Interval(tt.args...)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+					return
+				}
+				require.NoError(t, err)
+				// Границы расширяются наружу, поэтому результат содержит точный интервал
+				assert.LessOrEqual(t, result.Lo, tt.expected.Lo)
+				assert.GreaterOrEqual(t, result.Hi, tt.expected.Hi)
+				assert.InDelta(t, tt.expected.Lo, result.Lo, 1e-12)
+				assert.InDelta(t, tt.expected.Hi, result.Hi, 1e-12)
+			})
+		}
+	})
+
+	t.Run("outward rounding", func(t *testing.T) {
+		op, _ := operators.Lookup(operators.OpAdd)
+		result, err := op.Interval(operators.Degenerate(0.1), operators.Degenerate(0.2))
+		require.NoError(t, err)
+		// 0.1 + 0.2 в float64 округляется до 0.30000000000000004, но интервал содержит и 0.3
+		assert.LessOrEqual(t, result.Lo, 0.3)
+		assert.Greater(t, result.Hi, 0.30000000000000004)
+	})
+
+	t.Run("midpoint", func(t *testing.T) {
+		assert.Equal(t, 6.0, operators.Interval{Lo: 5.5, Hi: 6.5}.Mid())
+	})
+
+	t.Run("literal", func(t *testing.T) {
+		// 0.1 в float64 больше 0.1, 0.7 - меньше: интервал расширяется в сторону точного значения
+		assert.Equal(t, operators.Interval{Lo: math.Nextafter(0.1, 0), Hi: 0.1}, operators.LiteralInterval(0.1, "0.1"))
+		assert.Equal(t, operators.Interval{Lo: 0.7, Hi: math.Nextafter(0.7, 1)}, operators.LiteralInterval(0.7, "0.7"))
+		assert.Equal(t, operators.Degenerate(0.5), operators.LiteralInterval(0.5, "0.5"))
+		assert.Equal(t, operators.Degenerate(1000), operators.LiteralInterval(1000, "1e3"))
+		assert.Equal(t, operators.Degenerate(2), operators.LiteralInterval(2, ""))
+	})
+}
+
+func TestAggregates(t *testing.T) {
+	t.Run("float", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			symbol   string
+			args     []float64
+			expected float64
+		}{
+			{"sum", operators.FnSum, []float64{1, 2, 3, 4}, 10},
+			{"single argument", operators.FnSum, []float64{5}, 5},
+			{"min", operators.FnMin, []float64{3, -7, 1}, -7},
+			{"max", operators.FnMax, []float64{3, 7, 1, 9}, 9},
+			{"avg", operators.FnAvg, []float64{1, 2, 6}, 3},
+			{"median of odd count", operators.FnMedian, []float64{9, 1, 5}, 5},
+			{"median of even count", operators.FnMedian, []float64{4, 1, 3, 10}, 3.5},
+			{"prod", operators.FnProd, []float64{2, 3, 4}, 24},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				op, ok := operators.Lookup(tt.symbol)
+				require.True(t, ok)
+				result, err := op.Eval(tt.args...)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			})
+		}
+	})
+
+	t.Run("exact", func(t *testing.T) {
+		rat := func(s string) *big.Rat {
+			value, err := operators.ParseExact(s)
+			require.NoError(t, err)
+			return value
+		}
+		args := []*big.Rat{rat("1/2"), rat("1/3"), rat("1/6"), rat("1")}
+
+		for symbol, expected := range map[string]string{
+			operators.FnSum:    "2",
+			operators.FnMin:    "1/6",
+			operators.FnMax:    "1",
+			operators.FnAvg:    "1/2",
+			operators.FnMedian: "5/12",
+			operators.FnProd:   "1/36",
+		} {
+			op, _ := operators.Lookup(symbol)
+			require.NotNil(t, op.Exact, symbol)
+			result, err := op.Exact(args...)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, operators.FormatExact(result), symbol)
+		}
+		assert.Equal(t, "1/2", operators.FormatExact(args[0]), "arguments are not modified")
+	})
+
+	t.Run("decimal", func(t *testing.T) {
+		prec := operators.PrecisionBits(30 + operators.GuardDigits)
+		args := make([]*big.Float, 3)
+		for i, arg := range []string{"0.1", "0.2", "0.4"} {
+			value, err := operators.ParseDecimal(arg, prec)
+			require.NoError(t, err)
+			args[i] = value
+		}
+
+		for symbol, expected := range map[string]string{
+			operators.FnSum:    "0.7",
+			operators.FnMin:    "0.1",
+			operators.FnMax:    "0.4",
+			operators.FnAvg:    "0.233333333333333333333333333333",
+			operators.FnMedian: "0.2",
+			operators.FnProd:   "0.008",
+		} {
+			op, _ := operators.Lookup(symbol)
+			require.NotNil(t, op.Decimal, symbol)
+			result, err := op.Decimal(prec, args...)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, operators.FormatDecimalDigits(result, 30), symbol)
+		}
+	})
+
+	t.Run("complex numbers are not ordered", func(t *testing.T) {
+		op, _ := operators.Lookup(operators.FnAvg)
+		result, err := op.Complex(1+1i, 3-3i)
+		assert.NoError(t, err)
+		assert.Equal(t, 2-1i, result)
+
+		op, _ = operators.Lookup(operators.FnProd)
+		result, err = op.Complex(1i, 1i, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, complex(-2, 0), result)
+
+		for _, symbol := range []string{operators.FnMin, operators.FnMax, operators.FnMedian} {
+			op, _ := operators.Lookup(symbol)
+			assert.Nil(t, op.Complex, symbol)
+		}
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		args := []operators.Interval{{Lo: 1, Hi: 4}, {Lo: 2, Hi: 3}, {Lo: 0, Hi: 5}, {Lo: 3, Hi: 3}}
+
+		for symbol, expected := range map[string]operators.Interval{
+			operators.FnSum:    {Lo: 6, Hi: 15},
+			operators.FnMin:    {Lo: 0, Hi: 3},
+			operators.FnMax:    {Lo: 3, Hi: 5},
+			operators.FnAvg:    {Lo: 1.5, Hi: 3.75},
+			operators.FnMedian: {Lo: 1.5, Hi: 3.5},
+			operators.FnProd:   {Lo: 0, Hi: 180},
+		} {
+			op, _ := operators.Lookup(symbol)
+			require.NotNil(t, op.Interval, symbol)
+			result, err := op.Interval(args...)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, result.Lo, expected.Lo, symbol)
+			assert.GreaterOrEqual(t, result.Hi, expected.Hi, symbol)
+			assert.InDelta(t, expected.Lo, result.Lo, 1e-12, symbol)
+			assert.InDelta(t, expected.Hi, result.Hi, 1e-12, symbol)
+		}
+	})
+}
+
+func TestLogic(t *testing.T) {
+	t.Run("float", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			symbol   string
+			args     []float64
+			expected float64
+		}{
+			{"less", operators.OpLess, []float64{1, 2}, 1},
+			{"less of equal", operators.OpLess, []float64{2, 2}, 0},
+			{"less or equal", operators.OpLessEqual, []float64{2, 2}, 1},
+			{"greater", operators.OpGreater, []float64{-1, 2}, 0},
+			{"greater or equal", operators.OpGreaterEqual, []float64{3, 2}, 1},
+			{"equal", operators.OpEqual, []float64{0.5, 0.5}, 1},
+			{"not equal", operators.OpNotEqual, []float64{0.5, 0.5}, 0},
+			{"and", operators.OpAnd, []float64{2, -3}, 1},
+			{"and with zero", operators.OpAnd, []float64{2, 0}, 0},
+			{"or", operators.OpOr, []float64{0, 0.1}, 1},
+			{"not", operators.FnNot, []float64{5}, 0},
+			{"not of zero", operators.FnNot, []float64{0}, 1},
+			{"if true", operators.FnIf, []float64{-1, 7, 9}, 7},
+			{"if false", operators.FnIf, []float64{0, 7, 9}, 9},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				op, ok := operators.Lookup(tt.symbol)
+				require.True(t, ok)
+				result, err := op.Eval(tt.args...)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			})
+		}
+	})
+
+	t.Run("exact", func(t *testing.T) {
+		third, err := operators.ParseExact("1/3")
+		require.NoError(t, err)
+		approx, err := operators.ParseExact("3333/10000")
+		require.NoError(t, err)
+
+		for symbol, expected := range map[string]string{
+			operators.OpGreater:  "1",
+			operators.OpEqual:    "0",
+			operators.OpNotEqual: "1",
+			operators.OpAnd:      "1",
+			operators.FnIf:       "0",
+		} {
+			op, _ := operators.Lookup(symbol)
+			require.NotNil(t, op.Exact, symbol)
+			args := []*big.Rat{third, approx}
+			if symbol == operators.FnIf {
+				args = []*big.Rat{new(big.Rat), third, new(big.Rat)}
+			}
+			result, err := op.Exact(args...)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, operators.FormatExact(result), symbol)
+		}
+	})
+
+	t.Run("decimal", func(t *testing.T) {
+		prec := operators.PrecisionBits(30 + operators.GuardDigits)
+		a, err := operators.ParseDecimal("0.1", prec)
+		require.NoError(t, err)
+		b, err := operators.ParseDecimal("0.10", prec)
+		require.NoError(t, err)
+
+		op, _ := operators.Lookup(operators.OpEqual)
+		result, err := op.Decimal(prec, a, b)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", operators.FormatDecimalDigits(result, 30))
+	})
+
+	t.Run("no complex and interval evaluation", func(t *testing.T) {
+		for _, symbol := range []string{operators.OpLess, operators.OpEqual, operators.OpAnd, operators.FnNot, operators.FnIf} {
+			op, _ := operators.Lookup(symbol)
+			assert.Nil(t, op.Complex, symbol)
+			assert.Nil(t, op.Interval, symbol)
+		}
+	})
+}
+
+func TestInteger(t *testing.T) {
+	t.Run("float", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			symbol   string
+			args     []float64
+			expected float64
+			err      error
+		}{
+			{"mod", operators.OpMod, []float64{10, 4}, 2, nil},
+			{"mod follows divisor sign", operators.OpMod, []float64{-7, 3}, 2, nil},
+			{"mod of negative divisor", operators.OpMod, []float64{7, -3}, -2, nil},
+			{"mod of fractions", operators.OpMod, []float64{5.5, 2}, 1.5, nil},
+			{"mod of large integers", operators.OpMod, []float64{1e20, 7}, 2, nil},
+			{"mod by zero", operators.OpMod, []float64{5, 0}, 0, operators.ErrDivisionByZero},
+			{"floor division", operators.OpFloorDiv, []float64{7, 2}, 3, nil},
+			{"floor division rounds down", operators.OpFloorDiv, []float64{-7, 2}, -4, nil},
+			{"floor division of fractions", operators.OpFloorDiv, []float64{-5.5, 2}, -3, nil},
+			{"floor division by zero", operators.OpFloorDiv, []float64{5, 0}, 0, operators.ErrDivisionByZero},
+			{"factorial", operators.OpFactorial, []float64{5}, 120, nil},
+			{"factorial of zero", operators.OpFactorial, []float64{0}, 1, nil},
+			{"factorial overflow", operators.OpFactorial, []float64{171}, math.Inf(1), nil},
+			{"negative factorial", operators.OpFactorial, []float64{-3}, 0, operators.ErrFactorialDomain},
+			{"fractional factorial", operators.OpFactorial, []float64{2.5}, 0, operators.ErrFactorialDomain},
+			{"gcd", operators.FnGcd, []float64{12, 18}, 6, nil},
+			{"gcd of many", operators.FnGcd, []float64{-12, 18, 27}, 3, nil},
+			{"gcd of zeros", operators.FnGcd, []float64{0, 0}, 0, nil},
+			{"gcd of fractions", operators.FnGcd, []float64{1.5, 3}, 0, operators.ErrIntegerArgument},
+			{"lcm", operators.FnLcm, []float64{4, 6}, 12, nil},
+			{"lcm of many", operators.FnLcm, []float64{-4, 6, 10}, 60, nil},
+			{"lcm with zero", operators.FnLcm, []float64{0, 6}, 0, nil},
+			{"isprime", operators.FnIsPrime, []float64{97}, 1, nil},
+			{"isprime of composite", operators.FnIsPrime, []float64{91}, 0, nil},
+			{"isprime of one", operators.FnIsPrime, []float64{1}, 0, nil},
+			{"isprime of fraction", operators.FnIsPrime, []float64{2.5}, 0, nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				op, ok := operators.Lookup(tt.symbol)
+				require.True(t, ok)
+				result, err := op.Eval(tt.args...)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			})
+		}
+	})
+
+	t.Run("exact", func(t *testing.T) {
+		rat := func(s string) *big.Rat {
+			value, err := operators.ParseExact(s)
+			require.NoError(t, err)
+			return value
+		}
+
+		tests := []struct {
+			name     string
+			symbol   string
+			args     []string
+			expected string
+			err      error
+		}{
+			{"mod", operators.OpMod, []string{"-7", "3"}, "2", nil},
+			{"mod of fractions", operators.OpMod, []string{"7/2", "-1/3"}, "-1/6", nil},
+			{"mod by zero", operators.OpMod, []string{"1", "0"}, "", operators.ErrDivisionByZero},
+			{"floor division", operators.OpFloorDiv, []string{"-7/2", "1"}, "-4", nil},
+			{"factorial", operators.OpFactorial, []string{"25"}, "15511210043330985984000000", nil},
+			{"negative factorial", operators.OpFactorial, []string{"-1"}, "", operators.ErrFactorialDomain},
+			{"factorial limit", operators.OpFactorial, []string{"10001"}, "", operators.ErrFactorialLimit},
+			{"gcd", operators.FnGcd, []string{"123456789012345678901234567890", "987654321098765432109876543210"}, "9000000000900000000090", nil},
+			{"lcm of fractions", operators.FnLcm, []string{"1/2", "3"}, "", operators.ErrIntegerArgument},
+			{"isprime", operators.FnIsPrime, []string{"170141183460469231731687303715884105727"}, "1", nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				op, _ := operators.Lookup(tt.symbol)
+				require.NotNil(t, op.Exact)
+				args := make([]*big.Rat, len(tt.args))
+				for i, arg := range tt.args {
+					args[i] = rat(arg)
+				}
+				result, err := op.Exact(args...)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, operators.FormatExact(result))
+			})
+		}
+	})
+
+	t.Run("decimal", func(t *testing.T) {
+		prec := operators.PrecisionBits(30 + operators.GuardDigits)
+		decimal := func(s string) *big.Float {
+			value, err := operators.ParseDecimal(s, prec)
+			require.NoError(t, err)
+			return value
+		}
+
+		for _, tt := range []struct {
+			symbol   string
+			args     []string
+			expected string
+		}{
+			{operators.OpMod, []string{"5.5", "-2"}, "-0.5"},
+			{operators.OpFloorDiv, []string{"-1", "0.3"}, "-4"},
+			{operators.OpFactorial, []string{"20"}, "2432902008176640000"},
+			{operators.FnLcm, []string{"4", "6", "10"}, "60"},
+			{operators.FnIsPrime, []string{"7.5"}, "0"},
+		} {
+			op, _ := operators.Lookup(tt.symbol)
+			require.NotNil(t, op.Decimal, tt.symbol)
+			args := make([]*big.Float, len(tt.args))
+			for i, arg := range tt.args {
+				args[i] = decimal(arg)
+			}
+			result, err := op.Decimal(prec, args...)
+			assert.NoError(t, err, tt.symbol)
+			assert.Equal(t, tt.expected, operators.FormatDecimalDigits(result, 30), tt.symbol)
+		}
+	})
+
+	t.Run("factorial is a postfix operator", func(t *testing.T) {
+		assert.True(t, operators.IsPostfix(operators.OpFactorial))
+		assert.False(t, operators.IsPostfix(operators.OpUnaryMinus))
+		assert.False(t, operators.IsBinary(operators.OpFactorial))
+		assert.True(t, operators.IsBinary(operators.OpFloorDiv))
+	})
+
+	t.Run("no complex and interval evaluation", func(t *testing.T) {
+		for _, symbol := range []string{operators.OpMod, operators.OpFloorDiv, operators.OpFactorial, operators.FnGcd, operators.FnLcm, operators.FnIsPrime} {
+			op, _ := operators.Lookup(symbol)
+			assert.Nil(t, op.Complex, symbol)
+			assert.Nil(t, op.Interval, symbol)
+		}
+	})
+}
+
+func TestRandom(t *testing.T) {
+	t.Run("same seed gives the same result", func(t *testing.T) {
+		for _, tt := range []struct {
+			symbol string
+			args   []float64
+		}{
+			{operators.FnRand, nil},
+			{operators.FnRandInt, []float64{1, 6}},
+			{operators.FnNormal, []float64{10, 2}},
+		} {
+			op, ok := operators.Lookup(tt.symbol)
+			require.True(t, ok, tt.symbol)
+			require.Nil(t, op.Eval, tt.symbol)
+			first, err := op.Random(42, tt.args...)
+			require.NoError(t, err, tt.symbol)
+			second, err := op.Random(42, tt.args...)
+			require.NoError(t, err, tt.symbol)
+			assert.Equal(t, first, second, tt.symbol)
+		}
+	})
+
+	t.Run("values are in range", func(t *testing.T) {
+		rand, _ := operators.Lookup(operators.FnRand)
+		randint, _ := operators.Lookup(operators.FnRandInt)
+		seen := map[float64]bool{}
+		for seed := int64(0); seed < 1000; seed++ {
+			value, err := rand.Random(seed)
+			require.NoError(t, err)
+			assert.True(t, value >= 0 && value < 1, value)
+
+			value, err = randint.Random(seed, -2, 2)
+			require.NoError(t, err)
+			assert.True(t, value >= -2 && value <= 2 && value == math.Trunc(value), value)
+			seen[value] = true
+		}
+		assert.Len(t, seen, 5)
+	})
+
+	t.Run("degenerate bounds", func(t *testing.T) {
+		randint, _ := operators.Lookup(operators.FnRandInt)
+		value, err := randint.Random(7, 3, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 3.0, value)
+
+		normal, _ := operators.Lookup(operators.FnNormal)
+		value, err = normal.Random(7, 5, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 5.0, value)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			symbol string
+			args   []float64
+			err    error
+		}{
+			{"randint with reversed bounds", operators.FnRandInt, []float64{6, 1}, operators.ErrRandomBounds},
+			{"randint with fractional bound", operators.FnRandInt, []float64{1, 2.5}, operators.ErrRandomBounds},
+			{"randint with huge bound", operators.FnRandInt, []float64{0, 1e18}, operators.ErrRandomBounds},
+			{"normal with negative deviation", operators.FnNormal, []float64{0, -1}, operators.ErrNegativeStdDev},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				op, _ := operators.Lookup(tt.symbol)
+				_, err := op.Random(1, tt.args...)
+				assert.ErrorIs(t, err, tt.err)
+			})
+		}
+	})
+
+	t.Run("task seeds", func(t *testing.T) {
+		assert.Equal(t, operators.TaskSeed(42, 1), operators.TaskSeed(42, 1))
+		assert.NotEqual(t, operators.TaskSeed(42, 1), operators.TaskSeed(42, 2))
+		assert.NotEqual(t, operators.TaskSeed(42, 1), operators.TaskSeed(43, 1))
+	})
+}
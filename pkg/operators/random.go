@@ -0,0 +1,100 @@
+package operators
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+)
+
+// Функции случайных чисел. Результат определяется зерном задачи (см. TaskSeed), поэтому выражение,
+// повторно добавленное с тем же зерном, вычисляется одинаково на любом агенте.
+const (
+	FnRand    = "rand"    // равномерно распределенное число из [0, 1): rand()
+	FnRandInt = "randint" // равномерно распределенное целое число из [a, b]: randint(a, b)
+	FnNormal  = "normal"  // нормально распределенное число: normal(mu, sigma)
+)
+
+// MaxSeed - наибольшее зерно, которое оркестратор выбирает для выражения. Зерно не превышает 2^53,
+// чтобы точно передаваться числом JSON.
+const MaxSeed = 1<<53 - 1
+
+// randomStream - номер потока генератора PCG. Вместе с зерном задачи определяет последовательность чисел.
+const randomStream = 0x63616c635f33 // "calc_3"
+
+// Ошибки вычисления функций случайных чисел.
+var (
+	ErrRandomBounds   = errors.New("границы randint должны быть целыми числами не больше 2^53 по модулю, a <= b")
+	ErrNegativeStdDev = errors.New("стандартное отклонение normal не может быть отрицательным")
+)
+
+// TaskSeed возвращает зерно задачи выражения: разные задачи одного выражения получают независимые зерна,
+// а одна и та же задача выражения с тем же зерном - всегда одно и то же зерно.
+//
+// Args:
+//
+//	seed: int64 - Зерно выражения.
+//	index: int - Локальный индекс задачи в выражении (начиная с 1).
+//
+// Returns:
+//
+//	int64 - Зерно задачи.
+func TaskSeed(seed int64, index int) int64 {
+	// Перемешивание splitmix64 значения зерна, сдвинутого на индекс задачи
+	z := uint64(seed) + uint64(index)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// generator создает генератор случайных чисел задачи по ее зерну.
+func generator(seed int64) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(seed), randomStream))
+}
+
+// randEval возвращает равномерно распределенное число из [0, 1).
+func randEval(seed int64, _ ...float64) (float64, error) {
+	return generator(seed).Float64(), nil
+}
+
+// randIntEval возвращает равномерно распределенное целое число из [a, b].
+//
+// Args:
+//
+//	seed: int64 - Зерно задачи.
+//	args: ...float64 - Границы a и b.
+//
+// Returns:
+//
+//	float64 - Случайное целое число.
+//	error - ErrRandomBounds, если границы дробные, слишком велики или a > b.
+func randIntEval(seed int64, args ...float64) (float64, error) {
+	lo, hi := args[0], args[1]
+	for _, bound := range args {
+		if bound != math.Trunc(bound) || math.Abs(bound) > MaxSeed+1 {
+			return 0, ErrRandomBounds
+		}
+	}
+	if lo > hi {
+		return 0, ErrRandomBounds
+	}
+	return lo + float64(generator(seed).Int64N(int64(hi-lo)+1)), nil
+}
+
+// normalEval возвращает нормально распределенное число со средним mu и стандартным отклонением sigma.
+//
+// Args:
+//
+//	seed: int64 - Зерно задачи.
+//	args: ...float64 - Среднее mu и стандартное отклонение sigma.
+//
+// Returns:
+//
+//	float64 - Случайное число.
+//	error - ErrNegativeStdDev, если sigma отрицательно.
+func normalEval(seed int64, args ...float64) (float64, error) {
+	mu, sigma := args[0], args[1]
+	if sigma < 0 {
+		return 0, ErrNegativeStdDev
+	}
+	return mu + sigma*generator(seed).NormFloat64(), nil
+}
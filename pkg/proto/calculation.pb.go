@@ -66,6 +66,107 @@ func (x *WrappedDouble) GetValue() float64 {
 	return 0
 }
 
+// Rational - Точное рациональное число. Числитель и знаменатель записываются
+// десятичными строками, так как могут не помещаться в int64.
+type Rational struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Num - Числитель со знаком.
+	Num string `protobuf:"bytes,1,opt,name=num,proto3" json:"num,omitempty"`
+	// Den - Положительный знаменатель.
+	Den           string `protobuf:"bytes,2,opt,name=den,proto3" json:"den,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rational) Reset() {
+	*x = Rational{}
+	mi := &file_calculation_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rational) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rational) ProtoMessage() {}
+
+func (x *Rational) ProtoReflect() protoreflect.Message {
+	mi := &file_calculation_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rational.ProtoReflect.Descriptor instead.
+func (*Rational) Descriptor() ([]byte, []int) {
+	return file_calculation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Rational) GetNum() string {
+	if x != nil {
+		return x.Num
+	}
+	return ""
+}
+
+func (x *Rational) GetDen() string {
+	if x != nil {
+		return x.Den
+	}
+	return ""
+}
+
+type WrappedRational struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Отсутствие значения означает, что аргумент ещё не вычислен
+	Value         *Rational `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WrappedRational) Reset() {
+	*x = WrappedRational{}
+	mi := &file_calculation_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WrappedRational) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WrappedRational) ProtoMessage() {}
+
+func (x *WrappedRational) ProtoReflect() protoreflect.Message {
+	mi := &file_calculation_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WrappedRational.ProtoReflect.Descriptor instead.
+func (*WrappedRational) Descriptor() ([]byte, []int) {
+	return file_calculation_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WrappedRational) GetValue() *Rational {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
 type TaskResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// ID - Уникальный идентификатор задачи.
@@ -77,14 +178,33 @@ type TaskResponse struct {
 	// Expression - ID выражения, к которому принадлежит данная задача.
 	Expression int64 `protobuf:"varint,4,opt,name=expression,proto3" json:"expression,omitempty"`
 	// Error - Указывает на ошибку вычисления задачи
-	Error         string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Error string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	// ExactArgs - Точные значения аргументов задачи (только для точных задач).
+	ExactArgs []*WrappedRational `protobuf:"bytes,6,rep,name=exact_args,json=exactArgs,proto3" json:"exact_args,omitempty"`
+	// Exact - Признак вычисления задачи в точной рациональной арифметике.
+	Exact bool `protobuf:"varint,7,opt,name=exact,proto3" json:"exact,omitempty"`
+	// Precision - Точность десятичной задачи в значащих цифрах. 0, если задача не десятичная.
+	Precision int32 `protobuf:"varint,8,opt,name=precision,proto3" json:"precision,omitempty"`
+	// DecimalArgs - Десятичные записи аргументов задачи (только для десятичных задач).
+	// Пустая строка означает, что аргумент отсутствует.
+	DecimalArgs []string `protobuf:"bytes,9,rep,name=decimal_args,json=decimalArgs,proto3" json:"decimal_args,omitempty"`
+	// ImagArgs - Мнимые части аргументов задачи (только для комплексных задач).
+	ImagArgs []*WrappedDouble `protobuf:"bytes,10,rep,name=imag_args,json=imagArgs,proto3" json:"imag_args,omitempty"`
+	// Complex - Признак вычисления задачи в комплексных числах.
+	Complex bool `protobuf:"varint,11,opt,name=complex,proto3" json:"complex,omitempty"`
+	// UpperArgs - Верхние границы аргументов задачи (только для интервальных задач).
+	UpperArgs []*WrappedDouble `protobuf:"bytes,12,rep,name=upper_args,json=upperArgs,proto3" json:"upper_args,omitempty"`
+	// Interval - Признак интервальной задачи: args содержат нижние границы аргументов.
+	Interval bool `protobuf:"varint,13,opt,name=interval,proto3" json:"interval,omitempty"`
+	// Seed - Зерно задачи функции случайных чисел (только для таких задач).
+	Seed          int64 `protobuf:"varint,14,opt,name=seed,proto3" json:"seed,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TaskResponse) Reset() {
 	*x = TaskResponse{}
-	mi := &file_calculation_proto_msgTypes[1]
+	mi := &file_calculation_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -96,7 +216,7 @@ func (x *TaskResponse) String() string {
 func (*TaskResponse) ProtoMessage() {}
 
 func (x *TaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_calculation_proto_msgTypes[1]
+	mi := &file_calculation_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -109,7 +229,7 @@ func (x *TaskResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TaskResponse.ProtoReflect.Descriptor instead.
 func (*TaskResponse) Descriptor() ([]byte, []int) {
-	return file_calculation_proto_rawDescGZIP(), []int{1}
+	return file_calculation_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *TaskResponse) GetId() int64 {
@@ -147,6 +267,69 @@ func (x *TaskResponse) GetError() string {
 	return ""
 }
 
+func (x *TaskResponse) GetExactArgs() []*WrappedRational {
+	if x != nil {
+		return x.ExactArgs
+	}
+	return nil
+}
+
+func (x *TaskResponse) GetExact() bool {
+	if x != nil {
+		return x.Exact
+	}
+	return false
+}
+
+func (x *TaskResponse) GetPrecision() int32 {
+	if x != nil {
+		return x.Precision
+	}
+	return 0
+}
+
+func (x *TaskResponse) GetDecimalArgs() []string {
+	if x != nil {
+		return x.DecimalArgs
+	}
+	return nil
+}
+
+func (x *TaskResponse) GetImagArgs() []*WrappedDouble {
+	if x != nil {
+		return x.ImagArgs
+	}
+	return nil
+}
+
+func (x *TaskResponse) GetComplex() bool {
+	if x != nil {
+		return x.Complex
+	}
+	return false
+}
+
+func (x *TaskResponse) GetUpperArgs() []*WrappedDouble {
+	if x != nil {
+		return x.UpperArgs
+	}
+	return nil
+}
+
+func (x *TaskResponse) GetInterval() bool {
+	if x != nil {
+		return x.Interval
+	}
+	return false
+}
+
+func (x *TaskResponse) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
 type TaskCompleted struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Expression - ID корневого выражения, к которому принадлежит задача.
@@ -156,14 +339,22 @@ type TaskCompleted struct {
 	// Result - Результат вычисления задачи.
 	Result float64 `protobuf:"fixed64,3,opt,name=result,proto3" json:"result,omitempty"`
 	// Error - Указывает на невыполнимость задачи
-	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// ExactResult - Точный результат задачи. Не заполняется, если задача не точная.
+	ExactResult *Rational `protobuf:"bytes,5,opt,name=exact_result,json=exactResult,proto3" json:"exact_result,omitempty"`
+	// DecimalResult - Десятичная запись результата задачи. Не заполняется, если задача не десятичная.
+	DecimalResult string `protobuf:"bytes,6,opt,name=decimal_result,json=decimalResult,proto3" json:"decimal_result,omitempty"`
+	// ImagResult - Мнимая часть результата задачи. Заполняется только для комплексных задач.
+	ImagResult float64 `protobuf:"fixed64,7,opt,name=imag_result,json=imagResult,proto3" json:"imag_result,omitempty"`
+	// UpperResult - Верхняя граница результата задачи. Заполняется только для интервальных задач.
+	UpperResult   *WrappedDouble `protobuf:"bytes,8,opt,name=upper_result,json=upperResult,proto3" json:"upper_result,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TaskCompleted) Reset() {
 	*x = TaskCompleted{}
-	mi := &file_calculation_proto_msgTypes[2]
+	mi := &file_calculation_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -175,7 +366,7 @@ func (x *TaskCompleted) String() string {
 func (*TaskCompleted) ProtoMessage() {}
 
 func (x *TaskCompleted) ProtoReflect() protoreflect.Message {
-	mi := &file_calculation_proto_msgTypes[2]
+	mi := &file_calculation_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -188,7 +379,7 @@ func (x *TaskCompleted) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TaskCompleted.ProtoReflect.Descriptor instead.
 func (*TaskCompleted) Descriptor() ([]byte, []int) {
-	return file_calculation_proto_rawDescGZIP(), []int{2}
+	return file_calculation_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *TaskCompleted) GetExpression() int64 {
@@ -219,6 +410,34 @@ func (x *TaskCompleted) GetError() string {
 	return ""
 }
 
+func (x *TaskCompleted) GetExactResult() *Rational {
+	if x != nil {
+		return x.ExactResult
+	}
+	return nil
+}
+
+func (x *TaskCompleted) GetDecimalResult() string {
+	if x != nil {
+		return x.DecimalResult
+	}
+	return ""
+}
+
+func (x *TaskCompleted) GetImagResult() float64 {
+	if x != nil {
+		return x.ImagResult
+	}
+	return 0
+}
+
+func (x *TaskCompleted) GetUpperResult() *WrappedDouble {
+	if x != nil {
+		return x.UpperResult
+	}
+	return nil
+}
+
 type Empty struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -227,7 +446,7 @@ type Empty struct {
 
 func (x *Empty) Reset() {
 	*x = Empty{}
-	mi := &file_calculation_proto_msgTypes[3]
+	mi := &file_calculation_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -239,7 +458,7 @@ func (x *Empty) String() string {
 func (*Empty) ProtoMessage() {}
 
 func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_calculation_proto_msgTypes[3]
+	mi := &file_calculation_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -252,7 +471,7 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Empty.ProtoReflect.Descriptor instead.
 func (*Empty) Descriptor() ([]byte, []int) {
-	return file_calculation_proto_rawDescGZIP(), []int{3}
+	return file_calculation_proto_rawDescGZIP(), []int{5}
 }
 
 var File_calculation_proto protoreflect.FileDescriptor
@@ -262,7 +481,12 @@ const file_calculation_proto_rawDesc = "" +
 	"\x11calculation.proto\x12\vcalculation\"4\n" +
 	"\rWrappedDouble\x12\x19\n" +
 	"\x05value\x18\x01 \x01(\x01H\x00R\x05value\x88\x01\x01B\b\n" +
-	"\x06_value\"\xa2\x01\n" +
+	"\x06_value\".\n" +
+	"\bRational\x12\x10\n" +
+	"\x03num\x18\x01 \x01(\tR\x03num\x12\x10\n" +
+	"\x03den\x18\x02 \x01(\tR\x03den\">\n" +
+	"\x0fWrappedRational\x12+\n" +
+	"\x05value\x18\x01 \x01(\v2\x15.calculation.RationalR\x05value\"\xf4\x03\n" +
 	"\fTaskResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12.\n" +
 	"\x04args\x18\x02 \x03(\v2\x1a.calculation.WrappedDoubleR\x04args\x12\x1c\n" +
@@ -270,14 +494,31 @@ const file_calculation_proto_rawDesc = "" +
 	"\n" +
 	"expression\x18\x04 \x01(\x03R\n" +
 	"expression\x12\x14\n" +
-	"\x05error\x18\x05 \x01(\tR\x05error\"m\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12;\n" +
+	"\n" +
+	"exact_args\x18\x06 \x03(\v2\x1c.calculation.WrappedRationalR\texactArgs\x12\x14\n" +
+	"\x05exact\x18\a \x01(\bR\x05exact\x12\x1c\n" +
+	"\tprecision\x18\b \x01(\x05R\tprecision\x12!\n" +
+	"\fdecimal_args\x18\t \x03(\tR\vdecimalArgs\x127\n" +
+	"\timag_args\x18\n" +
+	" \x03(\v2\x1a.calculation.WrappedDoubleR\bimagArgs\x12\x18\n" +
+	"\acomplex\x18\v \x01(\bR\acomplex\x129\n" +
+	"\n" +
+	"upper_args\x18\f \x03(\v2\x1a.calculation.WrappedDoubleR\tupperArgs\x12\x1a\n" +
+	"\binterval\x18\r \x01(\bR\binterval\x12\x12\n" +
+	"\x04seed\x18\x0e \x01(\x03R\x04seed\"\xae\x02\n" +
 	"\rTaskCompleted\x12\x1e\n" +
 	"\n" +
 	"expression\x18\x01 \x01(\x03R\n" +
 	"expression\x12\x0e\n" +
 	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x16\n" +
 	"\x06result\x18\x03 \x01(\x01R\x06result\x12\x14\n" +
-	"\x05error\x18\x04 \x01(\tR\x05error\"\a\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x128\n" +
+	"\fexact_result\x18\x05 \x01(\v2\x15.calculation.RationalR\vexactResult\x12%\n" +
+	"\x0edecimal_result\x18\x06 \x01(\tR\rdecimalResult\x12\x1f\n" +
+	"\vimag_result\x18\a \x01(\x01R\n" +
+	"imagResult\x12=\n" +
+	"\fupper_result\x18\b \x01(\v2\x1a.calculation.WrappedDoubleR\vupperResult\"\a\n" +
 	"\x05Empty2\x8f\x01\n" +
 	"\x13OrchestratorService\x128\n" +
 	"\aGetTask\x12\x12.calculation.Empty\x1a\x19.calculation.TaskResponse\x12>\n" +
@@ -295,24 +536,32 @@ func file_calculation_proto_rawDescGZIP() []byte {
 	return file_calculation_proto_rawDescData
 }
 
-var file_calculation_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_calculation_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_calculation_proto_goTypes = []any{
-	(*WrappedDouble)(nil), // 0: calculation.WrappedDouble
-	(*TaskResponse)(nil),  // 1: calculation.TaskResponse
-	(*TaskCompleted)(nil), // 2: calculation.TaskCompleted
-	(*Empty)(nil),         // 3: calculation.Empty
+	(*WrappedDouble)(nil),   // 0: calculation.WrappedDouble
+	(*Rational)(nil),        // 1: calculation.Rational
+	(*WrappedRational)(nil), // 2: calculation.WrappedRational
+	(*TaskResponse)(nil),    // 3: calculation.TaskResponse
+	(*TaskCompleted)(nil),   // 4: calculation.TaskCompleted
+	(*Empty)(nil),           // 5: calculation.Empty
 }
 var file_calculation_proto_depIdxs = []int32{
-	0, // 0: calculation.TaskResponse.args:type_name -> calculation.WrappedDouble
-	3, // 1: calculation.OrchestratorService.GetTask:input_type -> calculation.Empty
-	2, // 2: calculation.OrchestratorService.SubmitResult:input_type -> calculation.TaskCompleted
-	1, // 3: calculation.OrchestratorService.GetTask:output_type -> calculation.TaskResponse
-	3, // 4: calculation.OrchestratorService.SubmitResult:output_type -> calculation.Empty
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1, // 0: calculation.WrappedRational.value:type_name -> calculation.Rational
+	0, // 1: calculation.TaskResponse.args:type_name -> calculation.WrappedDouble
+	2, // 2: calculation.TaskResponse.exact_args:type_name -> calculation.WrappedRational
+	0, // 3: calculation.TaskResponse.imag_args:type_name -> calculation.WrappedDouble
+	0, // 4: calculation.TaskResponse.upper_args:type_name -> calculation.WrappedDouble
+	1, // 5: calculation.TaskCompleted.exact_result:type_name -> calculation.Rational
+	0, // 6: calculation.TaskCompleted.upper_result:type_name -> calculation.WrappedDouble
+	5, // 7: calculation.OrchestratorService.GetTask:input_type -> calculation.Empty
+	4, // 8: calculation.OrchestratorService.SubmitResult:input_type -> calculation.TaskCompleted
+	3, // 9: calculation.OrchestratorService.GetTask:output_type -> calculation.TaskResponse
+	5, // 10: calculation.OrchestratorService.SubmitResult:output_type -> calculation.Empty
+	9, // [9:11] is the sub-list for method output_type
+	7, // [7:9] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_calculation_proto_init() }
@@ -327,7 +576,7 @@ func file_calculation_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_calculation_proto_rawDesc), len(file_calculation_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
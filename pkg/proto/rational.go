@@ -0,0 +1,43 @@
+package proto
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// NewRational преобразует рациональное число в сообщение Rational.
+//
+// Args:
+//
+//	value: *big.Rat - Число. nil преобразуется в nil.
+//
+// Returns:
+//
+//	*Rational - Сообщение с несократимой дробью.
+func NewRational(value *big.Rat) *Rational {
+	if value == nil {
+		return nil
+	}
+	return &Rational{Num: value.Num().String(), Den: value.Denom().String()}
+}
+
+// Rat преобразует сообщение Rational в рациональное число.
+//
+// Returns:
+//
+//	*big.Rat - Число.
+//	error - Ошибка, если сообщение пусто, числитель или знаменатель записаны неверно или знаменатель равен нулю.
+func (x *Rational) Rat() (*big.Rat, error) {
+	if x == nil {
+		return nil, fmt.Errorf("дробь не задана")
+	}
+	num, ok := new(big.Int).SetString(x.GetNum(), 10)
+	if !ok {
+		return nil, fmt.Errorf("неверный числитель дроби: %q", x.GetNum())
+	}
+	den, ok := new(big.Int).SetString(x.GetDen(), 10)
+	if !ok || den.Sign() == 0 {
+		return nil, fmt.Errorf("неверный знаменатель дроби: %q", x.GetDen())
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
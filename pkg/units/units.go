@@ -0,0 +1,336 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Ошибки разбора единиц измерения и проверки размерностей.
+var (
+	ErrUnknownUnit = errors.New("неизвестная единица измерения")
+	ErrInvalidUnit = errors.New("неверная запись единицы измерения")
+	ErrMismatch    = errors.New("несовместимые размерности")
+	ErrExponent    = errors.New("показатель степени размерной величины должен быть числом, дающим целые степени единиц")
+)
+
+// base - обозначения основных единиц СИ в порядке компонент Dimension.
+var base = [...]string{"m", "kg", "s", "A", "K", "mol", "cd"}
+
+// Dimension представляет размерность величины: показатели степеней основных единиц СИ
+// (метр, килограмм, секунда, ампер, кельвин, моль, кандела). Нулевое значение - безразмерная величина.
+type Dimension [len(base)]int
+
+// Основные размерности.
+var (
+	Length      = Dimension{1}
+	Mass        = Dimension{0, 1}
+	Time        = Dimension{0, 0, 1}
+	Current     = Dimension{0, 0, 0, 1}
+	Temperature = Dimension{0, 0, 0, 0, 1}
+	Amount      = Dimension{0, 0, 0, 0, 0, 1}
+	Luminosity  = Dimension{0, 0, 0, 0, 0, 0, 1}
+)
+
+// Dimensionless сообщает, является ли величина безразмерной.
+func (d Dimension) Dimensionless() bool {
+	return d == Dimension{}
+}
+
+// Mul возвращает размерность произведения величин.
+func (d Dimension) Mul(other Dimension) Dimension {
+	for i := range d {
+		d[i] += other[i]
+	}
+	return d
+}
+
+// Div возвращает размерность частного величин.
+func (d Dimension) Div(other Dimension) Dimension {
+	for i := range d {
+		d[i] -= other[i]
+	}
+	return d
+}
+
+// Pow возвращает размерность величины, возведенной в степень.
+//
+// Args:
+//
+//	exponent: float64 - Показатель степени.
+//
+// Returns:
+//
+//	Dimension - Размерность степени.
+//	bool - false, если степень какой-либо единицы получается нецелой (m^0.5).
+func (d Dimension) Pow(exponent float64) (Dimension, bool) {
+	for i, power := range d {
+		value := float64(power) * exponent
+		if value != math.Trunc(value) || math.Abs(value) > math.MaxInt32 {
+			return Dimension{}, false
+		}
+		d[i] = int(value)
+	}
+	return d, true
+}
+
+// String записывает размерность через основные единицы СИ: "m/s", "kg*m^2/s^2", "1/s".
+// Безразмерная величина записывается как "1".
+func (d Dimension) String() string {
+	var numerator, denominator []string
+	for i, power := range d {
+		switch {
+		case power == 1:
+			numerator = append(numerator, base[i])
+		case power > 1:
+			numerator = append(numerator, base[i]+"^"+strconv.Itoa(power))
+		case power == -1:
+			denominator = append(denominator, base[i])
+		case power < -1:
+			denominator = append(denominator, base[i]+"^"+strconv.Itoa(-power))
+		}
+	}
+
+	result := strings.Join(numerator, "*")
+	if result == "" {
+		result = "1"
+	}
+	if len(denominator) > 0 {
+		result += "/" + strings.Join(denominator, "/")
+	}
+	return result
+}
+
+// Unit представляет единицу измерения: ее размерность и значение в основных единицах СИ.
+type Unit struct {
+	Scale     float64   // Значение единицы в основных единицах СИ: для km - 1000
+	Dimension Dimension // Размерность единицы
+}
+
+// derived возвращает размерность, заданную показателями степеней основных единиц.
+func derived(m, kg, s, a int) Dimension {
+	return Dimension{m, kg, s, a}
+}
+
+// table - известные единицы измерения по обозначению. Обозначения регистрозависимы: m - метр, M не определена.
+var table = map[string]Unit{
+	// Длина
+	"m":  {1, Length},
+	"km": {1e3, Length},
+	"cm": {1e-2, Length},
+	"mm": {1e-3, Length},
+	"um": {1e-6, Length},
+	"nm": {1e-9, Length},
+	"mi": {1609.344, Length},
+	"yd": {0.9144, Length},
+	"ft": {0.3048, Length},
+	// Масса
+	"kg": {1, Mass},
+	"g":  {1e-3, Mass},
+	"mg": {1e-6, Mass},
+	"t":  {1e3, Mass},
+	"lb": {0.45359237, Mass},
+	// Время
+	"s":   {1, Time},
+	"ms":  {1e-3, Time},
+	"min": {60, Time},
+	"h":   {3600, Time},
+	"d":   {86400, Time},
+	// Сила тока, температура, количество вещества, сила света
+	"A":   {1, Current},
+	"mA":  {1e-3, Current},
+	"K":   {1, Temperature},
+	"mol": {1, Amount},
+	"cd":  {1, Luminosity},
+	// Производные единицы
+	"Hz":  {1, derived(0, 0, -1, 0)},
+	"N":   {1, derived(1, 1, -2, 0)},
+	"Pa":  {1, derived(-1, 1, -2, 0)},
+	"kPa": {1e3, derived(-1, 1, -2, 0)},
+	"bar": {1e5, derived(-1, 1, -2, 0)},
+	"J":   {1, derived(2, 1, -2, 0)},
+	"kJ":  {1e3, derived(2, 1, -2, 0)},
+	"kWh": {3.6e6, derived(2, 1, -2, 0)},
+	"W":   {1, derived(2, 1, -3, 0)},
+	"kW":  {1e3, derived(2, 1, -3, 0)},
+	"C":   {1, derived(0, 0, 1, 1)},
+	"V":   {1, derived(2, 1, -3, -1)},
+	"L":   {1e-3, derived(3, 0, 0, 0)},
+	"mL":  {1e-6, derived(3, 0, 0, 0)},
+}
+
+// Lookup возвращает единицу измерения по обозначению.
+//
+// Args:
+//
+//	symbol: string - Обозначение единицы: km, h, N.
+//
+// Returns:
+//
+//	Unit - Единица измерения.
+//	bool - true, если единица известна.
+func Lookup(symbol string) (Unit, bool) {
+	unit, ok := table[symbol]
+	return unit, ok
+}
+
+// Parse разбирает запись составной единицы измерения: обозначения единиц, соединенные "*" и "/",
+// с необязательными целыми показателями степени: "km/h", "m/s^2", "kg*m^2/s^2". Пробелы не допускаются.
+// Деление левоассоциативно: "m/s/s" - метр на секунду в квадрате.
+//
+// Args:
+//
+//	text: string - Запись единицы.
+//
+// Returns:
+//
+//	Unit - Составная единица.
+//	error - ErrUnknownUnit, если обозначение не известно; ErrInvalidUnit, если запись неверна.
+func Parse(text string) (Unit, error) {
+	result := Unit{Scale: 1}
+	runes := []rune(text)
+	divide := false
+	for i := 0; ; {
+		start := i
+		for i < len(runes) && unicode.IsLetter(runes[i]) {
+			i++
+		}
+		if i == start {
+			return Unit{}, fmt.Errorf("%w: %s", ErrInvalidUnit, text)
+		}
+		unit, ok := Lookup(string(runes[start:i]))
+		if !ok {
+			return Unit{}, fmt.Errorf("%w: %s", ErrUnknownUnit, string(runes[start:i]))
+		}
+
+		power := 1
+		if i < len(runes) && runes[i] == '^' {
+			end := i + 1
+			if end < len(runes) && runes[end] == '-' {
+				end++
+			}
+			for end < len(runes) && unicode.IsDigit(runes[end]) {
+				end++
+			}
+			var err error
+			if power, err = strconv.Atoi(string(runes[i+1 : end])); err != nil {
+				return Unit{}, fmt.Errorf("%w: %s", ErrInvalidUnit, text)
+			}
+			i = end
+		}
+		if divide {
+			power = -power
+		}
+
+		dimension, _ := unit.Dimension.Pow(float64(power))
+		result.Scale *= math.Pow(unit.Scale, float64(power))
+		result.Dimension = result.Dimension.Mul(dimension)
+
+		if i == len(runes) {
+			return result, nil
+		}
+		if runes[i] != '*' && runes[i] != '/' {
+			return Unit{}, fmt.Errorf("%w: %s", ErrInvalidUnit, text)
+		}
+		divide = runes[i] == '/'
+		i++
+	}
+}
+
+// Rule вычисляет размерность результата операции по размерностям ее аргументов.
+//
+// Args:
+//
+//	dims: []Dimension - Размерности аргументов.
+//	values: []*float64 - Значения аргументов, известные при разборе выражения (nil для вычисляемых аргументов).
+//
+// Returns:
+//
+//	Dimension - Размерность результата.
+//	error - Ошибка (ErrMismatch, ErrExponent), если размерности аргументов недопустимы для операции.
+type Rule func(dims []Dimension, values []*float64) (Dimension, error)
+
+// Dimensionless - правило операций над безразмерными величинами (sin, ln, ...): аргументы должны быть
+// безразмерными, результат безразмерен.
+func Dimensionless(dims []Dimension, _ []*float64) (Dimension, error) {
+	for _, dim := range dims {
+		if !dim.Dimensionless() {
+			return Dimension{}, fmt.Errorf("%w: %s и 1", ErrMismatch, dim)
+		}
+	}
+	return Dimension{}, nil
+}
+
+// Same - правило операций над величинами одной размерности (+, -, abs): размерности аргументов
+// должны совпадать, результат имеет ту же размерность.
+func Same(dims []Dimension, _ []*float64) (Dimension, error) {
+	for _, dim := range dims[1:] {
+		if dim != dims[0] {
+			return Dimension{}, fmt.Errorf("%w: %s и %s", ErrMismatch, dims[0], dim)
+		}
+	}
+	return dims[0], nil
+}
+
+// Product - правило умножения: размерности аргументов перемножаются.
+func Product(dims []Dimension, _ []*float64) (Dimension, error) {
+	product := dims[0]
+	for _, dim := range dims[1:] {
+		product = product.Mul(dim)
+	}
+	return product, nil
+}
+
+// Quotient - правило деления: размерность делимого делится на размерность делителя.
+func Quotient(dims []Dimension, _ []*float64) (Dimension, error) {
+	return dims[0].Div(dims[1]), nil
+}
+
+// Power - правило возведения в степень. Показатель должен быть безразмерным. Размерная величина
+// возводится только в известную при разборе степень, дающую целые степени единиц: (2 m)^2, (4 m^2)^0.5.
+func Power(dims []Dimension, values []*float64) (Dimension, error) {
+	if !dims[1].Dimensionless() {
+		return Dimension{}, fmt.Errorf("%w: %s и 1", ErrMismatch, dims[1])
+	}
+	if dims[0].Dimensionless() {
+		return Dimension{}, nil
+	}
+	if values[1] == nil {
+		return Dimension{}, ErrExponent
+	}
+	dim, ok := dims[0].Pow(*values[1])
+	if !ok {
+		return Dimension{}, ErrExponent
+	}
+	return dim, nil
+}
+
+// Root - правило квадратного корня: степени единиц делятся пополам и должны остаться целыми.
+func Root(dims []Dimension, _ []*float64) (Dimension, error) {
+	dim, ok := dims[0].Pow(0.5)
+	if !ok {
+		return Dimension{}, ErrExponent
+	}
+	return dim, nil
+}
+
+// Compare - правило сравнения (<, ==, ...): размерности аргументов должны совпадать, результат
+// (1 или 0) безразмерен.
+func Compare(dims []Dimension, _ []*float64) (Dimension, error) {
+	if _, err := Same(dims, nil); err != nil {
+		return Dimension{}, err
+	}
+	return Dimension{}, nil
+}
+
+// Select - правило выбора значения по условию if(c, a, b): условие безразмерно, значения должны иметь
+// одну размерность, результат имеет ту же размерность.
+func Select(dims []Dimension, _ []*float64) (Dimension, error) {
+	if _, err := Dimensionless(dims[:1], nil); err != nil {
+		return Dimension{}, err
+	}
+	return Same(dims[1:], nil)
+}
@@ -0,0 +1,139 @@
+package units_test
+
+import (
+	"testing"
+
+	"github.com/OinkiePie/calc_3/pkg/units"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		scale     float64
+		dimension string
+		err       error
+	}{
+		{name: "base unit", text: "m", scale: 1, dimension: "m"},
+		{name: "prefixed unit", text: "km", scale: 1000, dimension: "m"},
+		{name: "quotient", text: "km/h", scale: 1000.0 / 3600, dimension: "m/s"},
+		{name: "power", text: "m/s^2", scale: 1, dimension: "m/s^2"},
+		{name: "left associative division", text: "m/s/s", scale: 1, dimension: "m/s^2"},
+		{name: "negative power", text: "m*s^-1", scale: 1, dimension: "m/s"},
+		{name: "derived unit", text: "kWh", scale: 3.6e6, dimension: "m^2*kg/s^2"},
+		{name: "product with derived unit", text: "N*m", scale: 1, dimension: "m^2*kg/s^2"},
+		{name: "unknown unit", text: "km/parsec", err: units.ErrUnknownUnit},
+		{name: "case sensitive", text: "M", err: units.ErrUnknownUnit},
+		{name: "missing unit after operator", text: "m/", err: units.ErrInvalidUnit},
+		{name: "missing power", text: "m^", err: units.ErrInvalidUnit},
+		{name: "spaces are not allowed", text: "m / s", err: units.ErrInvalidUnit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit, err := units.Parse(tt.text)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.InDelta(t, tt.scale, unit.Scale, 1e-12*tt.scale)
+				assert.Equal(t, tt.dimension, unit.Dimension.String())
+			}
+		})
+	}
+}
+
+func TestDimension(t *testing.T) {
+	velocity := units.Length.Div(units.Time)
+
+	t.Run("string", func(t *testing.T) {
+		assert.Equal(t, "1", units.Dimension{}.String())
+		assert.Equal(t, "1/s", units.Dimension{}.Div(units.Time).String())
+		assert.Equal(t, "m/s", velocity.String())
+	})
+
+	t.Run("integer powers only", func(t *testing.T) {
+		area := units.Length.Mul(units.Length)
+		side, ok := area.Pow(0.5)
+		assert.True(t, ok)
+		assert.Equal(t, units.Length, side)
+
+		_, ok = units.Length.Pow(0.5)
+		assert.False(t, ok)
+	})
+}
+
+func TestRules(t *testing.T) {
+	two := 2.0
+	half := 0.5
+
+	t.Run("same", func(t *testing.T) {
+		dim, err := units.Same([]units.Dimension{units.Length, units.Length}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, units.Length, dim)
+
+		_, err = units.Same([]units.Dimension{units.Length, units.Time}, nil)
+		assert.ErrorIs(t, err, units.ErrMismatch)
+	})
+
+	t.Run("dimensionless", func(t *testing.T) {
+		_, err := units.Dimensionless([]units.Dimension{{}}, nil)
+		assert.NoError(t, err)
+
+		_, err = units.Dimensionless([]units.Dimension{units.Length}, nil)
+		assert.ErrorIs(t, err, units.ErrMismatch)
+	})
+
+	t.Run("product", func(t *testing.T) {
+		dim, err := units.Product([]units.Dimension{units.Length, units.Mass, units.Length}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "m^2*kg", dim.String())
+	})
+
+	t.Run("power", func(t *testing.T) {
+		dim, err := units.Power([]units.Dimension{units.Length, {}}, []*float64{nil, &two})
+		assert.NoError(t, err)
+		assert.Equal(t, "m^2", dim.String())
+
+		dim, err = units.Power([]units.Dimension{{}, {}}, []*float64{nil, nil})
+		assert.NoError(t, err)
+		assert.True(t, dim.Dimensionless())
+
+		_, err = units.Power([]units.Dimension{units.Length, {}}, []*float64{nil, nil})
+		assert.ErrorIs(t, err, units.ErrExponent)
+
+		_, err = units.Power([]units.Dimension{units.Length, {}}, []*float64{nil, &half})
+		assert.ErrorIs(t, err, units.ErrExponent)
+
+		_, err = units.Power([]units.Dimension{{}, units.Length}, []*float64{nil, &two})
+		assert.ErrorIs(t, err, units.ErrMismatch)
+	})
+
+	t.Run("compare", func(t *testing.T) {
+		dim, err := units.Compare([]units.Dimension{units.Length, units.Length}, nil)
+		assert.NoError(t, err)
+		assert.True(t, dim.Dimensionless())
+
+		_, err = units.Compare([]units.Dimension{units.Length, units.Time}, nil)
+		assert.ErrorIs(t, err, units.ErrMismatch)
+	})
+
+	t.Run("select", func(t *testing.T) {
+		dim, err := units.Select([]units.Dimension{{}, units.Length, units.Length}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, units.Length, dim)
+
+		_, err = units.Select([]units.Dimension{{}, units.Length, units.Time}, nil)
+		assert.ErrorIs(t, err, units.ErrMismatch)
+
+		_, err = units.Select([]units.Dimension{units.Length, {}, {}}, nil)
+		assert.ErrorIs(t, err, units.ErrMismatch)
+	})
+
+	t.Run("root", func(t *testing.T) {
+		_, err := units.Root([]units.Dimension{units.Length}, nil)
+		assert.ErrorIs(t, err, units.ErrExponent)
+	})
+}